@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/api"
+	"vehicles-service-stations/internal/api/stationspb"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/realtime"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: api serve|watch [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "serve":
+		os.Exit(runServe(os.Args[2:]))
+	case "watch":
+		os.Exit(runWatch(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+// connect returns the connection manager with the pool of the listener of
+// the realtime events and the config employees log in with.
+func connect(ctx context.Context) (*db.ConnectionManager, *db.Config, bool) {
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	poolCfg.MaxConns = 1
+
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "listener", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return nil, nil, false
+	}
+	return connManager, cfg.WithCredentials("", ""), true
+}
+
+// runServe serves the API. Employees call it with their own database login
+// and password, row level security applies to them as in the console.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8500", "Address to listen on")
+	certFile := fs.String("tls-cert", "", "TLS certificate, the API is served in plaintext without it")
+	keyFile := fs.String("tls-key", "", "TLS key")
+	maxConns := fs.Int("employee-conns", 4, "Max database connections per employee")
+	fs.Parse(args)
+
+	var opts []grpc.ServerOption
+	if *certFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(*certFile, *keyFile)
+		if err != nil {
+			log.Printf("Invalid TLS certificate: %v", err)
+			return 2
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager, cfg, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	hub := realtime.NewHub(connManager, "listener")
+	go hub.Run(ctx)
+	auth := api.NewPoolAuthenticator(cfg, connManager, int32(*maxConns))
+	server := api.NewGRPCServer(api.NewServer(auth, hub), opts...)
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Printf("Failed to listen: %v", err)
+		return 1
+	}
+	go func() {
+		<-ctx.Done()
+		// Watchers never finish on their own.
+		timer := time.AfterFunc(10*time.Second, server.Stop)
+		defer timer.Stop()
+		server.GracefulStop()
+	}()
+
+	log.Printf("API listening on %s", *addr)
+	if err := server.Serve(lis); err != nil {
+		log.Printf("API failed: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// runWatch prints the changes of orders as JSON lines, the password is taken
+// from API_PASSWORD.
+func runWatch(args []string) int {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8500", "Address of the API")
+	user := fs.String("user", os.Getenv("USER"), "Login of the employee")
+	caFile := fs.String("tls-ca", "", "CA of the API certificate, plaintext without it")
+	var req stationspb.WatchOrdersRequest
+	fs.Int64Var(&req.ServiceCenterId, "center", 0, "Service center to watch")
+	fs.Int64Var(&req.MasterId, "master", 0, "Master to watch")
+	fs.Parse(args)
+
+	auth := api.BasicAuth{Login: *user, Password: os.Getenv("API_PASSWORD"), Insecure: *caFile == ""}
+	transport := insecure.NewCredentials()
+	if *caFile != "" {
+		var err error
+		if transport, err = credentials.NewClientTLSFromFile(*caFile, ""); err != nil {
+			log.Printf("Invalid TLS CA: %v", err)
+			return 2
+		}
+	}
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(transport), grpc.WithPerRPCCredentials(auth))
+	if err != nil {
+		log.Printf("Failed to connect: %v", err)
+		return 1
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stream, err := stationspb.NewStationsClient(conn).WatchOrders(ctx, &req)
+	if err != nil {
+		log.Printf("Watch failed: %v", err)
+		return 1
+	}
+	for {
+		event, err := stream.Recv()
+		if ctx.Err() != nil {
+			return 0
+		}
+		if err != nil {
+			log.Printf("Watch failed: %v", err)
+			return 1
+		}
+		line, err := protojson.Marshal(event)
+		if err != nil {
+			log.Printf("Watch failed: %v", err)
+			return 1
+		}
+		fmt.Println(string(line))
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/audit"
+	"vehicles-service-stations/internal/db"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run answers questions like "who changed the master of order 123 and when":
+//
+//	audit -table orders -id 123 -column assigned_master_id
+func run() int {
+	var f audit.Filter
+	var since, until string
+	flag.StringVar(&f.Table, "table", "", "Audited table: "+strings.Join(audit.Tables, ", "))
+	flag.StringVar(&f.RowID, "id", "", "Primary key of the row, requires -table")
+	flag.StringVar(&f.Column, "column", "", "Only changes of this column")
+	flag.StringVar(&f.Actor, "actor", "", "Only changes made by this login")
+	flag.StringVar(&f.RequestID, "request", "", "Only changes of this request ID")
+	flag.StringVar(&since, "since", "", "Only changes at or after this time (RFC 3339 or YYYY-MM-DD)")
+	flag.StringVar(&until, "until", "", "Only changes before this time (RFC 3339 or YYYY-MM-DD)")
+	flag.Uint64Var(&f.Limit, "limit", 50, "Max entries listed, newest first, all if 0")
+	asJSON := flag.Bool("json", false, "Print the entries as JSON")
+	flag.Parse()
+
+	var err error
+	if f.Since, err = parseTime(since); err != nil {
+		log.Printf("Invalid -since: %v", err)
+		return 2
+	}
+	if f.Until, err = parseTime(until); err != nil {
+		log.Printf("Invalid -until: %v", err)
+		return 2
+	}
+	if err := f.Validate(); err != nil {
+		log.Printf("Invalid filter: %v", err)
+		return 2
+	}
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	entries, err := audit.History(ctx, connManager.GetPool("superuser"), f)
+	if err != nil {
+		log.Printf("Failed to read audit log: %v", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			log.Printf("Failed to write entries: %v", err)
+			return 1
+		}
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTOR\tEMPLOYEE\tREQUEST\tOPERATION\tROW\tCHANGES")
+	for _, e := range entries {
+		employee := "-"
+		if e.EmployeeID != nil {
+			employee = fmt.Sprint(*e.EmployeeID)
+		}
+		request := e.RequestID
+		if request == "" {
+			request = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s:%s\t%s\n",
+			e.OccurredAt.Local().Format(time.DateTime), e.Actor, employee, request,
+			e.Operation, e.Table, e.RowID, changes(e, f.Column))
+	}
+	if err := w.Flush(); err != nil {
+		log.Printf("Failed to write entries: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// changes describes the update as column: before -> after, inserts and
+// deletes only show the selected column.
+func changes(e *audit.Entry, column string) string {
+	columns := e.Changed
+	if column != "" {
+		columns = []string{column}
+	} else if e.Operation != "UPDATE" {
+		return fmt.Sprintf("%d columns", len(e.Changed))
+	}
+
+	parts := make([]string, 0, len(columns))
+	for _, c := range columns {
+		before, after := e.Change(c)
+		parts = append(parts, fmt.Sprintf("%s: %s -> %s", c, value(before, e.Old != nil), value(after, e.New != nil)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func value(v any, present bool) string {
+	switch {
+	case !present:
+		return "-"
+	case v == nil:
+		return "NULL"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
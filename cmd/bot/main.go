@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/bot"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/telegram"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: bot run|chat [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "run":
+		os.Exit(runBot(os.Args[2:]))
+	case "chat":
+		os.Exit(runChat(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func connect(ctx context.Context) (*db.ConnectionManager, *pgxpool.Pool, bool) {
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return nil, nil, false
+	}
+	return connManager, connManager.GetPool("superuser"), true
+}
+
+// runBot serves the bot, the token is read from TELEGRAM_BOT_TOKEN. It should
+// be the token "notify run" sends Telegram notifications with, so they reach
+// the chats the bot linked.
+func runBot(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	apiURL := fs.String("api", telegram.DefaultAPI, "Telegram Bot API address")
+	var opts bot.Options
+	fs.DurationVar(&opts.PollTimeout, "poll-timeout", 0, "Long polling timeout, 30s when 0")
+	fs.IntVar(&opts.Days, "days", 14, "Days ahead free slots are offered")
+	fs.Parse(args)
+
+	api, err := telegram.NewClient(*apiURL, os.Getenv("TELEGRAM_BOT_TOKEN"))
+	if err != nil {
+		log.Printf("Invalid bot: %v", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	opts.OnError = func(err error) { log.Printf("Bot failed: %v", err) }
+	log.Printf("Polling %s for updates", *apiURL)
+	if err := bot.New(api, bot.NewStore(pool), opts).Run(ctx); err != nil {
+		log.Printf("Bot failed: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// runChat talks to the bot from the terminal through an in-process fake Bot
+// API. Bookings are written to the database like real ones.
+func runChat(args []string) int {
+	fs := flag.NewFlagSet("chat", flag.ExitOnError)
+	userID := fs.Int64("user", 100, "Telegram user id, also the chat id")
+	name := fs.String("name", "Local", "First name of the user")
+	var opts bot.Options
+	fs.IntVar(&opts.Days, "days", 14, "Days ahead free slots are offered")
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	fake := telegram.NewFakeServer("chat")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	api, err := telegram.NewClient(server.URL, fake.Token)
+	if err != nil {
+		log.Printf("Invalid bot: %v", err)
+		return 1
+	}
+
+	// last is the latest message with buttons, /press refers to its buttons.
+	var mu sync.Mutex
+	var last *telegram.Message
+	show := func(prefix string, m telegram.Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Printf("%s %s\n", prefix, strings.ReplaceAll(m.Text, "\n", "\n  "))
+		if m.ReplyMarkup == nil {
+			return
+		}
+		n := 0
+		for _, row := range m.ReplyMarkup.InlineKeyboard {
+			for _, btn := range row {
+				n++
+				fmt.Printf("  [%d] %s\n", n, btn.Text)
+			}
+		}
+		last = &m
+	}
+	fake.OnSend = func(m telegram.Message) { show("bot:", m) }
+	fake.OnEdit = func(m telegram.Message) { show("bot (edited):", m) }
+
+	opts.PollTimeout = time.Second
+	opts.OnError = func(err error) { log.Printf("Bot failed: %v", err) }
+	b := bot.New(api, bot.NewStore(pool), opts)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Run(ctx)
+	}()
+
+	user := telegram.User{ID: *userID, FirstName: *name}
+	fmt.Println("Type messages to the bot, /contact +79990000000 to share a phone number, /press N to press a button, Ctrl+D to quit.")
+	fake.SendText(user, "/start")
+
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	for {
+		var line string
+		var ok bool
+		select {
+		case <-ctx.Done():
+		case line, ok = <-lines:
+		}
+		if !ok {
+			break
+		}
+
+		line = strings.TrimSpace(line)
+		command, arg, _ := strings.Cut(line, " ")
+		switch command {
+		case "":
+		case "/contact":
+			fake.SendContact(user, arg, user.ID)
+		case "/press":
+			mu.Lock()
+			msg := last
+			mu.Unlock()
+			n, _ := strconv.Atoi(arg)
+			btn, found := button(msg, n)
+			if !found {
+				fmt.Println("No such button")
+				continue
+			}
+			fake.Press(user, *msg, btn.CallbackData)
+		default:
+			fake.SendText(user, line)
+		}
+	}
+
+	stop()
+	<-done
+	return 0
+}
+
+// button returns the n-th button of a message, counting from 1.
+func button(msg *telegram.Message, n int) (telegram.InlineKeyboardButton, bool) {
+	if msg == nil || msg.ReplyMarkup == nil {
+		return telegram.InlineKeyboardButton{}, false
+	}
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		for _, btn := range row {
+			if n--; n == 0 {
+				return btn, true
+			}
+		}
+	}
+	return telegram.InlineKeyboardButton{}, false
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/cdc"
+	"vehicles-service-stations/internal/db"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: cdc stream|broker [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "stream":
+		os.Exit(runStream(os.Args[2:]))
+	case "broker":
+		runBroker(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+// runStream streams order, receipt and stock events until interrupted. It is
+// safe to restart at any time, delivery resumes after the checkpoint.
+func runStream(args []string) int {
+	fs := flag.NewFlagSet("stream", flag.ExitOnError)
+	var opts cdc.Options
+	var sinkCfg cdc.SinkConfig
+	fs.StringVar(&opts.Slot, "slot", cdc.DefaultSlot, "Logical replication slot")
+	fs.StringVar(&opts.Publication, "publication", cdc.DefaultPublication, "Publication of the domain tables")
+	fs.BoolVar(&opts.CreateSlot, "create-slot", true, "Create the slot if it does not exist")
+	fs.IntVar(&opts.BatchSize, "batch", 100, "Events buffered before they are written")
+	fs.DurationVar(&opts.StatusInterval, "status-interval", 0, "Max time events stay buffered and between status updates (default 10s)")
+	sinkKind := fs.String("sink", string(cdc.SinkStdout), "Where to deliver events: stdout, file or broker")
+	fs.StringVar(&sinkCfg.Path, "file", "cdc.jsonl", "JSON lines file of the file sink")
+	fs.StringVar(&sinkCfg.BrokerAddr, "broker-addr", "http://127.0.0.1:8300", "Address of the broker sink")
+	checkpointPath := fs.String("checkpoint", "cdc.checkpoint", "File keeping the last delivered position")
+	fs.Parse(args)
+	sinkCfg.Kind = cdc.SinkKind(*sinkKind)
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	sink, err := cdc.NewSink(sinkCfg)
+	if err != nil {
+		log.Printf("Invalid sink: %v", err)
+		return 1
+	}
+	defer func() {
+		if err := sink.Close(); err != nil {
+			log.Printf("Failed to close %s: %v", sink.Name(), err)
+		}
+	}()
+
+	// Events go to stdout for the stdout sink, progress always goes to stderr.
+	opts.OnFlush = func(confirmed cdc.LSN, events int) {
+		log.Printf("Delivered %d events to %s, confirmed %s", events, sink.Name(), confirmed)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Streaming %s from slot %s to %s", opts.Publication, opts.Slot, sink.Name())
+	if err := cdc.Run(ctx, cfg, sink, cdc.NewFileCheckpoint(*checkpointPath), opts); err != nil {
+		log.Printf("Stream failed: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func runBroker(args []string) {
+	fs := flag.NewFlagSet("broker", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8300", "Address to listen on")
+	fs.Parse(args)
+
+	log.Printf("Broker stand-in listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, cdc.NewBrokerServer()); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/check"
+	"vehicles-service-stations/internal/db"
+)
+
+var (
+	repair      bool
+	only        string
+	sampleLimit int
+	outPath     string
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	flag.BoolVar(&repair, "repair", false, "Repair repairable violations in one transaction")
+	flag.StringVar(&only, "only", "", "Comma separated invariants to check, all if empty")
+	flag.IntVar(&sampleLimit, "limit", 20, "Max violations listed per invariant")
+	flag.StringVar(&outPath, "out", "", "Write the JSON report to this file instead of stdout")
+	flag.Parse()
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	opts := check.Options{Repair: repair, SampleLimit: sampleLimit}
+	if only != "" {
+		opts.Only = strings.Split(only, ",")
+	}
+
+	report, err := check.Run(ctx, connManager.GetPool("superuser"), opts)
+	if err != nil {
+		log.Printf("Check failed: %v", err)
+		return 1
+	}
+
+	out := os.Stdout
+	if outPath != "" {
+		out, err = os.OpenFile(outPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+		if err != nil {
+			log.Printf("Cant create report file: %v", err)
+			return 1
+		}
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Printf("Cant write report: %v", err)
+		return 1
+	}
+	if out != os.Stdout {
+		if err := out.Close(); err != nil {
+			log.Printf("Cant write report: %v", err)
+			return 1
+		}
+	}
+
+	if !report.Clean() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/db"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run starts the console. Employees log in with their own database role, the
+// one create_user() made for them, so row level security applies to them.
+func run() int {
+	user := flag.String("user", os.Getenv("USER"), "Login of the employee")
+	flag.Parse()
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	// The superuser is replaced by the employee, it is never used.
+	cfg, err := db.NewConfig(envCfg, "", "")
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	connect := func(ctx context.Context, user, password string) (*pgxpool.Pool, error) {
+		poolCfg, err := cfg.WithCredentials(user, password).PoolConfig()
+		if err != nil {
+			return nil, err
+		}
+		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect: %w", err)
+		}
+		if err := pool.Ping(ctx); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to log in as %s: %w", user, err)
+		}
+		return pool, nil
+	}
+
+	model := console.New(context.Background(), connect, *user)
+	defer model.Close()
+	if _, err := tea.NewProgram(model, tea.WithAltScreen()).Run(); err != nil {
+		log.Printf("Console failed: %v", err)
+		return 1
+	}
+	return 0
+}
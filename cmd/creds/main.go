@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"vehicles-service-stations/internal/creds"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: creds show|kv-server [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "show":
+		runShow(os.Args[2:])
+	case "kv-server":
+		runKVServer(os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func runShow(args []string) {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	path := fs.String("file", "creds.enc", "Encrypted credentials file written by data-mock")
+	role := fs.String("role", "", "Only show users with this role")
+	fs.Parse(args)
+
+	passphrase := os.Getenv("CREDS_PASSPHRASE")
+	if passphrase == "" {
+		log.Fatal("CREDS_PASSPHRASE is not set")
+	}
+
+	users, err := creds.ReadFile(*path, passphrase)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *role != "" {
+		filtered := users[:0]
+		for _, u := range users {
+			if u.Role == *role {
+				filtered = append(filtered, u)
+			}
+		}
+		users = filtered
+	}
+
+	sink := &creds.StdoutSink{W: os.Stdout}
+	if err := sink.Write(context.Background(), users); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runKVServer(args []string) {
+	fs := flag.NewFlagSet("kv-server", flag.ExitOnError)
+	addr := fs.String("addr", "127.0.0.1:8200", "Address to listen on")
+	fs.Parse(args)
+
+	token := os.Getenv("KV_TOKEN")
+	if token == "" {
+		log.Fatal("KV_TOKEN is not set")
+	}
+
+	log.Printf("KV stand-in listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, creds.NewKVServer(token)); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/dashboard"
+	"vehicles-service-stations/internal/db"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run serves the dashboard. Employees log in with their own database role,
+// so the views and policies decide what every one of them sees.
+func run() int {
+	addr := flag.String("addr", "127.0.0.1:8400", "Address to listen on")
+	idle := flag.Duration("idle-timeout", 30*time.Minute, "Log out employees idle for this long")
+	lowStock := flag.Int("low-stock", 5, "Default stock quantity parts are reported below")
+	days := flag.Int("days", 30, "Default revenue period in days")
+	flag.Parse()
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	// The superuser is replaced by the employee, it is never used.
+	cfg, err := db.NewConfig(envCfg, "", "")
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	connect := func(ctx context.Context, user, password string) (*pgxpool.Pool, error) {
+		poolCfg, err := cfg.WithCredentials(user, password).PoolConfig()
+		if err != nil {
+			return nil, err
+		}
+		poolCfg.MaxConns = 2
+		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect: %w", err)
+		}
+		if err := pool.Ping(ctx); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to log in as %s: %w", user, err)
+		}
+		return pool, nil
+	}
+
+	handler, err := dashboard.NewServer(connect, dashboard.Options{IdleTimeout: *idle, LowStock: *lowStock, Days: *days})
+	if err != nil {
+		log.Printf("Failed to create the dashboard: %v", err)
+		return 1
+	}
+	defer handler.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: *addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Dashboard listening on %s", *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Dashboard failed: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -2,17 +2,17 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
-	"fmt"
 	"log"
 	"os"
 	"sync"
 	"time"
 	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/creds"
 	"vehicles-service-stations/internal/db"
 	"vehicles-service-stations/internal/gomock"
 	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/utils"
 )
 
 var (
@@ -22,6 +22,13 @@ var (
 	ordersCount           int
 	customersCount        int
 	serviceCentersCount   int
+	distributionsPath     string
+	historyDays           int
+	credsSink             string
+	credsFile             string
+	credsKVAddr           string
+	credsKVMount          string
+	credsSample           int
 )
 
 func main() {
@@ -31,13 +38,47 @@ func main() {
 	flag.IntVar(&ordersCount, "oc", 200, "Number of additional orders to create")
 	flag.IntVar(&customersCount, "cc", 100, "Number of additional customers to create")
 	flag.IntVar(&serviceCentersCount, "sc", 50, "Number of additional service centers to create")
+	flag.IntVar(&historyDays, "history-days", 0, "Simulate this many days of order history instead of random orders")
+	flag.StringVar(&distributionsPath, "distributions", "", "Path to a yaml/json file with picking distributions, uniform if empty")
+	flag.StringVar(&credsSink, "creds-sink", "file", "Where to store employees' credentials: file, kv, stdout or none")
+	flag.StringVar(&credsFile, "creds-file", "creds.enc", "Encrypted credentials file, the passphrase is read from CREDS_PASSPHRASE")
+	flag.StringVar(&credsKVAddr, "creds-kv-addr", "http://127.0.0.1:8200", "KV address, the token is read from KV_TOKEN")
+	flag.StringVar(&credsKVMount, "creds-kv-mount", "secret", "KV engine mount")
+	flag.IntVar(&credsSample, "creds-sample", 0, "Only store this many random users per role, all if 0")
 	flag.Parse()
 
+	// The sink is validated before anything is generated, so a missing
+	// passphrase does not leave a populated database without credentials.
+	// Without new employees there is nothing to store and no secret needed.
+	sinkKind := creds.SinkKind(credsSink)
+	if skipEmployeesCreation {
+		sinkKind = creds.SinkNone
+	}
+	sink, err := creds.NewSink(creds.SinkConfig{
+		Kind:       sinkKind,
+		Path:       credsFile,
+		Passphrase: os.Getenv("CREDS_PASSPHRASE"),
+		KVAddr:     credsKVAddr,
+		KVToken:    os.Getenv("KV_TOKEN"),
+		KVMount:    credsKVMount,
+	})
+	if err != nil {
+		log.Fatalf("Ошибка настройки хранилища учётных данных: %v", err)
+	}
+
 	env_cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Ошибка создания конфигурации: %v", err)
 		panic("error during cfg loading")
 	}
+	dists := utils.DefaultDistributionConfig()
+	if distributionsPath != "" {
+		dists, err = utils.LoadDistributionConfig(distributionsPath)
+		if err != nil {
+			log.Fatalf("Ошибка загрузки распределений: %v", err)
+		}
+	}
+
 	cfg, err := db.NewConfig(
 		env_cfg,
 		env_cfg.DbSuperuser,
@@ -47,8 +88,15 @@ func main() {
 		log.Fatalf("Ошибка создания конфигурации: %v", err)
 	}
 
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Fatalf("Ошибка создания конфигурации: %v", err)
+	}
+
 	connManager := db.NewConnectionManager()
-	connManager.AddPool(context.Background(), "superuser", cfg.ConnectionString())
+	if err := connManager.AddPoolWithConfig(context.Background(), "superuser", poolCfg); err != nil {
+		log.Fatalf("Ошибка подключения к базе: %v", err)
+	}
 
 	defer connManager.CloseAll()
 
@@ -66,16 +114,14 @@ func main() {
 	}
 	log.Println("Init admin done")
 
-	cfg, err = db.NewConfig(
-		env_cfg,
-		"admin_user",
-		"StrongPassword123!",
-	)
+	poolCfg, err = cfg.WithCredentials("admin_user", "StrongPassword123!").PoolConfig()
 	if err != nil {
 		log.Fatalf("Ошибка создания конфигурации: %v", err)
 	}
 
-	connManager.AddPool(context.Background(), "admin", cfg.ConnectionString())
+	if err := connManager.AddPoolWithConfig(context.Background(), "admin", poolCfg); err != nil {
+		log.Fatalf("Ошибка подключения к базе: %v", err)
+	}
 
 	var wg sync.WaitGroup
 	errCh := make(chan error, 5)
@@ -144,26 +190,11 @@ func main() {
 		}
 		log.Println("Creating employees...")
 		if !skipEmployeesCreation {
-			if err := gomock.CreateEmployees(ctx, connManager.GetPool("admin"), employeesCount, &users); err != nil {
+			if err := gomock.CreateEmployees(ctx, connManager.GetPool("admin"), employeesCount, &users, dists); err != nil {
 				errCh <- err
 			}
 		}
 		log.Println("Creating employees done")
-		log.Println("Saving users cred to file...")
-		file, err := os.Create("/tmp/creds.json")
-		if err != nil {
-			errCh <- fmt.Errorf("error while creation db users cred file")
-		}
-		encoder := json.NewEncoder(file)
-		if err := encoder.Encode(users); err != nil {
-			errCh <- fmt.Errorf("error while users creds serialization")
-		}
-		defer func() {
-			if cerr := file.Close(); cerr != nil {
-				errCh <- fmt.Errorf("error while file closing")
-			}
-		}()
-		log.Println("Saving users cred to file done")
 	}()
 
 	wg.Add(1)
@@ -191,15 +222,32 @@ func main() {
 		log.Fatalf("Err while executing mock funcs %v", err)
 	}
 	wg.Wait()
-	log.Println("Creating orders...")
-	if err := gomock.CreateOrders(ctx, connManager.GetPool("superuser"), ordersCount, &gomock.Pair[float64]{First: 500.0, Second: 500000.0}, 5, 2); err != nil {
-		log.Fatalf("Err while executing creation of orders mock func %v", err)
+
+	if sink != nil && len(users) > 0 {
+		log.Printf("Saving users credentials to %s...", sink.Name())
+		if err := sink.Write(context.Background(), creds.Sample(users, credsSample)); err != nil {
+			log.Fatalf("Err while saving users credentials %v", err)
+		}
+		log.Println("Saving users credentials done")
 	}
-	log.Println("Creating orders done")
-	log.Println("Creating receipts for completed orders...")
-	if err := gomock.CreateReceipts(ctx, connManager.GetPool("superuser")); err != nil {
-		log.Fatalf("Err while executing creation of receipts mock func %v", err)
+	if historyDays > 0 {
+		log.Println("Simulating order history...")
+		simCfg := gomock.DefaultSimulationConfig(historyDays)
+		if err := gomock.SimulateOrderHistory(context.Background(), connManager.GetPool("superuser"), simCfg, dists); err != nil {
+			log.Fatalf("Err while simulating order history %v", err)
+		}
+		log.Println("Simulating order history done")
+	} else {
+		log.Println("Creating orders...")
+		if err := gomock.CreateOrders(ctx, connManager.GetPool("superuser"), ordersCount, &gomock.Pair[float64]{First: 500.0, Second: 500000.0}, 5, 2, dists); err != nil {
+			log.Fatalf("Err while executing creation of orders mock func %v", err)
+		}
+		log.Println("Creating orders done")
+		log.Println("Creating receipts for completed orders...")
+		if err := gomock.CreateReceipts(ctx, connManager.GetPool("superuser")); err != nil {
+			log.Fatalf("Err while executing creation of receipts mock func %v", err)
+		}
+		log.Println("Creating receipts done")
 	}
-	log.Println("Creating receipts done")
 	log.Println("No problem found, the end")
 }
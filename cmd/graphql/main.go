@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/api"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/graph"
+	"vehicles-service-stations/internal/model"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run serves the GraphQL endpoint. The superuser only reads the catalog the
+// schema is checked against, employees query with their own login.
+func run() int {
+	addr := flag.String("addr", "127.0.0.1:8600", "Address to listen on")
+	maxConns := flag.Int("employee-conns", 4, "Max database connections per employee")
+	flag.Parse()
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager := db.NewConnectionManager()
+	defer connManager.CloseAll()
+	if err := connManager.AddPool(ctx, "catalog", cfg.ConnectionString()); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return 1
+	}
+	catalog := model.NewAllowedTables()
+	err = catalog.Initialize(ctx, connManager.GetPool("catalog"), "public")
+	connManager.RemovePool("catalog")
+	if err != nil {
+		log.Printf("Failed to load the catalog: %v", err)
+		return 1
+	}
+	schema, err := graph.NewSchema(catalog)
+	if err != nil {
+		log.Printf("The domain types do not match the database: %v", err)
+		return 1
+	}
+
+	auth := api.NewPoolAuthenticator(cfg.WithCredentials("", ""), connManager, int32(*maxConns))
+	mux := http.NewServeMux()
+	mux.Handle("/graphql", graph.NewHandler(schema, auth))
+	server := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("GraphQL listening on %s/graphql", *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("GraphQL failed: %v", err)
+		return 1
+	}
+	return 0
+}
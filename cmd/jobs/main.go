@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/jobs"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: jobs serve|run|list|history [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "serve":
+		os.Exit(runServe(os.Args[2:]))
+	case "run":
+		os.Exit(runRun(os.Args[2:]))
+	case "list":
+		os.Exit(runList(os.Args[2:]))
+	case "history":
+		os.Exit(runHistory(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func connect(ctx context.Context) (*db.ConnectionManager, *pgxpool.Pool, bool) {
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return nil, nil, false
+	}
+	return connManager, connManager.GetPool("superuser"), true
+}
+
+func builtinFlags(fs *flag.FlagSet) *jobs.BuiltinOptions {
+	var opts jobs.BuiltinOptions
+	fs.StringVar(&opts.ReportsDir, "reports", "reports", "Directory daily_report writes to")
+	fs.IntVar(&opts.LowStock, "low-stock", 5, "Stock quantity parts are reported below")
+	return &opts
+}
+
+func logRun(run jobs.Run) {
+	if run.Status == jobs.StatusFailed {
+		log.Printf("Job %s failed after %s: %s", run.Job, run.Duration.Round(time.Millisecond), run.Error)
+		return
+	}
+	log.Printf("Job %s succeeded in %s %s", run.Job, run.Duration.Round(time.Millisecond), run.Output)
+}
+
+// runServe runs the jobs on their schedules until interrupted. Several
+// instances may serve at once, every slot of a job runs on one of them.
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	builtin := builtinFlags(fs)
+	instance := fs.String("instance", "", "Name of this instance in the history, host:pid if empty")
+	fs.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	scheduler, err := jobs.NewScheduler(pool, jobs.Builtin(*builtin), jobs.Options{
+		Instance: *instance,
+		OnRun:    logRun,
+		OnSkip: func(job string, err error) {
+			log.Printf("Job %s skipped: %v", job, err)
+		},
+		OnError: func(job string, err error) {
+			log.Printf("Job %s could not run: %v", job, err)
+		},
+	})
+	if err != nil {
+		log.Printf("Invalid jobs: %v", err)
+		return 2
+	}
+
+	now := time.Now()
+	for _, job := range scheduler.Jobs() {
+		log.Printf("Job %s (%s) is next due at %s", job.Name, job.Schedule, scheduler.Next(job.Name, now).Format(time.RFC3339))
+	}
+	scheduler.Run(ctx)
+	return 0
+}
+
+func runRun(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	builtin := builtinFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Printf("usage: jobs run [flags] <job>")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	scheduler, err := jobs.NewScheduler(pool, jobs.Builtin(*builtin), jobs.Options{})
+	if err != nil {
+		log.Printf("Invalid jobs: %v", err)
+		return 2
+	}
+	run, err := scheduler.RunNow(ctx, fs.Arg(0))
+	if errors.Is(err, jobs.ErrLocked) {
+		log.Printf("Job %s not run: %v", fs.Arg(0), err)
+		return 1
+	}
+	if err != nil {
+		log.Printf("Job %s could not run: %v", fs.Arg(0), err)
+		return 1
+	}
+	logRun(*run)
+	if run.Status == jobs.StatusFailed {
+		return 1
+	}
+	return 0
+}
+
+func runList(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	fs.Parse(args)
+
+	scheduler, err := jobs.NewScheduler(nil, jobs.Builtin(jobs.BuiltinOptions{}), jobs.Options{})
+	if err != nil {
+		log.Printf("Invalid jobs: %v", err)
+		return 2
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "JOB\tSCHEDULE\tNEXT")
+	for _, job := range scheduler.Jobs() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", job.Name, job.Schedule, scheduler.Next(job.Name, now).Format(time.RFC3339))
+	}
+	w.Flush()
+	return 0
+}
+
+func runHistory(args []string) int {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	job := fs.String("job", "", "Job to list the runs of, all if empty")
+	limit := fs.Int("limit", 20, "Max runs listed")
+	output := fs.Bool("output", false, "Print the outputs")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	runs, err := jobs.History(ctx, pool, *job, *limit)
+	if err != nil {
+		log.Printf("Failed to list runs: %v", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tJOB\tSCHEDULED FOR\tSTARTED AT\tDURATION\tSTATUS\tINSTANCE\tERROR")
+	for _, r := range runs {
+		scheduled := "manual"
+		if r.ScheduledFor != nil {
+			scheduled = r.ScheduledFor.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ID, r.Job, scheduled, r.StartedAt.Format(time.RFC3339),
+			r.Duration.Round(time.Millisecond), r.Status, r.Instance, r.Error)
+		if *output && r.Output != "" {
+			fmt.Fprintf(w, "\t%s\n", r.Output)
+		}
+	}
+	w.Flush()
+	return 0
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/creds"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/loadtest"
+	"vehicles-service-stations/internal/utils"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	credsFile := flag.String("creds-file", "creds.enc", "Encrypted credentials written by data-mock, the passphrase is read from CREDS_PASSPHRASE")
+	rate := flag.Float64("rate", 50, "Operations started per second")
+	duration := flag.Duration("duration", 30*time.Second, "Length of the run")
+	concurrency := flag.Int("concurrency", 32, "Maximum operations in flight")
+	perRole := flag.Int("per-role", 10, "Employees of every role to log in as")
+	mix := flag.String("mix", "Master=5,Manager=3,Analyst=2", "Role weights")
+	asJSON := flag.Bool("json", false, "Print the report as JSON")
+	seed := flag.Uint64("seed", 0, "Seed of the actors and operations picked, random if 0")
+	flag.Parse()
+
+	if *seed != 0 {
+		utils.Seed(*seed)
+	}
+
+	roleMix, err := parseMix(*mix)
+	if err != nil {
+		log.Printf("Invalid -mix: %v", err)
+		return 2
+	}
+
+	users, err := creds.ReadFile(*credsFile, os.Getenv("CREDS_PASSPHRASE"))
+	if err != nil {
+		log.Printf("Ошибка чтения учётных данных: %v", err)
+		return 1
+	}
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	connManager := db.NewConnectionManager()
+	defer connManager.CloseAll()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return 1
+	}
+
+	actors, err := loadtest.Connect(ctx, cfg, connManager.GetPool("superuser"), users, *perRole)
+	if err != nil {
+		log.Printf("Failed to log in actors: %v", err)
+		return 1
+	}
+	defer loadtest.Close(actors)
+
+	log.Printf("Running %d actors at %.1f ops/s for %s", len(actors), *rate, *duration)
+	report, err := loadtest.Run(ctx, actors, loadtest.Options{
+		Rate:        *rate,
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		Mix:         roleMix,
+	})
+	if err != nil {
+		log.Printf("Load test failed: %v", err)
+		return 1
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		printReport(report)
+	}
+
+	for _, s := range report.Stats {
+		if s.Leaked > 0 {
+			log.Printf("RLS leak: %s as %s succeeded %d times", s.Workload, s.Role, s.Leaked)
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseMix(s string) (map[string]float64, error) {
+	mix := make(map[string]float64)
+	for _, part := range strings.Split(s, ",") {
+		role, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected role=weight, got %q", part)
+		}
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight of %s", role)
+		}
+		mix[role] = w
+	}
+	return mix, nil
+}
+
+func printReport(r *loadtest.Report) {
+	fmt.Printf("actors: %v, operations: %d (%.1f/s), dropped: %d\n\n",
+		r.Actors, r.Operations, float64(r.Operations)/r.Elapsed.Seconds(), r.Dropped)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "role\tworkload\tcalls\tok\tskipped\trls denied\tleaked\terrors\tp50\tp95\tp99\tmax")
+	for _, s := range r.Stats {
+		name := s.Workload
+		if s.Probe {
+			name += " (probe)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			s.Role, name, s.Calls, s.OK, s.Skipped, s.Denied, s.Leaked, formatErrors(s.Errors),
+			s.P50.Round(time.Microsecond), s.P95.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+	w.Flush()
+}
+
+func formatErrors(errs map[string]int) string {
+	if len(errs) == 0 {
+		return "-"
+	}
+	codes := make([]string, 0, len(errs))
+	for code := range errs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		parts = append(parts, fmt.Sprintf("%s:%d", code, errs[code]))
+	}
+	return strings.Join(parts, " ")
+}
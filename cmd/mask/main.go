@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/masking"
+)
+
+func main() {
+	target := flag.String("target", "", "Connection string of the database to write masked data to")
+	rulesPath := flag.String("rules", "", "Path to a masking rules file, built-in rules when empty")
+	schema := flag.String("schema", "public", "Schema to copy")
+	disableTriggers := flag.Bool("disable-triggers", true, "Write with triggers disabled (needs superuser)")
+	truncate := flag.Bool("truncate", false, "Empty target tables before writing")
+	force := flag.Bool("force", false, "Copy even if source and target schemas differ")
+	flag.Parse()
+
+	if *target == "" {
+		log.Fatal("-target is required")
+	}
+	// The secret is read from the environment only, so it does not end up in
+	// shell history or process listings.
+	secret := os.Getenv("MASK_SECRET")
+	if secret == "" {
+		log.Fatal("MASK_SECRET is not set")
+	}
+
+	rules := masking.DefaultConfig()
+	if *rulesPath != "" {
+		var err error
+		if rules, err = masking.LoadConfig(*rulesPath); err != nil {
+			log.Fatalf("Ошибка загрузки правил маскирования: %v", err)
+		}
+	}
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Ошибка создания конфигурации: %v", err)
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Fatalf("Ошибка создания конфигурации: %v", err)
+	}
+	sourceCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Fatalf("Ошибка создания конфигурации: %v", err)
+	}
+	targetCfg, err := pgxpool.ParseConfig(*target)
+	if err != nil {
+		log.Fatalf("Invalid target connection string: %v", err)
+	}
+
+	ctx := context.Background()
+	connManager := db.NewConnectionManager()
+	defer connManager.CloseAll()
+	if err := connManager.AddPoolWithConfig(ctx, "source", sourceCfg); err != nil {
+		log.Fatalf("Ошибка подключения к базе: %v", err)
+	}
+	if err := connManager.AddPoolWithConfig(ctx, "target", targetCfg); err != nil {
+		log.Fatalf("Ошибка подключения к целевой базе: %v", err)
+	}
+
+	reports, err := masking.Copy(ctx, connManager.GetPool("source"), connManager.GetPool("target"), masking.Options{
+		Schema:          *schema,
+		Rules:           rules,
+		Secret:          []byte(secret),
+		DisableTriggers: *disableTriggers,
+		Truncate:        *truncate,
+		Force:           *force,
+	})
+	if err != nil {
+		log.Fatalf("Masking failed: %v", err)
+	}
+
+	for _, r := range reports {
+		log.Printf("Copied %s: %d rows, masked %v", r.Name, r.Rows, r.Masked)
+		if len(r.Detached) > 0 {
+			log.Printf("  %s: %v reference other schemas and were set to NULL", r.Name, r.Detached)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/notify"
+	"vehicles-service-stations/internal/outbox"
+	"vehicles-service-stations/internal/telegram"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: notify run|fake|log|contact [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "run":
+		os.Exit(runService(os.Args[2:]))
+	case "fake":
+		os.Exit(runFake(os.Args[2:]))
+	case "log":
+		os.Exit(runLog(os.Args[2:]))
+	case "contact":
+		os.Exit(runContact(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func connect(ctx context.Context) (*db.ConnectionManager, *pgxpool.Pool, bool) {
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return nil, nil, false
+	}
+	return connManager, connManager.GetPool("superuser"), true
+}
+
+// runService consumes the outbox and sends the notifications. It takes the
+// place of "outbox relay", -forward keeps publishing the events to the broker.
+// Provider secrets are read from NOTIFY_SMS_TOKEN, NOTIFY_SMTP_PASSWORD and
+// NOTIFY_TELEGRAM_TOKEN.
+func runService(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	channelList := fs.String("channels", "sms,email,telegram", "Channels to notify through")
+	smsURL := fs.String("sms-url", "http://127.0.0.1:8401/messages", "SMS gateway endpoint")
+	smsRate := fs.Float64("sms-rate", 5, "SMS per second, 0 for unlimited")
+	smtpAddr := fs.String("smtp-addr", "127.0.0.1:2525", "SMTP server host:port")
+	smtpFrom := fs.String("smtp-from", "noreply@service-stations.local", "Sender address of emails")
+	smtpUser := fs.String("smtp-user", "", "SMTP username, no authentication when empty")
+	emailRate := fs.Float64("email-rate", 10, "Emails per second, 0 for unlimited")
+	telegramURL := fs.String("telegram-url", telegram.DefaultAPI, "Telegram Bot API address")
+	telegramRate := fs.Float64("telegram-rate", 25, "Telegram messages per second, 0 for unlimited")
+	forward := fs.String("forward", "", "Also publish the outbox to this broker address, e.g. http://127.0.0.1:8300")
+	var opts notify.DispatcherOptions
+	fs.IntVar(&opts.MaxAttempts, "max-attempts", 5, "Failed sends before a delivery fails")
+	fs.DurationVar(&opts.MinBackoff, "min-backoff", 5*time.Second, "Delay after the first failed send, doubled after every next one")
+	fs.Parse(args)
+
+	var routes []notify.Route
+	var channels []string
+	for _, name := range strings.Split(*channelList, ",") {
+		var ch notify.Channel
+		var rate float64
+		var err error
+		switch name = strings.TrimSpace(name); name {
+		case "":
+			continue
+		case notify.ChannelSMS:
+			ch, err = notify.NewSMSChannel(*smsURL, os.Getenv("NOTIFY_SMS_TOKEN"))
+			rate = *smsRate
+		case notify.ChannelEmail:
+			ch, err = notify.NewEmailChannel(*smtpAddr, *smtpFrom, *smtpUser, os.Getenv("NOTIFY_SMTP_PASSWORD"))
+			rate = *emailRate
+		case notify.ChannelTelegram:
+			ch, err = notify.NewTelegramChannel(*telegramURL, os.Getenv("NOTIFY_TELEGRAM_TOKEN"))
+			rate = *telegramRate
+		default:
+			err = fmt.Errorf("unknown channel %q", name)
+		}
+		if err != nil {
+			log.Printf("Invalid channel: %v", err)
+			return 2
+		}
+		routes = append(routes, notify.Route{Channel: ch, Rate: rate, Burst: max(int(rate), 1)})
+		channels = append(channels, name)
+	}
+	if len(routes) == 0 {
+		log.Printf("No channels to notify through")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	var broker outbox.Broker = notify.NewNotifier(pool, channels)
+	if *forward != "" {
+		httpBroker, err := outbox.NewHTTPBroker(*forward)
+		if err != nil {
+			log.Printf("Invalid broker: %v", err)
+			return 2
+		}
+		broker = outbox.FanOut(broker, httpBroker)
+	}
+	relay := outbox.NewRelay(pool, broker, outbox.Options{
+		OnError: func(err error) { log.Printf("Outbox relay failed: %v", err) },
+	})
+
+	opts.OnBatch = func(res notify.DispatchResult) {
+		log.Printf("Claimed %d deliveries: sent %d, retried %d, failed %d", res.Claimed, res.Sent, res.Retried, res.Failed)
+	}
+	opts.OnError = func(err error) {
+		log.Printf("Dispatch failed: %v", err)
+	}
+	dispatcher := notify.NewDispatcher(pool, routes, opts)
+
+	log.Printf("Notifying through %s", strings.Join(channels, ", "))
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		relay.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		dispatcher.Run(ctx)
+	}()
+	wg.Wait()
+	return 0
+}
+
+// runFake serves fake providers for local runs and prints what they receive.
+func runFake(args []string) int {
+	fs := flag.NewFlagSet("fake", flag.ExitOnError)
+	smsAddr := fs.String("sms-addr", "127.0.0.1:8401", "Address of the fake SMS gateway")
+	smtpAddr := fs.String("smtp-addr", "127.0.0.1:2525", "Address of the fake SMTP server")
+	telegramAddr := fs.String("telegram-addr", "127.0.0.1:8402", "Address of the fake Telegram Bot API")
+	fail := fs.Int("fail", 0, "Fail the first N sends of every provider")
+	fs.Parse(args)
+
+	var mu sync.Mutex
+	show := func(m notify.FakeMessage) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Printf("[%s #%s] to %s", m.Channel, m.ID, m.To)
+		if m.Subject != "" {
+			fmt.Printf(" %q", m.Subject)
+		}
+		fmt.Printf("\n  %s\n", m.Text)
+	}
+
+	sms := &notify.FakeSMSGateway{}
+	smtp := &notify.FakeSMTP{}
+	token := os.Getenv("NOTIFY_TELEGRAM_TOKEN")
+	if token == "" {
+		token = "fake"
+	}
+	bot := telegram.NewFakeServer(token)
+	sms.OnMessage, smtp.OnMessage = show, show
+	bot.OnSend = func(m telegram.Message) {
+		show(notify.FakeMessage{Channel: notify.ChannelTelegram, ID: strconv.FormatInt(m.MessageID, 10), To: strconv.FormatInt(m.Chat.ID, 10), Text: m.Text})
+	}
+	sms.FailNext(*fail)
+	smtp.FailNext(*fail)
+	bot.FailNext(*fail)
+
+	smtpListener, err := net.Listen("tcp", *smtpAddr)
+	if err != nil {
+		log.Printf("Failed to listen: %v", err)
+		return 1
+	}
+
+	errs := make(chan error, 3)
+	go func() { errs <- http.ListenAndServe(*smsAddr, sms) }()
+	go func() { errs <- http.ListenAndServe(*telegramAddr, bot) }()
+	go func() { errs <- smtp.Serve(smtpListener) }()
+	log.Printf("Fake SMS gateway on %s, SMTP on %s, Telegram on %s", *smsAddr, *smtpAddr, *telegramAddr)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	select {
+	case <-ctx.Done():
+		return 0
+	case err := <-errs:
+		log.Printf("Fake provider failed: %v", err)
+		return 1
+	}
+}
+
+func runLog(args []string) int {
+	fs := flag.NewFlagSet("log", flag.ExitOnError)
+	var f notify.LogFilter
+	fs.IntVar(&f.CustomerID, "customer", 0, "Only deliveries to this customer")
+	fs.IntVar(&f.OrderID, "order", 0, "Only deliveries about this order")
+	fs.StringVar(&f.Status, "status", "", "Only deliveries in this status: pending, sent or failed")
+	fs.StringVar(&f.Channel, "channel", "", "Only deliveries through this channel")
+	fs.Uint64Var(&f.Limit, "limit", 50, "Max deliveries listed")
+	body := fs.Bool("body", false, "Print the rendered messages")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	entries, err := notify.Log(ctx, pool, f)
+	if err != nil {
+		log.Printf("Failed to read delivery log: %v", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCREATED\tORDER\tKIND\tCHANNEL\tRECIPIENT\tSTATUS\tATTEMPTS\tERROR")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\t%s\t%d\t%s\n", e.ID, e.CreatedAt.Format(time.DateTime), e.OrderID, e.Kind,
+			e.Channel, e.Recipient, e.Status, e.Attempts, e.LastError)
+		if *body {
+			fmt.Fprintf(w, "\t%s\n", e.Body)
+		}
+	}
+	w.Flush()
+	return 0
+}
+
+func runContact(args []string) int {
+	fs := flag.NewFlagSet("contact", flag.ExitOnError)
+	var c notify.Contact
+	fs.IntVar(&c.CustomerID, "customer", 0, "Customer id")
+	email := fs.String("email", "", "Email address, none when empty")
+	chatID := fs.Int64("telegram", 0, "Telegram chat id, none when 0")
+	lang := fs.String("lang", string(notify.LangRU), "Language of the notifications: ru or en")
+	fs.BoolVar(&c.SMSEnabled, "sms", true, "Send SMS to the phone number of the customer")
+	fs.Parse(args)
+
+	if c.CustomerID <= 0 {
+		log.Printf("-customer is required")
+		return 2
+	}
+	if *email != "" {
+		c.Email = email
+	}
+	if *chatID != 0 {
+		c.TelegramChatID = chatID
+	}
+	c.Language = notify.Language(*lang)
+
+	ctx := context.Background()
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	if err := notify.SetContact(ctx, pool, c); err != nil {
+		log.Printf("Failed to save contact: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/outbox"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: outbox relay|dead|retry [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "relay":
+		os.Exit(runRelay(os.Args[2:]))
+	case "dead":
+		os.Exit(runDead(os.Args[2:]))
+	case "retry":
+		os.Exit(runRetry(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func connect(ctx context.Context) (*db.ConnectionManager, *pgxpool.Pool, bool) {
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return nil, nil, false
+	}
+	return connManager, connManager.GetPool("superuser"), true
+}
+
+// runRelay publishes the outbox until interrupted. Several relays, in one
+// process or many, share the work without publishing a message twice.
+func runRelay(args []string) int {
+	fs := flag.NewFlagSet("relay", flag.ExitOnError)
+	var opts outbox.Options
+	var brokerCfg outbox.BrokerConfig
+	fs.IntVar(&opts.BatchSize, "batch", 100, "Messages claimed per transaction")
+	fs.IntVar(&opts.MaxAttempts, "max-attempts", 10, "Failed publications before a message is dead-lettered")
+	fs.DurationVar(&opts.MinBackoff, "min-backoff", time.Second, "Delay after the first failure, doubled after every next one")
+	fs.DurationVar(&opts.MaxBackoff, "max-backoff", 10*time.Minute, "Max delay between attempts")
+	fs.DurationVar(&opts.PollInterval, "poll", time.Second, "Pause once the outbox is drained")
+	workers := fs.Int("workers", 1, "Relays run concurrently")
+	brokerKind := fs.String("broker", string(outbox.BrokerLog), "Where to publish: log or http")
+	fs.StringVar(&brokerCfg.Addr, "broker-addr", "http://127.0.0.1:8300", "Address of the http broker, e.g. cdc broker")
+	fs.Parse(args)
+	brokerCfg.Kind = outbox.BrokerKind(*brokerKind)
+
+	// Messages go to stdout for the log broker, progress always goes to stderr.
+	broker, err := outbox.NewBroker(brokerCfg, os.Stdout)
+	if err != nil {
+		log.Printf("Invalid broker: %v", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	opts.OnBatch = func(res outbox.BatchResult) {
+		log.Printf("Claimed %d: published %d, retried %d, dead %d",
+			res.Claimed, res.Published, res.Retried, res.Dead)
+	}
+	opts.OnError = func(err error) {
+		log.Printf("Relay failed: %v", err)
+	}
+
+	log.Printf("Relaying the outbox to %s with %d workers", broker.Name(), *workers)
+	var wg sync.WaitGroup
+	for range max(*workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outbox.NewRelay(pool, broker, opts).Run(ctx)
+		}()
+	}
+	wg.Wait()
+	return 0
+}
+
+func runDead(args []string) int {
+	fs := flag.NewFlagSet("dead", flag.ExitOnError)
+	limit := fs.Int("limit", 100, "Max dead letters listed")
+	payload := fs.Bool("payload", false, "Print the payloads")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	letters, err := outbox.DeadLetters(ctx, pool, *limit)
+	if err != nil {
+		log.Printf("Failed to list dead letters: %v", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tAGGREGATE\tATTEMPTS\tDEAD AT\tERROR")
+	for _, d := range letters {
+		fmt.Fprintf(w, "%d\t%s v%d\t%s/%s\t%d\t%s\t%s\n", d.ID, d.Type, d.Version, d.AggregateType, d.AggregateID,
+			d.Attempts, d.DeadAt.Format(time.RFC3339), d.LastError)
+		if *payload {
+			fmt.Fprintf(w, "\t%s\n", d.Payload)
+		}
+	}
+	w.Flush()
+	return 0
+}
+
+func runRetry(args []string) int {
+	fs := flag.NewFlagSet("retry", flag.ExitOnError)
+	idList := fs.String("id", "", "Comma separated ids of the dead letters to requeue")
+	all := fs.Bool("all", false, "Requeue every dead letter")
+	fs.Parse(args)
+
+	var ids []int64
+	for _, s := range strings.Split(*idList, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			log.Printf("Invalid -id: %v", err)
+			return 2
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 && !*all {
+		log.Printf("Either -id or -all is required")
+		return 2
+	}
+	if len(ids) > 0 && *all {
+		log.Printf("-id and -all are exclusive")
+		return 2
+	}
+
+	ctx := context.Background()
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	n, err := outbox.Requeue(ctx, pool, ids)
+	if err != nil {
+		log.Printf("Failed to requeue: %v", err)
+		return 1
+	}
+	log.Printf("Requeued %d dead letters", n)
+	return 0
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/pricing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pricing quote|receipt [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "quote":
+		os.Exit(runQuote(os.Args[2:]))
+	case "receipt":
+		os.Exit(runReceipt(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func connect(ctx context.Context) (*db.ConnectionManager, *pgxpool.Pool, bool) {
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return nil, nil, false
+	}
+
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return nil, nil, false
+	}
+	return connManager, connManager.GetPool("superuser"), true
+}
+
+// runQuote prices an order as it is now. The quote only becomes the price of
+// the order with -save.
+func runQuote(args []string) int {
+	fs := flag.NewFlagSet("quote", flag.ExitOnError)
+	orderID := fs.Int("order", 0, "Order to price")
+	code := fs.String("code", "", "Promo code")
+	save := fs.Bool("save", false, "Save the quote as the price of the order")
+	asJSON := fs.Bool("json", false, "Print the breakdown as JSON")
+	fs.Parse(args)
+	if *orderID == 0 {
+		log.Printf("-order is required")
+		return 2
+	}
+
+	ctx := context.Background()
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	in, err := pricing.Load(ctx, pool, *orderID, *code)
+	if err != nil {
+		log.Printf("Failed to price order %d: %v", *orderID, err)
+		return 1
+	}
+	q, err := pricing.Compute(*in)
+	if err != nil {
+		log.Printf("Failed to price order %d: %v", *orderID, err)
+		return 1
+	}
+	if *save {
+		if err := pricing.Save(ctx, pool, q); err != nil {
+			log.Printf("Failed to save the quote: %v", err)
+			return 1
+		}
+		log.Printf("Saved quote %d of order %d", q.ID, q.OrderID)
+	}
+	return printQuote(q, *asJSON)
+}
+
+// runReceipt prints the breakdown a receipt was charged by.
+func runReceipt(args []string) int {
+	fs := flag.NewFlagSet("receipt", flag.ExitOnError)
+	receiptID := fs.Int("id", 0, "Receipt to reproduce")
+	asJSON := fs.Bool("json", false, "Print the breakdown as JSON")
+	fs.Parse(args)
+	if *receiptID == 0 {
+		log.Printf("-id is required")
+		return 2
+	}
+
+	ctx := context.Background()
+	connManager, pool, ok := connect(ctx)
+	if !ok {
+		return 1
+	}
+	defer connManager.CloseAll()
+
+	q, err := pricing.ReceiptQuote(ctx, pool, *receiptID)
+	if err != nil {
+		log.Printf("Failed to load the receipt: %v", err)
+		return 1
+	}
+	return printQuote(q, *asJSON)
+}
+
+func printQuote(q *pricing.Quote, asJSON bool) int {
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(q); err != nil {
+			log.Printf("Cant write the quote: %v", err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Printf("Order %d quoted at %s, tier %s\n\n", q.OrderID, q.QuotedAt.Format("2006-01-02 15:04"), q.Tier)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ITEM\tQTY\tAMOUNT\tDISCOUNT\tTOTAL")
+	for _, l := range q.Lines {
+		discount := ""
+		if l.Discount != nil {
+			discount = fmt.Sprintf("-%s (%s%% %s)", l.Discount.Amount, l.Discount.Percent, l.Discount.Reason)
+		}
+		name := l.Name
+		if l.LabourHours > 0 {
+			name += fmt.Sprintf(" + %sh × %s", l.LabourHours, l.LabourRate)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n", name, l.Quantity, l.Amount, discount, l.Total)
+	}
+	fmt.Fprintf(w, "Subtotal\t\t\t\t%s\n", q.Subtotal)
+	if q.PromoCode != nil {
+		fmt.Fprintf(w, "%s\t\t\t\t-%s\n", q.PromoCode.Reason, q.PromoCode.Amount)
+	}
+	if q.PricesIncludeVAT {
+		fmt.Fprintf(w, "VAT %s%% included\t\t\t\t%s\n", q.VATRate, q.VAT)
+	} else {
+		fmt.Fprintf(w, "VAT %s%%\t\t\t\t%s\n", q.VATRate, q.VAT)
+	}
+	if q.Rounding != 0 {
+		fmt.Fprintf(w, "Rounding\t\t\t\t%s\n", q.Rounding)
+	}
+	fmt.Fprintf(w, "Total\t\t\t\t%s\n", q.Total)
+	w.Flush()
+	return 0
+}
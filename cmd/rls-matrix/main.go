@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/rls"
+	"vehicles-service-stations/internal/testutil"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run creates a throwaway database with the fixture and prints the effective
+// permission matrix of every role and table as Markdown. The policy cases
+// themselves are checked by the tests of internal/rls.
+func run() int {
+	out := flag.String("out", "", "Write the Markdown report to this file instead of stdout")
+	assets := flag.String("assets", testutil.DefaultAssetsDir, "Directory with the schema migrations")
+	keep := flag.Bool("keep", false, "Keep the throwaway database and roles for inspection")
+	flag.Parse()
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Printf("Ошибка создания конфигурации: %v", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	server, err := testutil.NewServer(ctx, cfg)
+	if err != nil {
+		log.Printf("Ошибка подключения к базе: %v", err)
+		return 1
+	}
+	defer server.Close()
+
+	database, err := server.CreateDatabase(ctx, "rls", *assets)
+	if err != nil {
+		log.Printf("Failed to create throwaway database: %v", err)
+		return 1
+	}
+	if *keep {
+		log.Printf("Keeping database %s", database.Name)
+	} else {
+		defer func() {
+			if err := database.Drop(context.Background()); err != nil {
+				log.Printf("Cleanup failed: %v", err)
+			}
+		}()
+	}
+
+	fixture, err := rls.NewFixture(ctx, database)
+	if err != nil {
+		log.Printf("Failed to seed fixture: %v", err)
+		return 1
+	}
+	matrices, err := rls.Matrix(ctx, fixture)
+	if err != nil {
+		log.Printf("Failed to compute permission matrix: %v", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Printf("Failed to create report: %v", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := rls.WriteMarkdown(w, matrices); err != nil {
+		log.Printf("Failed to write report: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"vehicles-service-stations/config"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/snapshot"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: snapshot export|import [flags]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	envCfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Ошибка создания конфигурации: %v", err)
+	}
+	cfg, err := db.NewConfig(envCfg, envCfg.DbSuperuser, envCfg.DbPassword)
+	if err != nil {
+		log.Fatalf("Ошибка создания конфигурации: %v", err)
+	}
+
+	switch os.Args[1] {
+	case "export":
+		runExport(cfg, os.Args[2:])
+	case "import":
+		runImport(cfg, os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func connect(ctx context.Context, cfg *db.Config) *db.ConnectionManager {
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		log.Fatalf("Ошибка создания конфигурации: %v", err)
+	}
+
+	connManager := db.NewConnectionManager()
+	if err := connManager.AddPoolWithConfig(ctx, "superuser", poolCfg); err != nil {
+		log.Fatalf("Ошибка подключения к базе: %v", err)
+	}
+	return connManager
+}
+
+func runExport(cfg *db.Config, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	dir := fs.String("dir", "snapshot", "Directory to write the snapshot to")
+	format := fs.String("format", "jsonl", "Table file format: jsonl or csv")
+	schema := fs.String("schema", "public", "Schema to export")
+	includeSecrets := fs.Bool("include-secrets", false, "Keep employees' password hashes")
+	fs.Parse(args)
+
+	f, err := snapshot.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	connManager := connect(ctx, cfg)
+	defer connManager.CloseAll()
+
+	manifest, err := snapshot.Export(ctx, connManager.GetPool("superuser"), snapshot.ExportOptions{
+		Dir:            *dir,
+		Schema:         *schema,
+		Format:         f,
+		IncludeSecrets: *includeSecrets,
+	})
+	if err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+
+	for _, t := range manifest.Tables {
+		log.Printf("Exported %s: %d rows", t.Name, t.Rows)
+	}
+	log.Printf("Snapshot written to %s", *dir)
+}
+
+func runImport(cfg *db.Config, args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dir := fs.String("dir", "snapshot", "Directory to read the snapshot from")
+	schema := fs.String("schema", "public", "Schema to import into")
+	disableTriggers := fs.Bool("disable-triggers", true, "Load with triggers disabled (needs superuser)")
+	truncate := fs.Bool("truncate", false, "Empty target tables before loading")
+	force := fs.Bool("force", false, "Load even if the schema fingerprint differs")
+	fs.Parse(args)
+
+	ctx := context.Background()
+	connManager := connect(ctx, cfg)
+	defer connManager.CloseAll()
+
+	manifest, err := snapshot.Import(ctx, connManager.GetPool("superuser"), snapshot.ImportOptions{
+		Dir:             *dir,
+		Schema:          *schema,
+		DisableTriggers: *disableTriggers,
+		Truncate:        *truncate,
+		Force:           *force,
+	})
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	for _, t := range manifest.Tables {
+		log.Printf("Imported %s: %d rows", t.Name, t.Rows)
+	}
+}
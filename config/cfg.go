@@ -7,17 +7,40 @@ import (
 )
 
 type Config struct {
-	DbIp        string `mapstructure:"DB_IP"`
-	DbPort      int    `mapstructure:"DB_PORT"`
-	DbName      string `mapstructure:"DB_NAME"`
-	DbSuperuser string `mapstructure:"DB_SUPERUSER_LOGIN"`
-	DbPassword  string `mapstructure:"DB_SUPERUSER_PASSWORD"`
+	DbDSN                string `mapstructure:"DB_DSN"`
+	DbIp                 string `mapstructure:"DB_IP"`
+	DbPort               int    `mapstructure:"DB_PORT"`
+	DbName               string `mapstructure:"DB_NAME"`
+	DbSuperuser          string `mapstructure:"DB_SUPERUSER_LOGIN"`
+	DbPassword           string `mapstructure:"DB_SUPERUSER_PASSWORD"`
+	DbSSLMode            string `mapstructure:"DB_SSLMODE"`
+	DbSSLRootCert        string `mapstructure:"DB_SSLROOTCERT"`
+	DbSSLCert            string `mapstructure:"DB_SSLCERT"`
+	DbSSLKey             string `mapstructure:"DB_SSLKEY"`
+	DbConnectTimeout     int    `mapstructure:"DB_CONNECT_TIMEOUT"`
+	DbSchema             string `mapstructure:"DB_SCHEMA"`
+	DbTargetSessionAttrs string `mapstructure:"DB_TARGET_SESSION_ATTRS"`
 }
 
 func LoadConfig() (*Config, error) {
 	var cfg Config
 	viper.AutomaticEnv()
 	viper.SetConfigFile(".env")
+
+	viper.SetDefault("DB_DSN", "")
+	viper.SetDefault("DB_IP", "localhost")
+	viper.SetDefault("DB_PORT", 5432)
+	viper.SetDefault("DB_NAME", "")
+	viper.SetDefault("DB_SUPERUSER_LOGIN", "")
+	viper.SetDefault("DB_SUPERUSER_PASSWORD", "")
+	viper.SetDefault("DB_SSLMODE", "")
+	viper.SetDefault("DB_SSLROOTCERT", "")
+	viper.SetDefault("DB_SSLCERT", "")
+	viper.SetDefault("DB_SSLKEY", "")
+	viper.SetDefault("DB_CONNECT_TIMEOUT", 0)
+	viper.SetDefault("DB_SCHEMA", "")
+	viper.SetDefault("DB_TARGET_SESSION_ATTRS", "")
+
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return &cfg, err
@@ -0,0 +1,106 @@
+CREATE SCHEMA IF NOT EXISTS audit;
+
+CREATE TABLE IF NOT EXISTS audit.log (
+    audit_id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp(),
+    tx_id BIGINT NOT NULL DEFAULT txid_current(),
+    actor TEXT NOT NULL,
+    employee_id INT,
+    request_id TEXT,
+    table_name TEXT NOT NULL,
+    operation TEXT NOT NULL CHECK (operation IN ('INSERT', 'UPDATE', 'DELETE')),
+    row_id TEXT NOT NULL,
+    old_row JSONB,
+    new_row JSONB,
+    changed_columns TEXT[] NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS log_row_idx ON audit.log (table_name, row_id, occurred_at);
+CREATE INDEX IF NOT EXISTS log_actor_idx ON audit.log (actor, occurred_at);
+CREATE INDEX IF NOT EXISTS log_request_idx ON audit.log (request_id) WHERE request_id IS NOT NULL;
+
+-- audit.capture(primary key columns, excluded columns) records a row change.
+-- Both arguments are comma separated column lists, excluded columns (secrets)
+-- are stripped from the stored rows. The function runs as its owner so that
+-- employees cannot write to the log themselves; current_user is the owner
+-- here, the login role of the employee is session_user. The application sets
+-- the request ID per transaction with set_config('app.request_id', id, true).
+CREATE OR REPLACE FUNCTION audit.capture()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_pk TEXT[] := string_to_array(TG_ARGV[0], ',');
+    v_excluded TEXT[] := COALESCE(string_to_array(NULLIF(TG_ARGV[1], ''), ','), '{}');
+    v_old JSONB;
+    v_new JSONB;
+    v_key JSONB;
+    v_changed TEXT[];
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        v_old := to_jsonb(OLD) - v_excluded;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        v_new := to_jsonb(NEW) - v_excluded;
+    END IF;
+    v_key := COALESCE(v_new, v_old);
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT COALESCE(array_agg(k ORDER BY k), '{}') INTO v_changed
+        FROM jsonb_object_keys(v_new) AS k
+        WHERE v_old -> k IS DISTINCT FROM v_new -> k;
+
+        IF cardinality(v_changed) = 0 THEN
+            RETURN NULL;
+        END IF;
+    ELSE
+        SELECT array_agg(k ORDER BY k) INTO v_changed
+        FROM jsonb_object_keys(v_key) AS k;
+    END IF;
+
+    INSERT INTO audit.log (
+        actor, employee_id, request_id, table_name, operation, row_id, old_row, new_row, changed_columns
+    ) VALUES (
+        session_user,
+        (SELECT employee_id FROM public.employees WHERE username = session_user),
+        NULLIF(current_setting('app.request_id', true), ''),
+        TG_TABLE_NAME,
+        TG_OP,
+        (SELECT string_agg(v_key ->> k, ',' ORDER BY ord) FROM unnest(v_pk) WITH ORDINALITY AS p(k, ord)),
+        v_old,
+        v_new,
+        v_changed
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+CREATE OR REPLACE TRIGGER audit_orders_trigger
+AFTER INSERT OR UPDATE OR DELETE ON orders
+FOR EACH ROW
+EXECUTE FUNCTION audit.capture('order_id', '');
+
+CREATE OR REPLACE TRIGGER audit_customers_trigger
+AFTER INSERT OR UPDATE OR DELETE ON customers
+FOR EACH ROW
+EXECUTE FUNCTION audit.capture('customer_id', '');
+
+CREATE OR REPLACE TRIGGER audit_receipts_trigger
+AFTER INSERT OR UPDATE OR DELETE ON receipts
+FOR EACH ROW
+EXECUTE FUNCTION audit.capture('receipt_id', '');
+
+CREATE OR REPLACE TRIGGER audit_spare_parts_trigger
+AFTER INSERT OR UPDATE OR DELETE ON spare_parts
+FOR EACH ROW
+EXECUTE FUNCTION audit.capture('part_id', '');
+
+CREATE OR REPLACE TRIGGER audit_employees_trigger
+AFTER INSERT OR UPDATE OR DELETE ON employees
+FOR EACH ROW
+EXECUTE FUNCTION audit.capture('employee_id', 'password_hash');
+
+REVOKE ALL ON SCHEMA audit FROM PUBLIC;
+REVOKE ALL ON ALL TABLES IN SCHEMA audit FROM PUBLIC;
+
+GRANT USAGE ON SCHEMA audit TO administrator;
+GRANT SELECT ON audit.log TO administrator;
@@ -0,0 +1,12 @@
+-- Change data capture needs wal_level = logical on the server. Updates of
+-- orders and spare_parts carry the whole old row so that status and stock
+-- transitions can be told apart.
+ALTER TABLE orders REPLICA IDENTITY FULL;
+ALTER TABLE spare_parts REPLICA IDENTITY FULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT FROM pg_catalog.pg_publication WHERE pubname = 'cdc_domain') THEN
+        CREATE PUBLICATION cdc_domain FOR TABLE orders, receipts, spare_parts;
+    END IF;
+END $$;
@@ -0,0 +1,125 @@
+CREATE SCHEMA IF NOT EXISTS outbox;
+
+-- Domain events written in the same transaction as the change they describe
+-- and published by the relay (cmd/outbox). Payloads follow the schemas of
+-- internal/events, identified by event_type and version.
+CREATE TABLE IF NOT EXISTS outbox.events (
+    event_id BIGSERIAL PRIMARY KEY,
+    aggregate_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    version INT NOT NULL CHECK (version > 0),
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    published_at TIMESTAMP WITH TIME ZONE,
+    dead_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS events_pending_idx ON outbox.events (available_at, event_id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS events_aggregate_idx ON outbox.events (aggregate_type, aggregate_id, event_id)
+    WHERE published_at IS NULL AND dead_at IS NULL;
+CREATE INDEX IF NOT EXISTS events_dead_idx ON outbox.events (dead_at) WHERE dead_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION outbox.enqueue(
+    p_aggregate_type TEXT,
+    p_aggregate_id TEXT,
+    p_event_type TEXT,
+    p_version INT,
+    p_payload JSONB
+) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO outbox.events (aggregate_type, aggregate_id, event_type, version, payload)
+    VALUES (p_aggregate_type, p_aggregate_id, p_event_type, p_version, p_payload);
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+-- Events of the changes made by the schema itself, e.g. the customer updates
+-- of update_customer_on_receipt, so they do not go unnoticed. It runs as its
+-- owner, employees have no access to the outbox.
+CREATE OR REPLACE FUNCTION outbox.capture()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_TABLE_NAME = 'orders' THEN
+        IF TG_OP = 'INSERT' THEN
+            PERFORM outbox.enqueue('order', NEW.order_id::TEXT, 'order.created', 1, jsonb_build_object(
+                'order_id', NEW.order_id,
+                'customer_id', NEW.customer_id,
+                'service_center_id', NEW.service_center_id,
+                'manager_id', NEW.manager_id,
+                'master_id', NEW.assigned_master_id,
+                'scheduled_date', NEW.scheduled_date,
+                'status', NEW.status
+            ));
+        ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+            PERFORM outbox.enqueue('order', NEW.order_id::TEXT, 'order.status_changed', 1, jsonb_build_object(
+                'order_id', NEW.order_id,
+                'from', OLD.status,
+                'to', NEW.status
+            ));
+        END IF;
+    ELSIF TG_TABLE_NAME = 'receipts' THEN
+        PERFORM outbox.enqueue('order', NEW.order_id::TEXT, 'receipt.issued', 1, jsonb_build_object(
+            'receipt_id', NEW.receipt_id,
+            'order_id', NEW.order_id,
+            'total_paid', NEW.total_paid,
+            'bonus_points_spent', NEW.bonus_points_spent,
+            'receipt_date', NEW.receipt_date
+        ));
+    ELSIF TG_TABLE_NAME = 'customers' THEN
+        IF NEW.spent_money IS DISTINCT FROM OLD.spent_money
+            OR NEW.bonus_points IS DISTINCT FROM OLD.bonus_points
+            OR NEW.loyalty_status IS DISTINCT FROM OLD.loyalty_status THEN
+            PERFORM outbox.enqueue('customer', NEW.customer_id::TEXT, 'customer.balance_changed', 1, jsonb_build_object(
+                'customer_id', NEW.customer_id,
+                'spent_money_from', OLD.spent_money,
+                'spent_money_to', NEW.spent_money,
+                'bonus_points_from', OLD.bonus_points,
+                'bonus_points_to', NEW.bonus_points,
+                'loyalty_status', NEW.loyalty_status
+            ));
+        END IF;
+    ELSIF TG_TABLE_NAME = 'spare_parts' THEN
+        IF NEW.stock_quantity IS DISTINCT FROM OLD.stock_quantity THEN
+            PERFORM outbox.enqueue('spare_part', NEW.part_id::TEXT, 'stock.changed', 1, jsonb_build_object(
+                'part_id', NEW.part_id,
+                'from', OLD.stock_quantity,
+                'to', NEW.stock_quantity
+            ));
+        END IF;
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+CREATE OR REPLACE TRIGGER outbox_orders_trigger
+AFTER INSERT OR UPDATE OF status ON orders
+FOR EACH ROW
+EXECUTE FUNCTION outbox.capture();
+
+CREATE OR REPLACE TRIGGER outbox_receipts_trigger
+AFTER INSERT ON receipts
+FOR EACH ROW
+EXECUTE FUNCTION outbox.capture();
+
+CREATE OR REPLACE TRIGGER outbox_customers_trigger
+AFTER UPDATE ON customers
+FOR EACH ROW
+EXECUTE FUNCTION outbox.capture();
+
+CREATE OR REPLACE TRIGGER outbox_spare_parts_trigger
+AFTER UPDATE OF stock_quantity ON spare_parts
+FOR EACH ROW
+EXECUTE FUNCTION outbox.capture();
+
+REVOKE ALL ON SCHEMA outbox FROM PUBLIC;
+REVOKE ALL ON ALL TABLES IN SCHEMA outbox FROM PUBLIC;
+REVOKE EXECUTE ON FUNCTION outbox.enqueue(TEXT, TEXT, TEXT, INT, JSONB) FROM PUBLIC;
+
+GRANT USAGE ON SCHEMA outbox TO administrator;
+GRANT EXECUTE ON FUNCTION outbox.enqueue(TEXT, TEXT, TEXT, INT, JSONB) TO administrator;
+GRANT SELECT ON outbox.events TO administrator;
@@ -0,0 +1,79 @@
+-- Rescheduling and master reassignment are published to the outbox next to
+-- the events of 0004_outbox.sql. The master doing an order is the reassigned
+-- one when there is one.
+CREATE OR REPLACE FUNCTION outbox.capture_order_changes()
+RETURNS TRIGGER AS $$
+DECLARE
+    old_master INT := COALESCE(OLD.reassigned_master_id, OLD.assigned_master_id);
+    new_master INT := COALESCE(NEW.reassigned_master_id, NEW.assigned_master_id);
+BEGIN
+    IF NEW.scheduled_date IS DISTINCT FROM OLD.scheduled_date THEN
+        PERFORM outbox.enqueue('order', NEW.order_id::TEXT, 'order.rescheduled', 1, jsonb_build_object(
+            'order_id', NEW.order_id,
+            'from', OLD.scheduled_date,
+            'to', NEW.scheduled_date
+        ));
+    END IF;
+    IF new_master IS DISTINCT FROM old_master THEN
+        PERFORM outbox.enqueue('order', NEW.order_id::TEXT, 'order.master_changed', 1, jsonb_build_object(
+            'order_id', NEW.order_id,
+            'from', old_master,
+            'to', new_master
+        ));
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+CREATE OR REPLACE TRIGGER outbox_order_changes_trigger
+AFTER UPDATE OF scheduled_date, assigned_master_id, reassigned_master_id ON orders
+FOR EACH ROW
+EXECUTE FUNCTION outbox.capture_order_changes();
+
+CREATE SCHEMA IF NOT EXISTS notifications;
+
+-- Contacts of customers besides their phone number and the language of their
+-- notifications. Customers without a row get SMS in Russian.
+CREATE TABLE IF NOT EXISTS notifications.contacts (
+    customer_id INT PRIMARY KEY,
+    email TEXT CHECK (email ~ '^[^@\s]+@[^@\s]+$'),
+    telegram_chat_id BIGINT,
+    language TEXT NOT NULL DEFAULT 'ru' CHECK (language IN ('ru', 'en')),
+    sms_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    FOREIGN KEY (customer_id) REFERENCES customers (customer_id) ON DELETE CASCADE
+);
+
+-- Delivery log of the notifications service (cmd/notify). A notification is
+-- rendered once per event and channel, so replayed events are not sent twice.
+CREATE TABLE IF NOT EXISTS notifications.deliveries (
+    delivery_id BIGSERIAL PRIMARY KEY,
+    event_id BIGINT NOT NULL,
+    kind TEXT NOT NULL,
+    customer_id INT NOT NULL,
+    order_id INT NOT NULL,
+    channel TEXT NOT NULL,
+    recipient TEXT NOT NULL,
+    language TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    provider_id TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (event_id, channel)
+);
+
+CREATE INDEX IF NOT EXISTS deliveries_pending_idx ON notifications.deliveries (available_at, delivery_id)
+    WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS deliveries_customer_idx ON notifications.deliveries (customer_id, created_at);
+
+REVOKE ALL ON SCHEMA notifications FROM PUBLIC;
+REVOKE ALL ON ALL TABLES IN SCHEMA notifications FROM PUBLIC;
+
+GRANT USAGE ON SCHEMA notifications TO administrator;
+GRANT SELECT, INSERT, UPDATE, DELETE ON notifications.contacts TO administrator;
+GRANT SELECT ON notifications.deliveries TO administrator;
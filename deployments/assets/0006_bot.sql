@@ -0,0 +1,4 @@
+-- A Telegram chat belongs to one customer, the bot (cmd/bot) identifies
+-- customers by it once they shared their phone number.
+CREATE UNIQUE INDEX IF NOT EXISTS contacts_telegram_chat_idx ON notifications.contacts (telegram_chat_id)
+    WHERE telegram_chat_id IS NOT NULL;
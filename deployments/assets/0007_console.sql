@@ -0,0 +1,6 @@
+-- Managers build orders from the console (cmd/console) with their own role:
+-- services and spare parts are added to orders of their center, and the stock
+-- check of check_spare_part_stock() takes the parts off the stockpile.
+GRANT INSERT ON service_order TO manager;
+GRANT INSERT ON spare_part_order TO manager;
+GRANT UPDATE (stock_quantity) ON spare_parts TO manager;
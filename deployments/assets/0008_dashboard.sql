@@ -0,0 +1,11 @@
+-- The dashboard (cmd/dashboard) reads the reporting views as the logged in
+-- employee. Views run with the rights of their owner unless they are
+-- security_invoker, which would show every center to everybody.
+ALTER VIEW bookings_by_date SET (security_invoker = true);
+ALTER VIEW most_demanded_services SET (security_invoker = true);
+ALTER VIEW revenue_by_date SET (security_invoker = true);
+ALTER VIEW employee_performance SET (security_invoker = true);
+ALTER VIEW service_center_performance SET (security_invoker = true);
+
+GRANT SELECT ON bookings_by_date, most_demanded_services, revenue_by_date, employee_performance,
+    service_center_performance TO manager, master;
@@ -0,0 +1,25 @@
+-- Every change of an order is announced on the order_changes channel, the
+-- gRPC API (cmd/api) streams them to the workshop terminals. The payload
+-- carries no customer data: listeners read the order again with their own
+-- role, so row level security still decides who sees it.
+CREATE OR REPLACE FUNCTION notify_order_change() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW IS NOT DISTINCT FROM OLD THEN
+        RETURN NULL;
+    END IF;
+
+    PERFORM pg_notify('order_changes', json_build_object(
+        'op', TG_OP,
+        'order_id', NEW.order_id,
+        'service_center_id', NEW.service_center_id,
+        'master_id', COALESCE(NEW.reassigned_master_id, NEW.assigned_master_id),
+        'status', NEW.status
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER notify_order_change_trigger
+AFTER INSERT OR UPDATE ON orders
+FOR EACH ROW
+EXECUTE FUNCTION notify_order_change();
@@ -0,0 +1,86 @@
+-- Real-time events of the workshops: new and changed orders, issued receipts
+-- and parts running low, announced on the realtime channel and read by the
+-- hub of internal/realtime. They are derived from the audit log, so a
+-- listener that lost its connection replays what it missed with
+-- realtime.event() over the rows after the last one it saw. Payloads only
+-- carry IDs and statuses, any role may LISTEN.
+CREATE SCHEMA IF NOT EXISTS realtime;
+
+-- Parts are low once their stock goes below this.
+CREATE OR REPLACE FUNCTION realtime.low_stock_threshold() RETURNS INT AS $$
+    SELECT 5;
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION realtime.event(l audit.log) RETURNS JSONB AS $$
+DECLARE
+    v_event JSONB := jsonb_build_object('id', l.audit_id, 'at', l.occurred_at);
+    v_order public.orders%ROWTYPE;
+BEGIN
+    IF l.table_name = 'orders' AND l.operation IN ('INSERT', 'UPDATE') THEN
+        v_event := v_event || jsonb_build_object(
+            'kind', CASE l.operation WHEN 'INSERT' THEN 'order.created' ELSE 'order.updated' END,
+            'order_id', (l.new_row ->> 'order_id')::INT,
+            'service_center_id', (l.new_row ->> 'service_center_id')::INT,
+            'customer_id', (l.new_row ->> 'customer_id')::INT,
+            'master_id', COALESCE(l.new_row ->> 'reassigned_master_id', l.new_row ->> 'assigned_master_id')::INT,
+            'status', l.new_row ->> 'status'
+        );
+        IF l.operation = 'UPDATE' THEN
+            v_event := v_event || jsonb_build_object(
+                'previous_master_id', COALESCE(l.old_row ->> 'reassigned_master_id', l.old_row ->> 'assigned_master_id')::INT
+            );
+        END IF;
+        RETURN v_event;
+    END IF;
+
+    IF l.table_name = 'receipts' AND l.operation = 'INSERT' THEN
+        SELECT * INTO v_order FROM public.orders WHERE order_id = (l.new_row ->> 'order_id')::INT;
+        RETURN v_event || jsonb_build_object(
+            'kind', 'receipt.issued',
+            'receipt_id', (l.new_row ->> 'receipt_id')::INT,
+            'order_id', (l.new_row ->> 'order_id')::INT,
+            'service_center_id', v_order.service_center_id,
+            'customer_id', v_order.customer_id,
+            'master_id', COALESCE(v_order.reassigned_master_id, v_order.assigned_master_id)
+        );
+    END IF;
+
+    IF l.table_name = 'spare_parts' AND l.operation = 'UPDATE'
+        AND (l.new_row ->> 'stock_quantity')::INT < realtime.low_stock_threshold()
+        AND (l.old_row ->> 'stock_quantity')::INT >= realtime.low_stock_threshold() THEN
+        RETURN v_event || jsonb_build_object(
+            'kind', 'stock.low',
+            'part_id', (l.new_row ->> 'part_id')::INT,
+            'stock', (l.new_row ->> 'stock_quantity')::INT
+        );
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql STABLE SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+CREATE OR REPLACE FUNCTION realtime.notify() RETURNS TRIGGER AS $$
+DECLARE
+    v_event JSONB := realtime.event(NEW);
+BEGIN
+    IF v_event IS NOT NULL THEN
+        PERFORM pg_notify('realtime', v_event::TEXT);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+CREATE OR REPLACE TRIGGER realtime_notify_trigger
+AFTER INSERT ON audit.log
+FOR EACH ROW
+EXECUTE FUNCTION realtime.notify();
+
+-- The order changes of 0009 are part of the realtime events now.
+DROP TRIGGER IF EXISTS notify_order_change_trigger ON orders;
+DROP FUNCTION IF EXISTS notify_order_change();
+
+REVOKE ALL ON SCHEMA realtime FROM PUBLIC;
+REVOKE EXECUTE ON ALL FUNCTIONS IN SCHEMA realtime FROM PUBLIC;
+
+GRANT USAGE ON SCHEMA realtime TO administrator;
+GRANT EXECUTE ON FUNCTION realtime.event(audit.log) TO administrator;
@@ -0,0 +1,37 @@
+-- Scheduled jobs run by cmd/jobs instead of pg_cron, which managed Postgres
+-- does not offer. 0001 installs the extension and schedules the bonus reset,
+-- they are removed here on new and existing databases alike.
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM pg_catalog.pg_extension WHERE extname = 'pg_cron') THEN
+        EXECUTE 'SELECT cron.unschedule(jobid) FROM cron.job WHERE jobname = ''reset_bonus_points''';
+        DROP EXTENSION pg_cron;
+    END IF;
+END $$;
+
+CREATE SCHEMA IF NOT EXISTS jobs;
+
+-- Run history of the jobs. Scheduled runs carry the time they were due, so a
+-- slot is run once whichever instance of the scheduler gets to it first.
+CREATE TABLE IF NOT EXISTS jobs.runs (
+    run_id BIGSERIAL PRIMARY KEY,
+    job_name TEXT NOT NULL,
+    scheduled_for TIMESTAMP WITH TIME ZONE,
+    instance TEXT NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT clock_timestamp(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration INTERVAL,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    output TEXT,
+    error TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS runs_slot_idx ON jobs.runs (job_name, scheduled_for)
+    WHERE scheduled_for IS NOT NULL;
+CREATE INDEX IF NOT EXISTS runs_job_idx ON jobs.runs (job_name, started_at);
+
+REVOKE ALL ON SCHEMA jobs FROM PUBLIC;
+REVOKE ALL ON ALL TABLES IN SCHEMA jobs FROM PUBLIC;
+
+GRANT USAGE ON SCHEMA jobs TO administrator;
+GRANT SELECT ON jobs.runs TO administrator;
@@ -0,0 +1,166 @@
+-- Pricing of orders by internal/pricing: labour hours on top of the base
+-- prices of services, loyalty tier discounts, promotions and promo codes,
+-- VAT and rounding of the total. A quote keeps the itemised breakdown it was
+-- computed with; the order points at its current quote and the receipt at
+-- the quote it charged, so a receipt is reproduced from what was stored and
+-- not from today's prices.
+CREATE SCHEMA IF NOT EXISTS pricing;
+
+ALTER TABLE services ADD COLUMN IF NOT EXISTS labour_hours NUMERIC(6, 2) CHECK (labour_hours >= 0);
+
+-- The one row of settings.
+CREATE TABLE IF NOT EXISTS pricing.settings (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    vat_rate NUMERIC(5, 2) NOT NULL CHECK (vat_rate >= 0 AND vat_rate < 100),
+    prices_include_vat BOOLEAN NOT NULL,
+    -- The total is rounded to a multiple of the step, half up or down.
+    rounding_step NUMERIC(12, 2) NOT NULL CHECK (rounding_step > 0),
+    rounding_mode TEXT NOT NULL CHECK (rounding_mode IN ('half_up', 'down')),
+    -- Hourly rate of centers without one in labour_rates.
+    default_labour_rate NUMERIC(12, 2) NOT NULL CHECK (default_labour_rate >= 0)
+);
+
+INSERT INTO pricing.settings (vat_rate, prices_include_vat, rounding_step, rounding_mode, default_labour_rate)
+VALUES (20, TRUE, 1, 'half_up', 0)
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS pricing.labour_rates (
+    service_center_id INT NOT NULL REFERENCES service_centers (service_center_id) ON DELETE CASCADE,
+    vehicle_type vehicle_type NOT NULL,
+    hourly_rate NUMERIC(12, 2) NOT NULL CHECK (hourly_rate >= 0),
+    PRIMARY KEY (service_center_id, vehicle_type)
+);
+
+CREATE TABLE IF NOT EXISTS pricing.tier_discounts (
+    loyalty_status loyalty_status PRIMARY KEY,
+    percent NUMERIC(5, 2) NOT NULL CHECK (percent >= 0 AND percent <= 100)
+);
+
+INSERT INTO pricing.tier_discounts (loyalty_status, percent)
+VALUES ('Bronze', 0), ('Silver', 3), ('Gold', 5), ('Platinum', 10)
+ON CONFLICT DO NOTHING;
+
+-- A promotion discounts a service, or every service when service_id is
+-- NULL, at a center or at all of them. It does not add up with the tier
+-- discount, the larger one applies.
+CREATE TABLE IF NOT EXISTS pricing.promotions (
+    promotion_id SERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    percent NUMERIC(5, 2) NOT NULL CHECK (percent > 0 AND percent <= 100),
+    service_id INT REFERENCES services (service_id) ON DELETE CASCADE,
+    service_center_id INT REFERENCES service_centers (service_center_id) ON DELETE CASCADE,
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CHECK (ends_at > starts_at)
+);
+
+-- A promo code takes a percent or an amount off the order after the line
+-- discounts. Uses are counted when a receipt charges a quote with the code.
+CREATE TABLE IF NOT EXISTS pricing.promo_codes (
+    code TEXT PRIMARY KEY CHECK (code = upper(code)),
+    percent NUMERIC(5, 2) CHECK (percent > 0 AND percent <= 100),
+    amount NUMERIC(12, 2) CHECK (amount > 0),
+    valid_from TIMESTAMP WITH TIME ZONE,
+    valid_until TIMESTAMP WITH TIME ZONE,
+    max_uses INT CHECK (max_uses > 0),
+    uses INT NOT NULL DEFAULT 0 CHECK (uses >= 0),
+    CHECK ((percent IS NULL) <> (amount IS NULL)),
+    CHECK (uses <= max_uses)
+);
+
+CREATE TABLE IF NOT EXISTS pricing.quotes (
+    quote_id BIGSERIAL PRIMARY KEY,
+    order_id INT NOT NULL REFERENCES orders (order_id) ON DELETE CASCADE,
+    promo_code TEXT REFERENCES pricing.promo_codes (code),
+    subtotal NUMERIC(12, 2) NOT NULL,
+    discount NUMERIC(12, 2) NOT NULL,
+    vat NUMERIC(12, 2) NOT NULL,
+    rounding NUMERIC(12, 2) NOT NULL,
+    total NUMERIC(12, 2) NOT NULL CHECK (total >= 0),
+    breakdown JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_by TEXT NOT NULL DEFAULT current_user
+);
+
+CREATE INDEX IF NOT EXISTS quotes_order_idx ON pricing.quotes (order_id);
+
+-- Both stay nullable: snapshots and masked copies only take the public
+-- schema and write references to pricing as NULL.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS quote_id BIGINT REFERENCES pricing.quotes (quote_id) ON DELETE SET NULL;
+ALTER TABLE receipts ADD COLUMN IF NOT EXISTS quote_id BIGINT REFERENCES pricing.quotes (quote_id);
+
+-- An order whose services or spare parts change is no longer priced by its
+-- quote, it costs the sum of its prices until quoted again.
+CREATE OR REPLACE FUNCTION update_order_total_cost() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE orders
+    SET total_cost = (
+        COALESCE((
+            SELECT SUM(s.price)
+            FROM service_order so
+            JOIN services s ON so.service_id = s.service_id
+            WHERE so.order_id = NEW.order_id
+        ), 0)
+        +
+        COALESCE((
+            SELECT SUM(spo.purchase_price * spo.quantity)
+            FROM spare_part_order spo
+            WHERE spo.order_id = NEW.order_id
+        ), 0)
+    ),
+        quote_id = NULL
+    WHERE order_id = NEW.order_id;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- A receipt charges the current quote of its order and uses up its promo
+-- code, the receipt fails once the code has no uses left.
+CREATE OR REPLACE FUNCTION pricing.charge_quote() RETURNS TRIGGER AS $$
+DECLARE
+    v_code TEXT;
+BEGIN
+    IF NEW.quote_id IS NULL THEN
+        SELECT o.quote_id INTO NEW.quote_id FROM public.orders o WHERE o.order_id = NEW.order_id;
+    END IF;
+    SELECT q.promo_code INTO v_code FROM pricing.quotes q WHERE q.quote_id = NEW.quote_id;
+
+    IF v_code IS NOT NULL THEN
+        UPDATE pricing.promo_codes
+        SET uses = uses + 1
+        WHERE code = v_code AND (max_uses IS NULL OR uses < max_uses);
+        IF NOT FOUND THEN
+            RAISE EXCEPTION 'Promo code % is used up.', v_code;
+        END IF;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+CREATE OR REPLACE TRIGGER charge_quote_trigger
+BEFORE INSERT ON receipts
+FOR EACH ROW
+EXECUTE FUNCTION pricing.charge_quote();
+
+-- Quotes are seen and made by whoever sees the order.
+ALTER TABLE pricing.quotes ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS quotes_order_policy ON pricing.quotes;
+CREATE POLICY quotes_order_policy ON pricing.quotes
+    FOR ALL
+    USING (EXISTS (SELECT 1 FROM orders o WHERE o.order_id = quotes.order_id));
+
+REVOKE ALL ON SCHEMA pricing FROM PUBLIC;
+REVOKE EXECUTE ON ALL FUNCTIONS IN SCHEMA pricing FROM PUBLIC;
+
+GRANT USAGE ON SCHEMA pricing TO administrator, analyst, manager, master;
+GRANT SELECT, INSERT, UPDATE, DELETE ON pricing.settings, pricing.labour_rates, pricing.tier_discounts,
+    pricing.promotions, pricing.promo_codes TO administrator;
+GRANT USAGE ON SEQUENCE pricing.promotions_promotion_id_seq TO administrator;
+GRANT SELECT ON ALL TABLES IN SCHEMA pricing TO administrator, analyst;
+GRANT SELECT ON pricing.settings, pricing.labour_rates, pricing.tier_discounts, pricing.promotions,
+    pricing.promo_codes TO manager;
+GRANT SELECT, INSERT ON pricing.quotes TO manager;
+GRANT USAGE ON SEQUENCE pricing.quotes_quote_id_seq TO manager;
+GRANT SELECT ON pricing.quotes TO master;
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/db"
+)
+
+// Authenticator logs an employee in to the database with their own role,
+// the API keeps no credentials of its own.
+// Authenticate returns the database of the employee and a function to call
+// once the call is done with it.
+type Authenticator interface {
+	Authenticate(ctx context.Context, login, password string) (console.DB, func(), error)
+}
+
+// PoolAuthenticator keeps a pool per employee in the connection manager. The
+// password of a login is checked by the database the first time and against
+// a keyed hash of it later, a new password replaces the pool. A replaced pool
+// is closed once the calls using it are done.
+type PoolAuthenticator struct {
+	cfg      *db.Config
+	pools    *db.ConnectionManager
+	maxConns int32
+	key      []byte
+	mu       sync.Mutex
+	macs     map[string][]byte
+	// users counts the calls using a pool, retired holds the replaced pools
+	// still in use.
+	users   map[*pgxpool.Pool]int
+	retired map[*pgxpool.Pool]bool
+}
+
+// NewPoolAuthenticator connects employees with cfg, their login and
+// password replace its credentials.
+func NewPoolAuthenticator(cfg *db.Config, pools *db.ConnectionManager, maxConns int32) *PoolAuthenticator {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return &PoolAuthenticator{
+		cfg:      cfg,
+		pools:    pools,
+		maxConns: maxConns,
+		key:      key,
+		macs:     make(map[string][]byte),
+		users:    make(map[*pgxpool.Pool]int),
+		retired:  make(map[*pgxpool.Pool]bool),
+	}
+}
+
+func (a *PoolAuthenticator) Authenticate(ctx context.Context, login, password string) (console.DB, func(), error) {
+	h := hmac.New(sha256.New, a.key)
+	h.Write([]byte(login + "\x00" + password))
+	mac := h.Sum(nil)
+	role := "employee:" + login
+
+	a.mu.Lock()
+	if known, ok := a.macs[login]; ok && hmac.Equal(known, mac) {
+		defer a.mu.Unlock()
+		pool, release := a.use(role)
+		return pool, release, nil
+	}
+	a.mu.Unlock()
+
+	cfg := a.cfg.WithCredentials(login, password)
+	conn, err := pgx.Connect(ctx, cfg.ConnectionString())
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && (pgErr.Code == pgerrcode.InvalidPassword || pgErr.Code == pgerrcode.InvalidAuthorizationSpecification) {
+		return nil, nil, ErrBadCredentials
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to log in as %s: %w", login, err)
+	}
+	conn.Close(ctx)
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	poolCfg.MaxConns = a.maxConns
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if known, ok := a.macs[login]; !ok || !hmac.Equal(known, mac) {
+		// Unless logged in by a concurrent call, the new pool takes the
+		// place of the old one, which is closed when nobody uses it.
+		old, err := a.pools.ReplacePool(ctx, role, poolCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		a.macs[login] = mac
+		if old != nil {
+			if a.users[old] == 0 {
+				old.Close()
+			} else {
+				a.retired[old] = true
+			}
+		}
+	}
+	pool, release := a.use(role)
+	return pool, release, nil
+}
+
+// use counts a call using the pool of role, a.mu must be held.
+func (a *PoolAuthenticator) use(role string) (*pgxpool.Pool, func()) {
+	pool := a.pools.GetPool(role)
+	a.users[pool]++
+	var once sync.Once
+	return pool, func() { once.Do(func() { a.release(pool) }) }
+}
+
+func (a *PoolAuthenticator) release(pool *pgxpool.Pool) {
+	a.mu.Lock()
+	a.users[pool]--
+	done := a.users[pool] == 0 && a.retired[pool]
+	if a.users[pool] == 0 {
+		delete(a.users, pool)
+		delete(a.retired, pool)
+	}
+	a.mu.Unlock()
+	if done {
+		pool.Close()
+	}
+}
+
+// BasicAuth sends the login and password of the employee with every call.
+type BasicAuth struct {
+	Login, Password string
+	// Insecure allows sending them without TLS, for local use only.
+	Insecure bool
+}
+
+func (b BasicAuth) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token := base64.StdEncoding.EncodeToString([]byte(b.Login + ":" + b.Password))
+	return map[string]string{"authorization": "Basic " + token}, nil
+}
+
+func (b BasicAuth) RequireTransportSecurity() bool {
+	return !b.Insecure
+}
+
+type storeKey struct{}
+
+// call is what the logging interceptor learns about a call from the inner
+// ones.
+type call struct {
+	login string
+}
+
+type callKey struct{}
+
+func storeFrom(ctx context.Context) *console.Store {
+	return ctx.Value(storeKey{}).(*console.Store)
+}
+
+// authenticate finds the store of the employee of the call, release is
+// called when the call is done with it.
+func (s *Server) authenticate(ctx context.Context) (_ context.Context, release func(), err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) != 1 {
+		return nil, nil, status.Error(codes.Unauthenticated, "basic authorization is required")
+	}
+	token, ok := strings.CutPrefix(values[0], "Basic ")
+	if !ok {
+		return nil, nil, status.Error(codes.Unauthenticated, "basic authorization is required")
+	}
+	raw, err := base64.StdEncoding.DecodeString(token)
+	if err != nil {
+		return nil, nil, status.Error(codes.Unauthenticated, "malformed authorization")
+	}
+	login, password, ok := strings.Cut(string(raw), ":")
+	if !ok || login == "" {
+		return nil, nil, status.Error(codes.Unauthenticated, "malformed authorization")
+	}
+	if c, ok := ctx.Value(callKey{}).(*call); ok {
+		c.login = login
+	}
+
+	conn, release, err := s.auth.Authenticate(ctx, login, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, err := console.NewStore(ctx, conn)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return context.WithValue(ctx, storeKey{}, store), release, nil
+}
+
+func (s *Server) unaryInterceptors() []grpc.UnaryServerInterceptor {
+	return []grpc.UnaryServerInterceptor{
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			c := &call{}
+			start := time.Now()
+			resp, err := handler(context.WithValue(ctx, callKey{}, c), req)
+			logCall(c, info.FullMethod, start, err)
+			return resp, err
+		},
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			resp, err := handler(ctx, req)
+			return resp, toStatus(info.FullMethod, err)
+		},
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, release, err := s.authenticate(ctx)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return handler(ctx, req)
+		},
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *Server) streamInterceptors() []grpc.StreamServerInterceptor {
+	return []grpc.StreamServerInterceptor{
+		func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			c := &call{}
+			start := time.Now()
+			err := handler(srv, serverStream{ss, context.WithValue(ss.Context(), callKey{}, c)})
+			logCall(c, info.FullMethod, start, err)
+			return err
+		},
+		func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return toStatus(info.FullMethod, handler(srv, ss))
+		},
+		func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, release, err := s.authenticate(ss.Context())
+			if err != nil {
+				return err
+			}
+			defer release()
+			return handler(srv, serverStream{ss, ctx})
+		},
+	}
+}
+
+func logCall(c *call, method string, start time.Time, err error) {
+	login := c.login
+	if login == "" {
+		login = "-"
+	}
+	log.Printf("%s %s %s %s", login, method, status.Code(err), time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"vehicles-service-stations/internal/api/stationspb"
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/realtime"
+)
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	in := &stationspb.CreateOrderRequest{
+		CustomerId: 3,
+		MasterId:   5,
+		Date:       "2025-03-01",
+		Services:   []int64{1, 2},
+		Parts:      []*stationspb.PartQuantity{{PartId: 7, Quantity: 2}},
+	}
+	data, err := jsonCodec{}.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &stationspb.CreateOrderRequest{}
+	if err := (jsonCodec{}).Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(in, out) {
+		t.Errorf("decoded %v from %s, want %v", out, data, in)
+	}
+	if _, err := (jsonCodec{}).Marshal(struct{}{}); err == nil {
+		t.Error("marshalled a struct that is not a message")
+	}
+}
+
+type refusingAuthenticator struct{}
+
+func (refusingAuthenticator) Authenticate(ctx context.Context, login, password string) (console.DB, func(), error) {
+	return nil, nil, ErrBadCredentials
+}
+
+// Calls are refused before they reach the database, with either codec.
+func TestUnauthenticatedCalls(t *testing.T) {
+	client := newTestClient(t, NewServer(refusingAuthenticator{}, realtime.NewHub(nil, "")))
+	auth := grpc.PerRPCCredentials(BasicAuth{Login: "manager", Password: "wrong", Insecure: true})
+
+	tests := []struct {
+		name string
+		opts []grpc.CallOption
+		code codes.Code
+	}{
+		{"no credentials", nil, codes.Unauthenticated},
+		{"bad password", []grpc.CallOption{auth}, codes.Unauthenticated},
+		{"bad password over json", []grpc.CallOption{auth, grpc.CallContentSubtype(JSONCodec)}, codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ListServices(context.Background(), &emptypb.Empty{}, tt.opts...)
+			if status.Code(err) != tt.code {
+				t.Errorf("ListServices: %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
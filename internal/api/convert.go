@@ -0,0 +1,144 @@
+package api
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"vehicles-service-stations/internal/api/stationspb"
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/pricing"
+)
+
+// The replies are built from the types of the console, both run the same
+// queries.
+
+func customerReply(c *console.Customer) *stationspb.Customer {
+	return &stationspb.Customer{
+		Id:            int64(c.ID),
+		Name:          c.Name,
+		Phone:         c.Phone,
+		BonusPoints:   c.BonusPoints,
+		LoyaltyStatus: c.LoyaltyStatus,
+	}
+}
+
+func masterReply(m *console.Master) *stationspb.Master {
+	reply := &stationspb.Master{
+		Id:         int64(m.ID),
+		Name:       m.Name,
+		Experience: int32(m.Experience),
+		Active:     int32(m.Active),
+		InProgress: int32(m.InProgress),
+	}
+	if m.Next != nil {
+		reply.Next = timestamppb.New(*m.Next)
+	}
+	return reply
+}
+
+func serviceReply(s *console.Service) *stationspb.Service {
+	return &stationspb.Service{
+		Id:          int64(s.ID),
+		Name:        s.Name,
+		VehicleType: s.VehicleType,
+		Price:       s.Price,
+	}
+}
+
+func partReply(p *console.Part) *stationspb.SparePart {
+	return &stationspb.SparePart{
+		Id:      int64(p.ID),
+		Name:    p.Name,
+		Article: int64(p.Article),
+		Price:   p.Price,
+		Stock:   int32(p.Stock),
+	}
+}
+
+func orderReply(o *console.Order) *stationspb.Order {
+	return &stationspb.Order{
+		Id:            int64(o.ID),
+		ScheduledDate: timestamppb.New(o.ScheduledDate),
+		Status:        o.Status,
+		Customer:      o.Customer,
+		Phone:         o.Phone,
+		Master:        o.Master,
+		TotalCost:     o.TotalCost,
+		Receipt:       o.Receipt,
+	}
+}
+
+func orderLines(lines []console.OrderLine) []*stationspb.OrderLine {
+	reply := make([]*stationspb.OrderLine, 0, len(lines))
+	for _, l := range lines {
+		reply = append(reply, &stationspb.OrderLine{Name: l.Name, Quantity: int32(l.Quantity), Price: l.Price})
+	}
+	return reply
+}
+
+func orderDetailReply(o *console.OrderDetail) *stationspb.OrderDetail {
+	reply := &stationspb.OrderDetail{
+		Order:       orderReply(&o.Order),
+		CustomerId:  int64(o.CustomerID),
+		BonusPoints: o.BonusPoints,
+		Services:    orderLines(o.Services),
+		Parts:       orderLines(o.Parts),
+	}
+	if o.Quote != nil {
+		reply.Quote = quoteReply(o.Quote)
+	}
+	return reply
+}
+
+func adjustmentReply(a *pricing.Adjustment) *stationspb.Adjustment {
+	if a == nil {
+		return nil
+	}
+	return &stationspb.Adjustment{
+		Reason:      a.Reason,
+		PromotionId: int64(a.PromotionID),
+		Percent:     int64(a.Percent),
+		Amount:      int64(a.Amount),
+	}
+}
+
+func quoteReply(q *pricing.Quote) *stationspb.Quote {
+	reply := &stationspb.Quote{
+		OrderId:          int64(q.OrderID),
+		QuotedAt:         timestamppb.New(q.QuotedAt),
+		Tier:             q.Tier,
+		Lines:            make([]*stationspb.QuoteLine, 0, len(q.Lines)),
+		Subtotal:         int64(q.Subtotal),
+		Code:             q.Code,
+		PromoCode:        adjustmentReply(q.PromoCode),
+		Discount:         int64(q.Discount),
+		VatRate:          int64(q.VATRate),
+		PricesIncludeVat: q.PricesIncludeVAT,
+		Vat:              int64(q.VAT),
+		Rounding:         int64(q.Rounding),
+		Total:            int64(q.Total),
+	}
+	for _, l := range q.Lines {
+		reply.Lines = append(reply.Lines, &stationspb.QuoteLine{
+			Kind:        string(l.Kind),
+			Id:          int64(l.ID),
+			Name:        l.Name,
+			Quantity:    int32(l.Quantity),
+			UnitPrice:   int64(l.UnitPrice),
+			LabourHours: int64(l.LabourHours),
+			LabourRate:  int64(l.LabourRate),
+			Amount:      int64(l.Amount),
+			Discount:    adjustmentReply(l.Discount),
+			Total:       int64(l.Total),
+		})
+	}
+	return reply
+}
+
+func receiptReply(r *console.Receipt) *stationspb.Receipt {
+	return &stationspb.Receipt{
+		Id:          int64(r.ID),
+		BonusPoints: r.BonusPoints,
+		TotalPaid:   r.TotalPaid,
+		Date:        timestamppb.New(r.Date),
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"vehicles-service-stations/internal/console"
+)
+
+// ErrBadCredentials is returned for logins the database refused.
+var ErrBadCredentials = errors.New("invalid login or password")
+
+var domainCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{ErrBadCredentials, codes.Unauthenticated},
+	{console.ErrNotEmployee, codes.PermissionDenied},
+	{console.ErrNotFound, codes.NotFound},
+	{console.ErrMasterBusy, codes.FailedPrecondition},
+	{console.ErrNotAllowed, codes.FailedPrecondition},
+	{console.ErrOutOfStock, codes.FailedPrecondition},
+	{console.ErrReceiptIssued, codes.AlreadyExists},
+	{console.ErrBonusPoints, codes.InvalidArgument},
+}
+
+// toStatus translates the errors of the domain and of the schema to status
+// codes. Exceptions raised by the triggers keep their message, it is meant
+// for people. Unknown errors are logged and hidden from the client.
+func toStatus(method string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	for _, d := range domainCodes {
+		if errors.Is(err, d.err) {
+			return status.Error(d.code, err.Error())
+		}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.RaiseException:
+			return status.Error(codes.FailedPrecondition, pgErr.Message)
+		case pgerrcode.InsufficientPrivilege:
+			return status.Error(codes.PermissionDenied, pgErr.Message)
+		case pgerrcode.UniqueViolation:
+			return status.Error(codes.AlreadyExists, pgErr.Message)
+		case pgerrcode.ForeignKeyViolation, pgerrcode.CheckViolation, pgerrcode.NotNullViolation,
+			pgerrcode.InvalidTextRepresentation, pgerrcode.StringDataRightTruncationDataException,
+			pgerrcode.NumericValueOutOfRange, pgerrcode.InvalidDatetimeFormat, pgerrcode.DatetimeFieldOverflow:
+			return status.Error(codes.InvalidArgument, pgErr.Message)
+		case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected, pgerrcode.LockNotAvailable:
+			return status.Error(codes.Aborted, pgErr.Message)
+		case pgerrcode.TooManyConnections, pgerrcode.CannotConnectNow, pgerrcode.AdminShutdown:
+			return status.Error(codes.Unavailable, pgErr.Message)
+		}
+	}
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) {
+		log.Printf("%s: %v", method, err)
+		return status.Error(codes.Unavailable, "database is unavailable")
+	}
+
+	log.Printf("%s: %v", method, err)
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,222 @@
+package api
+
+//go:generate protoc -I ../../api --go_out=stationspb --go_opt=paths=source_relative --go-grpc_out=stationspb --go-grpc_opt=paths=source_relative stations.proto
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"vehicles-service-stations/internal/api/stationspb"
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/realtime"
+)
+
+// Server runs the API of api/stations.proto with the stores of the
+// console: every call is made as the employee that sent it.
+type Server struct {
+	stationspb.UnimplementedStationsServer
+
+	auth Authenticator
+	hub  *realtime.Hub
+}
+
+func NewServer(auth Authenticator, hub *realtime.Hub) *Server {
+	return &Server{auth: auth, hub: hub}
+}
+
+// NewGRPCServer returns a gRPC server with the API and its interceptors:
+// logging, status codes for the errors and authentication.
+func NewGRPCServer(srv *Server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(srv.unaryInterceptors()...),
+		grpc.ChainStreamInterceptor(srv.streamInterceptors()...),
+	)
+	s := grpc.NewServer(opts...)
+	stationspb.RegisterStationsServer(s, srv)
+	return s
+}
+
+func (s *Server) SearchCustomers(ctx context.Context, req *stationspb.SearchCustomersRequest) (*stationspb.CustomersReply, error) {
+	customers, err := storeFrom(ctx).SearchCustomers(ctx, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	reply := &stationspb.CustomersReply{}
+	for i := range customers {
+		reply.Customers = append(reply.Customers, customerReply(&customers[i]))
+	}
+	return reply, nil
+}
+
+func (s *Server) CreateCustomer(ctx context.Context, req *stationspb.CreateCustomerRequest) (*stationspb.Customer, error) {
+	if req.Name == "" || req.Phone == "" {
+		return nil, status.Error(codes.InvalidArgument, "name and phone are required")
+	}
+	customer, err := storeFrom(ctx).CreateCustomer(ctx, req.Name, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+	return customerReply(customer), nil
+}
+
+func (s *Server) ListServices(ctx context.Context, _ *emptypb.Empty) (*stationspb.ServicesReply, error) {
+	services, err := storeFrom(ctx).Services(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &stationspb.ServicesReply{}
+	for i := range services {
+		reply.Services = append(reply.Services, serviceReply(&services[i]))
+	}
+	return reply, nil
+}
+
+func (s *Server) ListSpareParts(ctx context.Context, _ *emptypb.Empty) (*stationspb.SparePartsReply, error) {
+	parts, err := storeFrom(ctx).Parts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &stationspb.SparePartsReply{}
+	for i := range parts {
+		reply.Parts = append(reply.Parts, partReply(&parts[i]))
+	}
+	return reply, nil
+}
+
+func (s *Server) ListMasters(ctx context.Context, _ *emptypb.Empty) (*stationspb.MastersReply, error) {
+	masters, err := storeFrom(ctx).Masters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply := &stationspb.MastersReply{}
+	for i := range masters {
+		reply.Masters = append(reply.Masters, masterReply(&masters[i]))
+	}
+	return reply, nil
+}
+
+func (s *Server) ListOrders(ctx context.Context, req *stationspb.ListOrdersRequest) (*stationspb.OrdersReply, error) {
+	orders, err := storeFrom(ctx).Orders(ctx, req.Status)
+	if err != nil {
+		return nil, err
+	}
+	reply := &stationspb.OrdersReply{}
+	for i := range orders {
+		reply.Orders = append(reply.Orders, orderReply(&orders[i]))
+	}
+	return reply, nil
+}
+
+func (s *Server) GetOrder(ctx context.Context, req *stationspb.OrderRequest) (*stationspb.OrderDetail, error) {
+	return s.order(ctx, storeFrom(ctx), req.OrderId)
+}
+
+func (s *Server) CreateOrder(ctx context.Context, req *stationspb.CreateOrderRequest) (*stationspb.OrderDetail, error) {
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "date must be given as 2006-01-02")
+	}
+	if req.CustomerId == 0 || req.MasterId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "customer and master are required")
+	}
+	draft := console.OrderDraft{
+		CustomerID: int(req.CustomerId),
+		MasterID:   int(req.MasterId),
+		Date:       date,
+	}
+	for _, id := range req.Services {
+		draft.Services = append(draft.Services, int(id))
+	}
+	for _, p := range req.Parts {
+		if p.Quantity <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "quantity of spare part %d must be positive", p.PartId)
+		}
+		draft.Parts = append(draft.Parts, console.PartQuantity{PartID: int(p.PartId), Quantity: int(p.Quantity)})
+	}
+	store := storeFrom(ctx)
+	id, err := store.CreateOrder(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+	return s.order(ctx, store, int64(id))
+}
+
+func (s *Server) CompleteOrder(ctx context.Context, req *stationspb.OrderRequest) (*stationspb.OrderDetail, error) {
+	store := storeFrom(ctx)
+	if err := store.Complete(ctx, int(req.OrderId)); err != nil {
+		return nil, err
+	}
+	return s.order(ctx, store, req.OrderId)
+}
+
+func (s *Server) IssueReceipt(ctx context.Context, req *stationspb.IssueReceiptRequest) (*stationspb.Receipt, error) {
+	receipt, err := storeFrom(ctx).IssueReceipt(ctx, int(req.OrderId), req.BonusPoints)
+	if err != nil {
+		return nil, err
+	}
+	return receiptReply(receipt), nil
+}
+
+func (s *Server) order(ctx context.Context, store *console.Store, id int64) (*stationspb.OrderDetail, error) {
+	order, err := store.Order(ctx, int(id))
+	if err != nil {
+		return nil, err
+	}
+	return orderDetailReply(order), nil
+}
+
+// WatchOrders streams the orders of a center or a master as they change.
+// Every changed order is read again as the employee, the orders they do
+// not see are skipped.
+func (s *Server) WatchOrders(req *stationspb.WatchOrdersRequest, stream stationspb.Stations_WatchOrdersServer) error {
+	ctx := stream.Context()
+	store := storeFrom(ctx)
+	if req.ServiceCenterId != 0 && req.MasterId != 0 {
+		return status.Error(codes.InvalidArgument, "watch either a center or a master")
+	}
+	filter := realtime.Filter{
+		Kinds:    []realtime.Kind{realtime.OrderCreated, realtime.OrderUpdated},
+		CenterID: int(req.ServiceCenterId),
+		MasterID: int(req.MasterId),
+	}
+	if filter.CenterID == 0 && filter.MasterID == 0 {
+		if me := store.Me(); me.Role == console.RoleMaster {
+			filter.MasterID = me.ID
+		} else {
+			filter.CenterID = me.CenterID
+		}
+	}
+
+	sub := s.hub.Subscribe(filter)
+	defer sub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "the watcher fell behind, watch again")
+			}
+			order, err := s.order(ctx, store, int64(e.OrderID))
+			if errors.Is(err, console.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			op := "UPDATE"
+			if e.Kind == realtime.OrderCreated {
+				op = "INSERT"
+			}
+			if err := stream.Send(&stationspb.OrderEvent{Op: op, Order: order}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"vehicles-service-stations/internal/api/stationspb"
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/realtime"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// newTestClient serves srv in memory until the test ends.
+func newTestClient(t *testing.T, srv *Server) stationspb.StationsClient {
+	t.Helper()
+	server := NewGRPCServer(srv)
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///api",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return stationspb.NewStationsClient(conn)
+}
+
+// txAuthenticator logs everybody in to the transaction of the test, it is
+// switched to the employee with testutil.LoginAs.
+type txAuthenticator struct {
+	tx pgx.Tx
+}
+
+func (a txAuthenticator) Authenticate(ctx context.Context, login, password string) (console.DB, func(), error) {
+	return a.tx, func() {}, nil
+}
+
+// A manager creates an order over gRPC and a watcher of the center is told
+// about it; the exceptions of the triggers and the errors of the console come
+// back as status codes, and a master of another order does not see it.
+func TestOrdersAndStatusCodes(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "api")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	serviceID := testutil.NewService(t, tx, 1000)
+	partID := testutil.NewPart(t, tx, 1)
+	testutil.LoginAs(t, tx, b.Manager, "manager")
+
+	hub := realtime.NewHub(nil, "")
+	client := newTestClient(t, NewServer(txAuthenticator{tx}, hub))
+	auth := grpc.PerRPCCredentials(BasicAuth{Login: "manager", Password: "secret", Insecure: true})
+	date := testutil.Day.Format(time.DateOnly)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.WatchOrders(watchCtx, &stationspb.WatchOrdersRequest{}, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for hub.Subscribers() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	order, err := client.CreateOrder(ctx, &stationspb.CreateOrderRequest{
+		CustomerId: int64(b.Customer),
+		MasterId:   int64(b.Masters[0]),
+		Date:       date,
+		Services:   []int64{int64(serviceID)},
+		Parts:      []*stationspb.PartQuantity{{PartId: int64(partID), Quantity: 1}},
+	}, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderID := order.Order.Id
+	if order.Order.TotalCost != 1100 || order.Order.Status != "Pending" {
+		t.Fatalf("created %v, want a pending order for 1100", order)
+	}
+
+	// Notifications are delivered on commit and the test rolls back, the
+	// hub is told directly instead.
+	hub.Publish(realtime.Event{Kind: realtime.OrderCreated, OrderID: int(orderID), CenterID: b.Center + 1000000})
+	hub.Publish(realtime.Event{Kind: realtime.OrderCreated, OrderID: int(orderID), CenterID: b.Center, MasterID: b.Masters[0]})
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Op != "INSERT" || event.Order.Order.Id != orderID {
+		t.Errorf("watched %v, want order %d", event, orderID)
+	}
+	cancel()
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"call without credentials", func() error {
+			_, err := client.ListServices(ctx, &emptypb.Empty{})
+			return err
+		}, codes.Unauthenticated},
+		{"order of a busy master", func() error {
+			_, err := client.CreateOrder(ctx, &stationspb.CreateOrderRequest{
+				CustomerId: int64(b.Customer), MasterId: int64(b.Masters[0]), Date: date,
+			}, auth)
+			return err
+		}, codes.FailedPrecondition},
+		{"order of parts out of stock", func() error {
+			_, err := client.CreateOrder(ctx, &stationspb.CreateOrderRequest{
+				CustomerId: int64(b.Customer), MasterId: int64(b.Masters[1]), Date: date,
+				Parts: []*stationspb.PartQuantity{{PartId: int64(partID), Quantity: 1}},
+			}, auth)
+			return err
+		}, codes.FailedPrecondition},
+		{"order without a date", func() error {
+			_, err := client.CreateOrder(ctx, &stationspb.CreateOrderRequest{
+				CustomerId: int64(b.Customer), MasterId: int64(b.Masters[1]), Date: "tomorrow",
+			}, auth)
+			return err
+		}, codes.InvalidArgument},
+		{"receipt of a pending order", func() error {
+			_, err := client.IssueReceipt(ctx, &stationspb.IssueReceiptRequest{OrderId: orderID}, auth)
+			return err
+		}, codes.FailedPrecondition},
+		{"missing order", func() error {
+			_, err := client.GetOrder(ctx, &stationspb.OrderRequest{OrderId: -1}, auth)
+			return err
+		}, codes.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); status.Code(err) != tt.code {
+				t.Errorf("%v, want %s", err, tt.code)
+			}
+		})
+	}
+
+	if order, err = client.CompleteOrder(ctx, &stationspb.OrderRequest{OrderId: orderID}, auth); err != nil {
+		t.Fatal(err)
+	}
+	if order.Order.Status != "Completed" {
+		t.Fatalf("completed order is %s", order.Order.Status)
+	}
+	_, err = client.IssueReceipt(ctx, &stationspb.IssueReceiptRequest{OrderId: orderID, BonusPoints: 1e6}, auth)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("receipt spending too many points: %v, want %s", err, codes.InvalidArgument)
+	}
+	receipt, err := client.IssueReceipt(ctx, &stationspb.IssueReceiptRequest{OrderId: orderID}, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TotalPaid != 1100 {
+		t.Errorf("receipt paid %.2f, want 1100", receipt.TotalPaid)
+	}
+	_, err = client.IssueReceipt(ctx, &stationspb.IssueReceiptRequest{OrderId: orderID}, auth)
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("second receipt: %v, want %s", err, codes.AlreadyExists)
+	}
+
+	testutil.LoginAs(t, tx, b.Masters[1], "master")
+	_, err = client.GetOrder(ctx, &stationspb.OrderRequest{OrderId: orderID}, auth)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("order of another master: %v, want %s", err, codes.NotFound)
+	}
+	orders, err := client.ListOrders(ctx, &stationspb.ListOrdersRequest{}, auth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders.Orders) != 0 {
+		t.Errorf("master of no orders sees %v", orders.Orders)
+	}
+}
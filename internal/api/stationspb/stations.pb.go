@@ -0,0 +1,1933 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: stations.proto
+
+// The API of the service stations for the mobile and the workshop terminal
+// clients. Employees call it with their database login and password in the
+// authorization metadata, row level security applies to them as in the
+// console.
+
+package stationspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Customer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Phone         string                 `protobuf:"bytes,3,opt,name=phone,proto3" json:"phone,omitempty"`
+	BonusPoints   float64                `protobuf:"fixed64,4,opt,name=bonus_points,json=bonusPoints,proto3" json:"bonus_points,omitempty"`
+	LoyaltyStatus string                 `protobuf:"bytes,5,opt,name=loyalty_status,json=loyaltyStatus,proto3" json:"loyalty_status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Customer) Reset() {
+	*x = Customer{}
+	mi := &file_stations_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Customer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Customer) ProtoMessage() {}
+
+func (x *Customer) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Customer.ProtoReflect.Descriptor instead.
+func (*Customer) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Customer) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Customer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Customer) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Customer) GetBonusPoints() float64 {
+	if x != nil {
+		return x.BonusPoints
+	}
+	return 0
+}
+
+func (x *Customer) GetLoyaltyStatus() string {
+	if x != nil {
+		return x.LoyaltyStatus
+	}
+	return ""
+}
+
+// Master is a master of the center with their load: active orders and the
+// nearest day they are booked.
+type Master struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Experience    int32                  `protobuf:"varint,3,opt,name=experience,proto3" json:"experience,omitempty"`
+	Active        int32                  `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	InProgress    int32                  `protobuf:"varint,5,opt,name=in_progress,json=inProgress,proto3" json:"in_progress,omitempty"`
+	Next          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=next,proto3" json:"next,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Master) Reset() {
+	*x = Master{}
+	mi := &file_stations_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Master) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Master) ProtoMessage() {}
+
+func (x *Master) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Master.ProtoReflect.Descriptor instead.
+func (*Master) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Master) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Master) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Master) GetExperience() int32 {
+	if x != nil {
+		return x.Experience
+	}
+	return 0
+}
+
+func (x *Master) GetActive() int32 {
+	if x != nil {
+		return x.Active
+	}
+	return 0
+}
+
+func (x *Master) GetInProgress() int32 {
+	if x != nil {
+		return x.InProgress
+	}
+	return 0
+}
+
+func (x *Master) GetNext() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Next
+	}
+	return nil
+}
+
+type Service struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	VehicleType   string                 `protobuf:"bytes,3,opt,name=vehicle_type,json=vehicleType,proto3" json:"vehicle_type,omitempty"`
+	Price         float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_stations_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Service) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Service) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Service) GetVehicleType() string {
+	if x != nil {
+		return x.VehicleType
+	}
+	return ""
+}
+
+func (x *Service) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+type SparePart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Article       int64                  `protobuf:"varint,3,opt,name=article,proto3" json:"article,omitempty"`
+	Price         float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	Stock         int32                  `protobuf:"varint,5,opt,name=stock,proto3" json:"stock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SparePart) Reset() {
+	*x = SparePart{}
+	mi := &file_stations_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SparePart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SparePart) ProtoMessage() {}
+
+func (x *SparePart) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SparePart.ProtoReflect.Descriptor instead.
+func (*SparePart) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SparePart) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SparePart) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SparePart) GetArticle() int64 {
+	if x != nil {
+		return x.Article
+	}
+	return 0
+}
+
+func (x *SparePart) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SparePart) GetStock() int32 {
+	if x != nil {
+		return x.Stock
+	}
+	return 0
+}
+
+type PartQuantity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PartId        int64                  `protobuf:"varint,1,opt,name=part_id,json=partId,proto3" json:"part_id,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartQuantity) Reset() {
+	*x = PartQuantity{}
+	mi := &file_stations_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartQuantity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartQuantity) ProtoMessage() {}
+
+func (x *PartQuantity) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartQuantity.ProtoReflect.Descriptor instead.
+func (*PartQuantity) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PartQuantity) GetPartId() int64 {
+	if x != nil {
+		return x.PartId
+	}
+	return 0
+}
+
+func (x *PartQuantity) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type Order struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ScheduledDate *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=scheduled_date,json=scheduledDate,proto3" json:"scheduled_date,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Customer      string                 `protobuf:"bytes,4,opt,name=customer,proto3" json:"customer,omitempty"`
+	Phone         string                 `protobuf:"bytes,5,opt,name=phone,proto3" json:"phone,omitempty"`
+	Master        string                 `protobuf:"bytes,6,opt,name=master,proto3" json:"master,omitempty"`
+	TotalCost     float64                `protobuf:"fixed64,7,opt,name=total_cost,json=totalCost,proto3" json:"total_cost,omitempty"`
+	// Receipt is unknown to masters, they cannot read receipts.
+	Receipt       bool `protobuf:"varint,8,opt,name=receipt,proto3" json:"receipt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_stations_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Order) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Order) GetScheduledDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ScheduledDate
+	}
+	return nil
+}
+
+func (x *Order) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Order) GetCustomer() string {
+	if x != nil {
+		return x.Customer
+	}
+	return ""
+}
+
+func (x *Order) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+func (x *Order) GetMaster() string {
+	if x != nil {
+		return x.Master
+	}
+	return ""
+}
+
+func (x *Order) GetTotalCost() float64 {
+	if x != nil {
+		return x.TotalCost
+	}
+	return 0
+}
+
+func (x *Order) GetReceipt() bool {
+	if x != nil {
+		return x.Receipt
+	}
+	return false
+}
+
+type OrderLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity      int32                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderLine) Reset() {
+	*x = OrderLine{}
+	mi := &file_stations_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderLine) ProtoMessage() {}
+
+func (x *OrderLine) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderLine.ProtoReflect.Descriptor instead.
+func (*OrderLine) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *OrderLine) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *OrderLine) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderLine) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+type OrderDetail struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Order       *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	CustomerId  int64                  `protobuf:"varint,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	BonusPoints float64                `protobuf:"fixed64,3,opt,name=bonus_points,json=bonusPoints,proto3" json:"bonus_points,omitempty"`
+	Services    []*OrderLine           `protobuf:"bytes,4,rep,name=services,proto3" json:"services,omitempty"`
+	Parts       []*OrderLine           `protobuf:"bytes,5,rep,name=parts,proto3" json:"parts,omitempty"`
+	// Quote is unset for orders changed since they were priced.
+	Quote         *Quote `protobuf:"bytes,6,opt,name=quote,proto3" json:"quote,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderDetail) Reset() {
+	*x = OrderDetail{}
+	mi := &file_stations_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderDetail) ProtoMessage() {}
+
+func (x *OrderDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderDetail.ProtoReflect.Descriptor instead.
+func (*OrderDetail) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *OrderDetail) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+func (x *OrderDetail) GetCustomerId() int64 {
+	if x != nil {
+		return x.CustomerId
+	}
+	return 0
+}
+
+func (x *OrderDetail) GetBonusPoints() float64 {
+	if x != nil {
+		return x.BonusPoints
+	}
+	return 0
+}
+
+func (x *OrderDetail) GetServices() []*OrderLine {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *OrderDetail) GetParts() []*OrderLine {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+func (x *OrderDetail) GetQuote() *Quote {
+	if x != nil {
+		return x.Quote
+	}
+	return nil
+}
+
+// Amounts of the quote are in kopecks, percents, rates and hours in
+// hundredths.
+type Adjustment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	PromotionId   int64                  `protobuf:"varint,2,opt,name=promotion_id,json=promotionId,proto3" json:"promotion_id,omitempty"`
+	Percent       int64                  `protobuf:"varint,3,opt,name=percent,proto3" json:"percent,omitempty"`
+	Amount        int64                  `protobuf:"varint,4,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Adjustment) Reset() {
+	*x = Adjustment{}
+	mi := &file_stations_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Adjustment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Adjustment) ProtoMessage() {}
+
+func (x *Adjustment) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Adjustment.ProtoReflect.Descriptor instead.
+func (*Adjustment) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Adjustment) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Adjustment) GetPromotionId() int64 {
+	if x != nil {
+		return x.PromotionId
+	}
+	return 0
+}
+
+func (x *Adjustment) GetPercent() int64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+func (x *Adjustment) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type QuoteLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Quantity      int32                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	UnitPrice     int64                  `protobuf:"varint,5,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`
+	LabourHours   int64                  `protobuf:"varint,6,opt,name=labour_hours,json=labourHours,proto3" json:"labour_hours,omitempty"`
+	LabourRate    int64                  `protobuf:"varint,7,opt,name=labour_rate,json=labourRate,proto3" json:"labour_rate,omitempty"`
+	Amount        int64                  `protobuf:"varint,8,opt,name=amount,proto3" json:"amount,omitempty"`
+	Discount      *Adjustment            `protobuf:"bytes,9,opt,name=discount,proto3" json:"discount,omitempty"`
+	Total         int64                  `protobuf:"varint,10,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuoteLine) Reset() {
+	*x = QuoteLine{}
+	mi := &file_stations_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuoteLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuoteLine) ProtoMessage() {}
+
+func (x *QuoteLine) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuoteLine.ProtoReflect.Descriptor instead.
+func (*QuoteLine) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *QuoteLine) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *QuoteLine) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *QuoteLine) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetUnitPrice() int64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetLabourHours() int64 {
+	if x != nil {
+		return x.LabourHours
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetLabourRate() int64 {
+	if x != nil {
+		return x.LabourRate
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *QuoteLine) GetDiscount() *Adjustment {
+	if x != nil {
+		return x.Discount
+	}
+	return nil
+}
+
+func (x *QuoteLine) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type Quote struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	OrderId          int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	QuotedAt         *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=quoted_at,json=quotedAt,proto3" json:"quoted_at,omitempty"`
+	Tier             string                 `protobuf:"bytes,3,opt,name=tier,proto3" json:"tier,omitempty"`
+	Lines            []*QuoteLine           `protobuf:"bytes,4,rep,name=lines,proto3" json:"lines,omitempty"`
+	Subtotal         int64                  `protobuf:"varint,5,opt,name=subtotal,proto3" json:"subtotal,omitempty"`
+	Code             string                 `protobuf:"bytes,6,opt,name=code,proto3" json:"code,omitempty"`
+	PromoCode        *Adjustment            `protobuf:"bytes,7,opt,name=promo_code,json=promoCode,proto3" json:"promo_code,omitempty"`
+	Discount         int64                  `protobuf:"varint,8,opt,name=discount,proto3" json:"discount,omitempty"`
+	VatRate          int64                  `protobuf:"varint,9,opt,name=vat_rate,json=vatRate,proto3" json:"vat_rate,omitempty"`
+	PricesIncludeVat bool                   `protobuf:"varint,10,opt,name=prices_include_vat,json=pricesIncludeVat,proto3" json:"prices_include_vat,omitempty"`
+	Vat              int64                  `protobuf:"varint,11,opt,name=vat,proto3" json:"vat,omitempty"`
+	Rounding         int64                  `protobuf:"varint,12,opt,name=rounding,proto3" json:"rounding,omitempty"`
+	Total            int64                  `protobuf:"varint,13,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Quote) Reset() {
+	*x = Quote{}
+	mi := &file_stations_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quote) ProtoMessage() {}
+
+func (x *Quote) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quote.ProtoReflect.Descriptor instead.
+func (*Quote) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Quote) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *Quote) GetQuotedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.QuotedAt
+	}
+	return nil
+}
+
+func (x *Quote) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *Quote) GetLines() []*QuoteLine {
+	if x != nil {
+		return x.Lines
+	}
+	return nil
+}
+
+func (x *Quote) GetSubtotal() int64 {
+	if x != nil {
+		return x.Subtotal
+	}
+	return 0
+}
+
+func (x *Quote) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Quote) GetPromoCode() *Adjustment {
+	if x != nil {
+		return x.PromoCode
+	}
+	return nil
+}
+
+func (x *Quote) GetDiscount() int64 {
+	if x != nil {
+		return x.Discount
+	}
+	return 0
+}
+
+func (x *Quote) GetVatRate() int64 {
+	if x != nil {
+		return x.VatRate
+	}
+	return 0
+}
+
+func (x *Quote) GetPricesIncludeVat() bool {
+	if x != nil {
+		return x.PricesIncludeVat
+	}
+	return false
+}
+
+func (x *Quote) GetVat() int64 {
+	if x != nil {
+		return x.Vat
+	}
+	return 0
+}
+
+func (x *Quote) GetRounding() int64 {
+	if x != nil {
+		return x.Rounding
+	}
+	return 0
+}
+
+func (x *Quote) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type Receipt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	BonusPoints   float64                `protobuf:"fixed64,2,opt,name=bonus_points,json=bonusPoints,proto3" json:"bonus_points,omitempty"`
+	TotalPaid     float64                `protobuf:"fixed64,3,opt,name=total_paid,json=totalPaid,proto3" json:"total_paid,omitempty"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Receipt) Reset() {
+	*x = Receipt{}
+	mi := &file_stations_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Receipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Receipt) ProtoMessage() {}
+
+func (x *Receipt) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Receipt.ProtoReflect.Descriptor instead.
+func (*Receipt) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Receipt) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Receipt) GetBonusPoints() float64 {
+	if x != nil {
+		return x.BonusPoints
+	}
+	return 0
+}
+
+func (x *Receipt) GetTotalPaid() float64 {
+	if x != nil {
+		return x.TotalPaid
+	}
+	return 0
+}
+
+func (x *Receipt) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+type SearchCustomersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Phone is matched by its digits, any part of the number will do.
+	Phone         string `protobuf:"bytes,1,opt,name=phone,proto3" json:"phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchCustomersRequest) Reset() {
+	*x = SearchCustomersRequest{}
+	mi := &file_stations_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchCustomersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchCustomersRequest) ProtoMessage() {}
+
+func (x *SearchCustomersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchCustomersRequest.ProtoReflect.Descriptor instead.
+func (*SearchCustomersRequest) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SearchCustomersRequest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+type CustomersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Customers     []*Customer            `protobuf:"bytes,1,rep,name=customers,proto3" json:"customers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomersReply) Reset() {
+	*x = CustomersReply{}
+	mi := &file_stations_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomersReply) ProtoMessage() {}
+
+func (x *CustomersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomersReply.ProtoReflect.Descriptor instead.
+func (*CustomersReply) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CustomersReply) GetCustomers() []*Customer {
+	if x != nil {
+		return x.Customers
+	}
+	return nil
+}
+
+type CreateCustomerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Phone         string                 `protobuf:"bytes,2,opt,name=phone,proto3" json:"phone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateCustomerRequest) Reset() {
+	*x = CreateCustomerRequest{}
+	mi := &file_stations_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateCustomerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCustomerRequest) ProtoMessage() {}
+
+func (x *CreateCustomerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCustomerRequest.ProtoReflect.Descriptor instead.
+func (*CreateCustomerRequest) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CreateCustomerRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCustomerRequest) GetPhone() string {
+	if x != nil {
+		return x.Phone
+	}
+	return ""
+}
+
+type ServicesReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Services      []*Service             `protobuf:"bytes,1,rep,name=services,proto3" json:"services,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServicesReply) Reset() {
+	*x = ServicesReply{}
+	mi := &file_stations_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServicesReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServicesReply) ProtoMessage() {}
+
+func (x *ServicesReply) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServicesReply.ProtoReflect.Descriptor instead.
+func (*ServicesReply) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ServicesReply) GetServices() []*Service {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+type SparePartsReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Parts         []*SparePart           `protobuf:"bytes,1,rep,name=parts,proto3" json:"parts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SparePartsReply) Reset() {
+	*x = SparePartsReply{}
+	mi := &file_stations_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SparePartsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SparePartsReply) ProtoMessage() {}
+
+func (x *SparePartsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SparePartsReply.ProtoReflect.Descriptor instead.
+func (*SparePartsReply) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SparePartsReply) GetParts() []*SparePart {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type MastersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Masters       []*Master              `protobuf:"bytes,1,rep,name=masters,proto3" json:"masters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MastersReply) Reset() {
+	*x = MastersReply{}
+	mi := &file_stations_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MastersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MastersReply) ProtoMessage() {}
+
+func (x *MastersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MastersReply.ProtoReflect.Descriptor instead.
+func (*MastersReply) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MastersReply) GetMasters() []*Master {
+	if x != nil {
+		return x.Masters
+	}
+	return nil
+}
+
+type ListOrdersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Status lists the orders of one status, all of them when empty.
+	Status        string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersRequest) Reset() {
+	*x = ListOrdersRequest{}
+	mi := &file_stations_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersRequest) ProtoMessage() {}
+
+func (x *ListOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListOrdersRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type OrdersReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrdersReply) Reset() {
+	*x = OrdersReply{}
+	mi := &file_stations_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrdersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrdersReply) ProtoMessage() {}
+
+func (x *OrdersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrdersReply.ProtoReflect.Descriptor instead.
+func (*OrdersReply) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *OrdersReply) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+type OrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderRequest) Reset() {
+	*x = OrderRequest{}
+	mi := &file_stations_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRequest) ProtoMessage() {}
+
+func (x *OrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRequest.ProtoReflect.Descriptor instead.
+func (*OrderRequest) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *OrderRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type CreateOrderRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId int64                  `protobuf:"varint,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	MasterId   int64                  `protobuf:"varint,2,opt,name=master_id,json=masterId,proto3" json:"master_id,omitempty"`
+	// Date is the day of the visit, as 2006-01-02.
+	Date          string          `protobuf:"bytes,3,opt,name=date,proto3" json:"date,omitempty"`
+	Services      []int64         `protobuf:"varint,4,rep,packed,name=services,proto3" json:"services,omitempty"`
+	Parts         []*PartQuantity `protobuf:"bytes,5,rep,name=parts,proto3" json:"parts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateOrderRequest) Reset() {
+	*x = CreateOrderRequest{}
+	mi := &file_stations_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateOrderRequest) ProtoMessage() {}
+
+func (x *CreateOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateOrderRequest.ProtoReflect.Descriptor instead.
+func (*CreateOrderRequest) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CreateOrderRequest) GetCustomerId() int64 {
+	if x != nil {
+		return x.CustomerId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetMasterId() int64 {
+	if x != nil {
+		return x.MasterId
+	}
+	return 0
+}
+
+func (x *CreateOrderRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetServices() []int64 {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *CreateOrderRequest) GetParts() []*PartQuantity {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type IssueReceiptRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	BonusPoints   float64                `protobuf:"fixed64,2,opt,name=bonus_points,json=bonusPoints,proto3" json:"bonus_points,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueReceiptRequest) Reset() {
+	*x = IssueReceiptRequest{}
+	mi := &file_stations_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueReceiptRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueReceiptRequest) ProtoMessage() {}
+
+func (x *IssueReceiptRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueReceiptRequest.ProtoReflect.Descriptor instead.
+func (*IssueReceiptRequest) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *IssueReceiptRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *IssueReceiptRequest) GetBonusPoints() float64 {
+	if x != nil {
+		return x.BonusPoints
+	}
+	return 0
+}
+
+// WatchOrdersRequest picks the orders to watch: those of a center or of a
+// master. Managers watch their center and masters their own orders when
+// both are unset.
+type WatchOrdersRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ServiceCenterId int64                  `protobuf:"varint,1,opt,name=service_center_id,json=serviceCenterId,proto3" json:"service_center_id,omitempty"`
+	MasterId        int64                  `protobuf:"varint,2,opt,name=master_id,json=masterId,proto3" json:"master_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *WatchOrdersRequest) Reset() {
+	*x = WatchOrdersRequest{}
+	mi := &file_stations_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchOrdersRequest) ProtoMessage() {}
+
+func (x *WatchOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchOrdersRequest.ProtoReflect.Descriptor instead.
+func (*WatchOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *WatchOrdersRequest) GetServiceCenterId() int64 {
+	if x != nil {
+		return x.ServiceCenterId
+	}
+	return 0
+}
+
+func (x *WatchOrdersRequest) GetMasterId() int64 {
+	if x != nil {
+		return x.MasterId
+	}
+	return 0
+}
+
+type OrderEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Op is INSERT or UPDATE.
+	Op            string       `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	Order         *OrderDetail `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_stations_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_stations_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_stations_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *OrderEvent) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetOrder() *OrderDetail {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+var File_stations_proto protoreflect.FileDescriptor
+
+const file_stations_proto_rawDesc = "" +
+	"\n" +
+	"\x0estations.proto\x12\vstations.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8e\x01\n" +
+	"\bCustomer\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05phone\x18\x03 \x01(\tR\x05phone\x12!\n" +
+	"\fbonus_points\x18\x04 \x01(\x01R\vbonusPoints\x12%\n" +
+	"\x0eloyalty_status\x18\x05 \x01(\tR\rloyaltyStatus\"\xb5\x01\n" +
+	"\x06Master\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1e\n" +
+	"\n" +
+	"experience\x18\x03 \x01(\x05R\n" +
+	"experience\x12\x16\n" +
+	"\x06active\x18\x04 \x01(\x05R\x06active\x12\x1f\n" +
+	"\vin_progress\x18\x05 \x01(\x05R\n" +
+	"inProgress\x12.\n" +
+	"\x04next\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x04next\"f\n" +
+	"\aService\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fvehicle_type\x18\x03 \x01(\tR\vvehicleType\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\"u\n" +
+	"\tSparePart\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\aarticle\x18\x03 \x01(\x03R\aarticle\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\x12\x14\n" +
+	"\x05stock\x18\x05 \x01(\x05R\x05stock\"C\n" +
+	"\fPartQuantity\x12\x17\n" +
+	"\apart_id\x18\x01 \x01(\x03R\x06partId\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\"\xf5\x01\n" +
+	"\x05Order\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12A\n" +
+	"\x0escheduled_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\rscheduledDate\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1a\n" +
+	"\bcustomer\x18\x04 \x01(\tR\bcustomer\x12\x14\n" +
+	"\x05phone\x18\x05 \x01(\tR\x05phone\x12\x16\n" +
+	"\x06master\x18\x06 \x01(\tR\x06master\x12\x1d\n" +
+	"\n" +
+	"total_cost\x18\a \x01(\x01R\ttotalCost\x12\x18\n" +
+	"\areceipt\x18\b \x01(\bR\areceipt\"Q\n" +
+	"\tOrderLine\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x05R\bquantity\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\"\x87\x02\n" +
+	"\vOrderDetail\x12(\n" +
+	"\x05order\x18\x01 \x01(\v2\x12.stations.v1.OrderR\x05order\x12\x1f\n" +
+	"\vcustomer_id\x18\x02 \x01(\x03R\n" +
+	"customerId\x12!\n" +
+	"\fbonus_points\x18\x03 \x01(\x01R\vbonusPoints\x122\n" +
+	"\bservices\x18\x04 \x03(\v2\x16.stations.v1.OrderLineR\bservices\x12,\n" +
+	"\x05parts\x18\x05 \x03(\v2\x16.stations.v1.OrderLineR\x05parts\x12(\n" +
+	"\x05quote\x18\x06 \x01(\v2\x12.stations.v1.QuoteR\x05quote\"y\n" +
+	"\n" +
+	"Adjustment\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12!\n" +
+	"\fpromotion_id\x18\x02 \x01(\x03R\vpromotionId\x12\x18\n" +
+	"\apercent\x18\x03 \x01(\x03R\apercent\x12\x16\n" +
+	"\x06amount\x18\x04 \x01(\x03R\x06amount\"\xa5\x02\n" +
+	"\tQuoteLine\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x05R\bquantity\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x05 \x01(\x03R\tunitPrice\x12!\n" +
+	"\flabour_hours\x18\x06 \x01(\x03R\vlabourHours\x12\x1f\n" +
+	"\vlabour_rate\x18\a \x01(\x03R\n" +
+	"labourRate\x12\x16\n" +
+	"\x06amount\x18\b \x01(\x03R\x06amount\x123\n" +
+	"\bdiscount\x18\t \x01(\v2\x17.stations.v1.AdjustmentR\bdiscount\x12\x14\n" +
+	"\x05total\x18\n" +
+	" \x01(\x03R\x05total\"\xae\x03\n" +
+	"\x05Quote\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x127\n" +
+	"\tquoted_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\bquotedAt\x12\x12\n" +
+	"\x04tier\x18\x03 \x01(\tR\x04tier\x12,\n" +
+	"\x05lines\x18\x04 \x03(\v2\x16.stations.v1.QuoteLineR\x05lines\x12\x1a\n" +
+	"\bsubtotal\x18\x05 \x01(\x03R\bsubtotal\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\tR\x04code\x126\n" +
+	"\n" +
+	"promo_code\x18\a \x01(\v2\x17.stations.v1.AdjustmentR\tpromoCode\x12\x1a\n" +
+	"\bdiscount\x18\b \x01(\x03R\bdiscount\x12\x19\n" +
+	"\bvat_rate\x18\t \x01(\x03R\avatRate\x12,\n" +
+	"\x12prices_include_vat\x18\n" +
+	" \x01(\bR\x10pricesIncludeVat\x12\x10\n" +
+	"\x03vat\x18\v \x01(\x03R\x03vat\x12\x1a\n" +
+	"\brounding\x18\f \x01(\x03R\brounding\x12\x14\n" +
+	"\x05total\x18\r \x01(\x03R\x05total\"\x8b\x01\n" +
+	"\aReceipt\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12!\n" +
+	"\fbonus_points\x18\x02 \x01(\x01R\vbonusPoints\x12\x1d\n" +
+	"\n" +
+	"total_paid\x18\x03 \x01(\x01R\ttotalPaid\x12.\n" +
+	"\x04date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\".\n" +
+	"\x16SearchCustomersRequest\x12\x14\n" +
+	"\x05phone\x18\x01 \x01(\tR\x05phone\"E\n" +
+	"\x0eCustomersReply\x123\n" +
+	"\tcustomers\x18\x01 \x03(\v2\x15.stations.v1.CustomerR\tcustomers\"A\n" +
+	"\x15CreateCustomerRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05phone\x18\x02 \x01(\tR\x05phone\"A\n" +
+	"\rServicesReply\x120\n" +
+	"\bservices\x18\x01 \x03(\v2\x14.stations.v1.ServiceR\bservices\"?\n" +
+	"\x0fSparePartsReply\x12,\n" +
+	"\x05parts\x18\x01 \x03(\v2\x16.stations.v1.SparePartR\x05parts\"=\n" +
+	"\fMastersReply\x12-\n" +
+	"\amasters\x18\x01 \x03(\v2\x13.stations.v1.MasterR\amasters\"+\n" +
+	"\x11ListOrdersRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"9\n" +
+	"\vOrdersReply\x12*\n" +
+	"\x06orders\x18\x01 \x03(\v2\x12.stations.v1.OrderR\x06orders\")\n" +
+	"\fOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"\xb3\x01\n" +
+	"\x12CreateOrderRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\x03R\n" +
+	"customerId\x12\x1b\n" +
+	"\tmaster_id\x18\x02 \x01(\x03R\bmasterId\x12\x12\n" +
+	"\x04date\x18\x03 \x01(\tR\x04date\x12\x1a\n" +
+	"\bservices\x18\x04 \x03(\x03R\bservices\x12/\n" +
+	"\x05parts\x18\x05 \x03(\v2\x19.stations.v1.PartQuantityR\x05parts\"S\n" +
+	"\x13IssueReceiptRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12!\n" +
+	"\fbonus_points\x18\x02 \x01(\x01R\vbonusPoints\"]\n" +
+	"\x12WatchOrdersRequest\x12*\n" +
+	"\x11service_center_id\x18\x01 \x01(\x03R\x0fserviceCenterId\x12\x1b\n" +
+	"\tmaster_id\x18\x02 \x01(\x03R\bmasterId\"L\n" +
+	"\n" +
+	"OrderEvent\x12\x0e\n" +
+	"\x02op\x18\x01 \x01(\tR\x02op\x12.\n" +
+	"\x05order\x18\x02 \x01(\v2\x18.stations.v1.OrderDetailR\x05order2\xa6\x06\n" +
+	"\bStations\x12S\n" +
+	"\x0fSearchCustomers\x12#.stations.v1.SearchCustomersRequest\x1a\x1b.stations.v1.CustomersReply\x12K\n" +
+	"\x0eCreateCustomer\x12\".stations.v1.CreateCustomerRequest\x1a\x15.stations.v1.Customer\x12B\n" +
+	"\fListServices\x12\x16.google.protobuf.Empty\x1a\x1a.stations.v1.ServicesReply\x12F\n" +
+	"\x0eListSpareParts\x12\x16.google.protobuf.Empty\x1a\x1c.stations.v1.SparePartsReply\x12@\n" +
+	"\vListMasters\x12\x16.google.protobuf.Empty\x1a\x19.stations.v1.MastersReply\x12F\n" +
+	"\n" +
+	"ListOrders\x12\x1e.stations.v1.ListOrdersRequest\x1a\x18.stations.v1.OrdersReply\x12?\n" +
+	"\bGetOrder\x12\x19.stations.v1.OrderRequest\x1a\x18.stations.v1.OrderDetail\x12H\n" +
+	"\vCreateOrder\x12\x1f.stations.v1.CreateOrderRequest\x1a\x18.stations.v1.OrderDetail\x12D\n" +
+	"\rCompleteOrder\x12\x19.stations.v1.OrderRequest\x1a\x18.stations.v1.OrderDetail\x12F\n" +
+	"\fIssueReceipt\x12 .stations.v1.IssueReceiptRequest\x1a\x14.stations.v1.Receipt\x12I\n" +
+	"\vWatchOrders\x12\x1f.stations.v1.WatchOrdersRequest\x1a\x17.stations.v1.OrderEvent0\x01B3Z1vehicles-service-stations/internal/api/stationspbb\x06proto3"
+
+var (
+	file_stations_proto_rawDescOnce sync.Once
+	file_stations_proto_rawDescData []byte
+)
+
+func file_stations_proto_rawDescGZIP() []byte {
+	file_stations_proto_rawDescOnce.Do(func() {
+		file_stations_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_stations_proto_rawDesc), len(file_stations_proto_rawDesc)))
+	})
+	return file_stations_proto_rawDescData
+}
+
+var file_stations_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_stations_proto_goTypes = []any{
+	(*Customer)(nil),               // 0: stations.v1.Customer
+	(*Master)(nil),                 // 1: stations.v1.Master
+	(*Service)(nil),                // 2: stations.v1.Service
+	(*SparePart)(nil),              // 3: stations.v1.SparePart
+	(*PartQuantity)(nil),           // 4: stations.v1.PartQuantity
+	(*Order)(nil),                  // 5: stations.v1.Order
+	(*OrderLine)(nil),              // 6: stations.v1.OrderLine
+	(*OrderDetail)(nil),            // 7: stations.v1.OrderDetail
+	(*Adjustment)(nil),             // 8: stations.v1.Adjustment
+	(*QuoteLine)(nil),              // 9: stations.v1.QuoteLine
+	(*Quote)(nil),                  // 10: stations.v1.Quote
+	(*Receipt)(nil),                // 11: stations.v1.Receipt
+	(*SearchCustomersRequest)(nil), // 12: stations.v1.SearchCustomersRequest
+	(*CustomersReply)(nil),         // 13: stations.v1.CustomersReply
+	(*CreateCustomerRequest)(nil),  // 14: stations.v1.CreateCustomerRequest
+	(*ServicesReply)(nil),          // 15: stations.v1.ServicesReply
+	(*SparePartsReply)(nil),        // 16: stations.v1.SparePartsReply
+	(*MastersReply)(nil),           // 17: stations.v1.MastersReply
+	(*ListOrdersRequest)(nil),      // 18: stations.v1.ListOrdersRequest
+	(*OrdersReply)(nil),            // 19: stations.v1.OrdersReply
+	(*OrderRequest)(nil),           // 20: stations.v1.OrderRequest
+	(*CreateOrderRequest)(nil),     // 21: stations.v1.CreateOrderRequest
+	(*IssueReceiptRequest)(nil),    // 22: stations.v1.IssueReceiptRequest
+	(*WatchOrdersRequest)(nil),     // 23: stations.v1.WatchOrdersRequest
+	(*OrderEvent)(nil),             // 24: stations.v1.OrderEvent
+	(*timestamppb.Timestamp)(nil),  // 25: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),          // 26: google.protobuf.Empty
+}
+var file_stations_proto_depIdxs = []int32{
+	25, // 0: stations.v1.Master.next:type_name -> google.protobuf.Timestamp
+	25, // 1: stations.v1.Order.scheduled_date:type_name -> google.protobuf.Timestamp
+	5,  // 2: stations.v1.OrderDetail.order:type_name -> stations.v1.Order
+	6,  // 3: stations.v1.OrderDetail.services:type_name -> stations.v1.OrderLine
+	6,  // 4: stations.v1.OrderDetail.parts:type_name -> stations.v1.OrderLine
+	10, // 5: stations.v1.OrderDetail.quote:type_name -> stations.v1.Quote
+	8,  // 6: stations.v1.QuoteLine.discount:type_name -> stations.v1.Adjustment
+	25, // 7: stations.v1.Quote.quoted_at:type_name -> google.protobuf.Timestamp
+	9,  // 8: stations.v1.Quote.lines:type_name -> stations.v1.QuoteLine
+	8,  // 9: stations.v1.Quote.promo_code:type_name -> stations.v1.Adjustment
+	25, // 10: stations.v1.Receipt.date:type_name -> google.protobuf.Timestamp
+	0,  // 11: stations.v1.CustomersReply.customers:type_name -> stations.v1.Customer
+	2,  // 12: stations.v1.ServicesReply.services:type_name -> stations.v1.Service
+	3,  // 13: stations.v1.SparePartsReply.parts:type_name -> stations.v1.SparePart
+	1,  // 14: stations.v1.MastersReply.masters:type_name -> stations.v1.Master
+	5,  // 15: stations.v1.OrdersReply.orders:type_name -> stations.v1.Order
+	4,  // 16: stations.v1.CreateOrderRequest.parts:type_name -> stations.v1.PartQuantity
+	7,  // 17: stations.v1.OrderEvent.order:type_name -> stations.v1.OrderDetail
+	12, // 18: stations.v1.Stations.SearchCustomers:input_type -> stations.v1.SearchCustomersRequest
+	14, // 19: stations.v1.Stations.CreateCustomer:input_type -> stations.v1.CreateCustomerRequest
+	26, // 20: stations.v1.Stations.ListServices:input_type -> google.protobuf.Empty
+	26, // 21: stations.v1.Stations.ListSpareParts:input_type -> google.protobuf.Empty
+	26, // 22: stations.v1.Stations.ListMasters:input_type -> google.protobuf.Empty
+	18, // 23: stations.v1.Stations.ListOrders:input_type -> stations.v1.ListOrdersRequest
+	20, // 24: stations.v1.Stations.GetOrder:input_type -> stations.v1.OrderRequest
+	21, // 25: stations.v1.Stations.CreateOrder:input_type -> stations.v1.CreateOrderRequest
+	20, // 26: stations.v1.Stations.CompleteOrder:input_type -> stations.v1.OrderRequest
+	22, // 27: stations.v1.Stations.IssueReceipt:input_type -> stations.v1.IssueReceiptRequest
+	23, // 28: stations.v1.Stations.WatchOrders:input_type -> stations.v1.WatchOrdersRequest
+	13, // 29: stations.v1.Stations.SearchCustomers:output_type -> stations.v1.CustomersReply
+	0,  // 30: stations.v1.Stations.CreateCustomer:output_type -> stations.v1.Customer
+	15, // 31: stations.v1.Stations.ListServices:output_type -> stations.v1.ServicesReply
+	16, // 32: stations.v1.Stations.ListSpareParts:output_type -> stations.v1.SparePartsReply
+	17, // 33: stations.v1.Stations.ListMasters:output_type -> stations.v1.MastersReply
+	19, // 34: stations.v1.Stations.ListOrders:output_type -> stations.v1.OrdersReply
+	7,  // 35: stations.v1.Stations.GetOrder:output_type -> stations.v1.OrderDetail
+	7,  // 36: stations.v1.Stations.CreateOrder:output_type -> stations.v1.OrderDetail
+	7,  // 37: stations.v1.Stations.CompleteOrder:output_type -> stations.v1.OrderDetail
+	11, // 38: stations.v1.Stations.IssueReceipt:output_type -> stations.v1.Receipt
+	24, // 39: stations.v1.Stations.WatchOrders:output_type -> stations.v1.OrderEvent
+	29, // [29:40] is the sub-list for method output_type
+	18, // [18:29] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_stations_proto_init() }
+func file_stations_proto_init() {
+	if File_stations_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stations_proto_rawDesc), len(file_stations_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   25,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_stations_proto_goTypes,
+		DependencyIndexes: file_stations_proto_depIdxs,
+		MessageInfos:      file_stations_proto_msgTypes,
+	}.Build()
+	File_stations_proto = out.File
+	file_stations_proto_goTypes = nil
+	file_stations_proto_depIdxs = nil
+}
@@ -0,0 +1,515 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: stations.proto
+
+// The API of the service stations for the mobile and the workshop terminal
+// clients. Employees call it with their database login and password in the
+// authorization metadata, row level security applies to them as in the
+// console.
+
+package stationspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Stations_SearchCustomers_FullMethodName = "/stations.v1.Stations/SearchCustomers"
+	Stations_CreateCustomer_FullMethodName  = "/stations.v1.Stations/CreateCustomer"
+	Stations_ListServices_FullMethodName    = "/stations.v1.Stations/ListServices"
+	Stations_ListSpareParts_FullMethodName  = "/stations.v1.Stations/ListSpareParts"
+	Stations_ListMasters_FullMethodName     = "/stations.v1.Stations/ListMasters"
+	Stations_ListOrders_FullMethodName      = "/stations.v1.Stations/ListOrders"
+	Stations_GetOrder_FullMethodName        = "/stations.v1.Stations/GetOrder"
+	Stations_CreateOrder_FullMethodName     = "/stations.v1.Stations/CreateOrder"
+	Stations_CompleteOrder_FullMethodName   = "/stations.v1.Stations/CompleteOrder"
+	Stations_IssueReceipt_FullMethodName    = "/stations.v1.Stations/IssueReceipt"
+	Stations_WatchOrders_FullMethodName     = "/stations.v1.Stations/WatchOrders"
+)
+
+// StationsClient is the client API for Stations service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StationsClient interface {
+	SearchCustomers(ctx context.Context, in *SearchCustomersRequest, opts ...grpc.CallOption) (*CustomersReply, error)
+	CreateCustomer(ctx context.Context, in *CreateCustomerRequest, opts ...grpc.CallOption) (*Customer, error)
+	ListServices(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ServicesReply, error)
+	ListSpareParts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SparePartsReply, error)
+	ListMasters(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*MastersReply, error)
+	ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*OrdersReply, error)
+	GetOrder(ctx context.Context, in *OrderRequest, opts ...grpc.CallOption) (*OrderDetail, error)
+	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*OrderDetail, error)
+	CompleteOrder(ctx context.Context, in *OrderRequest, opts ...grpc.CallOption) (*OrderDetail, error)
+	IssueReceipt(ctx context.Context, in *IssueReceiptRequest, opts ...grpc.CallOption) (*Receipt, error)
+	// WatchOrders streams the orders of a center or of a master as they
+	// change. Orders the employee does not see are skipped.
+	WatchOrders(ctx context.Context, in *WatchOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error)
+}
+
+type stationsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStationsClient(cc grpc.ClientConnInterface) StationsClient {
+	return &stationsClient{cc}
+}
+
+func (c *stationsClient) SearchCustomers(ctx context.Context, in *SearchCustomersRequest, opts ...grpc.CallOption) (*CustomersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CustomersReply)
+	err := c.cc.Invoke(ctx, Stations_SearchCustomers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) CreateCustomer(ctx context.Context, in *CreateCustomerRequest, opts ...grpc.CallOption) (*Customer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Customer)
+	err := c.cc.Invoke(ctx, Stations_CreateCustomer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) ListServices(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ServicesReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServicesReply)
+	err := c.cc.Invoke(ctx, Stations_ListServices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) ListSpareParts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SparePartsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SparePartsReply)
+	err := c.cc.Invoke(ctx, Stations_ListSpareParts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) ListMasters(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*MastersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MastersReply)
+	err := c.cc.Invoke(ctx, Stations_ListMasters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) ListOrders(ctx context.Context, in *ListOrdersRequest, opts ...grpc.CallOption) (*OrdersReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrdersReply)
+	err := c.cc.Invoke(ctx, Stations_ListOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) GetOrder(ctx context.Context, in *OrderRequest, opts ...grpc.CallOption) (*OrderDetail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderDetail)
+	err := c.cc.Invoke(ctx, Stations_GetOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*OrderDetail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderDetail)
+	err := c.cc.Invoke(ctx, Stations_CreateOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) CompleteOrder(ctx context.Context, in *OrderRequest, opts ...grpc.CallOption) (*OrderDetail, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderDetail)
+	err := c.cc.Invoke(ctx, Stations_CompleteOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) IssueReceipt(ctx context.Context, in *IssueReceiptRequest, opts ...grpc.CallOption) (*Receipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Receipt)
+	err := c.cc.Invoke(ctx, Stations_IssueReceipt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stationsClient) WatchOrders(ctx context.Context, in *WatchOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Stations_ServiceDesc.Streams[0], Stations_WatchOrders_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchOrdersRequest, OrderEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Stations_WatchOrdersClient = grpc.ServerStreamingClient[OrderEvent]
+
+// StationsServer is the server API for Stations service.
+// All implementations must embed UnimplementedStationsServer
+// for forward compatibility.
+type StationsServer interface {
+	SearchCustomers(context.Context, *SearchCustomersRequest) (*CustomersReply, error)
+	CreateCustomer(context.Context, *CreateCustomerRequest) (*Customer, error)
+	ListServices(context.Context, *emptypb.Empty) (*ServicesReply, error)
+	ListSpareParts(context.Context, *emptypb.Empty) (*SparePartsReply, error)
+	ListMasters(context.Context, *emptypb.Empty) (*MastersReply, error)
+	ListOrders(context.Context, *ListOrdersRequest) (*OrdersReply, error)
+	GetOrder(context.Context, *OrderRequest) (*OrderDetail, error)
+	CreateOrder(context.Context, *CreateOrderRequest) (*OrderDetail, error)
+	CompleteOrder(context.Context, *OrderRequest) (*OrderDetail, error)
+	IssueReceipt(context.Context, *IssueReceiptRequest) (*Receipt, error)
+	// WatchOrders streams the orders of a center or of a master as they
+	// change. Orders the employee does not see are skipped.
+	WatchOrders(*WatchOrdersRequest, grpc.ServerStreamingServer[OrderEvent]) error
+	mustEmbedUnimplementedStationsServer()
+}
+
+// UnimplementedStationsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStationsServer struct{}
+
+func (UnimplementedStationsServer) SearchCustomers(context.Context, *SearchCustomersRequest) (*CustomersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchCustomers not implemented")
+}
+func (UnimplementedStationsServer) CreateCustomer(context.Context, *CreateCustomerRequest) (*Customer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCustomer not implemented")
+}
+func (UnimplementedStationsServer) ListServices(context.Context, *emptypb.Empty) (*ServicesReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListServices not implemented")
+}
+func (UnimplementedStationsServer) ListSpareParts(context.Context, *emptypb.Empty) (*SparePartsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSpareParts not implemented")
+}
+func (UnimplementedStationsServer) ListMasters(context.Context, *emptypb.Empty) (*MastersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMasters not implemented")
+}
+func (UnimplementedStationsServer) ListOrders(context.Context, *ListOrdersRequest) (*OrdersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrders not implemented")
+}
+func (UnimplementedStationsServer) GetOrder(context.Context, *OrderRequest) (*OrderDetail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedStationsServer) CreateOrder(context.Context, *CreateOrderRequest) (*OrderDetail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrder not implemented")
+}
+func (UnimplementedStationsServer) CompleteOrder(context.Context, *OrderRequest) (*OrderDetail, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteOrder not implemented")
+}
+func (UnimplementedStationsServer) IssueReceipt(context.Context, *IssueReceiptRequest) (*Receipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IssueReceipt not implemented")
+}
+func (UnimplementedStationsServer) WatchOrders(*WatchOrdersRequest, grpc.ServerStreamingServer[OrderEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchOrders not implemented")
+}
+func (UnimplementedStationsServer) mustEmbedUnimplementedStationsServer() {}
+func (UnimplementedStationsServer) testEmbeddedByValue()                  {}
+
+// UnsafeStationsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StationsServer will
+// result in compilation errors.
+type UnsafeStationsServer interface {
+	mustEmbedUnimplementedStationsServer()
+}
+
+func RegisterStationsServer(s grpc.ServiceRegistrar, srv StationsServer) {
+	// If the following call pancis, it indicates UnimplementedStationsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Stations_ServiceDesc, srv)
+}
+
+func _Stations_SearchCustomers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchCustomersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).SearchCustomers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_SearchCustomers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).SearchCustomers(ctx, req.(*SearchCustomersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_CreateCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).CreateCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_CreateCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).CreateCustomer(ctx, req.(*CreateCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_ListServices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).ListServices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_ListServices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).ListServices(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_ListSpareParts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).ListSpareParts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_ListSpareParts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).ListSpareParts(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_ListMasters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).ListMasters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_ListMasters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).ListMasters(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_ListOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).ListOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_ListOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).ListOrders(ctx, req.(*ListOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).GetOrder(ctx, req.(*OrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_CreateOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).CreateOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_CreateOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).CreateOrder(ctx, req.(*CreateOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_CompleteOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).CompleteOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_CompleteOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).CompleteOrder(ctx, req.(*OrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_IssueReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IssueReceiptRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StationsServer).IssueReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Stations_IssueReceipt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StationsServer).IssueReceipt(ctx, req.(*IssueReceiptRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Stations_WatchOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchOrdersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StationsServer).WatchOrders(m, &grpc.GenericServerStream[WatchOrdersRequest, OrderEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Stations_WatchOrdersServer = grpc.ServerStreamingServer[OrderEvent]
+
+// Stations_ServiceDesc is the grpc.ServiceDesc for Stations service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Stations_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "stations.v1.Stations",
+	HandlerType: (*StationsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchCustomers",
+			Handler:    _Stations_SearchCustomers_Handler,
+		},
+		{
+			MethodName: "CreateCustomer",
+			Handler:    _Stations_CreateCustomer_Handler,
+		},
+		{
+			MethodName: "ListServices",
+			Handler:    _Stations_ListServices_Handler,
+		},
+		{
+			MethodName: "ListSpareParts",
+			Handler:    _Stations_ListSpareParts_Handler,
+		},
+		{
+			MethodName: "ListMasters",
+			Handler:    _Stations_ListMasters_Handler,
+		},
+		{
+			MethodName: "ListOrders",
+			Handler:    _Stations_ListOrders_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _Stations_GetOrder_Handler,
+		},
+		{
+			MethodName: "CreateOrder",
+			Handler:    _Stations_CreateOrder_Handler,
+		},
+		{
+			MethodName: "CompleteOrder",
+			Handler:    _Stations_CompleteOrder_Handler,
+		},
+		{
+			MethodName: "IssueReceipt",
+			Handler:    _Stations_IssueReceipt_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchOrders",
+			Handler:       _Stations_WatchOrders_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "stations.proto",
+}
@@ -0,0 +1,157 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Tables lists the tables captured by deployments/assets/0002_audit.sql.
+var Tables = []string{"orders", "customers", "receipts", "spare_parts", "employees"}
+
+// Entry is one captured row change. Actor is the login role the change was
+// made by and EmployeeID the matching employee, if any.
+type Entry struct {
+	ID         int64          `json:"id"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	TxID       int64          `json:"tx_id"`
+	Actor      string         `json:"actor"`
+	EmployeeID *int           `json:"employee_id,omitempty"`
+	RequestID  string         `json:"request_id,omitempty"`
+	Table      string         `json:"table"`
+	Operation  string         `json:"operation"`
+	RowID      string         `json:"row_id"`
+	Old        map[string]any `json:"old,omitempty"`
+	New        map[string]any `json:"new,omitempty"`
+	Changed    []string       `json:"changed"`
+}
+
+// Change returns the values of a column before and after the change.
+func (e *Entry) Change(column string) (before, after any) {
+	return e.Old[column], e.New[column]
+}
+
+type Filter struct {
+	Table string
+	RowID string
+	// Column keeps the entries that changed it, inserts and deletes change
+	// every column.
+	Column    string
+	Actor     string
+	RequestID string
+	Since     time.Time
+	Until     time.Time
+	// Limit caps the entries returned, newest first, all when 0.
+	Limit uint64
+}
+
+func (f Filter) Validate() error {
+	if f.RowID != "" && f.Table == "" {
+		return fmt.Errorf("row id requires a table")
+	}
+	if f.Table != "" {
+		known := false
+		for _, t := range Tables {
+			known = known || t == f.Table
+		}
+		if !known {
+			return fmt.Errorf("table %s is not audited", f.Table)
+		}
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return fmt.Errorf("until is before since")
+	}
+	return nil
+}
+
+// History returns the entries matching the filter ordered from the newest.
+func History(ctx context.Context, db *pgxpool.Pool, f Filter) ([]*Entry, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	builder := sq.Select(
+		"audit_id", "occurred_at", "tx_id", "actor", "employee_id", "COALESCE(request_id, '')",
+		"table_name", "operation", "row_id", "old_row", "new_row", "changed_columns",
+	).From("audit.log").OrderBy("occurred_at DESC", "audit_id DESC").PlaceholderFormat(sq.Dollar)
+
+	if f.Table != "" {
+		builder = builder.Where(sq.Eq{"table_name": f.Table})
+	}
+	if f.RowID != "" {
+		builder = builder.Where(sq.Eq{"row_id": f.RowID})
+	}
+	if f.Column != "" {
+		builder = builder.Where("? = ANY(changed_columns)", f.Column)
+	}
+	if f.Actor != "" {
+		builder = builder.Where(sq.Eq{"actor": f.Actor})
+	}
+	if f.RequestID != "" {
+		builder = builder.Where(sq.Eq{"request_id": f.RequestID})
+	}
+	if !f.Since.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"occurred_at": f.Since})
+	}
+	if !f.Until.IsZero() {
+		builder = builder.Where(sq.Lt{"occurred_at": f.Until})
+	}
+	if f.Limit > 0 {
+		builder = builder.Limit(f.Limit)
+	}
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*Entry
+	for rows.Next() {
+		e := &Entry{}
+		var oldRow, newRow []byte
+		err := rows.Scan(&e.ID, &e.OccurredAt, &e.TxID, &e.Actor, &e.EmployeeID, &e.RequestID,
+			&e.Table, &e.Operation, &e.RowID, &oldRow, &newRow, &e.Changed)
+		if err != nil {
+			return nil, err
+		}
+		if e.Old, err = decodeRow(oldRow); err != nil {
+			return nil, err
+		}
+		if e.New, err = decodeRow(newRow); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func decodeRow(data []byte) (map[string]any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var row map[string]any
+	if err := json.Unmarshal(data, &row); err != nil {
+		return nil, fmt.Errorf("failed to decode audited row: %w", err)
+	}
+	return row, nil
+}
+
+// SetRequestID attributes the changes made by tx to a request. It only lasts
+// until the end of the transaction.
+func SetRequestID(ctx context.Context, tx pgx.Tx, requestID string) error {
+	_, err := tx.Exec(ctx, `SELECT set_config('app.request_id', $1, true)`, requestID)
+	if err != nil {
+		return fmt.Errorf("failed to set request id: %w", err)
+	}
+	return nil
+}
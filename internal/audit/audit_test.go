@@ -0,0 +1,46 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"vehicles-service-stations/internal/audit"
+	"vehicles-service-stations/internal/testutil"
+)
+
+func TestOrderUpdateIsAudited(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "audit")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+
+	if err := audit.SetRequestID(ctx, tx, "test-request"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE orders SET assigned_master_id = $1 WHERE order_id = $2`, b.Masters[1], orderID); err != nil {
+		t.Fatal(err)
+	}
+
+	var actor, requestID string
+	var changed []string
+	var before, after int
+	err := tx.QueryRow(ctx, `
+        SELECT actor, request_id, changed_columns,
+               (old_row ->> 'assigned_master_id')::int, (new_row ->> 'assigned_master_id')::int
+        FROM audit.log
+        WHERE table_name = 'orders' AND row_id = $1::text AND operation = 'UPDATE'
+    `, orderID).Scan(&actor, &requestID, &changed, &before, &after)
+	if err != nil {
+		t.Fatalf("update was not audited: %v", err)
+	}
+	if requestID != "test-request" || actor == "" {
+		t.Errorf("entry attributed to %q with request %q", actor, requestID)
+	}
+	if len(changed) != 1 || changed[0] != "assigned_master_id" {
+		t.Errorf("changed columns are %v, want [assigned_master_id]", changed)
+	}
+	if before != b.Masters[0] || after != b.Masters[1] {
+		t.Errorf("master changed from %d to %d, want %d to %d", before, after, b.Masters[0], b.Masters[1])
+	}
+}
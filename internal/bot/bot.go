@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"vehicles-service-stations/internal/telegram"
+)
+
+type Options struct {
+	// PollTimeout is how long a getUpdates request waits for updates.
+	PollTimeout time.Duration
+	// Days is how far ahead free slots are offered, starting tomorrow.
+	Days int
+	// Now is the clock of the bot, time.Now when nil.
+	Now     func() time.Time
+	OnError func(error)
+}
+
+func (o *Options) defaults() {
+	if o.PollTimeout <= 0 {
+		o.PollTimeout = 30 * time.Second
+	}
+	if o.Days <= 0 {
+		o.Days = 14
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+}
+
+type state int
+
+const (
+	stateIdle state = iota
+	// stateAwaitName waits for the name of a customer not found by phone.
+	stateAwaitName
+)
+
+// session is the conversation with a chat. Bookings in progress are kept in
+// memory only, a restart asks to start over.
+type session struct {
+	state state
+	phone string
+
+	cities   []string
+	centerID int
+	vehicle  string
+	services []Service
+	chosen   map[int]bool
+	slots    []Slot
+	slot     *Slot
+}
+
+// Bot lets customers book repairs and follow their orders. Updates are
+// handled one at a time.
+type Bot struct {
+	api      *telegram.Client
+	store    *Store
+	opts     Options
+	sessions map[int64]*session
+}
+
+func New(api *telegram.Client, store *Store, opts Options) *Bot {
+	opts.defaults()
+	return &Bot{api: api, store: store, opts: opts, sessions: make(map[int64]*session)}
+}
+
+func (b *Bot) report(err error) {
+	if b.opts.OnError != nil {
+		b.opts.OnError(err)
+	}
+}
+
+// Run long polls for updates until ctx is done.
+func (b *Bot) Run(ctx context.Context) error {
+	var offset int64
+	for {
+		updates, err := b.api.GetUpdates(ctx, offset, b.opts.PollTimeout)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			b.report(err)
+			wait := 3 * time.Second
+			var apiErr *telegram.Error
+			if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+				wait = apiErr.RetryAfter
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			b.Handle(ctx, u)
+			offset = u.UpdateID + 1
+		}
+	}
+}
+
+// Handle reacts to one update. Failures are reported and the user is asked to
+// try again.
+func (b *Bot) Handle(ctx context.Context, u telegram.Update) {
+	var chatID int64
+	var err error
+	switch {
+	case u.Message != nil:
+		chatID = u.Message.Chat.ID
+		err = b.onMessage(ctx, u.Message)
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
+		chatID = u.CallbackQuery.Message.Chat.ID
+		if err := b.api.AnswerCallbackQuery(ctx, u.CallbackQuery.ID, ""); err != nil {
+			b.report(err)
+		}
+		err = b.onCallback(ctx, u.CallbackQuery)
+	default:
+		return
+	}
+
+	if err != nil {
+		b.report(fmt.Errorf("chat %d: %w", chatID, err))
+		if _, err := b.api.SendMessage(ctx, telegram.SendMessage{ChatID: chatID, Text: textFailed}); err != nil {
+			b.report(err)
+		}
+	}
+}
+
+func (b *Bot) session(chatID int64) *session {
+	s, ok := b.sessions[chatID]
+	if !ok {
+		s = &session{}
+		b.sessions[chatID] = s
+	}
+	return s
+}
+
+func (b *Bot) send(ctx context.Context, chatID int64, text string, markup any) error {
+	_, err := b.api.SendMessage(ctx, telegram.SendMessage{ChatID: chatID, Text: text, ReplyMarkup: markup})
+	return err
+}
@@ -0,0 +1,140 @@
+package bot_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"vehicles-service-stations/internal/bot"
+	"vehicles-service-stations/internal/telegram"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// A customer shares their phone number, books a service through the inline
+// keyboards and gets a Pending order for the first free day, the chat
+// becomes their notification contact.
+func TestBooking(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "bot")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	_, err := tx.Exec(ctx, `
+        INSERT INTO services (full_name, vehicle_type, price)
+        VALUES ('Fixture oil change', 'Car', 1500)
+    `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := telegram.NewFakeServer("test")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	api, err := telegram.NewClient(server.URL, fake.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var botErr error
+	bt := bot.New(api, bot.NewStore(tx), bot.Options{
+		PollTimeout: time.Second,
+		Now:         func() time.Time { return testutil.Day.AddDate(0, 0, -1) },
+		OnError:     func(err error) { botErr = err },
+	})
+	botCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bt.Run(botCtx)
+	}()
+	// The transaction is used by the bot until it stops.
+	stopped := false
+	stop := func() {
+		if !stopped {
+			cancel()
+			<-done
+			stopped = true
+		}
+	}
+	defer stop()
+
+	user := telegram.User{ID: 500, FirstName: "Test"}
+	waitCtx, waitCancel := context.WithTimeout(ctx, 10*time.Second)
+	defer waitCancel()
+	// reply waits for the n-th message of the bot.
+	reply := func(n int) telegram.Message {
+		t.Helper()
+		sent, err := fake.WaitSent(waitCtx, user.ID, n)
+		if err != nil {
+			t.Fatalf("bot sent %d messages, want %d: %v", len(sent), n, err)
+		}
+		return sent[n-1]
+	}
+	// press presses the button of the n-th message whose text contains
+	// label.
+	press := func(n int, label string) {
+		t.Helper()
+		m := reply(n)
+		if m.ReplyMarkup != nil {
+			for _, row := range m.ReplyMarkup.InlineKeyboard {
+				for _, btn := range row {
+					if strings.Contains(btn.Text, label) {
+						fake.Press(user, m, btn.CallbackData)
+						return
+					}
+				}
+			}
+		}
+		t.Fatalf("no %q button under %q", label, m.Text)
+	}
+
+	fake.SendText(user, "/start")
+	reply(1)
+	fake.SendContact(user, "7 (111) 111-11-11", user.ID)
+	reply(2)
+	fake.SendText(user, "/book")
+	for i, label := range []string{"Fixture City", "Fixture street 1", "Автомобиль", "Fixture oil change"} {
+		press(3+i, label)
+	}
+	// Selecting the service edits the message, Done is pressed under the
+	// same one.
+	press(6, "Готово")
+	press(7, "10.01.2030")
+	press(8, "Подтвердить")
+	confirmation := reply(9)
+	stop()
+	if botErr != nil {
+		t.Fatalf("bot failed: %v", botErr)
+	}
+
+	var orderID, services int
+	var status string
+	var master int
+	err = tx.QueryRow(ctx, `
+        SELECT o.order_id, o.status::text, o.assigned_master_id, count(so.service_id)
+        FROM orders o
+        LEFT JOIN service_order so ON so.order_id = o.order_id
+        WHERE o.customer_id = $1 AND o.scheduled_date = $2
+        GROUP BY o.order_id
+    `, b.Customer, testutil.Day).Scan(&orderID, &status, &master, &services)
+	if err != nil {
+		t.Fatalf("failed to find the booked order: %v", err)
+	}
+	if status != "Pending" || master != b.Masters[0] || services != 1 {
+		t.Errorf("order %d is %s with master %d and %d services, want Pending with master %d and the service",
+			orderID, status, master, services, b.Masters[0])
+	}
+	if !strings.Contains(confirmation.Text, "№"+strconv.Itoa(orderID)) {
+		t.Errorf("confirmation %q lacks order %d", confirmation.Text, orderID)
+	}
+
+	var chatID int64
+	err = tx.QueryRow(ctx, `SELECT telegram_chat_id FROM notifications.contacts WHERE customer_id = $1`, b.Customer).Scan(&chatID)
+	if err != nil {
+		t.Fatalf("failed to read the contact: %v", err)
+	}
+	if chatID != user.ID {
+		t.Errorf("customer is linked to chat %d, want %d", chatID, user.ID)
+	}
+}
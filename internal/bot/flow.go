@@ -0,0 +1,363 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"vehicles-service-stations/internal/telegram"
+)
+
+const (
+	textFailed  = "Что-то пошло не так, попробуйте ещё раз."
+	textMenu    = "/book — записаться на ремонт\n/orders — мои заказы и их статусы\n/bonus — бонусный баланс"
+	textExpired = "Запись устарела, начните заново: /book"
+)
+
+var (
+	weekdays = [...]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"}
+	statuses = map[string]string{
+		"Pending":     "ожидает",
+		"In Progress": "в работе",
+		"Completed":   "выполнен",
+		"Cancelled":   "отменён",
+	}
+	vehicles = []struct{ value, label string }{
+		{"Car", "Автомобиль"},
+		{"Moto", "Мотоцикл"},
+	}
+)
+
+func formatDate(t time.Time) string {
+	return weekdays[t.Weekday()] + " " + t.Format("02.01.2006")
+}
+
+func button(text, data string) telegram.InlineKeyboardButton {
+	return telegram.InlineKeyboardButton{Text: text, CallbackData: data}
+}
+
+// column puts every button on its own row.
+func column(buttons ...telegram.InlineKeyboardButton) *telegram.InlineKeyboardMarkup {
+	markup := &telegram.InlineKeyboardMarkup{}
+	for _, btn := range buttons {
+		markup.InlineKeyboard = append(markup.InlineKeyboard, []telegram.InlineKeyboardButton{btn})
+	}
+	return markup
+}
+
+func (b *Bot) onMessage(ctx context.Context, msg *telegram.Message) error {
+	chatID := msg.Chat.ID
+	s := b.session(chatID)
+
+	if msg.Contact != nil {
+		return b.onContact(ctx, msg)
+	}
+	text := strings.TrimSpace(msg.Text)
+	if s.state == stateAwaitName && text != "" && !strings.HasPrefix(text, "/") {
+		return b.onName(ctx, chatID, s, text)
+	}
+
+	customer, err := b.store.CustomerByChat(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return b.askContact(ctx, chatID)
+	}
+
+	command, _, _ := strings.Cut(text, " ")
+	switch command {
+	case "/start", "/help":
+		return b.send(ctx, chatID, fmt.Sprintf("Здравствуйте, %s!\n\n%s", customer.Name, textMenu), nil)
+	case "/book":
+		return b.startBooking(ctx, chatID, s)
+	case "/orders":
+		return b.listOrders(ctx, chatID, customer)
+	case "/bonus":
+		return b.send(ctx, chatID, fmt.Sprintf("Бонусных баллов: %.2f\nУровень лояльности: %s\nПотрачено всего: %.2f ₽",
+			customer.BonusPoints, customer.LoyaltyStatus, customer.SpentMoney), nil)
+	default:
+		return b.send(ctx, chatID, textMenu, nil)
+	}
+}
+
+func (b *Bot) askContact(ctx context.Context, chatID int64) error {
+	return b.send(ctx, chatID,
+		"Здравствуйте! Чтобы записаться на ремонт, поделитесь номером телефона: по нему мы найдём вашу карточку клиента.",
+		telegram.ReplyKeyboardMarkup{
+			Keyboard:        [][]telegram.KeyboardButton{{{Text: "Поделиться номером", RequestContact: true}}},
+			ResizeKeyboard:  true,
+			OneTimeKeyboard: true,
+		})
+}
+
+// onContact identifies the customer by the shared phone number. Only the own
+// number of the user is accepted, anyone could share someone else's contact.
+func (b *Bot) onContact(ctx context.Context, msg *telegram.Message) error {
+	chatID := msg.Chat.ID
+	if msg.From == nil || msg.Contact.UserID != msg.From.ID {
+		return b.send(ctx, chatID, "Пожалуйста, поделитесь своим номером кнопкой ниже.", nil)
+	}
+
+	phone := NormalizePhone(msg.Contact.PhoneNumber)
+	customer, err := b.store.CustomerByPhone(ctx, phone)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		s := b.session(chatID)
+		s.state, s.phone = stateAwaitName, phone
+		return b.send(ctx, chatID, fmt.Sprintf("Мы не нашли клиента с номером %s. Как к вам обращаться? Напишите имя и фамилию.", phone),
+			telegram.ReplyKeyboardRemove{RemoveKeyboard: true})
+	}
+	return b.link(ctx, chatID, customer)
+}
+
+func (b *Bot) onName(ctx context.Context, chatID int64, s *session, name string) error {
+	if len([]rune(name)) > 100 {
+		return b.send(ctx, chatID, "Слишком длинное имя, напишите покороче.", nil)
+	}
+	customer, err := b.store.CreateCustomer(ctx, name, s.phone)
+	if err != nil {
+		return err
+	}
+	s.state, s.phone = stateIdle, ""
+	return b.link(ctx, chatID, customer)
+}
+
+func (b *Bot) link(ctx context.Context, chatID int64, customer *Customer) error {
+	if err := b.store.LinkChat(ctx, customer.ID, chatID); err != nil {
+		return err
+	}
+	return b.send(ctx, chatID,
+		fmt.Sprintf("Готово, %s! Уведомления о ваших заказах будут приходить в этот чат.\n\n%s", customer.Name, textMenu),
+		telegram.ReplyKeyboardRemove{RemoveKeyboard: true})
+}
+
+func (b *Bot) listOrders(ctx context.Context, chatID int64, customer *Customer) error {
+	orders, err := b.store.Orders(ctx, customer.ID, 10)
+	if err != nil {
+		return err
+	}
+	if len(orders) == 0 {
+		return b.send(ctx, chatID, "У вас пока нет заказов. Записаться: /book", nil)
+	}
+	var sb strings.Builder
+	sb.WriteString("Ваши заказы:\n")
+	for _, o := range orders {
+		status, ok := statuses[o.Status]
+		if !ok {
+			status = o.Status
+		}
+		fmt.Fprintf(&sb, "\n№%d · %s · %s\n%s · %.2f ₽\n", o.ID, formatDate(o.ScheduledDate), status, o.Address, o.TotalCost)
+	}
+	return b.send(ctx, chatID, sb.String(), nil)
+}
+
+func (b *Bot) startBooking(ctx context.Context, chatID int64, s *session) error {
+	cities, err := b.store.Cities(ctx)
+	if err != nil {
+		return err
+	}
+	if len(cities) == 0 {
+		return b.send(ctx, chatID, "Сервисные центры пока не добавлены.", nil)
+	}
+	*s = session{cities: cities}
+
+	// Cities are referred to by index, callback data is limited to 64 bytes.
+	markup := &telegram.InlineKeyboardMarkup{}
+	for i, city := range cities {
+		if i%2 == 0 {
+			markup.InlineKeyboard = append(markup.InlineKeyboard, nil)
+		}
+		row := &markup.InlineKeyboard[len(markup.InlineKeyboard)-1]
+		*row = append(*row, button(city, "city:"+strconv.Itoa(i)))
+	}
+	return b.send(ctx, chatID, "Выберите город:", markup)
+}
+
+func (b *Bot) onCallback(ctx context.Context, q *telegram.CallbackQuery) error {
+	chatID := q.Message.Chat.ID
+	s := b.session(chatID)
+	customer, err := b.store.CustomerByChat(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	if customer == nil {
+		return b.askContact(ctx, chatID)
+	}
+
+	kind, arg, _ := strings.Cut(q.Data, ":")
+	n, _ := strconv.Atoi(arg)
+	switch kind {
+	case "city":
+		if n < 0 || n >= len(s.cities) {
+			return b.send(ctx, chatID, textExpired, nil)
+		}
+		return b.chooseCity(ctx, chatID, s.cities[n])
+	case "center":
+		return b.chooseCenter(ctx, chatID, s, n)
+	case "vehicle":
+		return b.chooseVehicle(ctx, chatID, s, arg)
+	case "svc":
+		if s.chosen == nil {
+			return b.send(ctx, chatID, textExpired, nil)
+		}
+		if arg == "done" {
+			return b.servicesChosen(ctx, chatID, s)
+		}
+		s.chosen[n] = !s.chosen[n]
+		return b.api.EditMessageText(ctx, chatID, q.Message.MessageID, "Выберите услуги и нажмите «Готово»:", b.servicesMarkup(s))
+	case "slot":
+		if n < 0 || n >= len(s.slots) || s.chosen == nil {
+			return b.send(ctx, chatID, textExpired, nil)
+		}
+		return b.chooseSlot(ctx, chatID, s, n)
+	case "confirm":
+		if s.slot == nil {
+			return b.send(ctx, chatID, textExpired, nil)
+		}
+		return b.confirm(ctx, chatID, s, customer)
+	case "cancel":
+		*s = session{}
+		return b.send(ctx, chatID, "Запись отменена.\n\n"+textMenu, nil)
+	default:
+		return b.send(ctx, chatID, textExpired, nil)
+	}
+}
+
+func (b *Bot) chooseCity(ctx context.Context, chatID int64, city string) error {
+	centers, err := b.store.Centers(ctx, city)
+	if err != nil {
+		return err
+	}
+	if len(centers) == 0 {
+		return b.send(ctx, chatID, textExpired, nil)
+	}
+	var buttons []telegram.InlineKeyboardButton
+	for _, c := range centers {
+		buttons = append(buttons, button(c.Address, "center:"+strconv.Itoa(c.ID)))
+	}
+	return b.send(ctx, chatID, "Выберите сервисный центр в городе "+city+":", column(buttons...))
+}
+
+func (b *Bot) chooseCenter(ctx context.Context, chatID int64, s *session, centerID int) error {
+	s.centerID, s.vehicle, s.services, s.chosen, s.slots, s.slot = centerID, "", nil, nil, nil, nil
+
+	var buttons []telegram.InlineKeyboardButton
+	for _, v := range vehicles {
+		buttons = append(buttons, button(v.label, "vehicle:"+v.value))
+	}
+	return b.send(ctx, chatID, "Какой у вас транспорт?", column(buttons...))
+}
+
+func (b *Bot) chooseVehicle(ctx context.Context, chatID int64, s *session, vehicle string) error {
+	if s.centerID == 0 || (vehicle != "Car" && vehicle != "Moto") {
+		return b.send(ctx, chatID, textExpired, nil)
+	}
+	services, err := b.store.Services(ctx, vehicle)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		return b.send(ctx, chatID, "Для этого транспорта пока нет услуг.", nil)
+	}
+	s.vehicle, s.services, s.chosen = vehicle, services, make(map[int]bool)
+	return b.send(ctx, chatID, "Выберите услуги и нажмите «Готово»:", b.servicesMarkup(s))
+}
+
+func (b *Bot) servicesMarkup(s *session) *telegram.InlineKeyboardMarkup {
+	var buttons []telegram.InlineKeyboardButton
+	for _, svc := range s.services {
+		mark := ""
+		if s.chosen[svc.ID] {
+			mark = "✓ "
+		}
+		buttons = append(buttons, button(fmt.Sprintf("%s%s — %.2f ₽", mark, svc.Name, svc.Price), "svc:"+strconv.Itoa(svc.ID)))
+	}
+	buttons = append(buttons, button("Готово", "svc:done"))
+	return column(buttons...)
+}
+
+func (s *session) chosenServices() []Service {
+	var out []Service
+	for _, svc := range s.services {
+		if s.chosen[svc.ID] {
+			out = append(out, svc)
+		}
+	}
+	return out
+}
+
+func (b *Bot) servicesChosen(ctx context.Context, chatID int64, s *session) error {
+	if len(s.chosenServices()) == 0 {
+		return b.send(ctx, chatID, "Выберите хотя бы одну услугу.", nil)
+	}
+	return b.offerSlots(ctx, chatID, s, "Выберите день:")
+}
+
+func (b *Bot) offerSlots(ctx context.Context, chatID int64, s *session, title string) error {
+	today := b.opts.Now()
+	from := time.Date(today.Year(), today.Month(), today.Day()+1, 0, 0, 0, 0, time.UTC)
+	slots, err := b.store.FreeSlots(ctx, s.centerID, from, from.AddDate(0, 0, b.opts.Days-1))
+	if err != nil {
+		return err
+	}
+	if len(slots) == 0 {
+		return b.send(ctx, chatID, fmt.Sprintf("В ближайшие %d дней свободных мастеров нет, попробуйте другой центр: /book", b.opts.Days), nil)
+	}
+	s.slots, s.slot = slots, nil
+
+	var buttons []telegram.InlineKeyboardButton
+	for i, slot := range slots {
+		buttons = append(buttons, button(fmt.Sprintf("%s — мастер %s", formatDate(slot.Date), slot.MasterName), "slot:"+strconv.Itoa(i)))
+	}
+	return b.send(ctx, chatID, title, column(buttons...))
+}
+
+func (b *Bot) chooseSlot(ctx context.Context, chatID int64, s *session, n int) error {
+	slot := s.slots[n]
+	s.slot = &slot
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Проверьте запись:\n\n%s, мастер %s\n", formatDate(slot.Date), slot.MasterName)
+	var total float64
+	for _, svc := range s.chosenServices() {
+		fmt.Fprintf(&sb, "• %s — %.2f ₽\n", svc.Name, svc.Price)
+		total += svc.Price
+	}
+	fmt.Fprintf(&sb, "\nИтого без скидок: %.2f ₽", total)
+	markup := &telegram.InlineKeyboardMarkup{InlineKeyboard: [][]telegram.InlineKeyboardButton{{
+		button("Подтвердить", "confirm"),
+		button("Отмена", "cancel"),
+	}}}
+	return b.send(ctx, chatID, sb.String(), markup)
+}
+
+func (b *Bot) confirm(ctx context.Context, chatID int64, s *session, customer *Customer) error {
+	var ids []int
+	for _, svc := range s.chosenServices() {
+		ids = append(ids, svc.ID)
+	}
+	orderID, total, err := b.store.CreateOrder(ctx, customer.ID, s.centerID, *s.slot, ids)
+	if errors.Is(err, ErrSlotTaken) {
+		return b.offerSlots(ctx, chatID, s, "Этот день только что заняли, выберите другой:")
+	}
+	if errors.Is(err, ErrNoManager) {
+		*s = session{}
+		return b.send(ctx, chatID, "Этот центр сейчас не принимает записи, выберите другой: /book", nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	date := s.slot.Date
+	*s = session{}
+	return b.send(ctx, chatID, fmt.Sprintf(
+		"Заказ №%d создан на %s. Предварительная стоимость %.2f ₽, статус: ожидает.\n"+
+			"Об изменениях статуса мы напишем в этот чат, посмотреть заказы: /orders",
+		orderID, formatDate(date), total), nil)
+}
@@ -0,0 +1,294 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vehicles-service-stations/internal/pricing"
+)
+
+// DB is satisfied by pools and transactions.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+var (
+	// ErrSlotTaken is returned when the master got booked meanwhile.
+	ErrSlotTaken = errors.New("slot is taken")
+	// ErrNoManager is returned for centers without a manager to own orders.
+	ErrNoManager = errors.New("service center has no manager")
+)
+
+type Customer struct {
+	ID            int
+	Name          string
+	Phone         string
+	BonusPoints   float64
+	SpentMoney    float64
+	LoyaltyStatus string
+}
+
+type Center struct {
+	ID      int
+	Address string
+	Phone   string
+}
+
+type Service struct {
+	ID    int
+	Name  string
+	Price float64
+}
+
+// Slot is a day a master of a center has no active order.
+type Slot struct {
+	Date       time.Time
+	MasterID   int
+	MasterName string
+}
+
+type OrderSummary struct {
+	ID            int
+	ScheduledDate time.Time
+	Status        string
+	Address       string
+	TotalCost     float64
+}
+
+// Store holds the queries of the bot.
+type Store struct {
+	db DB
+}
+
+func NewStore(db DB) *Store {
+	return &Store{db: db}
+}
+
+// NormalizePhone keeps the digits of a phone number with a leading +, the
+// way customers.phone_number stores them.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	b.WriteByte('+')
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+const customerColumns = `c.customer_id, c.full_name, c.phone_number, c.bonus_points, c.spent_money, c.loyalty_status::text`
+
+func scanCustomer(row pgx.Row) (*Customer, error) {
+	c := &Customer{}
+	err := row.Scan(&c.ID, &c.Name, &c.Phone, &c.BonusPoints, &c.SpentMoney, &c.LoyaltyStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load customer: %w", err)
+	}
+	return c, nil
+}
+
+// CustomerByChat returns the customer linked to a chat, nil if none is.
+func (s *Store) CustomerByChat(ctx context.Context, chatID int64) (*Customer, error) {
+	return scanCustomer(s.db.QueryRow(ctx, `
+        SELECT `+customerColumns+`
+        FROM notifications.contacts ct
+        JOIN customers c ON c.customer_id = ct.customer_id
+        WHERE ct.telegram_chat_id = $1
+    `, chatID))
+}
+
+// CustomerByPhone matches phone numbers by their digits, nil if none does.
+func (s *Store) CustomerByPhone(ctx context.Context, phone string) (*Customer, error) {
+	return scanCustomer(s.db.QueryRow(ctx, `
+        SELECT `+customerColumns+`
+        FROM customers c
+        WHERE regexp_replace(c.phone_number, '\D', '', 'g') = regexp_replace($1, '\D', '', 'g')
+        ORDER BY c.customer_id
+        LIMIT 1
+    `, phone))
+}
+
+func (s *Store) CreateCustomer(ctx context.Context, name, phone string) (*Customer, error) {
+	return scanCustomer(s.db.QueryRow(ctx, `
+        INSERT INTO customers AS c (full_name, phone_number)
+        VALUES ($1, $2)
+        RETURNING `+customerColumns,
+		name, NormalizePhone(phone)))
+}
+
+// LinkChat makes the chat the Telegram contact of the customer, so status
+// notifications reach it, and unlinks it from any other customer.
+func (s *Store) LinkChat(ctx context.Context, customerID int, chatID int64) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        UPDATE notifications.contacts SET telegram_chat_id = NULL
+        WHERE telegram_chat_id = $1 AND customer_id <> $2
+    `, chatID, customerID)
+	if err != nil {
+		return fmt.Errorf("failed to link chat: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO notifications.contacts (customer_id, telegram_chat_id)
+        VALUES ($1, $2)
+        ON CONFLICT (customer_id) DO UPDATE SET telegram_chat_id = EXCLUDED.telegram_chat_id
+    `, customerID, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to link chat: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *Store) Cities(ctx context.Context) ([]string, error) {
+	rows, err := s.db.Query(ctx, `SELECT DISTINCT city FROM service_centers ORDER BY city`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cities: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (s *Store) Centers(ctx context.Context, city string) ([]Center, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT service_center_id, full_address, phone_number
+        FROM service_centers
+        WHERE city = $1
+        ORDER BY service_center_id
+    `, city)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list centers: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Center])
+}
+
+func (s *Store) Services(ctx context.Context, vehicleType string) ([]Service, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT service_id, full_name, price::float8
+        FROM services
+        WHERE vehicle_type = $1::vehicle_type
+        ORDER BY full_name, service_id
+    `, vehicleType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Service])
+}
+
+// FreeSlots lists the days from..to on which masters of the center are free,
+// by the rule of check_and_suggest_alternative_slot: a master takes one
+// active order a day. The first free master is offered for each day.
+func (s *Store) FreeSlots(ctx context.Context, centerID int, from, to time.Time) ([]Slot, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT DISTINCT ON (d) d::date, e.employee_id, e.full_name
+        FROM generate_series($2::date, $3::date, INTERVAL '1 day') d
+        JOIN employee_service_center esc ON esc.service_center_id = $1 AND esc.employee_role = 'Master'
+        JOIN employees e ON e.employee_id = esc.employee_id
+        WHERE NOT EXISTS (
+            SELECT 1
+            FROM orders o
+            WHERE o.assigned_master_id = e.employee_id
+              AND o.status IN ('Pending', 'In Progress')
+              AND o.scheduled_date = d::date
+        )
+        ORDER BY d, e.employee_id
+    `, centerID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list free slots: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Slot])
+}
+
+// CreateOrder books a Pending order with the services, owned by the first
+// manager of the center and priced with the discounts of the customer. It
+// returns the order id and its total cost.
+func (s *Store) CreateOrder(ctx context.Context, customerID, centerID int, slot Slot, serviceIDs []int) (int, float64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var managerID int
+	err = tx.QueryRow(ctx, `
+        SELECT employee_id
+        FROM employee_service_center
+        WHERE service_center_id = $1 AND employee_role = 'Manager'
+        ORDER BY employee_id
+        LIMIT 1
+    `, centerID).Scan(&managerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, ErrNoManager
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find a manager: %w", err)
+	}
+
+	var orderID int
+	err = tx.QueryRow(ctx, `
+        INSERT INTO orders (customer_id, service_center_id, manager_id, assigned_master_id, scheduled_date, status)
+        VALUES ($1, $2, $3, $4, $5, 'Pending')
+        RETURNING order_id
+    `, customerID, centerID, managerID, slot.MasterID, slot.Date).Scan(&orderID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.RaiseException {
+		// Raised by before_order_insert when the master is busy.
+		return 0, 0, ErrSlotTaken
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	for _, id := range serviceIDs {
+		if _, err := tx.Exec(ctx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, id, orderID); err != nil {
+			return 0, 0, fmt.Errorf("failed to add service %d: %w", id, err)
+		}
+	}
+
+	quote, err := pricing.QuoteOrder(ctx, tx, orderID, "")
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return orderID, quote.Total.Float64(), nil
+}
+
+// Orders lists the latest orders of a customer.
+func (s *Store) Orders(ctx context.Context, customerID, limit int) ([]OrderSummary, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT o.order_id, o.scheduled_date, COALESCE(o.status::text, ''), sc.city || ', ' || sc.full_address,
+               COALESCE(o.total_cost, 0)::float8
+        FROM orders o
+        JOIN service_centers sc ON sc.service_center_id = o.service_center_id
+        WHERE o.customer_id = $1
+        ORDER BY o.scheduled_date DESC, o.order_id DESC
+        LIMIT $2
+    `, customerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[OrderSummary])
+}
+
+// Customer reloads a customer, for the current balance.
+func (s *Store) Customer(ctx context.Context, id int) (*Customer, error) {
+	return scanCustomer(s.db.QueryRow(ctx, `SELECT `+customerColumns+` FROM customers c WHERE c.customer_id = $1`, id))
+}
@@ -0,0 +1,242 @@
+package cdc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BrokerSink publishes every event to the topic named by its type through the
+// HTTP API of BrokerServer, a local stand-in for NATS or Kafka.
+type BrokerSink struct {
+	addr   string
+	client *http.Client
+}
+
+func NewBrokerSink(addr string) (*BrokerSink, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("broker address is empty")
+	}
+	return &BrokerSink{addr: strings.TrimRight(addr, "/"), client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (s *BrokerSink) Name() string { return "broker " + s.addr }
+
+func (s *BrokerSink) Write(ctx context.Context, batch []Envelope) error {
+	// Topics are published one after another, each keeping the commit order
+	// of its events.
+	var topics []string
+	byTopic := make(map[string][]Envelope)
+	for _, env := range batch {
+		if _, ok := byTopic[env.Type]; !ok {
+			topics = append(topics, env.Type)
+		}
+		byTopic[env.Type] = append(byTopic[env.Type], env)
+	}
+
+	for _, topic := range topics {
+		var body bytes.Buffer
+		if err := writeLines(&body, byTopic[topic]); err != nil {
+			return err
+		}
+		endpoint := fmt.Sprintf("%s/topics/%s", s.addr, url.PathEscape(topic))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-ndjson")
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to publish to %s: %w", topic, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("failed to publish to %s: broker responded %s", topic, resp.Status)
+		}
+	}
+	return nil
+}
+
+func (s *BrokerSink) Close() error { return nil }
+
+// BrokerMessage is a message of a topic, offsets start at 0.
+type BrokerMessage struct {
+	Offset  int64           `json:"offset"`
+	Message json.RawMessage `json:"message"`
+}
+
+// BrokerServer keeps append-only topics in memory. Publishing is idempotent
+// by message id, so redelivered events are stored once. Nothing is persisted.
+//
+//	POST /topics/<topic>                      appends JSON lines with an "id"
+//	GET  /topics/<topic>?offset=N&limit=M     reads JSON lines of BrokerMessage
+//	GET  /topics                              lists the topics and their sizes
+type BrokerServer struct {
+	mu     sync.RWMutex
+	topics map[string]*brokerTopic
+}
+
+type brokerTopic struct {
+	messages []BrokerMessage
+	seen     map[string]struct{}
+}
+
+func NewBrokerServer() *BrokerServer {
+	return &BrokerServer{topics: make(map[string]*brokerTopic)}
+}
+
+func (s *BrokerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	if path == "topics" && r.Method == http.MethodGet {
+		s.list(w)
+		return
+	}
+	topic, ok := strings.CutPrefix(path, "topics/")
+	if !ok || topic == "" || strings.Contains(topic, "/") {
+		http.Error(w, "unsupported path", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.publish(w, r, topic)
+	case http.MethodGet:
+		s.fetch(w, r, topic)
+	default:
+		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *BrokerServer) publish(w http.ResponseWriter, r *http.Request, topic string) {
+	var messages []json.RawMessage
+	var ids []string
+	scanner := bufio.NewScanner(io.LimitReader(r.Body, 16<<20))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var head struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(line, &head); err != nil || head.ID == "" {
+			http.Error(w, "every line must be a JSON object with an id", http.StatusBadRequest)
+			return
+		}
+		messages = append(messages, json.RawMessage(bytes.Clone(line)))
+		ids = append(ids, head.ID)
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	t, ok := s.topics[topic]
+	if !ok {
+		t = &brokerTopic{seen: make(map[string]struct{})}
+		s.topics[topic] = t
+	}
+	appended := 0
+	for i, msg := range messages {
+		if _, dup := t.seen[ids[i]]; dup {
+			continue
+		}
+		t.seen[ids[i]] = struct{}{}
+		t.messages = append(t.messages, BrokerMessage{Offset: int64(len(t.messages)), Message: msg})
+		appended++
+	}
+	size := len(t.messages)
+	s.mu.Unlock()
+
+	writeBrokerJSON(w, map[string]int{"appended": appended, "duplicates": len(messages) - appended, "size": size})
+}
+
+func (s *BrokerServer) fetch(w http.ResponseWriter, r *http.Request, topic string) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "invalid offset", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", 100)
+	if err != nil || limit <= 0 {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	var page []BrokerMessage
+	if t, ok := s.topics[topic]; ok && offset < len(t.messages) {
+		page = t.messages[offset:min(offset+limit, len(t.messages))]
+	}
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	enc := json.NewEncoder(w)
+	for _, msg := range page {
+		enc.Encode(msg)
+	}
+}
+
+func (s *BrokerServer) list(w http.ResponseWriter) {
+	s.mu.RLock()
+	sizes := make(map[string]int, len(s.topics))
+	for name, t := range s.topics {
+		sizes[name] = len(t.messages)
+	}
+	s.mu.RUnlock()
+	writeBrokerJSON(w, sizes)
+}
+
+func queryInt(r *http.Request, key string, def int) (int, error) {
+	v := r.URL.Query().Get(key)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
+
+func writeBrokerJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// FetchBroker reads up to limit messages of a topic from offset.
+func FetchBroker(ctx context.Context, client *http.Client, addr, topic string, offset int64, limit int) ([]BrokerMessage, error) {
+	endpoint := fmt.Sprintf("%s/topics/%s?offset=%d&limit=%d", strings.TrimRight(addr, "/"), url.PathEscape(topic), offset, limit)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", topic, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to fetch %s: broker responded %s", topic, resp.Status)
+	}
+
+	var messages []BrokerMessage
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var msg BrokerMessage
+		if err := dec.Decode(&msg); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", topic, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, nil
+}
@@ -0,0 +1,78 @@
+package cdc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Checkpoint persists the end of the last transaction delivered to the sink.
+// The stream resumes after it and drops transactions replayed by the server
+// up to it.
+type Checkpoint interface {
+	Load() (LSN, error)
+	Save(lsn LSN) error
+}
+
+// FileCheckpoint keeps the position in a small JSON file, replaced
+// atomically on every save.
+type FileCheckpoint struct {
+	path string
+}
+
+type checkpointFile struct {
+	LSN     LSN       `json:"lsn"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+func NewFileCheckpoint(path string) *FileCheckpoint {
+	return &FileCheckpoint{path: path}
+}
+
+// Load returns 0 when nothing was saved yet.
+func (c *FileCheckpoint) Load() (LSN, error) {
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var cp checkpointFile
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return 0, fmt.Errorf("failed to decode checkpoint %s: %w", c.path, err)
+	}
+	return cp.LSN, nil
+}
+
+func (c *FileCheckpoint) Save(lsn LSN) error {
+	data, err := json.Marshal(checkpointFile{LSN: lsn, SavedAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to move checkpoint into place: %w", err)
+	}
+	return nil
+}
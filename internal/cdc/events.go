@@ -0,0 +1,153 @@
+package cdc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"vehicles-service-stations/internal/events"
+)
+
+// Envelope is the unit delivered to sinks. Delivery is at least once, ID is
+// stable across redeliveries and can be used to drop duplicates.
+type Envelope struct {
+	ID         string       `json:"id"`
+	Type       string       `json:"type"`
+	LSN        LSN          `json:"lsn"`
+	XID        uint32       `json:"xid"`
+	CommitTime time.Time    `json:"commit_time"`
+	Version    int          `json:"version"`
+	Event      events.Event `json:"payload"`
+}
+
+// Tables lists the tables the events are derived from, they must be in the
+// publication and have REPLICA IDENTITY FULL for the old values of updates.
+var Tables = []string{"orders", "receipts", "spare_parts"}
+
+// eventsOf maps a change to domain events, other changes produce none.
+func eventsOf(c *Change) ([]events.Event, error) {
+	row := rowReader{change: c}
+	var out []events.Event
+
+	switch c.Table {
+	case "orders":
+		switch c.Op {
+		case OpInsert:
+			out = append(out, events.OrderCreated{
+				OrderID:         row.int(c.New, "order_id"),
+				CustomerID:      row.int(c.New, "customer_id"),
+				ServiceCenterID: row.int(c.New, "service_center_id"),
+				ManagerID:       row.int(c.New, "manager_id"),
+				MasterID:        row.int(c.New, "assigned_master_id"),
+				ScheduledDate:   row.text(c.New, "scheduled_date"),
+				Status:          row.text(c.New, "status"),
+			})
+		case OpUpdate:
+			orderID := row.int(c.New, "order_id")
+			from, to := row.text(c.Old, "status"), row.text(c.New, "status")
+			if from != to {
+				out = append(out, events.OrderStatusChanged{OrderID: orderID, From: from, To: to})
+			}
+			from, to = row.text(c.Old, "scheduled_date"), row.text(c.New, "scheduled_date")
+			if from != to {
+				out = append(out, events.OrderRescheduled{OrderID: orderID, From: from, To: to})
+			}
+			if from, to := row.master(c.Old), row.master(c.New); from != to {
+				out = append(out, events.OrderMasterChanged{OrderID: orderID, From: from, To: to})
+			}
+		}
+	case "receipts":
+		if c.Op == OpInsert {
+			out = append(out, events.ReceiptIssued{
+				ReceiptID:        row.int(c.New, "receipt_id"),
+				OrderID:          row.int(c.New, "order_id"),
+				TotalPaid:        row.float(c.New, "total_paid"),
+				BonusPointsSpent: row.float(c.New, "bonus_points_spent"),
+				ReceiptDate:      row.text(c.New, "receipt_date"),
+			})
+		}
+	case "spare_parts":
+		var ev events.StockChanged
+		switch c.Op {
+		case OpInsert:
+			ev = events.StockChanged{PartID: row.int(c.New, "part_id"), To: row.int(c.New, "stock_quantity")}
+		case OpUpdate:
+			ev = events.StockChanged{PartID: row.int(c.New, "part_id"), From: row.int(c.Old, "stock_quantity"), To: row.int(c.New, "stock_quantity")}
+		case OpDelete:
+			ev = events.StockChanged{PartID: row.int(c.Old, "part_id"), From: row.int(c.Old, "stock_quantity")}
+		}
+		if ev.From != ev.To {
+			out = append(out, ev)
+		}
+	}
+	return out, row.err
+}
+
+// rowReader converts text values, the first failure is kept in err.
+type rowReader struct {
+	change *Change
+	err    error
+}
+
+func (r *rowReader) text(row map[string]*string, column string) string {
+	value, ok := row[column]
+	if !ok && r.err == nil {
+		r.err = fmt.Errorf("%s %s: column %s is missing", r.change.Op, r.change.Table, column)
+	}
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func (r *rowReader) int(row map[string]*string, column string) int {
+	s := r.text(row, column)
+	if s == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%s %s: column %s: %w", r.change.Op, r.change.Table, column, err)
+	}
+	return n
+}
+
+// master returns the master doing an order.
+func (r *rowReader) master(row map[string]*string) int {
+	if reassigned := r.int(row, "reassigned_master_id"); reassigned != 0 {
+		return reassigned
+	}
+	return r.int(row, "assigned_master_id")
+}
+
+func (r *rowReader) float(row map[string]*string, column string) float64 {
+	s := r.text(row, column)
+	if s == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%s %s: column %s: %w", r.change.Op, r.change.Table, column, err)
+	}
+	return f
+}
+
+// UnmarshalJSON decodes the payload through the default event registry.
+func (e *Envelope) UnmarshalJSON(data []byte) error {
+	type plain Envelope
+	var raw struct {
+		plain
+		Event json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	event, err := events.Default.Decode(raw.Type, raw.Version, raw.Event)
+	if err != nil {
+		return err
+	}
+	*e = Envelope(raw.plain)
+	e.Event = event
+	return nil
+}
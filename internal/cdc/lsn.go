@@ -0,0 +1,31 @@
+package cdc
+
+import (
+	"github.com/jackc/pglogrepl"
+)
+
+// LSN is a position in the write-ahead log. It is pglogrepl.LSN with a text
+// encoding for checkpoints and events.
+type LSN pglogrepl.LSN
+
+func ParseLSN(s string) (LSN, error) {
+	lsn, err := pglogrepl.ParseLSN(s)
+	return LSN(lsn), err
+}
+
+func (l LSN) String() string {
+	return pglogrepl.LSN(l).String()
+}
+
+func (l LSN) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *LSN) UnmarshalText(text []byte) error {
+	parsed, err := ParseLSN(string(text))
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
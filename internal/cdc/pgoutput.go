@@ -0,0 +1,126 @@
+package cdc
+
+import (
+	"fmt"
+
+	"github.com/jackc/pglogrepl"
+)
+
+// Decoding of the pgoutput plugin messages, protocol version 1, on top of
+// pglogrepl.Parse.
+
+type Op string
+
+const (
+	OpInsert Op = "INSERT"
+	OpUpdate Op = "UPDATE"
+	OpDelete Op = "DELETE"
+)
+
+// Change is a decoded row change. Values are in text format, nil for NULL;
+// unchanged TOASTed values are missing from the maps.
+type Change struct {
+	Table string
+	Op    Op
+	Old   map[string]*string
+	New   map[string]*string
+}
+
+// decoder turns pgoutput messages into changes, tracking the relations
+// announced by the server.
+type decoder struct {
+	relations map[uint32]*pglogrepl.RelationMessage
+}
+
+func newDecoder() *decoder {
+	return &decoder{relations: make(map[uint32]*pglogrepl.RelationMessage)}
+}
+
+// decode returns *pglogrepl.BeginMessage, *pglogrepl.CommitMessage,
+// *pglogrepl.RelationMessage, *Change or nil for the messages the stream
+// does not use (origin, type, truncate and logical messages).
+func (d *decoder) decode(data []byte) (any, error) {
+	msg, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch msg := msg.(type) {
+	case *pglogrepl.BeginMessage, *pglogrepl.CommitMessage:
+		return msg, nil
+	case *pglogrepl.RelationMessage:
+		d.relations[msg.RelationID] = msg
+		return msg, nil
+	case *pglogrepl.InsertMessage:
+		return d.change(msg.RelationID, OpInsert, nil, msg.Tuple)
+	case *pglogrepl.UpdateMessage:
+		return d.change(msg.RelationID, OpUpdate, msg.OldTuple, msg.NewTuple)
+	case *pglogrepl.DeleteMessage:
+		return d.change(msg.RelationID, OpDelete, msg.OldTuple, nil)
+	default:
+		return nil, nil
+	}
+}
+
+// parse guards pglogrepl.Parse, which indexes past the end of truncated
+// tuple data instead of reporting it.
+func parse(data []byte) (msg pglogrepl.Message, err error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty pgoutput message")
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			msg, err = nil, fmt.Errorf("malformed pgoutput message %q: %v", data[0], r)
+		}
+	}()
+	msg, err = pglogrepl.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("malformed pgoutput message %q: %w", data[0], err)
+	}
+	return msg, nil
+}
+
+func (d *decoder) change(relationID uint32, op Op, old, new *pglogrepl.TupleData) (*Change, error) {
+	rel, ok := d.relations[relationID]
+	if !ok {
+		return nil, fmt.Errorf("change of unknown relation %d", relationID)
+	}
+
+	change := &Change{Table: rel.RelationName, Op: op}
+	var err error
+	if old != nil {
+		if change.Old, err = tuple(rel, old); err != nil {
+			return nil, err
+		}
+	}
+	if new != nil {
+		if change.New, err = tuple(rel, new); err != nil {
+			return nil, err
+		}
+	}
+	return change, nil
+}
+
+func tuple(rel *pglogrepl.RelationMessage, data *pglogrepl.TupleData) (map[string]*string, error) {
+	row := make(map[string]*string, len(data.Columns))
+	for i, col := range data.Columns {
+		var name string
+		if i < len(rel.Columns) {
+			name = rel.Columns[i].Name
+		} else {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+
+		switch col.DataType {
+		case pglogrepl.TupleDataTypeNull:
+			row[name] = nil
+		case pglogrepl.TupleDataTypeToast:
+		case pglogrepl.TupleDataTypeText:
+			value := string(col.Data)
+			row[name] = &value
+		default:
+			return nil, fmt.Errorf("unsupported column kind %q of %s.%s", col.DataType, rel.RelationName, name)
+		}
+	}
+	return row, nil
+}
@@ -0,0 +1,155 @@
+package cdc
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pglogrepl"
+)
+
+// pgoutput messages of protocol version 1 as the server sends them for
+//
+//	CREATE TABLE spare_parts (part_id int, name text, stock_quantity int);
+//	ALTER TABLE spare_parts REPLICA IDENTITY FULL;
+var (
+	relationMsg = concat([]byte{'R'}, u32(16390), cstr("public"), cstr("spare_parts"), []byte{'f'}, u16(3),
+		[]byte{1}, cstr("part_id"), u32(23), u32(0xffffffff),
+		[]byte{0}, cstr("name"), u32(25), u32(0xffffffff),
+		[]byte{0}, cstr("stock_quantity"), u32(23), u32(0xffffffff))
+	// INSERT INTO spare_parts VALUES (7, NULL, 5)
+	insertMsg = concat([]byte{'I'}, u32(16390), []byte{'N'}, u16(3),
+		[]byte{'t'}, u32(1), []byte("7"),
+		[]byte{'n'},
+		[]byte{'t'}, u32(1), []byte("5"))
+	// UPDATE spare_parts SET stock_quantity = 3 with name left TOASTed.
+	updateMsg = concat([]byte{'U'}, u32(16390),
+		[]byte{'O'}, u16(3), []byte{'t'}, u32(1), []byte("7"), []byte{'u'}, []byte{'t'}, u32(1), []byte("5"),
+		[]byte{'N'}, u16(3), []byte{'t'}, u32(1), []byte("7"), []byte{'u'}, []byte{'t'}, u32(1), []byte("3"))
+	// DELETE FROM spare_parts WHERE part_id = 7
+	deleteMsg = concat([]byte{'D'}, u32(16390),
+		[]byte{'O'}, u16(3), []byte{'t'}, u32(1), []byte("7"), []byte{'n'}, []byte{'t'}, u32(1), []byte("3"))
+	beginMsg  = concat([]byte{'B'}, u64(0x16B3748), u64(0), u32(750))
+	commitMsg = concat([]byte{'C'}, []byte{0}, u64(0x16B3748), u64(0x16B3778), u64(0))
+)
+
+func TestDecodeChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      []byte
+		op       Op
+		old, new map[string]*string
+	}{
+		{"insert", insertMsg, OpInsert, nil, map[string]*string{"part_id": ptr("7"), "name": nil, "stock_quantity": ptr("5")}},
+		{
+			"update with toasted column", updateMsg, OpUpdate,
+			map[string]*string{"part_id": ptr("7"), "stock_quantity": ptr("5")},
+			map[string]*string{"part_id": ptr("7"), "stock_quantity": ptr("3")},
+		},
+		{"delete", deleteMsg, OpDelete, map[string]*string{"part_id": ptr("7"), "name": nil, "stock_quantity": ptr("3")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDecoder()
+			if _, err := d.decode(relationMsg); err != nil {
+				t.Fatal(err)
+			}
+			msg, err := d.decode(tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			change, ok := msg.(*Change)
+			if !ok {
+				t.Fatalf("decoded %T, want *Change", msg)
+			}
+			if change.Table != "spare_parts" || change.Op != tt.op {
+				t.Errorf("change of %s %s, want spare_parts %s", change.Op, change.Table, tt.op)
+			}
+			assertRow(t, "old", change.Old, tt.old)
+			assertRow(t, "new", change.New, tt.new)
+		})
+	}
+}
+
+func TestDecodeTransaction(t *testing.T) {
+	d := newDecoder()
+	msg, err := d.decode(beginMsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := msg.(*pglogrepl.BeginMessage); !ok || LSN(b.FinalLSN).String() != "0/16B3748" || b.Xid != 750 {
+		t.Errorf("begin decoded as %+v", msg)
+	}
+	msg, err = d.decode(commitMsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := msg.(*pglogrepl.CommitMessage); !ok || LSN(c.TransactionEndLSN).String() != "0/16B3778" {
+		t.Errorf("commit decoded as %+v", msg)
+	}
+	// Origin messages are skipped.
+	if msg, err := d.decode(concat([]byte{'O'}, u64(1), cstr("origin"))); err != nil || msg != nil {
+		t.Errorf("origin decoded as %v, %v, want nothing", msg, err)
+	}
+}
+
+func TestDecodeUnknownRelation(t *testing.T) {
+	_, err := newDecoder().decode(insertMsg)
+	if err == nil || !strings.Contains(err.Error(), "unknown relation 16390") {
+		t.Errorf("insert before its relation: %v, want an unknown relation error", err)
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	for _, full := range [][]byte{relationMsg, insertMsg, updateMsg, deleteMsg, beginMsg, commitMsg} {
+		for n := 0; n < len(full); n++ {
+			d := newDecoder()
+			if full[0] != 'R' {
+				if _, err := d.decode(relationMsg); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if msg, err := d.decode(full[:n]); err == nil {
+				t.Errorf("%q cut to %d of %d bytes decoded as %+v, want an error", full[0], n, len(full), msg)
+			}
+		}
+	}
+}
+
+func assertRow(t *testing.T, which string, got, want map[string]*string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s row has %d columns, want %d", which, len(got), len(want))
+	}
+	for name, value := range want {
+		v, ok := got[name]
+		switch {
+		case !ok:
+			t.Errorf("%s row lacks %s", which, name)
+		case (v == nil) != (value == nil) || v != nil && *v != *value:
+			t.Errorf("%s row %s = %v, want %v", which, name, deref(v), deref(value))
+		}
+	}
+}
+
+func ptr(s string) *string { return &s }
+
+func deref(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+func u16(v uint16) []byte  { return binary.BigEndian.AppendUint16(nil, v) }
+func u32(v uint32) []byte  { return binary.BigEndian.AppendUint32(nil, v) }
+func u64(v uint64) []byte  { return binary.BigEndian.AppendUint64(nil, v) }
+func cstr(s string) []byte { return append([]byte(s), 0) }
@@ -0,0 +1,131 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgconn/ctxwatch"
+	"github.com/jackc/pgx/v5/pgproto3"
+
+	"vehicles-service-stations/internal/db"
+)
+
+// replConn is a walsender connection speaking the streaming replication
+// protocol through pglogrepl.
+type replConn struct {
+	conn *pgconn.PgConn
+}
+
+func connectReplication(ctx context.Context, cfg *db.Config) (*replConn, error) {
+	connCfg, err := pgconn.ParseConfig(cfg.ConnectionString())
+	if err != nil {
+		return nil, err
+	}
+	connCfg.RuntimeParams["replication"] = "database"
+	// A cancel request would end the replication stream, receive timeouts
+	// only need to interrupt the read.
+	connCfg.BuildContextWatcherHandler = func(conn *pgconn.PgConn) ctxwatch.Handler {
+		return &pgconn.DeadlineContextWatcherHandler{Conn: conn.Conn()}
+	}
+
+	conn, err := pgconn.ConnectConfig(ctx, connCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replication connection: %w", err)
+	}
+	return &replConn{conn: conn}, nil
+}
+
+func (c *replConn) Close(ctx context.Context) error {
+	return c.conn.Close(ctx)
+}
+
+// createSlot creates a pgoutput slot, an existing slot is kept.
+func (c *replConn) createSlot(ctx context.Context, slot string) error {
+	_, err := pglogrepl.CreateReplicationSlot(ctx, c.conn, pgx.Identifier{slot}.Sanitize(), "pgoutput",
+		pglogrepl.CreateReplicationSlotOptions{Mode: pglogrepl.LogicalReplication, SnapshotAction: "NOEXPORT_SNAPSHOT"})
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.DuplicateObject {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create replication slot %s: %w", slot, err)
+	}
+	return nil
+}
+
+// start switches the connection to copy both mode streaming the changes of
+// the publication from the given position. The server resumes from the
+// confirmed position of the slot when it is ahead.
+func (c *replConn) start(ctx context.Context, slot, publication string, from LSN) error {
+	err := pglogrepl.StartReplication(ctx, c.conn, pgx.Identifier{slot}.Sanitize(), pglogrepl.LSN(from),
+		pglogrepl.StartReplicationOptions{
+			Mode:       pglogrepl.LogicalReplication,
+			PluginArgs: []string{"proto_version '1'", "publication_names " + quoteLiteral(publication)},
+		})
+	if err != nil {
+		return fmt.Errorf("failed to start replication: %w", err)
+	}
+	return nil
+}
+
+// receive returns the next *pglogrepl.XLogData or
+// *pglogrepl.PrimaryKeepaliveMessage. It returns nil, nil when ctx expires
+// first so the caller can send a status update.
+func (c *replConn) receive(ctx context.Context) (any, error) {
+	msg, err := c.conn.ReceiveMessage(ctx)
+	if err != nil {
+		if pgconn.Timeout(err) && ctx.Err() == context.DeadlineExceeded {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	switch msg := msg.(type) {
+	case *pgproto3.CopyData:
+		if len(msg.Data) == 0 {
+			return nil, fmt.Errorf("empty copy data message")
+		}
+		switch msg.Data[0] {
+		case pglogrepl.XLogDataByteID:
+			xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
+			if err != nil {
+				return nil, err
+			}
+			return &xld, nil
+		case pglogrepl.PrimaryKeepaliveMessageByteID:
+			pkm, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
+			if err != nil {
+				return nil, err
+			}
+			return &pkm, nil
+		default:
+			return nil, fmt.Errorf("unknown copy data message %q", msg.Data[0])
+		}
+	case *pgproto3.ErrorResponse:
+		return nil, pgconn.ErrorResponseToPgError(msg)
+	case *pgproto3.CopyDone:
+		return nil, fmt.Errorf("server ended the replication stream")
+	default:
+		return nil, fmt.Errorf("unexpected message %T", msg)
+	}
+}
+
+// sendStatus reports everything up to lsn as durably processed, the server
+// may then recycle that WAL and resumes after it on reconnect.
+func (c *replConn) sendStatus(ctx context.Context, lsn LSN) error {
+	err := pglogrepl.SendStandbyStatusUpdate(ctx, c.conn, pglogrepl.StandbyStatusUpdate{WALWritePosition: pglogrepl.LSN(lsn)})
+	if err != nil {
+		return fmt.Errorf("failed to send standby status: %w", err)
+	}
+	return nil
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
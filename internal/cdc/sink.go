@@ -0,0 +1,108 @@
+package cdc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Sink receives the events of committed transactions in commit order. Write
+// must only return once the batch is durable, a failed batch is delivered
+// again after a restart.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, batch []Envelope) error
+	Close() error
+}
+
+type SinkKind string
+
+const (
+	SinkStdout SinkKind = "stdout"
+	SinkFile   SinkKind = "file"
+	SinkBroker SinkKind = "broker"
+)
+
+type SinkConfig struct {
+	Kind SinkKind
+	// Path configures the file sink.
+	Path string
+	// BrokerAddr configures the broker sink.
+	BrokerAddr string
+}
+
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Kind {
+	case SinkStdout, "":
+		return &StdoutSink{W: os.Stdout}, nil
+	case SinkFile:
+		return NewFileSink(cfg.Path)
+	case SinkBroker:
+		return NewBrokerSink(cfg.BrokerAddr)
+	default:
+		return nil, fmt.Errorf("unknown cdc sink %q", cfg.Kind)
+	}
+}
+
+// StdoutSink prints every envelope as a JSON line.
+type StdoutSink struct {
+	W io.Writer
+}
+
+func (s *StdoutSink) Name() string { return "stdout" }
+
+func (s *StdoutSink) Write(_ context.Context, batch []Envelope) error {
+	return writeLines(s.W, batch)
+}
+
+func (s *StdoutSink) Close() error { return nil }
+
+// FileSink appends JSON lines to a file and syncs it after every batch.
+type FileSink struct {
+	path string
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("cdc file path is empty")
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cdc file: %w", err)
+	}
+	return &FileSink{path: path, file: file}, nil
+}
+
+func (s *FileSink) Name() string { return "file " + s.path }
+
+func (s *FileSink) Write(_ context.Context, batch []Envelope) error {
+	if err := writeLines(s.file, batch); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync cdc file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+func writeLines(w io.Writer, batch []Envelope) error {
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+	for _, env := range batch {
+		if err := enc.Encode(env); err != nil {
+			return fmt.Errorf("failed to encode event %s: %w", env.ID, err)
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+
+	"vehicles-service-stations/internal/db"
+)
+
+// DefaultPublication and DefaultSlot match deployments/assets/0003_cdc.sql.
+const (
+	DefaultPublication = "cdc_domain"
+	DefaultSlot        = "cdc_events"
+)
+
+type Options struct {
+	Slot        string
+	Publication string
+	// CreateSlot creates the slot when it does not exist yet. A new slot
+	// starts at the current end of the WAL, earlier changes are not streamed.
+	CreateSlot bool
+	// BatchSize is the number of events buffered before they are written,
+	// buffered events are also written every StatusInterval.
+	BatchSize      int
+	StatusInterval time.Duration
+	// OnFlush is called after a batch is written and checkpointed.
+	OnFlush func(confirmed LSN, events int)
+}
+
+func (o *Options) defaults() {
+	if o.Slot == "" {
+		o.Slot = DefaultSlot
+	}
+	if o.Publication == "" {
+		o.Publication = DefaultPublication
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.StatusInterval <= 0 {
+		o.StatusInterval = 10 * time.Second
+	}
+}
+
+// Run streams the events of the publication to the sink until ctx is done.
+//
+// Events are delivered at least once: a batch is written to the sink before
+// the checkpoint is saved and before the server is told it may discard the
+// WAL, so a crash in between replays the batch. Transactions up to the
+// checkpoint are skipped when the server replays them.
+func Run(ctx context.Context, cfg *db.Config, sink Sink, checkpoint Checkpoint, opts Options) error {
+	opts.defaults()
+
+	confirmed, err := checkpoint.Load()
+	if err != nil {
+		return err
+	}
+
+	conn, err := connectReplication(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if opts.CreateSlot {
+		if err := conn.createSlot(ctx, opts.Slot); err != nil {
+			return err
+		}
+	}
+	if err := conn.start(ctx, opts.Slot, opts.Publication, confirmed); err != nil {
+		return err
+	}
+
+	s := &stream{
+		conn:       conn,
+		sink:       sink,
+		checkpoint: checkpoint,
+		opts:       opts,
+		decoder:    newDecoder(),
+		confirmed:  confirmed,
+		end:        confirmed,
+	}
+	return s.run(ctx)
+}
+
+type stream struct {
+	conn       *replConn
+	sink       Sink
+	checkpoint Checkpoint
+	opts       Options
+	decoder    *decoder
+
+	// confirmed is the checkpointed position, end the position reached once
+	// pending is written.
+	confirmed LSN
+	end       LSN
+	pending   []Envelope
+
+	tx       *pglogrepl.BeginMessage
+	txEvents []Envelope
+}
+
+func (s *stream) run(ctx context.Context) error {
+	nextStatus := time.Now().Add(s.opts.StatusInterval)
+	for {
+		if !time.Now().Before(nextStatus) {
+			if err := s.flush(ctx); err != nil {
+				return err
+			}
+			nextStatus = time.Now().Add(s.opts.StatusInterval)
+		}
+
+		recvCtx, cancel := context.WithDeadline(ctx, nextStatus)
+		msg, err := s.conn.receive(recvCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return s.flush(context.WithoutCancel(ctx))
+			}
+			return err
+		}
+
+		switch msg := msg.(type) {
+		case *pglogrepl.PrimaryKeepaliveMessage:
+			// Every transaction before the end reported by the server has
+			// been received, so an idle stream can move on.
+			if end := LSN(msg.ServerWALEnd); s.tx == nil && end > s.end {
+				s.end = end
+			}
+			if msg.ReplyRequested {
+				if err := s.flush(ctx); err != nil {
+					return err
+				}
+			}
+		case *pglogrepl.XLogData:
+			if err := s.handle(ctx, msg.WALData); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+var errReplicaIdentity = errors.New("table needs REPLICA IDENTITY FULL")
+
+func (s *stream) handle(ctx context.Context, data []byte) error {
+	msg, err := s.decoder.decode(data)
+	if err != nil {
+		return err
+	}
+
+	switch msg := msg.(type) {
+	case *pglogrepl.BeginMessage:
+		s.tx = msg
+		s.txEvents = s.txEvents[:0]
+	case *pglogrepl.RelationMessage:
+		if (msg.RelationName == "orders" || msg.RelationName == "spare_parts") && msg.ReplicaIdentity != 'f' {
+			return fmt.Errorf("%w: %s.%s", errReplicaIdentity, msg.Namespace, msg.RelationName)
+		}
+	case *Change:
+		if s.tx == nil {
+			return fmt.Errorf("change of %s outside of a transaction", msg.Table)
+		}
+		events, err := eventsOf(msg)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			s.txEvents = append(s.txEvents, Envelope{
+				// The position of the commit and the index of the event in
+				// the transaction identify it across redeliveries.
+				ID:         fmt.Sprintf("%s-%d", s.tx.FinalLSN, len(s.txEvents)),
+				Type:       event.EventType(),
+				LSN:        LSN(s.tx.FinalLSN),
+				XID:        s.tx.Xid,
+				CommitTime: s.tx.CommitTime,
+				Version:    event.EventVersion(),
+				Event:      event,
+			})
+		}
+	case *pglogrepl.CommitMessage:
+		s.tx = nil
+		end := LSN(msg.TransactionEndLSN)
+		if end <= s.confirmed {
+			return nil
+		}
+		s.pending = append(s.pending, s.txEvents...)
+		s.end = max(s.end, end)
+		if len(s.pending) >= s.opts.BatchSize {
+			return s.flush(ctx)
+		}
+	}
+	return nil
+}
+
+// flush writes the pending events, saves the checkpoint and confirms it to
+// the server, in that order.
+func (s *stream) flush(ctx context.Context) error {
+	written := len(s.pending)
+	if written > 0 {
+		if err := s.sink.Write(ctx, s.pending); err != nil {
+			return fmt.Errorf("failed to write to %s: %w", s.sink.Name(), err)
+		}
+		s.pending = s.pending[:0]
+	}
+	if s.end > s.confirmed {
+		if err := s.checkpoint.Save(s.end); err != nil {
+			return err
+		}
+		s.confirmed = s.end
+		if s.opts.OnFlush != nil {
+			s.opts.OnFlush(s.confirmed, written)
+		}
+	}
+	return s.conn.sendStatus(ctx, s.confirmed)
+}
@@ -0,0 +1,156 @@
+package check
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type Options struct {
+	// Only restricts the run to the named invariants, all when empty.
+	Only []string
+	// Repair fixes repairable violations in the same transaction.
+	Repair bool
+	// SampleLimit caps the violations listed per invariant.
+	SampleLimit int
+}
+
+type Violation struct {
+	ID      string         `json:"id"`
+	Details map[string]any `json:"details"`
+}
+
+type Result struct {
+	Invariant   string      `json:"invariant"`
+	Description string      `json:"description"`
+	Violations  int         `json:"violations"`
+	Repairable  bool        `json:"repairable"`
+	Repaired    int64       `json:"repaired,omitempty"`
+	Remaining   int         `json:"remaining"`
+	Samples     []Violation `json:"samples,omitempty"`
+}
+
+type Report struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Repair    bool      `json:"repair"`
+	Results   []Result  `json:"results"`
+}
+
+func (r *Report) Clean() bool {
+	for _, res := range r.Results {
+		if res.Remaining > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func selectInvariants(only []string) ([]Invariant, error) {
+	if len(only) == 0 {
+		return Invariants, nil
+	}
+
+	byName := make(map[string]Invariant, len(Invariants))
+	for _, inv := range Invariants {
+		byName[inv.Name] = inv
+	}
+
+	selected := make([]Invariant, 0, len(only))
+	for _, name := range only {
+		inv, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown invariant %q", name)
+		}
+		selected = append(selected, inv)
+	}
+	return selected, nil
+}
+
+// DB is satisfied by pools and connections.
+type DB interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+func Run(ctx context.Context, db DB, opts Options) (*Report, error) {
+	invariants, err := selectInvariants(opts.Only)
+	if err != nil {
+		return nil, err
+	}
+
+	accessMode := pgx.ReadOnly
+	if opts.Repair {
+		accessMode = pgx.ReadWrite
+	}
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: accessMode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin check transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	report := &Report{CheckedAt: time.Now(), Repair: opts.Repair}
+	for _, inv := range invariants {
+		res := Result{
+			Invariant:   inv.Name,
+			Description: inv.Description,
+			Repairable:  inv.Repair != "",
+		}
+
+		res.Violations, res.Samples, err = violations(ctx, tx, inv, opts.SampleLimit)
+		if err != nil {
+			return nil, err
+		}
+		res.Remaining = res.Violations
+
+		if opts.Repair && res.Repairable && res.Violations > 0 {
+			tag, err := tx.Exec(ctx, inv.Repair)
+			if err != nil {
+				return nil, fmt.Errorf("failed to repair %s: %w", inv.Name, err)
+			}
+			res.Repaired = tag.RowsAffected()
+
+			res.Remaining, _, err = violations(ctx, tx, inv, 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		report.Results = append(report.Results, res)
+	}
+
+	if opts.Repair {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit repairs: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+func violations(ctx context.Context, tx pgx.Tx, inv Invariant, limit int) (int, []Violation, error) {
+	rows, err := tx.Query(ctx, inv.Query)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to check %s: %w", inv.Name, err)
+	}
+	defer rows.Close()
+
+	var count int
+	var samples []Violation
+	for rows.Next() {
+		var v Violation
+		if err := rows.Scan(&v.ID, &v.Details); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan %s violation: %w", inv.Name, err)
+		}
+		count++
+		if count <= limit {
+			samples = append(samples, v)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("error iterating %s violations: %w", inv.Name, err)
+	}
+
+	return count, samples, nil
+}
@@ -0,0 +1,172 @@
+package check
+
+type Invariant struct {
+	Name        string
+	Description string
+	// Query returns one row per violation: an entity id and a jsonb object
+	// describing the mismatch.
+	Query string
+	// Repair brings violating rows back in line, empty when the violation
+	// needs a human decision.
+	Repair string
+}
+
+const loyaltyTierExpr = `
+	CASE
+		WHEN spent_money >= 100000 THEN 'Platinum'
+		WHEN spent_money >= 50000 THEN 'Gold'
+		WHEN spent_money >= 10000 THEN 'Silver'
+		ELSE 'Bronze'
+	END::loyalty_status`
+
+// Orders priced by internal/pricing cost the total of their current quote.
+const expectedTotalCostExpr = `
+	COALESCE((
+		SELECT q.total
+		FROM pricing.quotes q
+		WHERE q.quote_id = o.quote_id
+	),
+	COALESCE((
+		SELECT SUM(s.price)
+		FROM service_order so
+		JOIN services s ON so.service_id = s.service_id
+		WHERE so.order_id = o.order_id
+	), 0)
+	+
+	COALESCE((
+		SELECT SUM(spo.purchase_price * spo.quantity)
+		FROM spare_part_order spo
+		WHERE spo.order_id = o.order_id
+	), 0))`
+
+var Invariants = []Invariant{
+	{
+		Name:        "employees_count",
+		Description: "service_centers.employees_count equals the number of employee_service_center rows",
+		Query: `
+			SELECT sc.service_center_id::text,
+			       jsonb_build_object('employees_count', sc.employees_count, 'actual', COUNT(esc.employee_id))
+			FROM service_centers sc
+			LEFT JOIN employee_service_center esc ON esc.service_center_id = sc.service_center_id
+			GROUP BY sc.service_center_id
+			HAVING sc.employees_count <> COUNT(esc.employee_id)
+			ORDER BY sc.service_center_id`,
+		Repair: `
+			UPDATE service_centers sc
+			SET employees_count = actual.cnt
+			FROM (
+				SELECT sc2.service_center_id, COUNT(esc.employee_id) AS cnt
+				FROM service_centers sc2
+				LEFT JOIN employee_service_center esc ON esc.service_center_id = sc2.service_center_id
+				GROUP BY sc2.service_center_id
+			) actual
+			WHERE actual.service_center_id = sc.service_center_id
+			  AND sc.employees_count <> actual.cnt`,
+	},
+	{
+		Name:        "order_total_cost",
+		Description: "orders.total_cost equals the total of its quote, or the price of its services plus its spare parts",
+		Query: `
+			SELECT e.order_id::text,
+			       jsonb_build_object('total_cost', e.total_cost, 'expected', e.expected)
+			FROM (
+				SELECT o.order_id, o.total_cost, ` + expectedTotalCostExpr + ` AS expected
+				FROM orders o
+			) e
+			WHERE e.total_cost IS DISTINCT FROM e.expected
+			ORDER BY e.order_id`,
+		Repair: `
+			UPDATE orders o
+			SET total_cost = ` + expectedTotalCostExpr + `
+			WHERE o.total_cost IS DISTINCT FROM (` + expectedTotalCostExpr + `)`,
+	},
+	{
+		Name:        "loyalty_tier",
+		Description: "customers.loyalty_status matches the tier of spent_money",
+		Query: `
+			SELECT customer_id::text,
+			       jsonb_build_object('spent_money', spent_money, 'loyalty_status', loyalty_status, 'expected', ` + loyaltyTierExpr + `)
+			FROM customers
+			WHERE loyalty_status <> ` + loyaltyTierExpr + `
+			ORDER BY customer_id`,
+		Repair: `
+			UPDATE customers
+			SET loyalty_status = ` + loyaltyTierExpr + `
+			WHERE loyalty_status <> ` + loyaltyTierExpr,
+	},
+	{
+		Name:        "receipt_order_completed",
+		Description: "receipts exist only for Completed orders",
+		Query: `
+			SELECT r.receipt_id::text,
+			       jsonb_build_object('order_id', o.order_id, 'status', o.status)
+			FROM receipts r
+			JOIN orders o ON o.order_id = r.order_id
+			WHERE o.status IS DISTINCT FROM 'Completed'
+			ORDER BY r.receipt_id`,
+	},
+	{
+		Name:        "receipt_quote_total",
+		Description: "receipts charging a quote are paid its total less the bonus points spent",
+		Query: `
+			SELECT r.receipt_id::text,
+			       jsonb_build_object('total_paid', r.total_paid, 'bonus_points_spent', r.bonus_points_spent,
+			                          'quote_id', q.quote_id, 'quote_total', q.total)
+			FROM receipts r
+			JOIN pricing.quotes q ON q.quote_id = r.quote_id
+			WHERE r.total_paid + r.bonus_points_spent <> q.total
+			ORDER BY r.receipt_id`,
+	},
+	{
+		Name:        "bonus_balance",
+		Description: "customers.bonus_points is not negative",
+		Query: `
+			SELECT customer_id::text,
+			       jsonb_build_object('bonus_points', bonus_points)
+			FROM customers
+			WHERE bonus_points < 0
+			ORDER BY customer_id`,
+		Repair: `
+			UPDATE customers
+			SET bonus_points = 0
+			WHERE bonus_points < 0`,
+	},
+	{
+		Name:        "order_master_role",
+		Description: "assigned and reassigned masters hold the Master role at the order's service center",
+		Query: `
+			SELECT o.order_id::text,
+			       jsonb_build_object('column', m.col, 'employee_id', m.employee_id, 'service_center_id', o.service_center_id)
+			FROM orders o
+			CROSS JOIN LATERAL (
+				VALUES ('assigned_master_id', o.assigned_master_id),
+				       ('reassigned_master_id', o.reassigned_master_id)
+			) AS m(col, employee_id)
+			WHERE m.employee_id IS NOT NULL
+			  AND NOT EXISTS (
+				SELECT 1
+				FROM employee_service_center esc
+				WHERE esc.employee_id = m.employee_id
+				  AND esc.service_center_id = o.service_center_id
+				  AND esc.employee_role = 'Master'
+			  )
+			ORDER BY o.order_id`,
+	},
+	{
+		Name:        "order_manager_role",
+		Description: "the order's manager holds the Manager role at the order's service center",
+		Query: `
+			SELECT o.order_id::text,
+			       jsonb_build_object('employee_id', o.manager_id, 'service_center_id', o.service_center_id)
+			FROM orders o
+			WHERE o.manager_id IS NOT NULL
+			  AND NOT EXISTS (
+				SELECT 1
+				FROM employee_service_center esc
+				WHERE esc.employee_id = o.manager_id
+				  AND esc.service_center_id = o.service_center_id
+				  AND esc.employee_role = 'Manager'
+			  )
+			ORDER BY o.order_id`,
+	},
+}
@@ -0,0 +1,216 @@
+package check_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/check"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// txDB runs the checks inside the transaction of the test, their
+// transactions become savepoints.
+type txDB struct {
+	pgx.Tx
+}
+
+func (db txDB) BeginTx(ctx context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return db.Begin(ctx)
+}
+
+func exec(t *testing.T, tx pgx.Tx, sql string, args ...any) {
+	t.Helper()
+	if _, err := tx.Exec(context.Background(), sql, args...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newQuote prices an order at total without going through internal/pricing.
+func newQuote(t *testing.T, tx pgx.Tx, orderID int, total float64) int64 {
+	t.Helper()
+	var id int64
+	err := tx.QueryRow(context.Background(), `
+        INSERT INTO pricing.quotes (order_id, subtotal, discount, vat, rounding, total, breakdown)
+        VALUES ($1, $2, 0, 0, 0, $2, '{}')
+        RETURNING quote_id
+    `, orderID, total).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// Every invariant is broken on a row the triggers would keep consistent,
+// then checked and, when it can be, repaired.
+func TestInvariants(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "check")
+	ctx := context.Background()
+
+	tests := []struct {
+		invariant string
+		// corrupt breaks the invariant with the triggers off and returns the
+		// id of the violation.
+		corrupt func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64
+		// repaired checks the row after the repair.
+		repaired string
+	}{
+		{
+			invariant: "employees_count",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				exec(t, tx, `UPDATE service_centers SET employees_count = 10 WHERE service_center_id = $1`, b.Center)
+				return int64(b.Center)
+			},
+			repaired: `SELECT employees_count = 3 FROM service_centers WHERE service_center_id = $1`,
+		},
+		{
+			invariant: "order_total_cost",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				order := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+				exec(t, tx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, testutil.NewService(t, tx, 1000), order)
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				exec(t, tx, `UPDATE orders SET total_cost = 1 WHERE order_id = $1`, order)
+				return int64(order)
+			},
+			repaired: `SELECT total_cost = 1000 FROM orders WHERE order_id = $1`,
+		},
+		{
+			// A quoted order costs the total of its quote, not the sum of
+			// its prices.
+			invariant: "order_total_cost",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				order := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+				exec(t, tx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, testutil.NewService(t, tx, 1000), order)
+				quote := newQuote(t, tx, order, 900)
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				exec(t, tx, `UPDATE orders SET quote_id = $2 WHERE order_id = $1`, order, quote)
+				return int64(order)
+			},
+			repaired: `SELECT total_cost = 900 FROM orders WHERE order_id = $1`,
+		},
+		{
+			invariant: "loyalty_tier",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				customer := testutil.NewCustomer(t, tx, 60000, 0)
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				exec(t, tx, `UPDATE customers SET loyalty_status = 'Bronze' WHERE customer_id = $1`, customer)
+				return int64(customer)
+			},
+			repaired: `SELECT loyalty_status = 'Gold' FROM customers WHERE customer_id = $1`,
+		},
+		{
+			invariant: "receipt_order_completed",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				order := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				var receipt int64
+				err := tx.QueryRow(ctx, `INSERT INTO receipts (order_id) VALUES ($1) RETURNING receipt_id`, order).Scan(&receipt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return receipt
+			},
+		},
+		{
+			invariant: "receipt_quote_total",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				order := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+				quote := newQuote(t, tx, order, 900)
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				exec(t, tx, `UPDATE orders SET status = 'Completed', quote_id = $2 WHERE order_id = $1`, order, quote)
+				var receipt int64
+				err := tx.QueryRow(ctx, `
+                    INSERT INTO receipts (order_id, quote_id, bonus_points_spent, total_paid)
+                    VALUES ($1, $2, 50, 800)
+                    RETURNING receipt_id
+                `, order, quote).Scan(&receipt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return receipt
+			},
+		},
+		{
+			invariant: "bonus_balance",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				// The CHECK constraint is dropped with the transaction.
+				exec(t, tx, `ALTER TABLE customers DROP CONSTRAINT customers_bonus_points_check`)
+				exec(t, tx, `UPDATE customers SET bonus_points = -5 WHERE customer_id = $1`, b.Customer)
+				return int64(b.Customer)
+			},
+			repaired: `SELECT bonus_points = 0 FROM customers WHERE customer_id = $1`,
+		},
+		{
+			invariant: "order_master_role",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				order := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				exec(t, tx, `UPDATE orders SET reassigned_master_id = $2 WHERE order_id = $1`, order, b.Manager)
+				return int64(order)
+			},
+		},
+		{
+			invariant: "order_manager_role",
+			corrupt: func(t *testing.T, tx pgx.Tx, b *testutil.Base) int64 {
+				order := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+				exec(t, tx, `SET LOCAL session_replication_role = replica`)
+				exec(t, tx, `UPDATE orders SET manager_id = $2 WHERE order_id = $1`, order, b.Masters[1])
+				return int64(order)
+			},
+		},
+	}
+	covered := make(map[string]bool)
+	for i, tt := range tests {
+		covered[tt.invariant] = true
+		t.Run(fmt.Sprintf("%s/%d", tt.invariant, i), func(t *testing.T) {
+			tx := testutil.Tx(t, d.Pool)
+			b := testutil.NewBase(t, tx)
+			id := tt.corrupt(t, tx, b)
+
+			only := check.Options{Only: []string{tt.invariant}, SampleLimit: 100}
+			report, err := check.Run(ctx, txDB{tx}, only)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := report.Results[0]
+			found := false
+			for _, v := range res.Samples {
+				found = found || v.ID == strconv.FormatInt(id, 10)
+			}
+			if !found {
+				t.Fatalf("%d is not among the violations %+v", id, res.Samples)
+			}
+			if res.Repairable != (tt.repaired != "") {
+				t.Fatalf("repairable is %t", res.Repairable)
+			}
+			if !res.Repairable {
+				return
+			}
+
+			only.Repair = true
+			report, err = check.Run(ctx, txDB{tx}, only)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res := report.Results[0]; res.Repaired == 0 || res.Remaining != 0 {
+				t.Errorf("repaired %d, %d violations remain", res.Repaired, res.Remaining)
+			}
+			var ok bool
+			if err := tx.QueryRow(ctx, tt.repaired, id).Scan(&ok); err != nil {
+				t.Fatal(err)
+			}
+			if !ok {
+				t.Errorf("%d is not repaired: %s", id, tt.repaired)
+			}
+		})
+	}
+	for _, inv := range check.Invariants {
+		if !covered[inv.Name] {
+			t.Errorf("invariant %s is not tested", inv.Name)
+		}
+	}
+}
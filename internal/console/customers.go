@@ -0,0 +1,174 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type customersMsg []Customer
+
+// customersScreen searches customers by phone number, a found customer gets
+// a new order.
+type customersScreen struct {
+	app       *app
+	phone     textinput.Model
+	table     table.Model
+	customers []Customer
+	searched  bool
+}
+
+func newCustomersScreen(a *app) *customersScreen {
+	s := &customersScreen{app: a, phone: textinput.New()}
+	s.phone.Prompt = "Phone: "
+	s.phone.Placeholder = "digits of the phone number"
+	s.phone.Focus()
+	s.table = newTable([]table.Column{
+		{Title: "ID", Width: 6},
+		{Title: "Name", Width: 30},
+		{Title: "Phone", Width: 16},
+		{Title: "Loyalty", Width: 9},
+		{Title: "Bonus", Width: 10},
+	})
+	s.table.Blur()
+	return s
+}
+
+func (s *customersScreen) Title() string { return "Customers" }
+
+func (s *customersScreen) Help() string {
+	if s.phone.Focused() {
+		return "enter search · tab results · ctrl+n new customer"
+	}
+	return "enter new order · tab phone · ctrl+n new customer"
+}
+
+func (s *customersScreen) Init() tea.Cmd { return textinput.Blink }
+
+func (s *customersScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case customersMsg:
+		s.customers, s.searched = msg, true
+		rows := make([]table.Row, len(msg))
+		for i, c := range msg {
+			rows[i] = table.Row{strconv.Itoa(c.ID), c.Name, c.Phone, c.LoyaltyStatus, money(c.BonusPoints)}
+		}
+		s.table.SetRows(rows)
+		s.table.SetCursor(0)
+		if len(msg) > 0 {
+			s.phone.Blur()
+			s.table.Focus()
+		}
+		return s, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab", "shift+tab":
+			if s.phone.Focused() {
+				s.phone.Blur()
+				s.table.Focus()
+				return s, nil
+			}
+			s.table.Blur()
+			return s, s.phone.Focus()
+		case "ctrl+n":
+			return s, push(newCustomerForm(s.app, s.phone.Value()))
+		case "enter":
+			if s.phone.Focused() {
+				phone := s.phone.Value()
+				return s, s.app.do(func(ctx context.Context) (tea.Msg, error) {
+					customers, err := s.app.store.SearchCustomers(ctx, phone)
+					return customersMsg(customers), err
+				})
+			}
+			if n := s.table.Cursor(); n >= 0 && n < len(s.customers) {
+				return s, push(newOrderScreen(s.app, s.customers[n]))
+			}
+			return s, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	if s.phone.Focused() {
+		s.phone, cmd = s.phone.Update(msg)
+	} else {
+		s.table, cmd = s.table.Update(msg)
+	}
+	return s, cmd
+}
+
+func (s *customersScreen) View() string {
+	view := s.phone.View() + "\n\n"
+	if s.searched && len(s.customers) == 0 {
+		return view + "No customers found, ctrl+n adds one."
+	}
+	return view + s.table.View()
+}
+
+type customerForm struct {
+	app         *app
+	name, phone textinput.Model
+}
+
+func newCustomerForm(a *app, phone string) *customerForm {
+	s := &customerForm{app: a, name: textinput.New(), phone: textinput.New()}
+	s.name.Prompt, s.phone.Prompt = "Full name: ", "Phone:     "
+	s.name.CharLimit, s.phone.CharLimit = 100, 16
+	s.phone.Placeholder = "+79990000000"
+	s.phone.SetValue(strings.TrimSpace(phone))
+	s.name.Focus()
+	return s
+}
+
+func (s *customerForm) Title() string { return "New customer" }
+
+func (s *customerForm) Help() string { return "tab switch field · enter save" }
+
+func (s *customerForm) Init() tea.Cmd { return textinput.Blink }
+
+func (s *customerForm) Update(msg tea.Msg) (screen, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "tab", "shift+tab", "up", "down":
+			if s.name.Focused() {
+				s.name.Blur()
+				return s, s.phone.Focus()
+			}
+			s.phone.Blur()
+			return s, s.name.Focus()
+		case "enter":
+			return s, s.save()
+		}
+	}
+
+	var cmd tea.Cmd
+	if s.name.Focused() {
+		s.name, cmd = s.name.Update(msg)
+	} else {
+		s.phone, cmd = s.phone.Update(msg)
+	}
+	return s, cmd
+}
+
+// save creates the customer and goes on to their order.
+func (s *customerForm) save() tea.Cmd {
+	name, phone := strings.TrimSpace(s.name.Value()), strings.TrimSpace(s.phone.Value())
+	if name == "" || phone == "" {
+		return func() tea.Msg { return errMsg{fmt.Errorf("name and phone are required")} }
+	}
+	return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+		c, err := s.app.store.CreateCustomer(ctx, name, phone)
+		if err != nil {
+			return nil, err
+		}
+		return popMsg{then: newOrderScreen(s.app, *c)}, nil
+	})
+}
+
+func (s *customerForm) View() string {
+	return s.name.View() + "\n" + s.phone.View()
+}
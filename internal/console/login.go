@@ -0,0 +1,167 @@
+package console
+
+import (
+	"context"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type loginScreen struct {
+	app        *app
+	user, pass textinput.Model
+	busy       bool
+}
+
+func newLoginScreen(a *app, user string) *loginScreen {
+	s := &loginScreen{app: a, user: textinput.New(), pass: textinput.New()}
+	s.user.Prompt, s.pass.Prompt = "Login:    ", "Password: "
+	s.user.SetValue(user)
+	s.pass.EchoMode = textinput.EchoPassword
+	if user == "" {
+		s.user.Focus()
+	} else {
+		s.pass.Focus()
+	}
+	return s
+}
+
+func (s *loginScreen) Title() string { return "Service stations console" }
+
+func (s *loginScreen) Help() string { return "enter next · tab switch field" }
+
+func (s *loginScreen) Init() tea.Cmd { return textinput.Blink }
+
+func (s *loginScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case errMsg:
+		s.busy = false
+		return s, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab", "shift+tab", "up", "down":
+			return s, s.switchField()
+		case "enter":
+			if s.user.Focused() {
+				return s, s.switchField()
+			}
+			if s.busy || strings.TrimSpace(s.user.Value()) == "" {
+				return s, nil
+			}
+			s.busy = true
+			return s, s.login()
+		}
+	}
+
+	var cmd tea.Cmd
+	if s.user.Focused() {
+		s.user, cmd = s.user.Update(msg)
+	} else {
+		s.pass, cmd = s.pass.Update(msg)
+	}
+	return s, cmd
+}
+
+func (s *loginScreen) switchField() tea.Cmd {
+	if s.user.Focused() {
+		s.user.Blur()
+		return s.pass.Focus()
+	}
+	s.pass.Blur()
+	return s.user.Focus()
+}
+
+// login connects as the employee's role and finds out who they are.
+func (s *loginScreen) login() tea.Cmd {
+	user, pass := strings.TrimSpace(s.user.Value()), s.pass.Value()
+	return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+		pool, err := s.app.connect(ctx, user, pass)
+		if err != nil {
+			return nil, err
+		}
+		store, err := NewStore(ctx, pool)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		return loginMsg{pool: pool, store: store}, nil
+	})
+}
+
+func (s *loginScreen) View() string {
+	view := s.user.View() + "\n" + s.pass.View()
+	if s.busy {
+		view += "\n\nLogging in..."
+	}
+	return view
+}
+
+type menuItem struct {
+	label string
+	open  func() screen
+}
+
+type menuScreen struct {
+	app    *app
+	items  []menuItem
+	cursor int
+}
+
+func newMenuScreen(a *app) *menuScreen {
+	s := &menuScreen{app: a}
+	if a.store.Me().Role == RoleManager {
+		s.items = []menuItem{
+			{"Find a customer and create an order", func() screen { return newCustomersScreen(a) }},
+			{"Orders of the center", func() screen { return newOrdersScreen(a) }},
+			{"Masters and their load", func() screen { return newMastersScreen(a) }},
+		}
+	} else {
+		s.items = []menuItem{
+			{"My orders", func() screen { return newOrdersScreen(a) }},
+		}
+	}
+	return s
+}
+
+func (s *menuScreen) Title() string { return "Menu" }
+
+func (s *menuScreen) Help() string { return "↑/↓ choose · enter open" }
+
+func (s *menuScreen) Init() tea.Cmd { return nil }
+
+func (s *menuScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return s, nil
+	}
+	switch key.String() {
+	case "up", "k":
+		s.cursor = max(s.cursor-1, 0)
+	case "down", "j":
+		s.cursor = min(s.cursor+1, len(s.items)-1)
+	case "enter":
+		return s, push(s.items[s.cursor].open())
+	default:
+		// Items can be opened by their number too.
+		if k := key.String(); len(k) == 1 && k[0] >= '1' && int(k[0]-'1') < len(s.items) {
+			s.cursor = int(k[0] - '1')
+			return s, push(s.items[s.cursor].open())
+		}
+	}
+	return s, nil
+}
+
+func (s *menuScreen) View() string {
+	var b strings.Builder
+	for i, item := range s.items {
+		line := string(rune('1'+i)) + ". " + item.label
+		if i == s.cursor {
+			line = focusStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
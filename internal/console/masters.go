@@ -0,0 +1,73 @@
+package console
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type mastersMsg []Master
+
+// mastersScreen shows the masters of the center of the manager and their load.
+type mastersScreen struct {
+	app   *app
+	table table.Model
+}
+
+func newMastersScreen(a *app) *mastersScreen {
+	return &mastersScreen{app: a, table: newTable(masterColumns)}
+}
+
+var masterColumns = []table.Column{
+	{Title: "ID", Width: 6},
+	{Title: "Master", Width: 30},
+	{Title: "Experience", Width: 10},
+	{Title: "Active orders", Width: 13},
+	{Title: "In progress", Width: 11},
+	{Title: "Next booked", Width: 12},
+}
+
+func masterRow(m Master) table.Row {
+	next := "-"
+	if m.Next != nil {
+		next = formatDate(*m.Next)
+	}
+	return table.Row{strconv.Itoa(m.ID), m.Name, strconv.Itoa(m.Experience), strconv.Itoa(m.Active), strconv.Itoa(m.InProgress), next}
+}
+
+func (s *mastersScreen) Title() string { return "Masters" }
+
+func (s *mastersScreen) Help() string { return "r refresh" }
+
+func (s *mastersScreen) Init() tea.Cmd {
+	return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+		masters, err := s.app.store.Masters(ctx)
+		return mastersMsg(masters), err
+	})
+}
+
+func (s *mastersScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case mastersMsg:
+		rows := make([]table.Row, len(msg))
+		for i, m := range msg {
+			rows[i] = masterRow(m)
+		}
+		s.table.SetRows(rows)
+		return s, nil
+	case tea.KeyMsg:
+		if msg.String() == "r" {
+			return s, s.Init()
+		}
+	}
+
+	var cmd tea.Cmd
+	s.table, cmd = s.table.Update(msg)
+	return s, cmd
+}
+
+func (s *mastersScreen) View() string {
+	return s.table.View()
+}
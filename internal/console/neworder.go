@@ -0,0 +1,356 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"vehicles-service-stations/internal/pricing"
+)
+
+// checklist picks items with a quantity each, up to max.
+type checklist struct {
+	labels []string
+	max    []int
+	qty    []int
+	cursor int
+}
+
+func (c *checklist) add(label string, max int) {
+	c.labels = append(c.labels, label)
+	c.max = append(c.max, max)
+	c.qty = append(c.qty, 0)
+}
+
+func (c *checklist) update(key string) {
+	if len(c.labels) == 0 {
+		return
+	}
+	switch key {
+	case "up", "k":
+		c.cursor = max(c.cursor-1, 0)
+	case "down", "j":
+		c.cursor = min(c.cursor+1, len(c.labels)-1)
+	case " ", "x":
+		if c.qty[c.cursor] > 0 {
+			c.qty[c.cursor] = 0
+		} else {
+			c.qty[c.cursor] = 1
+		}
+	case "+", "=", "right":
+		c.qty[c.cursor] = min(c.qty[c.cursor]+1, c.max[c.cursor])
+	case "-", "left":
+		c.qty[c.cursor] = max(c.qty[c.cursor]-1, 0)
+	}
+}
+
+func (c *checklist) view(quantities bool) string {
+	if len(c.labels) == 0 {
+		return "Nothing to choose from."
+	}
+	// Only a window around the cursor fits the screen.
+	const height = 15
+	from := max(0, min(c.cursor-height/2, len(c.labels)-height))
+	var b strings.Builder
+	for i := from; i < min(from+height, len(c.labels)); i++ {
+		mark := "[ ]"
+		if c.qty[i] > 0 {
+			mark = "[x]"
+			if quantities {
+				mark = fmt.Sprintf("[%d]", c.qty[i])
+			}
+		}
+		line := mark + " " + c.labels[i]
+		if i == c.cursor {
+			line = focusStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+type orderDataMsg struct {
+	masters  []Master
+	services []Service
+	parts    []Part
+}
+
+type orderCreatedMsg int
+
+const (
+	stepMaster = iota
+	stepDate
+	stepServices
+	stepParts
+	stepCode
+	stepConfirm
+)
+
+// newOrder walks a manager through a new order: the master, the day,
+// services, spare parts and a promo code.
+type newOrder struct {
+	app      *app
+	customer Customer
+	step     int
+	busy     bool
+
+	masters  []Master
+	services []Service
+	parts    []Part
+
+	masterTable table.Model
+	date        textinput.Model
+	code        textinput.Model
+	serviceList checklist
+	partList    checklist
+}
+
+func newOrderScreen(a *app, c Customer) *newOrder {
+	s := &newOrder{app: a, customer: c, date: textinput.New(), code: textinput.New(), busy: true}
+	s.masterTable = newTable(masterColumns)
+	s.date.Prompt = "Day (YYYY-MM-DD): "
+	s.date.SetValue(formatDate(time.Now().AddDate(0, 0, 1)))
+	s.date.CharLimit = 10
+	s.code.Prompt = "Promo code: "
+	s.code.CharLimit = 32
+	return s
+}
+
+func (s *newOrder) Title() string {
+	return fmt.Sprintf("New order for %s, %s", s.customer.Name, s.customer.Phone)
+}
+
+func (s *newOrder) Help() string {
+	switch s.step {
+	case stepMaster:
+		return "enter choose master"
+	case stepDate:
+		return "enter next"
+	case stepServices:
+		return "space toggle · enter next"
+	case stepParts:
+		return "space toggle · +/- quantity · enter next"
+	case stepCode:
+		return "enter next, leave empty without a code"
+	default:
+		return "enter create order"
+	}
+}
+
+func (s *newOrder) Init() tea.Cmd {
+	return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+		var msg orderDataMsg
+		var err error
+		if msg.masters, err = s.app.store.Masters(ctx); err != nil {
+			return nil, err
+		}
+		if msg.services, err = s.app.store.Services(ctx); err != nil {
+			return nil, err
+		}
+		if msg.parts, err = s.app.store.Parts(ctx); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	})
+}
+
+func (s *newOrder) back() bool {
+	// The screen closes itself once the order is created.
+	if s.busy {
+		return true
+	}
+	if s.step == stepMaster {
+		return false
+	}
+	s.step--
+	s.focus()
+	return true
+}
+
+func (s *newOrder) focus() {
+	s.date.Blur()
+	s.code.Blur()
+	switch s.step {
+	case stepDate:
+		s.date.Focus()
+	case stepCode:
+		s.code.Focus()
+	}
+}
+
+func (s *newOrder) Update(msg tea.Msg) (screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case errMsg:
+		s.busy = false
+		switch {
+		case errors.Is(msg.err, ErrMasterBusy):
+			s.step = stepDate
+			s.focus()
+		case errors.Is(msg.err, pricing.ErrUnknownCode), errors.Is(msg.err, pricing.ErrCodeExpired),
+			errors.Is(msg.err, pricing.ErrCodeUsedUp):
+			s.step = stepCode
+			s.focus()
+		}
+		return s, nil
+	case orderDataMsg:
+		s.busy = false
+		s.masters, s.services, s.parts = msg.masters, msg.services, msg.parts
+		rows := make([]table.Row, len(msg.masters))
+		for i, m := range msg.masters {
+			rows[i] = masterRow(m)
+		}
+		s.masterTable.SetRows(rows)
+		s.serviceList, s.partList = checklist{}, checklist{}
+		for _, svc := range msg.services {
+			s.serviceList.add(fmt.Sprintf("%-4s %s — %s", svc.VehicleType, svc.Name, money(svc.Price)), 1)
+		}
+		for _, p := range msg.parts {
+			s.partList.add(fmt.Sprintf("%s (art. %d) — %s, %d in stock", p.Name, p.Article, money(p.Price), p.Stock), p.Stock)
+		}
+		return s, nil
+	case orderCreatedMsg:
+		return s, tea.Batch(
+			func() tea.Msg { return popMsg{then: newOrderDetail(s.app, int(msg))} },
+			info("Order %d created", int(msg)),
+		)
+	case tea.KeyMsg:
+		if s.busy {
+			return s, nil
+		}
+		if msg.String() == "enter" {
+			return s, s.next()
+		}
+		switch s.step {
+		case stepServices:
+			s.serviceList.update(msg.String())
+			return s, nil
+		case stepParts:
+			s.partList.update(msg.String())
+			return s, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch s.step {
+	case stepMaster:
+		s.masterTable, cmd = s.masterTable.Update(msg)
+	case stepDate:
+		s.date, cmd = s.date.Update(msg)
+	case stepCode:
+		s.code, cmd = s.code.Update(msg)
+	}
+	return s, cmd
+}
+
+func fail(format string, args ...any) tea.Cmd {
+	err := fmt.Errorf(format, args...)
+	return func() tea.Msg { return errMsg{err} }
+}
+
+func (s *newOrder) next() tea.Cmd {
+	switch s.step {
+	case stepMaster:
+		if len(s.masters) == 0 {
+			return fail("the center has no masters")
+		}
+	case stepDate:
+		day, err := s.day()
+		if err != nil {
+			return fail("invalid day: %v", err)
+		}
+		now := time.Now()
+		if day.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+			return fail("the day has passed")
+		}
+	case stepParts:
+		if len(s.draft().Services)+len(s.draft().Parts) == 0 {
+			return fail("choose a service or a spare part")
+		}
+	case stepConfirm:
+		s.busy = true
+		draft := s.draft()
+		return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+			id, err := s.app.store.CreateOrder(ctx, draft)
+			return orderCreatedMsg(id), err
+		})
+	}
+	s.step++
+	s.focus()
+	return nil
+}
+
+func (s *newOrder) day() (time.Time, error) {
+	return time.Parse("2006-01-02", strings.TrimSpace(s.date.Value()))
+}
+
+func (s *newOrder) draft() OrderDraft {
+	d := OrderDraft{CustomerID: s.customer.ID}
+	if n := s.masterTable.Cursor(); n >= 0 && n < len(s.masters) {
+		d.MasterID = s.masters[n].ID
+	}
+	d.Date, _ = s.day()
+	d.PromoCode = strings.TrimSpace(s.code.Value())
+	for i, svc := range s.services {
+		if s.serviceList.qty[i] > 0 {
+			d.Services = append(d.Services, svc.ID)
+		}
+	}
+	for i, p := range s.parts {
+		if q := s.partList.qty[i]; q > 0 {
+			d.Parts = append(d.Parts, PartQuantity{PartID: p.ID, Quantity: q})
+		}
+	}
+	return d
+}
+
+func (s *newOrder) View() string {
+	if s.masters == nil && s.busy {
+		return "Loading..."
+	}
+	switch s.step {
+	case stepMaster:
+		return "Choose the master:\n\n" + s.masterTable.View()
+	case stepDate:
+		return s.date.View() + "\n\nA master takes one active order a day."
+	case stepServices:
+		return "Services:\n\n" + s.serviceList.view(false)
+	case stepParts:
+		return "Spare parts:\n\n" + s.partList.view(true)
+	case stepCode:
+		return s.code.View() + "\n\nTier discounts and promotions apply without a code."
+	}
+
+	var b strings.Builder
+	draft := s.draft()
+	master := s.masters[s.masterTable.Cursor()]
+	fmt.Fprintf(&b, "Customer: %s, %s\nMaster:   %s\nDay:      %s\n\n", s.customer.Name, s.customer.Phone, master.Name, formatDate(draft.Date))
+	var total float64
+	for i, svc := range s.services {
+		if s.serviceList.qty[i] > 0 {
+			fmt.Fprintf(&b, "  %s — %s\n", svc.Name, money(svc.Price))
+			total += svc.Price
+		}
+	}
+	for i, p := range s.parts {
+		if q := s.partList.qty[i]; q > 0 {
+			fmt.Fprintf(&b, "  %s × %d — %s\n", p.Name, q, money(p.Price*float64(q)))
+			total += p.Price * float64(q)
+		}
+	}
+	if draft.PromoCode != "" {
+		fmt.Fprintf(&b, "\nPromo code: %s", strings.ToUpper(draft.PromoCode))
+	}
+	fmt.Fprintf(&b, "\nSubtotal: %s, discounts and VAT are priced on creation", money(total))
+	if s.busy {
+		b.WriteString("\n\nCreating the order...")
+	}
+	return b.String()
+}
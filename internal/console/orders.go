@@ -0,0 +1,288 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type ordersMsg []Order
+
+var statusKeys = map[string]string{
+	"a": "",
+	"p": "Pending",
+	"i": "In Progress",
+	"c": "Completed",
+	"x": "Cancelled",
+}
+
+// ordersScreen lists the orders row level security lets the employee see.
+type ordersScreen struct {
+	app    *app
+	status string
+	table  table.Model
+	orders []Order
+}
+
+func newOrdersScreen(a *app) *ordersScreen {
+	return &ordersScreen{app: a, table: newTable([]table.Column{
+		{Title: "ID", Width: 6},
+		{Title: "Day", Width: 10},
+		{Title: "Status", Width: 11},
+		{Title: "Customer", Width: 24},
+		{Title: "Phone", Width: 16},
+		{Title: "Master", Width: 22},
+		{Title: "Total", Width: 10},
+		{Title: "Receipt", Width: 7},
+	})}
+}
+
+func (s *ordersScreen) Title() string {
+	title := "Orders"
+	if s.app.store.Me().Role == RoleMaster {
+		title = "My orders"
+	}
+	if s.status != "" {
+		title += ": " + s.status
+	}
+	return title
+}
+
+func (s *ordersScreen) Help() string {
+	return "enter details · a all · p pending · i in progress · c completed · x cancelled · r refresh"
+}
+
+func (s *ordersScreen) Init() tea.Cmd {
+	status := s.status
+	return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+		orders, err := s.app.store.Orders(ctx, status)
+		return ordersMsg(orders), err
+	})
+}
+
+func (s *ordersScreen) resume() tea.Cmd {
+	return s.Init()
+}
+
+func (s *ordersScreen) Update(msg tea.Msg) (screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ordersMsg:
+		s.orders = msg
+		masters := s.app.store.Me().Role == RoleMaster
+		rows := make([]table.Row, len(msg))
+		for i, o := range msg {
+			receipt := ""
+			switch {
+			case masters:
+			case o.Receipt:
+				receipt = "yes"
+			case o.Status == "Completed":
+				receipt = "due"
+			}
+			rows[i] = table.Row{strconv.Itoa(o.ID), formatDate(o.ScheduledDate), o.Status, o.Customer, o.Phone, o.Master, money(o.TotalCost), receipt}
+		}
+		s.table.SetRows(rows)
+		s.table.SetCursor(min(s.table.Cursor(), max(len(rows)-1, 0)))
+		return s, nil
+	case tea.KeyMsg:
+		key := msg.String()
+		if status, ok := statusKeys[key]; ok {
+			s.status = status
+			return s, s.Init()
+		}
+		switch key {
+		case "r":
+			return s, s.Init()
+		case "enter":
+			if n := s.table.Cursor(); n >= 0 && n < len(s.orders) {
+				return s, push(newOrderDetail(s.app, s.orders[n].ID))
+			}
+			return s, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	s.table, cmd = s.table.Update(msg)
+	return s, cmd
+}
+
+func (s *ordersScreen) View() string {
+	if len(s.orders) == 0 {
+		return "No orders."
+	}
+	return s.table.View()
+}
+
+type (
+	orderMsg     *OrderDetail
+	completedMsg struct{}
+	receiptMsg   *Receipt
+)
+
+// orderDetail shows an order. Managers complete it and issue its receipt,
+// spending the bonus points the customer chooses to.
+type orderDetail struct {
+	app   *app
+	id    int
+	order *OrderDetail
+	bonus textinput.Model
+	// receipt is set while the bonus points of the receipt are asked for.
+	receipt bool
+}
+
+func newOrderDetail(a *app, id int) *orderDetail {
+	s := &orderDetail{app: a, id: id, bonus: textinput.New()}
+	s.bonus.Prompt = "Bonus points to spend: "
+	s.bonus.CharLimit = 12
+	return s
+}
+
+func (s *orderDetail) Title() string { return fmt.Sprintf("Order %d", s.id) }
+
+func (s *orderDetail) manager() bool {
+	return s.app.store.Me().Role == RoleManager
+}
+
+func (s *orderDetail) Help() string {
+	switch {
+	case s.receipt:
+		return "enter issue receipt"
+	case s.manager():
+		return "c complete · p issue receipt · r refresh"
+	default:
+		return "r refresh"
+	}
+}
+
+func (s *orderDetail) Init() tea.Cmd {
+	return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+		order, err := s.app.store.Order(ctx, s.id)
+		return orderMsg(order), err
+	})
+}
+
+func (s *orderDetail) back() bool {
+	if s.receipt {
+		s.receipt = false
+		s.bonus.Blur()
+		return true
+	}
+	return false
+}
+
+func (s *orderDetail) Update(msg tea.Msg) (screen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case orderMsg:
+		s.order = msg
+		return s, nil
+	case completedMsg:
+		return s, tea.Batch(s.Init(), info("Order %d completed", s.id))
+	case receiptMsg:
+		s.receipt = false
+		s.bonus.Blur()
+		return s, tea.Batch(s.Init(), info("Receipt %d issued: paid %s, %s bonus points spent", msg.ID, money(msg.TotalPaid), money(msg.BonusPoints)))
+	case tea.KeyMsg:
+		if s.receipt {
+			if msg.String() == "enter" {
+				return s, s.issue()
+			}
+			var cmd tea.Cmd
+			s.bonus, cmd = s.bonus.Update(msg)
+			return s, cmd
+		}
+		switch msg.String() {
+		case "r":
+			return s, s.Init()
+		case "c":
+			if !s.manager() || s.order == nil {
+				return s, nil
+			}
+			return s, s.app.do(func(ctx context.Context) (tea.Msg, error) {
+				return completedMsg{}, s.app.store.Complete(ctx, s.id)
+			})
+		case "p":
+			if !s.manager() || s.order == nil {
+				return s, nil
+			}
+			s.receipt = true
+			s.bonus.SetValue("0")
+			return s, s.bonus.Focus()
+		}
+	}
+	return s, nil
+}
+
+func (s *orderDetail) issue() tea.Cmd {
+	bonus, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(s.bonus.Value()), ",", "."), 64)
+	if err != nil {
+		return fail("invalid bonus points: %v", err)
+	}
+	return s.app.do(func(ctx context.Context) (tea.Msg, error) {
+		r, err := s.app.store.IssueReceipt(ctx, s.id, bonus)
+		return receiptMsg(r), err
+	})
+}
+
+func (s *orderDetail) View() string {
+	o := s.order
+	if o == nil {
+		return "Loading..."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Day:      %s\nStatus:   %s\nCustomer: %s, %s\nMaster:   %s\n", formatDate(o.ScheduledDate), o.Status, o.Customer, o.Phone, o.Master)
+	if s.manager() {
+		fmt.Fprintf(&b, "Bonus:    %s points\n", money(o.BonusPoints))
+	}
+	b.WriteString("\nServices:\n")
+	if len(o.Services) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, l := range o.Services {
+		fmt.Fprintf(&b, "  %s — %s\n", l.Name, money(l.Price))
+	}
+	b.WriteString("Spare parts:\n")
+	if len(o.Parts) == 0 {
+		b.WriteString("  none\n")
+	}
+	for _, l := range o.Parts {
+		fmt.Fprintf(&b, "  %s × %d — %s\n", l.Name, l.Quantity, money(l.Price*float64(l.Quantity)))
+	}
+	if q := o.Quote; q != nil {
+		b.WriteString("\n")
+		for _, l := range q.Lines {
+			if l.Discount != nil {
+				fmt.Fprintf(&b, "  %s: -%s (%s%%, %s)\n", l.Name, l.Discount.Amount, l.Discount.Percent, l.Discount.Reason)
+			}
+		}
+		if q.PromoCode != nil {
+			fmt.Fprintf(&b, "  %s: -%s\n", q.PromoCode.Reason, q.PromoCode.Amount)
+		}
+		fmt.Fprintf(&b, "Subtotal: %s · discount %s", q.Subtotal, q.Discount)
+		if q.Rounding != 0 {
+			fmt.Fprintf(&b, " · rounding %s", q.Rounding)
+		}
+		if q.PricesIncludeVAT {
+			fmt.Fprintf(&b, " · VAT %s%% included %s\n", q.VATRate, q.VAT)
+		} else {
+			fmt.Fprintf(&b, " · VAT %s%% %s\n", q.VATRate, q.VAT)
+		}
+	}
+	fmt.Fprintf(&b, "\nTotal: %s", money(o.TotalCost))
+	if s.manager() {
+		if o.Receipt {
+			b.WriteString(" · receipt issued")
+		} else if o.Status == "Completed" {
+			b.WriteString(" · receipt due")
+		}
+	}
+	if s.receipt {
+		b.WriteString("\n\n" + s.bonus.View())
+	}
+	return b.String()
+}
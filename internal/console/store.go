@@ -0,0 +1,459 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vehicles-service-stations/internal/pricing"
+)
+
+// DB is satisfied by pools and transactions.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+const (
+	RoleManager = "Manager"
+	RoleMaster  = "Master"
+)
+
+var (
+	// ErrNotEmployee is returned for roles without a row in employees, or
+	// with a role the console is not for.
+	ErrNotEmployee = errors.New("the console is for managers and masters")
+	// ErrMasterBusy is returned when the master has an active order that day.
+	ErrMasterBusy = errors.New("master is busy that day")
+	// ErrNotAllowed is returned for orders the employee may not change, or
+	// not in that state.
+	ErrNotAllowed = errors.New("order cannot be changed")
+	// ErrReceiptIssued is returned for orders that already have a receipt.
+	ErrReceiptIssued = errors.New("receipt is already issued")
+	// ErrNotFound is returned for orders that do not exist or the employee
+	// does not see.
+	ErrNotFound = errors.New("order not found")
+	// ErrOutOfStock is returned for spare parts with less in stock than
+	// ordered.
+	ErrOutOfStock = errors.New("not enough spare parts in stock")
+	// ErrBonusPoints is returned for receipts spending more bonus points
+	// than the customer has or the order costs.
+	ErrBonusPoints = errors.New("invalid bonus points")
+)
+
+// Employee is the logged in employee, as found by current_user.
+type Employee struct {
+	ID       int
+	Name     string
+	Role     string
+	CenterID int
+	Center   string
+}
+
+type Customer struct {
+	ID            int     `json:"id"`
+	Name          string  `json:"name"`
+	Phone         string  `json:"phone"`
+	BonusPoints   float64 `json:"bonus_points"`
+	LoyaltyStatus string  `json:"loyalty_status"`
+}
+
+// Master is a master of the center with their load: active orders and the
+// nearest day they are booked.
+type Master struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Experience int        `json:"experience"`
+	Active     int        `json:"active"`
+	InProgress int        `json:"in_progress"`
+	Next       *time.Time `json:"next,omitempty"`
+}
+
+type Service struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	VehicleType string  `json:"vehicle_type"`
+	Price       float64 `json:"price"`
+}
+
+type Part struct {
+	ID      int     `json:"id"`
+	Name    string  `json:"name"`
+	Article int     `json:"article"`
+	Price   float64 `json:"price"`
+	Stock   int     `json:"stock"`
+}
+
+type PartQuantity struct {
+	PartID   int `json:"part_id"`
+	Quantity int `json:"quantity"`
+}
+
+// OrderDraft is an order a manager is about to create at their center.
+type OrderDraft struct {
+	CustomerID int
+	MasterID   int
+	Date       time.Time
+	Services   []int
+	Parts      []PartQuantity
+	// PromoCode is optional.
+	PromoCode string
+}
+
+type Order struct {
+	ID            int       `json:"id"`
+	ScheduledDate time.Time `json:"scheduled_date"`
+	Status        string    `json:"status"`
+	Customer      string    `json:"customer"`
+	Phone         string    `json:"phone"`
+	Master        string    `json:"master"`
+	TotalCost     float64   `json:"total_cost"`
+	// Receipt is unknown to masters, they cannot read receipts.
+	Receipt bool `json:"receipt"`
+}
+
+type OrderLine struct {
+	Name     string  `json:"name"`
+	Quantity int     `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+type OrderDetail struct {
+	Order
+	CustomerID  int         `json:"customer_id"`
+	BonusPoints float64     `json:"bonus_points"`
+	Services    []OrderLine `json:"services"`
+	Parts       []OrderLine `json:"parts"`
+	// Quote is nil for orders changed since they were priced.
+	Quote *pricing.Quote `json:"quote,omitempty"`
+}
+
+type Receipt struct {
+	ID          int       `json:"id"`
+	BonusPoints float64   `json:"bonus_points"`
+	TotalPaid   float64   `json:"total_paid"`
+	Date        time.Time `json:"date"`
+}
+
+// Store runs the console queries as the logged in employee, row level
+// security decides what they see.
+type Store struct {
+	db DB
+	me Employee
+}
+
+// NewStore looks up the employee of current_user.
+func NewStore(ctx context.Context, db DB) (*Store, error) {
+	s := &Store{db: db}
+	err := db.QueryRow(ctx, `
+        SELECT e.employee_id, e.full_name, esc.employee_role::text, sc.service_center_id, sc.city || ', ' || sc.full_address
+        FROM employees e
+        JOIN employee_service_center esc ON esc.employee_id = e.employee_id
+        JOIN service_centers sc ON sc.service_center_id = esc.service_center_id
+        WHERE e.username = current_user AND esc.employee_role IN ('Manager', 'Master')
+        ORDER BY esc.service_center_id
+        LIMIT 1
+    `).Scan(&s.me.ID, &s.me.Name, &s.me.Role, &s.me.CenterID, &s.me.Center)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotEmployee
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) Me() Employee {
+	return s.me
+}
+
+// SearchCustomers finds customers whose phone number contains the digits.
+func (s *Store) SearchCustomers(ctx context.Context, phone string) ([]Customer, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	rows, err := s.db.Query(ctx, `
+        SELECT customer_id, full_name, phone_number, bonus_points::float8, loyalty_status::text
+        FROM customers
+        WHERE regexp_replace(phone_number, '\D', '', 'g') LIKE '%' || $1 || '%'
+        ORDER BY phone_number, customer_id
+        LIMIT 50
+    `, digits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search customers: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Customer])
+}
+
+func (s *Store) CreateCustomer(ctx context.Context, name, phone string) (*Customer, error) {
+	c := &Customer{}
+	err := s.db.QueryRow(ctx, `
+        INSERT INTO customers (full_name, phone_number)
+        VALUES ($1, $2)
+        RETURNING customer_id, full_name, phone_number, bonus_points::float8, loyalty_status::text
+    `, name, phone).Scan(&c.ID, &c.Name, &c.Phone, &c.BonusPoints, &c.LoyaltyStatus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create customer: %w", err)
+	}
+	return c, nil
+}
+
+// Masters lists the masters of the center of the manager. The master of an
+// order is the reassigned one when there is one.
+func (s *Store) Masters(ctx context.Context) ([]Master, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT e.employee_id, e.full_name, e.experience,
+               count(o.order_id) FILTER (WHERE o.status IN ('Pending', 'In Progress'))::int,
+               count(o.order_id) FILTER (WHERE o.status = 'In Progress')::int,
+               min(o.scheduled_date) FILTER (WHERE o.status IN ('Pending', 'In Progress') AND o.scheduled_date >= current_date)
+        FROM employee_service_center esc
+        JOIN employees e ON e.employee_id = esc.employee_id
+        LEFT JOIN orders o ON COALESCE(o.reassigned_master_id, o.assigned_master_id) = e.employee_id
+                          AND o.service_center_id = esc.service_center_id
+        WHERE esc.service_center_id = $1 AND esc.employee_role = 'Master'
+        GROUP BY e.employee_id, e.full_name, e.experience
+        ORDER BY e.full_name, e.employee_id
+    `, s.me.CenterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list masters: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Master])
+}
+
+func (s *Store) Services(ctx context.Context) ([]Service, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT service_id, full_name, vehicle_type::text, price::float8
+        FROM services
+        ORDER BY vehicle_type, full_name, service_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Service])
+}
+
+// Parts lists the spare parts in stock.
+func (s *Store) Parts(ctx context.Context) ([]Part, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT part_id, name, article_number, price::float8, stock_quantity
+        FROM spare_parts
+        WHERE stock_quantity > 0
+        ORDER BY name, part_id
+    `)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list spare parts: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Part])
+}
+
+// CreateOrder creates a Pending order managed by the employee. Spare parts are
+// bought at their current price and taken off the stock, the order is priced
+// with the discounts of the customer and the promo code.
+func (s *Store) CreateOrder(ctx context.Context, d OrderDraft) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID int
+	err = tx.QueryRow(ctx, `
+        INSERT INTO orders (customer_id, service_center_id, manager_id, assigned_master_id, scheduled_date, status)
+        VALUES ($1, $2, $3, $4, $5, 'Pending')
+        RETURNING order_id
+    `, d.CustomerID, s.me.CenterID, s.me.ID, d.MasterID, d.Date).Scan(&orderID)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.RaiseException {
+		// Raised by before_order_insert.
+		return 0, ErrMasterBusy
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	for _, id := range d.Services {
+		if _, err := tx.Exec(ctx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, id, orderID); err != nil {
+			return 0, fmt.Errorf("failed to add service %d: %w", id, err)
+		}
+	}
+	for _, p := range d.Parts {
+		_, err := tx.Exec(ctx, `
+            INSERT INTO spare_part_order (part_id, order_id, quantity, purchase_price)
+            SELECT part_id, $2, $3, price FROM spare_parts WHERE part_id = $1
+        `, p.PartID, orderID, p.Quantity)
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.RaiseException {
+			// Raised by check_spare_part_stock, the message tells the stock.
+			return 0, fmt.Errorf("%w: %s", ErrOutOfStock, pgErr.Message)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to add spare part %d: %w", p.PartID, err)
+		}
+	}
+	if _, err := pricing.QuoteOrder(ctx, tx, orderID, d.PromoCode); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return orderID, nil
+}
+
+const ordersQuery = `
+    SELECT o.order_id, o.scheduled_date, COALESCE(o.status::text, ''), c.full_name, c.phone_number,
+           COALESCE(m.full_name, ''), COALESCE(o.total_cost, 0)::float8, %s
+    FROM orders o
+    JOIN customers c ON c.customer_id = o.customer_id
+    LEFT JOIN employees m ON m.employee_id = COALESCE(o.reassigned_master_id, o.assigned_master_id)
+`
+
+func (s *Store) ordersQuery() string {
+	if s.me.Role == RoleMaster {
+		return fmt.Sprintf(ordersQuery, "false")
+	}
+	return fmt.Sprintf(ordersQuery, "EXISTS (SELECT 1 FROM receipts r WHERE r.order_id = o.order_id)")
+}
+
+// Orders lists the orders the employee sees: the orders of the center for
+// managers, the assigned and reassigned ones for masters. An empty status
+// lists all of them.
+func (s *Store) Orders(ctx context.Context, status string) ([]Order, error) {
+	rows, err := s.db.Query(ctx, s.ordersQuery()+`
+        WHERE $1 = '' OR o.status::text = $1
+        ORDER BY o.scheduled_date DESC, o.order_id DESC
+        LIMIT 200
+    `, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByPos[Order])
+}
+
+func (s *Store) Order(ctx context.Context, id int) (*OrderDetail, error) {
+	d := &OrderDetail{}
+	err := s.db.QueryRow(ctx, s.ordersQuery()+`WHERE o.order_id = $1`, id).Scan(
+		&d.ID, &d.ScheduledDate, &d.Status, &d.Customer, &d.Phone, &d.Master, &d.TotalCost, &d.Receipt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("order %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order: %w", err)
+	}
+	err = s.db.QueryRow(ctx, `
+        SELECT c.customer_id, c.bonus_points::float8
+        FROM orders o
+        JOIN customers c ON c.customer_id = o.customer_id
+        WHERE o.order_id = $1
+    `, id).Scan(&d.CustomerID, &d.BonusPoints)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load customer: %w", err)
+	}
+
+	lines := func(sql string) ([]OrderLine, error) {
+		rows, err := s.db.Query(ctx, sql, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load order lines: %w", err)
+		}
+		return pgx.CollectRows(rows, pgx.RowToStructByPos[OrderLine])
+	}
+	if d.Services, err = lines(`
+        SELECT s.full_name, 1, s.price::float8
+        FROM service_order so
+        JOIN services s ON s.service_id = so.service_id
+        WHERE so.order_id = $1
+        ORDER BY s.full_name
+    `); err != nil {
+		return nil, err
+	}
+	if d.Parts, err = lines(`
+        SELECT p.name, spo.quantity, spo.purchase_price::float8
+        FROM spare_part_order spo
+        JOIN spare_parts p ON p.part_id = spo.part_id
+        WHERE spo.order_id = $1
+        ORDER BY p.name
+    `); err != nil {
+		return nil, err
+	}
+	if d.Quote, err = pricing.OrderQuote(ctx, s.db, id); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Complete marks an active order completed. Managers may complete the orders
+// they manage.
+func (s *Store) Complete(ctx context.Context, id int) error {
+	tag, err := s.db.Exec(ctx, `
+        UPDATE orders SET status = 'Completed'
+        WHERE order_id = $1 AND status IN ('Pending', 'In Progress')
+    `, id)
+	if err != nil {
+		return fmt.Errorf("failed to complete order: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+// IssueReceipt issues the receipt of a completed order, the customer pays the
+// total cost less the bonus points spent. Triggers add the payment to the
+// spent money of the customer and take the points off.
+func (s *Store) IssueReceipt(ctx context.Context, orderID int, bonus float64) (*Receipt, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var total, available float64
+	err = tx.QueryRow(ctx, `
+        SELECT COALESCE(o.status::text, ''), COALESCE(o.total_cost, 0)::float8, c.bonus_points::float8
+        FROM orders o
+        JOIN customers c ON c.customer_id = o.customer_id
+        WHERE o.order_id = $1
+        FOR UPDATE
+    `, orderID).Scan(&status, &total, &available)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotAllowed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order: %w", err)
+	}
+	if status != "Completed" {
+		return nil, fmt.Errorf("order is %s, receipts are issued for completed orders: %w", status, ErrNotAllowed)
+	}
+	if bonus < 0 || bonus > available || bonus > total {
+		return nil, fmt.Errorf("%w: at most %.2f can be spent", ErrBonusPoints, min(available, total))
+	}
+
+	r := &Receipt{BonusPoints: bonus}
+	err = tx.QueryRow(ctx, `
+        INSERT INTO receipts (order_id, bonus_points_spent, total_paid)
+        VALUES ($1, $2, $3)
+        RETURNING receipt_id, total_paid::float8, receipt_date
+    `, orderID, bonus, total-bonus).Scan(&r.ID, &r.TotalPaid, &r.Date)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return nil, ErrReceiptIssued
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue receipt: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,111 @@
+package console_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// A manager builds an order with a service and spare parts under their own
+// role, completes it and issues the receipt; the master of the order sees it
+// and not the orders of the other master.
+func TestManagerAndMaster(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "console")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	serviceID := testutil.NewService(t, tx, 1000)
+	partID := testutil.NewPart(t, tx, 5)
+	otherOrder := b.Order(t, tx, b.Customer, b.Masters[1], testutil.Day)
+
+	testutil.LoginAs(t, tx, b.Manager, "manager")
+	manager, err := console.NewStore(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me := manager.Me(); me.Role != console.RoleManager || me.CenterID != b.Center {
+		t.Fatalf("manager logged in as %+v", me)
+	}
+	customers, err := manager.SearchCustomers(ctx, "111 111-11-11")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(customers) != 1 || customers[0].ID != b.Customer {
+		t.Errorf("search found %+v, want customer %d", customers, b.Customer)
+	}
+	masters, err := manager.Masters(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(masters) != 2 || masters[0].Active+masters[1].Active != 1 {
+		t.Errorf("masters %+v, want 2 with one active order", masters)
+	}
+
+	orderID, err := manager.CreateOrder(ctx, console.OrderDraft{
+		CustomerID: b.Customer,
+		MasterID:   b.Masters[0],
+		Date:       testutil.Day,
+		Services:   []int{serviceID},
+		Parts:      []console.PartQuantity{{PartID: partID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := manager.Order(ctx, orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.TotalCost != 1200 || len(order.Services) != 1 || len(order.Parts) != 1 {
+		t.Errorf("order %+v, want a service and parts for 1200", order)
+	}
+	if _, err := manager.IssueReceipt(ctx, orderID, 0); !errors.Is(err, console.ErrNotAllowed) {
+		t.Errorf("receipt of a pending order: %v, want %v", err, console.ErrNotAllowed)
+	}
+	if err := manager.Complete(ctx, orderID); err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := manager.IssueReceipt(ctx, orderID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TotalPaid != 1200 {
+		t.Errorf("receipt paid %.2f, want 1200", receipt.TotalPaid)
+	}
+	if _, err := manager.IssueReceipt(ctx, orderID, 0); !errors.Is(err, console.ErrReceiptIssued) {
+		t.Errorf("second receipt: %v, want %v", err, console.ErrReceiptIssued)
+	}
+
+	testutil.LoginAs(t, tx, b.Masters[0], "master")
+	master, err := console.NewStore(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orders, err := master.Orders(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 1 || orders[0].ID != orderID {
+		t.Errorf("master sees %+v, want only order %d and not %d", orders, orderID, otherOrder)
+	}
+	if _, err := tx.Exec(ctx, `RESET SESSION AUTHORIZATION`); err != nil {
+		t.Fatal(err)
+	}
+
+	var stock int
+	var spent float64
+	err = tx.QueryRow(ctx, `
+        SELECT p.stock_quantity, c.spent_money::float8
+        FROM spare_parts p, customers c
+        WHERE p.part_id = $1 AND c.customer_id = $2
+    `, partID, b.Customer).Scan(&stock, &spent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stock != 3 || math.Abs(spent-1200) > 0.001 {
+		t.Errorf("stock %d and spent money %.2f, want 3 and 1200", stock, spent)
+	}
+}
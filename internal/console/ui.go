@@ -0,0 +1,211 @@
+package console
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ConnectFunc logs in to the database as the employee's own role.
+type ConnectFunc func(ctx context.Context, user, password string) (*pgxpool.Pool, error)
+
+var (
+	titleStyle = lipgloss.NewStyle().Bold(true).MarginBottom(1)
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	infoStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	helpStyle  = lipgloss.NewStyle().Faint(true)
+	focusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
+)
+
+// screen is one page of the console. Screens are stacked, esc returns to the
+// previous one.
+type screen interface {
+	Init() tea.Cmd
+	Update(msg tea.Msg) (screen, tea.Cmd)
+	View() string
+	Title() string
+	Help() string
+}
+
+// backer is a screen with steps of its own, back reports whether it went a
+// step back instead of leaving.
+type backer interface {
+	back() bool
+}
+
+// resumer is a screen that reloads when it is shown again.
+type resumer interface {
+	resume() tea.Cmd
+}
+
+type (
+	errMsg   struct{ err error }
+	infoMsg  string
+	pushMsg  struct{ s screen }
+	popMsg   struct{ then screen }
+	loginMsg struct {
+		pool  *pgxpool.Pool
+		store *Store
+	}
+)
+
+func push(s screen) tea.Cmd {
+	return func() tea.Msg { return pushMsg{s} }
+}
+
+func info(format string, args ...any) tea.Cmd {
+	return func() tea.Msg { return infoMsg(fmt.Sprintf(format, args...)) }
+}
+
+// app is shared by the screens.
+type app struct {
+	ctx     context.Context
+	connect ConnectFunc
+	pool    *pgxpool.Pool
+	store   *Store
+}
+
+// do runs a query off the UI loop, failures are shown under the screen.
+func (a *app) do(f func(ctx context.Context) (tea.Msg, error)) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 15*time.Second)
+		defer cancel()
+		msg, err := f(ctx)
+		if err != nil {
+			return errMsg{err}
+		}
+		return msg
+	}
+}
+
+// Model is the console, starting at the login screen.
+type Model struct {
+	app   *app
+	stack []screen
+	err   error
+	info  string
+}
+
+func New(ctx context.Context, connect ConnectFunc, user string) *Model {
+	a := &app{ctx: ctx, connect: connect}
+	return &Model{app: a, stack: []screen{newLoginScreen(a, user)}}
+}
+
+// Close closes the connections of the logged in employee.
+func (m *Model) Close() {
+	if m.app.pool != nil {
+		m.app.pool.Close()
+	}
+}
+
+func (m *Model) top() screen {
+	return m.stack[len(m.stack)-1]
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.top().Init()
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.err, m.info = nil, ""
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			if b, ok := m.top().(backer); ok && b.back() {
+				return m, nil
+			}
+			if len(m.stack) > 1 {
+				return m, m.pop(nil)
+			}
+			return m, nil
+		}
+	case errMsg:
+		m.err = msg.err
+	case infoMsg:
+		m.info = string(msg)
+		return m, nil
+	case pushMsg:
+		m.stack = append(m.stack, msg.s)
+		return m, msg.s.Init()
+	case popMsg:
+		if len(m.stack) == 1 {
+			return m, nil
+		}
+		return m, m.pop(msg.then)
+	case loginMsg:
+		m.app.pool, m.app.store = msg.pool, msg.store
+		menu := newMenuScreen(m.app)
+		m.stack = []screen{menu}
+		return m, menu.Init()
+	}
+
+	var cmd tea.Cmd
+	m.stack[len(m.stack)-1], cmd = m.top().Update(msg)
+	return m, cmd
+}
+
+// pop leaves the current screen, then opens the next one if any.
+func (m *Model) pop(then screen) tea.Cmd {
+	m.stack = m.stack[:len(m.stack)-1]
+	if then != nil {
+		m.stack = append(m.stack, then)
+		return then.Init()
+	}
+	if r, ok := m.top().(resumer); ok {
+		return r.resume()
+	}
+	return nil
+}
+
+func (m *Model) View() string {
+	var b strings.Builder
+	title := m.top().Title()
+	if store := m.app.store; store != nil {
+		me := store.Me()
+		title = fmt.Sprintf("%s · %s, %s · %s", title, me.Name, strings.ToLower(me.Role), me.Center)
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n")
+	b.WriteString(m.top().View())
+	b.WriteString("\n\n")
+	switch {
+	case m.err != nil:
+		b.WriteString(errorStyle.Render("Error: " + m.err.Error()))
+		b.WriteString("\n")
+	case m.info != "":
+		b.WriteString(infoStyle.Render(m.info))
+		b.WriteString("\n")
+	}
+	help := m.top().Help()
+	if len(m.stack) > 1 {
+		help += " · esc back"
+	}
+	b.WriteString(helpStyle.Render(help + " · ctrl+c quit"))
+	return b.String()
+}
+
+func newTable(columns []table.Column) table.Model {
+	t := table.New(table.WithColumns(columns), table.WithFocused(true), table.WithHeight(15))
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.Bold(true).BorderStyle(lipgloss.NormalBorder()).BorderBottom(true)
+	styles.Selected = styles.Selected.Foreground(lipgloss.Color("0")).Background(lipgloss.Color("12"))
+	t.SetStyles(styles)
+	return t
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
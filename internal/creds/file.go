@@ -0,0 +1,163 @@
+package creds
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+
+	"vehicles-service-stations/internal/model"
+)
+
+const (
+	fileVersion = 1
+	scryptN     = 1 << 15
+	scryptR     = 8
+	scryptP     = 1
+
+	// The parameters of a file are bounded, so a crafted file cannot make
+	// ReadFile allocate or compute without limit.
+	maxScryptMemory = 256 << 20
+	maxScryptP      = 16
+)
+
+// envelope is the on-disk format of the encrypted file: the JSON encoded
+// users sealed with NaCl secretbox under a key derived from the passphrase
+// with scrypt.
+type envelope struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Box     []byte `json:"box"`
+}
+
+type FileSink struct {
+	path       string
+	passphrase string
+}
+
+func NewFileSink(path, passphrase string) (*FileSink, error) {
+	if path == "" {
+		return nil, fmt.Errorf("credentials file path is empty")
+	}
+	if len(passphrase) < 12 {
+		return nil, fmt.Errorf("credentials passphrase must be at least 12 characters")
+	}
+	return &FileSink{path: path, passphrase: passphrase}, nil
+}
+
+func (s *FileSink) Name() string { return "file " + s.path }
+
+// Write replaces the file atomically, so a failed run never leaves a partial
+// or stale file behind.
+func (s *FileSink) Write(_ context.Context, users []*model.User) error {
+	plain, err := json.Marshal(users)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+
+	env := envelope{Version: fileVersion, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, 16)}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return err
+	}
+	key, err := deriveKey(s.passphrase, env)
+	if err != nil {
+		return err
+	}
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
+	}
+	env.Nonce = nonce[:]
+	env.Box = secretbox.Seal(nil, plain, &nonce, key)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".creds-*")
+	if err != nil {
+		return fmt.Errorf("failed to create credentials file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to restrict credentials file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close credentials file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to move credentials file into place: %w", err)
+	}
+	return nil
+}
+
+// ReadFile decrypts a file written by FileSink.
+func ReadFile(path, passphrase string) ([]*model.User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials file: %w", err)
+	}
+	if env.Version != fileVersion || env.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported credentials file version %d", env.Version)
+	}
+	if len(env.Nonce) != 24 {
+		return nil, fmt.Errorf("credentials file is corrupted")
+	}
+	if env.N < 2 || env.N&(env.N-1) != 0 || env.R < 1 || env.P < 1 || env.P > maxScryptP ||
+		env.R > maxScryptMemory/128/env.N {
+		return nil, fmt.Errorf("unsupported scrypt parameters N=%d r=%d p=%d", env.N, env.R, env.P)
+	}
+
+	key, err := deriveKey(passphrase, env)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], env.Nonce)
+	plain, ok := secretbox.Open(nil, env.Box, &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("wrong passphrase or corrupted credentials file")
+	}
+
+	var users []*model.User
+	if err := json.Unmarshal(plain, &users); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials: %w", err)
+	}
+	return users, nil
+}
+
+func deriveKey(passphrase string, env envelope) (*[32]byte, error) {
+	derived, err := scrypt.Key([]byte(passphrase), env.Salt, env.N, env.R, env.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
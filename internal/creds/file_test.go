@@ -0,0 +1,90 @@
+package creds
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"vehicles-service-stations/internal/model"
+)
+
+const passphrase = "correct horse battery"
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "creds.enc")
+	users := []*model.User{
+		{Login: "manager_1", Password: "p@ss:1", Role: "Manager", ServiceCenterID: 1},
+		{Login: "master_1", Password: "p@ss:2", Role: "Master", ServiceCenterID: 2},
+	}
+	sink, err := NewFileSink(path, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Write(context.Background(), users); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("file mode %o, want 600", mode)
+	}
+
+	got, err := ReadFile(path, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, users) {
+		t.Errorf("read %+v, want %+v", got, users)
+	}
+
+	if _, err := ReadFile(path, "wrong passphrase"); err == nil {
+		t.Error("read with a wrong passphrase")
+	}
+}
+
+func TestReadFileBoundsScrypt(t *testing.T) {
+	tests := []struct {
+		name    string
+		n, r, p int
+	}{
+		{"n not a power of two", 3 << 10, scryptR, scryptP},
+		{"n too small", 1, scryptR, scryptP},
+		{"memory too large", 1 << 22, scryptR, scryptP},
+		{"r too large", scryptN, 1 << 10, scryptP},
+		{"p too large", scryptN, scryptR, 1 << 10},
+		{"r zero", scryptN, 0, scryptP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(envelope{
+				Version: fileVersion, KDF: "scrypt", N: tt.n, R: tt.r, P: tt.p,
+				Salt: make([]byte, 16), Nonce: make([]byte, 24), Box: make([]byte, 32),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "creds.enc")
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ReadFile(path, passphrase); err == nil {
+				t.Error("read a file with unsupported scrypt parameters")
+			}
+		})
+	}
+}
+
+func TestNewFileSink(t *testing.T) {
+	if _, err := NewFileSink("", passphrase); err == nil {
+		t.Error("created a sink without a path")
+	}
+	if _, err := NewFileSink("creds.enc", "short"); err == nil {
+		t.Error("created a sink with a short passphrase")
+	}
+}
@@ -0,0 +1,165 @@
+package creds
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"vehicles-service-stations/internal/model"
+)
+
+// KVSink writes every user as a secret of a KV version 2 engine, so it works
+// with Vault as well as with the local KVServer stand-in. Secrets are stored
+// under <mount>/data/<prefix>/<login>.
+type KVSink struct {
+	addr   string
+	token  string
+	mount  string
+	prefix string
+	client *http.Client
+}
+
+func NewKVSink(addr, token, mount, prefix string) (*KVSink, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("kv address is empty")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("kv token is empty")
+	}
+	if mount == "" {
+		mount = "secret"
+	}
+	if prefix == "" {
+		prefix = "vehicles-service-stations/employees"
+	}
+	return &KVSink{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		mount:  strings.Trim(mount, "/"),
+		prefix: strings.Trim(prefix, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (s *KVSink) Name() string { return "kv " + s.addr }
+
+func (s *KVSink) Write(ctx context.Context, users []*model.User) error {
+	for _, u := range users {
+		body, err := json.Marshal(map[string]any{"data": u})
+		if err != nil {
+			return err
+		}
+
+		endpoint := fmt.Sprintf("%s/v1/%s/data/%s/%s", s.addr, s.mount, s.prefix, url.PathEscape(u.Login))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("X-Vault-Token", s.token)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to store credentials of %s: %w", u.Login, err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return fmt.Errorf("failed to store credentials of %s: kv responded %s", u.Login, resp.Status)
+		}
+	}
+	return nil
+}
+
+// KVServer is an in-memory stand-in for the KV version 2 HTTP API, enough for
+// local development: it supports writing, reading and deleting secrets
+// authenticated by a single token. Nothing is persisted.
+type KVServer struct {
+	token string
+
+	mu      sync.RWMutex
+	secrets map[string]kvSecret
+}
+
+type kvSecret struct {
+	Data    json.RawMessage
+	Version int
+	Created time.Time
+}
+
+func NewKVServer(token string) *KVServer {
+	return &KVServer{token: token, secrets: make(map[string]kvSecret)}
+}
+
+func (s *KVServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Vault-Token")), []byte(s.token)) != 1 {
+		writeKVError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	// /v1/<mount>/data/<path>
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/v1/"), "/", 3)
+	if len(parts) != 3 || parts[1] != "data" || parts[2] == "" {
+		writeKVError(w, http.StatusNotFound, "unsupported path")
+		return
+	}
+	key := parts[0] + "/" + parts[2]
+
+	switch r.Method {
+	case http.MethodPost, http.MethodPut:
+		var body struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&body); err != nil || len(body.Data) == 0 {
+			writeKVError(w, http.StatusBadRequest, "no data provided")
+			return
+		}
+
+		s.mu.Lock()
+		secret := kvSecret{Data: body.Data, Version: s.secrets[key].Version + 1, Created: time.Now().UTC()}
+		s.secrets[key] = secret
+		s.mu.Unlock()
+
+		writeKVJSON(w, map[string]any{"data": kvMetadata(secret)})
+	case http.MethodGet:
+		s.mu.RLock()
+		secret, ok := s.secrets[key]
+		s.mu.RUnlock()
+		if !ok {
+			writeKVError(w, http.StatusNotFound, "secret not found")
+			return
+		}
+
+		writeKVJSON(w, map[string]any{"data": map[string]any{"data": secret.Data, "metadata": kvMetadata(secret)}})
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.secrets, key)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeKVError(w, http.StatusMethodNotAllowed, "unsupported method")
+	}
+}
+
+func kvMetadata(secret kvSecret) map[string]any {
+	return map[string]any{"version": secret.Version, "created_time": secret.Created}
+}
+
+func writeKVJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeKVError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"errors": []string{msg}})
+}
@@ -0,0 +1,103 @@
+package creds
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/utils"
+)
+
+// Sink stores the credentials of generated employees.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, users []*model.User) error
+}
+
+type SinkKind string
+
+const (
+	SinkNone   SinkKind = "none"
+	SinkFile   SinkKind = "file"
+	SinkKV     SinkKind = "kv"
+	SinkStdout SinkKind = "stdout"
+)
+
+type SinkConfig struct {
+	Kind SinkKind
+	// Path and Passphrase configure the encrypted file sink.
+	Path       string
+	Passphrase string
+	// KVAddr, KVToken, KVMount and KVPrefix configure the KV sink.
+	KVAddr   string
+	KVToken  string
+	KVMount  string
+	KVPrefix string
+}
+
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Kind {
+	case SinkNone, "":
+		return nil, nil
+	case SinkFile:
+		return NewFileSink(cfg.Path, cfg.Passphrase)
+	case SinkKV:
+		return NewKVSink(cfg.KVAddr, cfg.KVToken, cfg.KVMount, cfg.KVPrefix)
+	case SinkStdout:
+		return &StdoutSink{W: os.Stdout}, nil
+	default:
+		return nil, fmt.Errorf("unknown credentials sink %q", cfg.Kind)
+	}
+}
+
+// StdoutSink prints the credentials as JSON, meant for throwaway local
+// databases only.
+type StdoutSink struct {
+	W io.Writer
+}
+
+func (s *StdoutSink) Name() string { return "stdout" }
+
+func (s *StdoutSink) Write(_ context.Context, users []*model.User) error {
+	enc := json.NewEncoder(s.W)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(users); err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	return nil
+}
+
+// Sample keeps at most n random users of every role, all users when n <= 0.
+// The result is ordered by role and login.
+func Sample(users []*model.User, n int) []*model.User {
+	if n <= 0 {
+		return users
+	}
+
+	byRole := make(map[string][]*model.User)
+	for _, u := range users {
+		byRole[u.Role] = append(byRole[u.Role], u)
+	}
+
+	var sampled []*model.User
+	for _, group := range byRole {
+		group = append([]*model.User(nil), group...)
+		utils.Rand.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		if len(group) > n {
+			group = group[:n]
+		}
+		sampled = append(sampled, group...)
+	}
+
+	sort.Slice(sampled, func(i, j int) bool {
+		if sampled[i].Role != sampled[j].Role {
+			return sampled[i].Role < sampled[j].Role
+		}
+		return sampled[i].Login < sampled[j].Login
+	})
+	return sampled
+}
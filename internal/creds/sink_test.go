@@ -0,0 +1,47 @@
+package creds
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"vehicles-service-stations/internal/model"
+)
+
+func TestSample(t *testing.T) {
+	var users []*model.User
+	for role, count := range map[string]int{"Manager": 5, "Master": 12, "Analyst": 1} {
+		for i := range count {
+			users = append(users, &model.User{Login: fmt.Sprintf("%s_%d", role, i), Role: role})
+		}
+	}
+
+	if got := Sample(users, 0); len(got) != len(users) {
+		t.Errorf("kept %d users without a cap, want all %d", len(got), len(users))
+	}
+
+	got := Sample(users, 3)
+	perRole := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, u := range got {
+		perRole[u.Role]++
+		if seen[u.Login] {
+			t.Errorf("%s sampled twice", u.Login)
+		}
+		seen[u.Login] = true
+	}
+	want := map[string]int{"Manager": 3, "Master": 3, "Analyst": 1}
+	for role, n := range want {
+		if perRole[role] != n {
+			t.Errorf("kept %d users of %s, want %d", perRole[role], role, n)
+		}
+	}
+	if !sort.SliceIsSorted(got, func(i, j int) bool {
+		if got[i].Role != got[j].Role {
+			return got[i].Role < got[j].Role
+		}
+		return got[i].Login < got[j].Login
+	}) {
+		t.Error("the sample is not ordered by role and login")
+	}
+}
@@ -0,0 +1,75 @@
+package dashboard
+
+import (
+	"fmt"
+	"time"
+)
+
+// Chart is a bar chart of daily values, drawn as SVG by the templates.
+type Chart struct {
+	Width, Height int
+	Max           float64
+	Bars          []Bar
+	// Labels are the dates under some of the bars, as many as fit.
+	Labels []Label
+}
+
+type Bar struct {
+	X, Y, W, H float64
+	Title      string
+}
+
+type Label struct {
+	X    float64
+	Text string
+}
+
+const (
+	chartWidth  = 720
+	chartHeight = 180
+	// chartAxis is the room under the bars for date labels.
+	chartAxis = 20
+)
+
+// dailyChart draws a bar for every day from..to, days without a value are
+// zero.
+func dailyChart(values []DayValue, from, to time.Time, format string) Chart {
+	byDay := make(map[string]float64, len(values))
+	for _, v := range values {
+		byDay[v.Date.Format(time.DateOnly)] += v.Value
+	}
+	var days []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+
+	c := Chart{Width: chartWidth, Height: chartHeight + chartAxis}
+	for _, d := range days {
+		c.Max = max(c.Max, byDay[d.Format(time.DateOnly)])
+	}
+	if len(days) == 0 {
+		return c
+	}
+
+	step := float64(chartWidth) / float64(len(days))
+	labelEvery := max(1, len(days)/10)
+	for i, d := range days {
+		v := byDay[d.Format(time.DateOnly)]
+		h := 0.0
+		if c.Max > 0 {
+			h = v / c.Max * chartHeight
+		}
+		x := float64(i) * step
+		c.Bars = append(c.Bars, Bar{
+			X:     x + step*0.1,
+			Y:     chartHeight - h,
+			W:     step * 0.8,
+			H:     h,
+			Title: fmt.Sprintf("%s: "+format, d.Format(time.DateOnly), v),
+		})
+		if i%labelEvery == 0 {
+			c.Labels = append(c.Labels, Label{X: x + step/2, Text: d.Format("02.01")})
+		}
+	}
+	return c
+}
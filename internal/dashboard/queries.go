@@ -0,0 +1,191 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DB is satisfied by pools and transactions.
+type DB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Filter narrows the dashboard. Zero CenterID means every center the
+// employee sees.
+type Filter struct {
+	From, To time.Time
+	CenterID int
+	// LowStock is the stock quantity parts are reported below.
+	LowStock int
+}
+
+// Employee is the logged in role. Name and Role are empty for roles that are
+// not employees, such as the superuser.
+type Employee struct {
+	Login string
+	Name  string
+	Role  string
+}
+
+type Center struct {
+	ID      int
+	Address string
+}
+
+type CenterKPI struct {
+	ID      int
+	Address string
+	Orders  int
+	Revenue float64
+}
+
+type DayValue struct {
+	Date  time.Time
+	Value float64
+}
+
+type MasterKPI struct {
+	ID      int
+	Name    string
+	Orders  int
+	Revenue float64
+}
+
+type LowStockPart struct {
+	ID        int
+	Name      string
+	Article   int
+	Stock     int
+	Stockpile string
+}
+
+// Data is everything the dashboard shows.
+type Data struct {
+	Centers       []Center
+	CenterKPIs    []CenterKPI
+	Revenue       []DayValue
+	Bookings      []DayValue
+	TodayBookings int
+	TopMasters    []MasterKPI
+	LowStock      []LowStockPart
+}
+
+func Whoami(ctx context.Context, db DB) (*Employee, error) {
+	e := &Employee{}
+	err := db.QueryRow(ctx, `
+        SELECT current_user, COALESCE(e.full_name, ''), COALESCE(esc.employee_role::text, '')
+        FROM (SELECT 1) one
+        LEFT JOIN employees e ON e.username = current_user
+        LEFT JOIN employee_service_center esc ON esc.employee_id = e.employee_id
+        LIMIT 1
+    `).Scan(&e.Login, &e.Name, &e.Role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+	return e, nil
+}
+
+// Load runs the dashboard queries. Every one of them goes through the views
+// and tables as the employee, so what they see is up to the policies.
+func Load(ctx context.Context, db DB, f Filter) (*Data, error) {
+	d := &Data{}
+	var err error
+	if d.Centers, err = collect(ctx, db, pgx.RowToStructByPos[Center], `
+        SELECT service_center_id, city || ', ' || full_address
+        FROM service_centers
+        ORDER BY city, full_address
+    `); err != nil {
+		return nil, fmt.Errorf("failed to list centers: %w", err)
+	}
+	if d.CenterKPIs, err = collect(ctx, db, pgx.RowToStructByPos[CenterKPI], `
+        SELECT service_center_id, full_address, total_orders::int, COALESCE(total_revenue, 0)::float8
+        FROM service_center_performance
+        WHERE $1 = 0 OR service_center_id = $1
+        ORDER BY total_revenue DESC NULLS LAST
+    `, f.CenterID); err != nil {
+		return nil, fmt.Errorf("failed to load center performance: %w", err)
+	}
+
+	// revenue_by_date and bookings_by_date have no center, so a center
+	// filter goes to the orders behind them.
+	if f.CenterID == 0 {
+		d.Revenue, err = collect(ctx, db, pgx.RowToStructByPos[DayValue], `
+            SELECT revenue_date, COALESCE(total_revenue, 0)::float8
+            FROM revenue_by_date
+            WHERE revenue_date BETWEEN $1 AND $2
+        `, f.From, f.To)
+	} else {
+		d.Revenue, err = collect(ctx, db, pgx.RowToStructByPos[DayValue], `
+            SELECT creation_date, COALESCE(sum(total_cost), 0)::float8
+            FROM orders
+            WHERE status = 'Completed' AND service_center_id = $3 AND creation_date BETWEEN $1 AND $2
+            GROUP BY creation_date
+            ORDER BY creation_date
+        `, f.From, f.To, f.CenterID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revenue: %w", err)
+	}
+
+	if f.CenterID == 0 {
+		d.Bookings, err = collect(ctx, db, pgx.RowToStructByPos[DayValue], `
+            SELECT scheduled_date, total_bookings::float8
+            FROM bookings_by_date
+            WHERE scheduled_date BETWEEN current_date AND current_date + 13
+        `)
+	} else {
+		d.Bookings, err = collect(ctx, db, pgx.RowToStructByPos[DayValue], `
+            SELECT scheduled_date, count(*)::float8
+            FROM orders
+            WHERE service_center_id = $1 AND scheduled_date BETWEEN current_date AND current_date + 13
+            GROUP BY scheduled_date
+            ORDER BY scheduled_date
+        `, f.CenterID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bookings: %w", err)
+	}
+	today := time.Now()
+	for _, b := range d.Bookings {
+		if b.Date.Year() == today.Year() && b.Date.YearDay() == today.YearDay() {
+			d.TodayBookings = int(b.Value)
+		}
+	}
+
+	if d.TopMasters, err = collect(ctx, db, pgx.RowToStructByPos[MasterKPI], `
+        SELECT p.employee_id, p.full_name, p.orders_handled::int, COALESCE(p.total_revenue_generated, 0)::float8
+        FROM employee_performance p
+        WHERE $1 = 0 OR EXISTS (
+            SELECT 1 FROM employee_service_center esc
+            WHERE esc.employee_id = p.employee_id AND esc.service_center_id = $1
+        )
+        ORDER BY p.orders_handled DESC, p.total_revenue_generated DESC NULLS LAST
+        LIMIT 10
+    `, f.CenterID); err != nil {
+		return nil, fmt.Errorf("failed to load top masters: %w", err)
+	}
+
+	if d.LowStock, err = collect(ctx, db, pgx.RowToStructByPos[LowStockPart], `
+        SELECT p.part_id, p.name, p.article_number, p.stock_quantity, COALESCE(s.full_address, '')
+        FROM spare_parts p
+        LEFT JOIN stockpile s ON s.stockpile_id = p.stockpile_id
+        WHERE p.stock_quantity < $1
+        ORDER BY p.stock_quantity, p.name
+        LIMIT 50
+    `, f.LowStock); err != nil {
+		return nil, fmt.Errorf("failed to load low stock parts: %w", err)
+	}
+	return d, nil
+}
+
+func collect[T any](ctx context.Context, db DB, fn pgx.RowToFunc[T], sql string, args ...any) ([]T, error) {
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, fn)
+}
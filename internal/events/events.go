@@ -0,0 +1,105 @@
+package events
+
+// Event is a domain event payload. The type and version identify its schema
+// in the Registry. Adding a field is compatible, any other change needs a new
+// version registered with an upgrade from the previous one.
+type Event interface {
+	EventType() string
+	EventVersion() int
+}
+
+type OrderCreated struct {
+	OrderID         int    `json:"order_id"`
+	CustomerID      int    `json:"customer_id"`
+	ServiceCenterID int    `json:"service_center_id"`
+	ManagerID       int    `json:"manager_id"`
+	MasterID        int    `json:"master_id"`
+	ScheduledDate   string `json:"scheduled_date"`
+	Status          string `json:"status"`
+}
+
+type OrderStatusChanged struct {
+	OrderID int    `json:"order_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+type OrderRescheduled struct {
+	OrderID int    `json:"order_id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+}
+
+// OrderMasterChanged reports a change of the master doing the order, which
+// is the reassigned master when there is one.
+type OrderMasterChanged struct {
+	OrderID int `json:"order_id"`
+	From    int `json:"from"`
+	To      int `json:"to"`
+}
+
+type ReceiptIssued struct {
+	ReceiptID        int     `json:"receipt_id"`
+	OrderID          int     `json:"order_id"`
+	TotalPaid        float64 `json:"total_paid"`
+	BonusPointsSpent float64 `json:"bonus_points_spent"`
+	ReceiptDate      string  `json:"receipt_date"`
+}
+
+// OrderReminderDue is enqueued by the order_reminders job of cmd/jobs the
+// day before the order is scheduled.
+type OrderReminderDue struct {
+	OrderID       int    `json:"order_id"`
+	ScheduledDate string `json:"scheduled_date"`
+}
+
+// StockChanged is emitted when the stock of a part changes, including
+// inserted and deleted parts which change from and to zero.
+type StockChanged struct {
+	PartID int `json:"part_id"`
+	From   int `json:"from"`
+	To     int `json:"to"`
+}
+
+// CustomerBalanceChanged reports the changes the receipt and bonus triggers
+// make to a customer.
+type CustomerBalanceChanged struct {
+	CustomerID      int     `json:"customer_id"`
+	SpentMoneyFrom  float64 `json:"spent_money_from"`
+	SpentMoneyTo    float64 `json:"spent_money_to"`
+	BonusPointsFrom float64 `json:"bonus_points_from"`
+	BonusPointsTo   float64 `json:"bonus_points_to"`
+	LoyaltyStatus   string  `json:"loyalty_status"`
+}
+
+func (OrderCreated) EventType() string           { return "order.created" }
+func (OrderStatusChanged) EventType() string     { return "order.status_changed" }
+func (OrderRescheduled) EventType() string       { return "order.rescheduled" }
+func (OrderMasterChanged) EventType() string     { return "order.master_changed" }
+func (ReceiptIssued) EventType() string          { return "receipt.issued" }
+func (OrderReminderDue) EventType() string       { return "order.reminder_due" }
+func (StockChanged) EventType() string           { return "stock.changed" }
+func (CustomerBalanceChanged) EventType() string { return "customer.balance_changed" }
+
+func (OrderCreated) EventVersion() int           { return 1 }
+func (OrderStatusChanged) EventVersion() int     { return 1 }
+func (OrderRescheduled) EventVersion() int       { return 1 }
+func (OrderMasterChanged) EventVersion() int     { return 1 }
+func (ReceiptIssued) EventVersion() int          { return 1 }
+func (OrderReminderDue) EventVersion() int       { return 1 }
+func (StockChanged) EventVersion() int           { return 1 }
+func (CustomerBalanceChanged) EventVersion() int { return 1 }
+
+// Default knows every event of this package.
+var Default = NewRegistry()
+
+func init() {
+	Register[OrderCreated](Default, nil)
+	Register[OrderStatusChanged](Default, nil)
+	Register[OrderRescheduled](Default, nil)
+	Register[OrderMasterChanged](Default, nil)
+	Register[ReceiptIssued](Default, nil)
+	Register[OrderReminderDue](Default, nil)
+	Register[StockChanged](Default, nil)
+	Register[CustomerBalanceChanged](Default, nil)
+}
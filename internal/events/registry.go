@@ -0,0 +1,146 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrUnknownType    = errors.New("unknown event type")
+	ErrUnknownVersion = errors.New("unknown event version")
+)
+
+// Upgrade rewrites a payload of the previous version into the registered one.
+type Upgrade func(payload json.RawMessage) (json.RawMessage, error)
+
+type schema struct {
+	decode func(data []byte) (Event, error)
+	// upgrade converts version-1 payloads, nil when there is no way to.
+	upgrade Upgrade
+}
+
+// Registry maps event types and versions to Go types. Consumers decode
+// payloads through it: older versions are upgraded step by step to the
+// latest one they know, newer versions are refused instead of being decoded
+// partially.
+type Registry struct {
+	mu      sync.RWMutex
+	schemas map[string]map[int]schema
+}
+
+func NewRegistry() *Registry {
+	return &Registry{schemas: make(map[string]map[int]schema)}
+}
+
+// Register adds the schema of T. upgrade converts payloads of the previous
+// version of the type, it may be nil for the first version. Registering the
+// same version twice panics, registries are built at start up.
+func Register[T Event](r *Registry, upgrade Upgrade) {
+	var zero T
+	name, version := zero.EventType(), zero.EventVersion()
+	if version < 1 {
+		panic(fmt.Sprintf("event %s has invalid version %d", name, version))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions, ok := r.schemas[name]
+	if !ok {
+		versions = make(map[int]schema)
+		r.schemas[name] = versions
+	}
+	if _, dup := versions[version]; dup {
+		panic(fmt.Sprintf("event %s v%d registered twice", name, version))
+	}
+	versions[version] = schema{decode: decodeAs[T], upgrade: upgrade}
+}
+
+func decodeAs[T Event](data []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Latest returns the highest registered version of a type.
+func (r *Registry) Latest(eventType string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions, ok := r.schemas[eventType]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownType, eventType)
+	}
+	latest := 0
+	for v := range versions {
+		latest = max(latest, v)
+	}
+	return latest, nil
+}
+
+// Check fails unless the event is registered under its version.
+func (r *Registry) Check(e Event) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions, ok := r.schemas[e.EventType()]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownType, e.EventType())
+	}
+	if _, ok := versions[e.EventVersion()]; !ok {
+		return fmt.Errorf("%w: %s v%d", ErrUnknownVersion, e.EventType(), e.EventVersion())
+	}
+	return nil
+}
+
+// Decode returns the payload as the latest registered version of its type.
+func (r *Registry) Decode(eventType string, version int, payload json.RawMessage) (Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.schemas[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, eventType)
+	}
+	latest := 0
+	for v := range versions {
+		latest = max(latest, v)
+	}
+	if _, ok := versions[version]; !ok {
+		return nil, fmt.Errorf("%w: %s v%d, latest known is v%d", ErrUnknownVersion, eventType, version, latest)
+	}
+
+	var err error
+	for v := version + 1; v <= latest; v++ {
+		next, ok := versions[v]
+		if !ok || next.upgrade == nil {
+			return nil, fmt.Errorf("%w: no upgrade of %s from v%d to v%d", ErrUnknownVersion, eventType, v-1, v)
+		}
+		if payload, err = next.upgrade(payload); err != nil {
+			return nil, fmt.Errorf("failed to upgrade %s to v%d: %w", eventType, v, err)
+		}
+	}
+
+	event, err := versions[latest].decode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s v%d: %w", eventType, latest, err)
+	}
+	return event, nil
+}
+
+// Types lists the registered types with their versions, for documentation
+// and diagnostics.
+func (r *Registry) Types() map[string][]int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[string][]int, len(r.schemas))
+	for name, versions := range r.schemas {
+		for v := range versions {
+			out[name] = append(out[name], v)
+		}
+		sort.Ints(out[name])
+	}
+	return out
+}
@@ -2,19 +2,22 @@ package gomock
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"math/rand/v2"
 	"os"
 	"regexp"
 	"time"
 
-	"github.com/Masterminds/squirrel"
 	"github.com/brianvoe/gofakeit/v7"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/query"
 	"vehicles-service-stations/internal/utils"
 )
 
@@ -37,7 +40,7 @@ func CreateServiceCenters(ctx context.Context, db *pgxpool.Pool, cityCount int,
 	}
 
 	for i := 0; i < serviceCenterCount; i++ {
-		city := cities[rand.IntN(len(cities))]
+		city := cities[utils.Rand.IntN(len(cities))]
 		re := regexp.MustCompile(`, (\w+),`)
 		updatedAddress := re.ReplaceAllString(gofakeit.Address().Address, fmt.Sprintf(", %s,", city))
 		postalCode := gofakeit.Zip()
@@ -52,7 +55,28 @@ func CreateServiceCenters(ctx context.Context, db *pgxpool.Pool, cityCount int,
 	return nil
 }
 
-func CreateEmployees(ctx context.Context, db *pgxpool.Pool, employeesCount int, users *[]*model.User) error {
+type employeeOptions struct {
+	usernamePrefix string
+}
+
+type EmployeeOption func(*employeeOptions)
+
+// WithUsernamePrefix prefixes generated logins. Logins are database roles and
+// so cluster wide, the prefix keeps databases seeded alike from clashing.
+func WithUsernamePrefix(prefix string) EmployeeOption {
+	return func(o *employeeOptions) {
+		o.usernamePrefix = prefix
+	}
+}
+
+func CreateEmployees(ctx context.Context, db *pgxpool.Pool, employeesCount int, users *[]*model.User, dists *utils.DistributionConfig, opts ...EmployeeOption) error {
+	var o employeeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if dists == nil {
+		dists = utils.DefaultDistributionConfig()
+	}
 
 	empRoles := []string{"Analyst", "Master", "Manager"}
 
@@ -77,9 +101,16 @@ func CreateEmployees(ctx context.Context, db *pgxpool.Pool, employeesCount int,
 		return fmt.Errorf("error during service centers loading")
 	}
 
+	serviceCenters, err := utils.NewWeighted(serviceCenterIDs, dists.CenterWeights(serviceCenterIDs))
+	if err != nil {
+		return fmt.Errorf("no service centers found: %w", err)
+	}
+
 	for employeeID := 1; employeeID <= employeesCount; employeeID++ {
 		password := gofakeit.Password(true, true, true, false, false, 10)
-		username := gofakeit.Username()
+		username := o.usernamePrefix + gofakeit.Username()
+		role := empRoles[utils.Rand.IntN(len(empRoles))]
+		serviceCenterID := serviceCenters.Pick()
 		_, err := db.Exec(ctx, `SELECT create_user(
 				$1,
 				$2,
@@ -89,16 +120,17 @@ func CreateEmployees(ctx context.Context, db *pgxpool.Pool, employeesCount int,
 				$6,
 				$7,
 				$8
-			);`, gofakeit.Name(), gofakeit.Number(0, 30), gofakeit.Number(18, 65), gofakeit.Price(30000, 150000), username, password, empRoles[rand.IntN(len(empRoles))], serviceCenterIDs[rand.IntN(len(serviceCenterIDs))])
-
-		user := &model.User{
-			Login:    username,
-			Password: password,
-		}
-		*users = append(*users, user)
+			);`, gofakeit.Name(), gofakeit.Number(0, 30), gofakeit.Number(18, 65), gofakeit.Price(30000, 150000), username, password, role, serviceCenterID)
 		if err != nil {
 			return fmt.Errorf("failed to map employee %d to service center: %v", employeeID, err)
 		}
+
+		*users = append(*users, &model.User{
+			Login:           username,
+			Password:        password,
+			Role:            role,
+			ServiceCenterID: serviceCenterID,
+		})
 	}
 
 	return nil
@@ -149,7 +181,7 @@ func CreateServices(ctx context.Context, db *pgxpool.Pool, servicesCount int, se
 
 		_, err := db.Exec(ctx, `INSERT INTO services (full_name, vehicle_type, price)
 			VALUES ($1, $2, $3)`,
-			services[rand.IntN(len(services))], vehicleType[rand.IntN(len(vehicleType))], price)
+			services[utils.Rand.IntN(len(services))], vehicleType[utils.Rand.IntN(len(vehicleType))], price)
 		if err != nil {
 			return fmt.Errorf("failed to insert service %d: %v", i+1, err)
 		}
@@ -195,7 +227,7 @@ func CreateSpareParts(ctx context.Context, db *pgxpool.Pool, sparePartsCount int
 		_, err := db.Exec(ctx, `
 			INSERT INTO spare_parts (name, article_number, description, price, stock_quantity, stockpile_id)
 			VALUES ($1, $2, $3, $4, $5, $6)`,
-			partNames[rand.IntN(len(partNames))], articleNumber, description, price, stockQuantity, 1)
+			partNames[utils.Rand.IntN(len(partNames))], articleNumber, description, price, stockQuantity, 1)
 		if err != nil {
 			return fmt.Errorf("failed to insert spare part %d: %v", i+1, err)
 		}
@@ -204,28 +236,38 @@ func CreateSpareParts(ctx context.Context, db *pgxpool.Pool, sparePartsCount int
 	return nil
 }
 
-func CreateOrders(ctx context.Context, db *pgxpool.Pool, createOrdersTries int, purchasePrice *Pair[float64], sparePartsCountPerOrder int, serviceCountPerOrder int) error {
+func CreateOrders(ctx context.Context, db *pgxpool.Pool, createOrdersTries int, purchasePrice *Pair[float64], sparePartsCountPerOrder int, serviceCountPerOrder int, dists *utils.DistributionConfig) error {
+	if dists == nil {
+		dists = utils.DefaultDistributionConfig()
+	}
+
+	catalog, err := loadCatalog(ctx, db)
+	if err != nil {
+		return err
+	}
+	serviceCenters, err := loadStaffedServiceCenters(ctx, db, dists)
+	if err != nil {
+		return err
+	}
+	services, err := loadServicesByPopularity(ctx, db, dists.Services)
+	if err != nil {
+		return err
+	}
+	creationDates, err := utils.NewDateSampler(time.Now().AddDate(-1, 0, 0), time.Now(), dists.Seasonality)
+	if err != nil {
+		return err
+	}
+
 	tx, err := db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 
-	for i := 0; i < createOrdersTries; i++ {
-		var serviceCenterId int
-		err := db.QueryRow(ctx, `
-			SELECT service_center_id
-			FROM employee_service_center
-			WHERE employee_role IN ('Manager', 'Master')
-			GROUP BY service_center_id
-			HAVING COUNT(DISTINCT employee_role) > 1
-			ORDER BY RANDOM()
-			LIMIT 1;
-		`).Scan(&serviceCenterId)
+	idCache := utils.NewIDCache(0)
+	customers := utils.NewWeightedIDs(dists.Customers, 0)
 
-		if err != nil {
-			log.Fatal("Error getting service center:", err)
-			continue
-		}
+	for i := 0; i < createOrdersTries; i++ {
+		serviceCenterId := serviceCenters.Pick()
 
 		joins := []utils.Join{
 			{
@@ -235,60 +277,51 @@ func CreateOrders(ctx context.Context, db *pgxpool.Pool, createOrdersTries int,
 			},
 		}
 
-		whereClause := squirrel.And{
-			squirrel.Eq{"employee_service_center.employee_role": "Master"},
-			squirrel.Eq{"employee_service_center.service_center_id": serviceCenterId},
-		}
-
 		masterId, err := utils.RandomIDWithBuilder(ctx, db, "employees", "employee_id",
-			utils.WithJoins(joins), utils.WithWhereClause(whereClause))
+			utils.WithCatalog(catalog), utils.WithStrategy(idCache), utils.WithJoins(joins), utils.WithFilters(
+				query.Eq("employee_service_center.employee_role", "Master"),
+				query.Eq("employee_service_center.service_center_id", serviceCenterId),
+			))
 
 		if err != nil {
 			log.Println("Error getting master in service center", err)
 			continue
 		}
 
-		whereClause = squirrel.And{
-			squirrel.Eq{"employee_role": "Manager"},
-			squirrel.Eq{"employee_service_center.service_center_id": serviceCenterId},
-		}
-
 		managerId, err := utils.RandomIDWithBuilder(ctx, db, "employees", "employee_id",
-			utils.WithJoins(joins), utils.WithWhereClause(whereClause))
+			utils.WithCatalog(catalog), utils.WithStrategy(idCache), utils.WithJoins(joins), utils.WithFilters(
+				query.Eq("employee_service_center.employee_role", "Manager"),
+				query.Eq("employee_service_center.service_center_id", serviceCenterId),
+			))
 		if err != nil {
 			log.Println("Error getting meneger in service center", err)
 			continue
 		}
-		customerId, err := utils.RandomIDWithBuilder(ctx, db, "customers", "customer_id")
+		customerId, err := utils.RandomIDWithBuilder(ctx, db, "customers", "customer_id", utils.WithCatalog(catalog), utils.WithStrategy(customers))
 		if err != nil {
 			log.Println("Error getting customer", err)
 			continue
 		}
 		orderStatuses := []string{"Pending", "In Progress", "Completed"}
-		var orderID int
-		err = tx.QueryRow(ctx, `
-			INSERT INTO orders
-			(customer_id, service_center_id, manager_id, assigned_master_id, scheduled_date, status, creation_date)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
-			RETURNING order_id`,
-			customerId, serviceCenterId, managerId, masterId, gofakeit.FutureDate().Add(time.Hour*24*time.Duration(rand.IntN(20))), orderStatuses[rand.IntN(len(orderStatuses))], gofakeit.PastDate().Add(-time.Hour*24*time.Duration(rand.IntN(20)))).Scan(&orderID)
+		creationDate := creationDates.Pick()
+		orderID, _, err := insertOrder(ctx, tx, orderRow{
+			CustomerID:      customerId,
+			ServiceCenterID: serviceCenterId,
+			ManagerID:       managerId,
+			MasterID:        masterId,
+			CreationDate:    creationDate,
+			ScheduledDate:   creationDate.AddDate(0, 0, utils.Rand.IntN(20)),
+			Status:          orderStatuses[utils.Rand.IntN(len(orderStatuses))],
+		})
 		if err != nil {
 			return fmt.Errorf("failed to insert order %d: %v", i+1, err)
 		}
 
-		usedSpareParts := make(map[int]bool)
-		for v := 0; v < sparePartsCountPerOrder; v++ {
-			sparePartId, err := utils.RandomIDWithBuilder(ctx, db, "spare_parts", "part_id")
-			if err != nil {
-				log.Fatal("Error getting stockpile:", err)
-				continue
-			}
-
-			if usedSpareParts[sparePartId] {
-				continue
-			}
-			usedSpareParts[sparePartId] = true
-
+		sparePartIds, err := utils.RandomIDs(ctx, db, "spare_parts", "part_id", sparePartsCountPerOrder, utils.WithCatalog(catalog), utils.WithStrategy(idCache))
+		if err != nil {
+			log.Fatal("Error getting stockpile:", err)
+		}
+		for _, sparePartId := range sparePartIds {
 			quantity := gofakeit.Number(1, 5)
 
 			var stockQuantity int
@@ -311,19 +344,7 @@ func CreateOrders(ctx context.Context, db *pgxpool.Pool, createOrdersTries int,
 			}
 		}
 
-		usedService := make(map[int]bool)
-		for v := 0; v < serviceCountPerOrder; v++ {
-			serviceID, err := utils.RandomIDWithBuilder(ctx, db, "services", "service_id")
-			if err != nil {
-				log.Fatal("Error getting service:", err)
-				continue
-			}
-
-			if usedService[serviceID] {
-				continue
-			}
-			usedService[serviceID] = true
-
+		for _, serviceID := range services.PickN(serviceCountPerOrder) {
 			_, err = tx.Exec(ctx, `
 				INSERT INTO service_order (service_id, order_id)
 				VALUES ($1, $2)`,
@@ -339,6 +360,127 @@ func CreateOrders(ctx context.Context, db *pgxpool.Pool, createOrdersTries int,
 	return nil
 }
 
+// loadCatalog introspects the public schema the random id picks are
+// validated against.
+func loadCatalog(ctx context.Context, db *pgxpool.Pool) (*model.AllowedTables, error) {
+	catalog := model.NewAllowedTables()
+	if err := catalog.Initialize(ctx, db, "public"); err != nil {
+		return nil, fmt.Errorf("failed to load schema catalog: %w", err)
+	}
+	return catalog, nil
+}
+
+// loadStaffedServiceCenters weights the centers having both a manager and a
+// master by their configured size.
+func loadStaffedServiceCenters(ctx context.Context, db *pgxpool.Pool, dists *utils.DistributionConfig) (*utils.Weighted[int], error) {
+	rows, err := db.Query(ctx, `
+		SELECT service_center_id
+		FROM employee_service_center
+		WHERE employee_role IN ('Manager', 'Master')
+		GROUP BY service_center_id
+		HAVING COUNT(DISTINCT employee_role) > 1;
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service centers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan service center: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during service centers loading: %w", err)
+	}
+
+	centers, err := utils.NewWeighted(ids, dists.CenterWeights(ids))
+	if err != nil {
+		return nil, fmt.Errorf("no service center has both a manager and a master: %w", err)
+	}
+	return centers, nil
+}
+
+func loadServicesByPopularity(ctx context.Context, db *pgxpool.Pool, popularity map[string]float64) (*utils.Weighted[int], error) {
+	rows, err := db.Query(ctx, `SELECT service_id, full_name FROM services`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query services: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	var weights []float64
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan service: %w", err)
+		}
+		weight, ok := popularity[name]
+		if !ok {
+			weight = 1
+		}
+		ids = append(ids, id)
+		weights = append(weights, weight)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during services loading: %w", err)
+	}
+
+	services, err := utils.NewWeighted(ids, weights)
+	if err != nil {
+		return nil, fmt.Errorf("no services to order: %w", err)
+	}
+	return services, nil
+}
+
+type orderRow struct {
+	CustomerID      int
+	ServiceCenterID int
+	ManagerID       int
+	MasterID        int
+	CreationDate    time.Time
+	ScheduledDate   time.Time
+	Status          string
+}
+
+// insertOrder inserts the order in a savepoint and moves it to the next day
+// while trigger_before_order_insert reports the master as busy. It returns the
+// order id and the day the order ended up scheduled on.
+func insertOrder(ctx context.Context, tx pgx.Tx, order orderRow) (int, time.Time, error) {
+	const maxReschedules = 30
+
+	for attempt := 0; ; attempt++ {
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+
+		var orderID int
+		err = sp.QueryRow(ctx, `
+			INSERT INTO orders
+			(customer_id, service_center_id, manager_id, assigned_master_id, scheduled_date, status, creation_date)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING order_id`,
+			order.CustomerID, order.ServiceCenterID, order.ManagerID, order.MasterID, order.ScheduledDate, order.Status, order.CreationDate).Scan(&orderID)
+		if err == nil {
+			return orderID, order.ScheduledDate, sp.Commit(ctx)
+		}
+		if rbErr := sp.Rollback(ctx); rbErr != nil {
+			return 0, time.Time{}, rbErr
+		}
+
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.RaiseException || attempt >= maxReschedules {
+			return 0, time.Time{}, err
+		}
+		order.ScheduledDate = order.ScheduledDate.AddDate(0, 0, 1)
+	}
+}
+
 func InitAdmin(ctx context.Context, db *pgxpool.Pool) error {
 	var count int
 	err := db.QueryRow(ctx, `SELECT COUNT(*) FROM employee_service_center WHERE employee_role = $1`, "Administrator").Scan(&count)
@@ -412,7 +554,7 @@ func CreateReceipts(ctx context.Context, db *pgxpool.Pool) error {
 			continue
 		}
 
-		spentBonusPoints := receiptDTO.BonusPoints * (rand.Float64()*0.9 + 0.1)
+		spentBonusPoints := receiptDTO.BonusPoints * (utils.Rand.Float64()*0.9 + 0.1)
 		_, err := db.Exec(ctx, `INSERT INTO receipts (order_id, bonus_points_spent, total_paid)
             VALUES ($1, $2, $3)`, receiptDTO.OrderId, spentBonusPoints, receiptDTO.TotalCost-spentBonusPoints)
 		if err != nil {
@@ -0,0 +1,108 @@
+package gomock_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"vehicles-service-stations/internal/check"
+	"vehicles-service-stations/internal/testutil"
+)
+
+func TestGenerators(t *testing.T) {
+	d, users := testutil.NewSeededDatabase(t, "gen")
+	ctx := context.Background()
+	seed := testutil.DefaultSeedOptions()
+
+	tests := []struct {
+		table string
+		// cond must hold for every generated row.
+		cond string
+		want int
+	}{
+		{"service_centers", `city <> '' AND phone_number ~ '^\+\d{10,15}$'`, seed.ServiceCenters},
+		{"customers", "spent_money >= 1", seed.Customers},
+		{"services", "price BETWEEN 2000 AND 50000", seed.Services},
+		{"spare_parts", "stock_quantity BETWEEN 50 AND 100", seed.SpareParts},
+		{
+			"employees e",
+			"EXISTS (SELECT 1 FROM employee_service_center esc WHERE esc.employee_id = e.employee_id)" +
+				" AND EXISTS (SELECT 1 FROM pg_roles r WHERE r.rolname = e.username)",
+			seed.Employees,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Fields(tt.table)[0], func(t *testing.T) {
+			var total, matching int
+			err := d.Pool.QueryRow(ctx, fmt.Sprintf(
+				`SELECT count(*), count(*) FILTER (WHERE %s) FROM %s`, tt.cond, tt.table,
+			)).Scan(&total, &matching)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.want {
+				t.Errorf("%d rows, want %d", total, tt.want)
+			}
+			if matching != total {
+				t.Errorf("%d of %d rows violate %s", total-matching, total, tt.cond)
+			}
+		})
+	}
+
+	t.Run("users", func(t *testing.T) {
+		if len(users) != seed.Employees {
+			t.Errorf("%d users returned, want %d", len(users), seed.Employees)
+		}
+		for _, u := range users {
+			if !strings.HasPrefix(u.Login, d.Name+"_") {
+				t.Errorf("login %s is not scoped to %s", u.Login, d.Name)
+			}
+		}
+	})
+
+	t.Run("orders", func(t *testing.T) {
+		var orders int
+		if err := d.Pool.QueryRow(ctx, `SELECT count(*) FROM orders`).Scan(&orders); err != nil {
+			t.Fatal(err)
+		}
+		if orders == 0 {
+			t.Fatalf("no orders were generated in %d tries", seed.OrderTries)
+		}
+		report, err := check.Run(ctx, d.Pool, check.Options{Only: []string{
+			"employees_count",
+			"order_total_cost",
+			"receipt_order_completed",
+			"order_master_role",
+			"order_manager_role",
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, res := range report.Results {
+			if res.Remaining > 0 {
+				t.Errorf("invariant %s has %d violations", res.Invariant, res.Remaining)
+			}
+		}
+	})
+
+	t.Run("determinism", func(t *testing.T) {
+		other, _ := testutil.NewSeededDatabase(t, "gen")
+		for _, q := range []string{
+			`SELECT string_agg(full_name || ' ' || phone_number, ',' ORDER BY customer_id) FROM customers`,
+			`SELECT string_agg(full_name || ' ' || price, ',' ORDER BY service_id) FROM services`,
+			`SELECT string_agg(name || ' ' || stock_quantity, ',' ORDER BY part_id) FROM spare_parts`,
+		} {
+			var a, b string
+			if err := d.Pool.QueryRow(ctx, q).Scan(&a); err != nil {
+				t.Fatal(err)
+			}
+			if err := other.Pool.QueryRow(ctx, q).Scan(&b); err != nil {
+				t.Fatal(err)
+			}
+			if a != b {
+				t.Errorf("seed %d produced different data: %s", seed.Seed, q)
+			}
+		}
+	})
+}
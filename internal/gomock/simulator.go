@@ -0,0 +1,385 @@
+package gomock
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/utils"
+)
+
+type SimulationConfig struct {
+	From time.Time
+	To   time.Time
+	// ArrivalsPerDay is the mean number of new orders per day for a center
+	// with an average number of masters, before seasonality is applied.
+	ArrivalsPerDay float64
+	// LeadDays is how many days ahead customers book.
+	LeadDays Pair[int]
+	// WorkDays is how long an order stays In Progress.
+	WorkDays   Pair[int]
+	CancelRate float64
+	// BonusSpendRate is the share of receipts paying with bonus points.
+	BonusSpendRate     float64
+	ServicesPerOrder   Pair[int]
+	SparePartsPerOrder Pair[int]
+	PurchasePrice      Pair[float64]
+	Seasonality        utils.Seasonality
+}
+
+func DefaultSimulationConfig(days int) *SimulationConfig {
+	to := time.Now()
+	return &SimulationConfig{
+		From:               to.AddDate(0, 0, -days),
+		To:                 to,
+		ArrivalsPerDay:     1.5,
+		LeadDays:           Pair[int]{First: 0, Second: 14},
+		WorkDays:           Pair[int]{First: 0, Second: 3},
+		CancelRate:         0.07,
+		BonusSpendRate:     0.3,
+		ServicesPerOrder:   Pair[int]{First: 1, Second: 3},
+		SparePartsPerOrder: Pair[int]{First: 0, Second: 4},
+		PurchasePrice:      Pair[float64]{First: 500.0, Second: 50000.0},
+		Seasonality: utils.Seasonality{
+			Monthly: []float64{0.6, 0.6, 0.9, 1.6, 1.3, 1.0, 0.9, 0.9, 1.0, 1.5, 1.4, 0.8},
+			Weekday: []float64{0.3, 1.2, 1.1, 1.0, 1.0, 1.3, 0.8},
+		},
+	}
+}
+
+type simCenter struct {
+	id       int
+	masters  []int
+	managers []int
+}
+
+type simOrder struct {
+	id        int
+	masterID  int
+	scheduled time.Time
+	completes time.Time
+	status    string
+}
+
+type simulator struct {
+	cfg       *SimulationConfig
+	db        *pgxpool.Pool
+	catalog   *model.AllowedTables
+	centers   []*simCenter
+	weights   []float64
+	services  *utils.Weighted[int]
+	customers *utils.WeightedIDs
+	parts     *utils.IDCache
+	// busy holds the days each master already has an active order on.
+	busy   map[int]map[time.Time]bool
+	active []*simOrder
+}
+
+// SimulateOrderHistory walks the configured date range day by day. Every day
+// each center receives a Poisson number of bookings shaped by seasonality and
+// center size, booked orders start on their scheduled day, finish after the
+// work duration and get a receipt dated at completion. Orders whose next step
+// lies after cfg.To keep the status they have at that date.
+func SimulateOrderHistory(ctx context.Context, db *pgxpool.Pool, cfg *SimulationConfig, dists *utils.DistributionConfig) error {
+	if dists == nil {
+		dists = utils.DefaultDistributionConfig()
+	}
+	if !cfg.To.After(cfg.From) {
+		return fmt.Errorf("simulation range is empty")
+	}
+	if len(dists.Seasonality.Monthly) > 0 || len(dists.Seasonality.Weekday) > 0 {
+		cfg.Seasonality = dists.Seasonality
+	}
+
+	catalog, err := loadCatalog(ctx, db)
+	if err != nil {
+		return err
+	}
+	centers, err := loadCenterStaff(ctx, db)
+	if err != nil {
+		return err
+	}
+	services, err := loadServicesByPopularity(ctx, db, dists.Services)
+	if err != nil {
+		return err
+	}
+
+	s := &simulator{
+		cfg:       cfg,
+		db:        db,
+		catalog:   catalog,
+		centers:   centers,
+		services:  services,
+		customers: utils.NewWeightedIDs(dists.Customers, 0),
+		parts:     utils.NewIDCache(0),
+		busy:      make(map[int]map[time.Time]bool),
+	}
+
+	var totalMasters int
+	for _, c := range centers {
+		totalMasters += len(c.masters)
+	}
+	for _, c := range centers {
+		s.weights = append(s.weights, float64(len(c.masters))*float64(len(centers))/float64(totalMasters))
+	}
+
+	from := truncateDay(cfg.From)
+	to := truncateDay(cfg.To)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if err := s.simulateDay(ctx, day); err != nil {
+			return fmt.Errorf("simulation of %s failed: %w", day.Format(time.DateOnly), err)
+		}
+	}
+
+	return nil
+}
+
+func (s *simulator) simulateDay(ctx context.Context, day time.Time) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.advanceOrders(ctx, tx, day); err != nil {
+		return err
+	}
+
+	season := s.cfg.Seasonality.Weight(day)
+	for i, center := range s.centers {
+		arrivals := poisson(s.cfg.ArrivalsPerDay * s.weights[i] * season)
+		for a := 0; a < arrivals; a++ {
+			if err := s.bookOrder(ctx, tx, center, day); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (s *simulator) advanceOrders(ctx context.Context, tx pgx.Tx, day time.Time) error {
+	remaining := s.active[:0]
+	for _, order := range s.active {
+		switch {
+		case order.status == "Pending" && !order.scheduled.After(day):
+			if utils.Rand.Float64() < s.cfg.CancelRate {
+				if err := s.setStatus(ctx, tx, order, "Cancelled"); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := s.setStatus(ctx, tx, order, "In Progress"); err != nil {
+				return err
+			}
+			order.completes = day.AddDate(0, 0, randBetween(s.cfg.WorkDays))
+			remaining = append(remaining, order)
+		case order.status == "In Progress" && !order.completes.After(day):
+			if err := s.setStatus(ctx, tx, order, "Completed"); err != nil {
+				return err
+			}
+			if err := s.issueReceipt(ctx, tx, order, day); err != nil {
+				return err
+			}
+		default:
+			remaining = append(remaining, order)
+		}
+	}
+	s.active = remaining
+	return nil
+}
+
+func (s *simulator) setStatus(ctx context.Context, tx pgx.Tx, order *simOrder, status string) error {
+	if _, err := tx.Exec(ctx, `UPDATE orders SET status = $1 WHERE order_id = $2`, status, order.id); err != nil {
+		return fmt.Errorf("failed to move order %d to %s: %w", order.id, status, err)
+	}
+	order.status = status
+	if status == "Completed" || status == "Cancelled" {
+		delete(s.busy[order.masterID], order.scheduled)
+	}
+	return nil
+}
+
+func (s *simulator) bookOrder(ctx context.Context, tx pgx.Tx, center *simCenter, day time.Time) error {
+	customerID, err := utils.RandomIDWithBuilder(ctx, s.db, "customers", "customer_id", utils.WithCatalog(s.catalog), utils.WithStrategy(s.customers))
+	if err != nil {
+		return err
+	}
+
+	masterID, scheduled := s.freeSlot(center, day.AddDate(0, 0, randBetween(s.cfg.LeadDays)))
+	managerID := center.managers[utils.Rand.IntN(len(center.managers))]
+
+	orderID, scheduled, err := insertOrder(ctx, tx, orderRow{
+		CustomerID:      customerID,
+		ServiceCenterID: center.id,
+		ManagerID:       managerID,
+		MasterID:        masterID,
+		CreationDate:    day,
+		ScheduledDate:   scheduled,
+		Status:          "Pending",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to insert order: %w", err)
+	}
+
+	for _, serviceID := range s.services.PickN(randBetween(s.cfg.ServicesPerOrder)) {
+		if _, err := tx.Exec(ctx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, serviceID, orderID); err != nil {
+			return fmt.Errorf("failed to insert service of order %d: %w", orderID, err)
+		}
+	}
+
+	if partsCount := randBetween(s.cfg.SparePartsPerOrder); partsCount > 0 {
+		partIDs, err := utils.RandomIDs(ctx, s.db, "spare_parts", "part_id", partsCount, utils.WithCatalog(s.catalog), utils.WithStrategy(s.parts))
+		if err != nil {
+			return err
+		}
+		for _, partID := range partIDs {
+			quantity := gofakeit.Number(1, 5)
+
+			var stockQuantity int
+			err := tx.QueryRow(ctx, "SELECT stock_quantity FROM spare_parts WHERE part_id = $1 FOR UPDATE", partID).Scan(&stockQuantity)
+			if err != nil {
+				return fmt.Errorf("failed to read stock of part %d: %w", partID, err)
+			}
+			if stockQuantity < quantity {
+				continue
+			}
+
+			_, err = tx.Exec(ctx, `
+				INSERT INTO spare_part_order (part_id, order_id, quantity, purchase_price)
+				VALUES ($1, $2, $3, $4)`,
+				partID, orderID, quantity, gofakeit.Price(s.cfg.PurchasePrice.First, s.cfg.PurchasePrice.Second))
+			if err != nil {
+				return fmt.Errorf("failed to insert spare part of order %d: %w", orderID, err)
+			}
+		}
+	}
+
+	if s.busy[masterID] == nil {
+		s.busy[masterID] = make(map[time.Time]bool)
+	}
+	s.busy[masterID][scheduled] = true
+	s.active = append(s.active, &simOrder{id: orderID, masterID: masterID, scheduled: scheduled, status: "Pending"})
+
+	return nil
+}
+
+// freeSlot returns a master of the center free on the wanted day, or the
+// earliest later day any of them is free.
+func (s *simulator) freeSlot(center *simCenter, wanted time.Time) (int, time.Time) {
+	for day := wanted; ; day = day.AddDate(0, 0, 1) {
+		for _, idx := range utils.Rand.Perm(len(center.masters)) {
+			masterID := center.masters[idx]
+			if !s.busy[masterID][day] {
+				return masterID, day
+			}
+		}
+	}
+}
+
+func (s *simulator) issueReceipt(ctx context.Context, tx pgx.Tx, order *simOrder, day time.Time) error {
+	var totalCost, bonusPoints float64
+	err := tx.QueryRow(ctx, `
+		SELECT o.total_cost, c.bonus_points
+		FROM orders o
+		JOIN customers c ON o.customer_id = c.customer_id
+		WHERE o.order_id = $1`, order.id).Scan(&totalCost, &bonusPoints)
+	if err != nil {
+		return fmt.Errorf("failed to read order %d: %w", order.id, err)
+	}
+
+	var spent float64
+	if utils.Rand.Float64() < s.cfg.BonusSpendRate {
+		spent = math.Floor(min(bonusPoints*(utils.Rand.Float64()*0.9+0.1), totalCost)*100) / 100
+	}
+
+	receiptDate := day.Add(time.Duration(9+utils.Rand.IntN(10))*time.Hour + time.Duration(utils.Rand.IntN(60))*time.Minute)
+	_, err = tx.Exec(ctx, `
+		INSERT INTO receipts (order_id, bonus_points_spent, total_paid, receipt_date)
+		VALUES ($1, $2, $3, $4)`, order.id, spent, totalCost-spent, receiptDate)
+	if err != nil {
+		return fmt.Errorf("failed to insert receipt for order %d: %w", order.id, err)
+	}
+	return nil
+}
+
+func loadCenterStaff(ctx context.Context, db *pgxpool.Pool) ([]*simCenter, error) {
+	rows, err := db.Query(ctx, `
+		SELECT service_center_id, employee_id, employee_role::text
+		FROM employee_service_center
+		WHERE employee_role IN ('Manager', 'Master')
+		ORDER BY service_center_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query staff: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int]*simCenter)
+	var order []int
+	for rows.Next() {
+		var centerID, employeeID int
+		var role string
+		if err := rows.Scan(&centerID, &employeeID, &role); err != nil {
+			return nil, fmt.Errorf("failed to scan staff: %w", err)
+		}
+		center, ok := byID[centerID]
+		if !ok {
+			center = &simCenter{id: centerID}
+			byID[centerID] = center
+			order = append(order, centerID)
+		}
+		if role == "Master" {
+			center.masters = append(center.masters, employeeID)
+		} else {
+			center.managers = append(center.managers, employeeID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during staff loading: %w", err)
+	}
+
+	var centers []*simCenter
+	for _, id := range order {
+		if c := byID[id]; len(c.masters) > 0 && len(c.managers) > 0 {
+			centers = append(centers, c)
+		}
+	}
+	if len(centers) == 0 {
+		return nil, fmt.Errorf("no service center has both a manager and a master")
+	}
+	log.Printf("Simulating %d staffed service centers", len(centers))
+
+	return centers, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func randBetween(p Pair[int]) int {
+	if p.Second <= p.First {
+		return p.First
+	}
+	return p.First + utils.Rand.IntN(p.Second-p.First+1)
+}
+
+// poisson draws from a Poisson distribution (Knuth), fine for the small
+// daily rates used here.
+func poisson(lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	limit := math.Exp(-lambda)
+	k := 0
+	for p := utils.Rand.Float64(); p > limit; p *= utils.Rand.Float64() {
+		k++
+	}
+	return k
+}
@@ -0,0 +1,92 @@
+package gomock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"vehicles-service-stations/internal/gomock"
+	"vehicles-service-stations/internal/testutil"
+)
+
+func TestSimulateOrderHistory(t *testing.T) {
+	d, _ := testutil.NewSeededDatabase(t, "sim")
+	ctx := context.Background()
+
+	// The orders of the seed are left out of the checks.
+	var seeded int
+	if err := d.Pool.QueryRow(ctx, `SELECT COALESCE(max(order_id), 0) FROM orders`).Scan(&seeded); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := gomock.DefaultSimulationConfig(60)
+	cfg.ArrivalsPerDay = 3
+	if err := gomock.SimulateOrderHistory(ctx, d.Pool, cfg, nil); err != nil {
+		t.Fatal(err)
+	}
+	end := cfg.To.Format(time.DateOnly)
+
+	rows, err := d.Pool.Query(ctx, `
+        SELECT o.order_id, o.status::text, o.scheduled_date, r.receipt_date
+        FROM orders o
+        LEFT JOIN receipts r ON r.order_id = o.order_id
+        WHERE o.order_id > $1`, seeded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var orders, completed int
+	for rows.Next() {
+		var (
+			id        int
+			status    string
+			scheduled time.Time
+			receipt   *time.Time
+		)
+		if err := rows.Scan(&id, &status, &scheduled, &receipt); err != nil {
+			t.Fatal(err)
+		}
+		orders++
+		if status != "Completed" {
+			if receipt != nil {
+				t.Errorf("order %d is %s and has a receipt", id, status)
+			}
+			continue
+		}
+		completed++
+		if receipt == nil {
+			t.Errorf("completed order %d has no receipt", id)
+			continue
+		}
+		paid := receipt.In(cfg.To.Location()).Format(time.DateOnly)
+		if paid > end {
+			t.Errorf("order %d is completed on %s, after the end of the simulation %s", id, paid, end)
+		}
+		if day := scheduled.Format(time.DateOnly); paid < day {
+			t.Errorf("order %d is paid on %s, before its scheduled date %s", id, paid, day)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if completed == 0 {
+		t.Fatalf("none of %d simulated orders is completed", orders)
+	}
+
+	// A cancelled order frees its master for the day.
+	var doubleBooked int
+	err = d.Pool.QueryRow(ctx, `
+        SELECT count(*) FROM (
+            SELECT assigned_master_id, scheduled_date
+            FROM orders
+            WHERE order_id > $1 AND status <> 'Cancelled'
+            GROUP BY assigned_master_id, scheduled_date
+            HAVING count(*) > 1
+        ) s`, seeded).Scan(&doubleBooked)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doubleBooked > 0 {
+		t.Errorf("masters have more than one order on %d days", doubleBooked)
+	}
+}
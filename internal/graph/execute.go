@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxDepth bounds the nesting of queries, every level of relations is a
+// query to the database.
+const MaxDepth = 10
+
+type DB interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Execute runs a request as the employee logged in to db, fields their role
+// may not read resolve to null with an error.
+func (s *Schema) Execute(ctx context.Context, db DB, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{
+		Body: []byte(req.Query),
+		Name: "GraphQL request",
+	})})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if v := graphql.ValidateDocument(&s.schema, doc, nil); !v.IsValid {
+		return &graphql.Result{Errors: v.Errors}
+	}
+	if depth := queryDepth(doc); depth > MaxDepth {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query depth %d exceeds %d", depth, MaxDepth))}
+	}
+
+	r := &request{db: db, loaders: make(map[string]*loader)}
+	err = db.QueryRow(ctx, `
+        SELECT current_user, COALESCE(e.employee_id, 0), COALESCE(esc.employee_role::text, '')
+        FROM (SELECT 1) one
+        LEFT JOIN employees e ON e.username = current_user
+        LEFT JOIN employee_service_center esc ON esc.employee_id = e.employee_id
+        LIMIT 1
+    `).Scan(&r.login, &r.me, &r.role)
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("failed to find employee: %w", err))}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, requestKey{}, r),
+	})
+}
+
+// queryDepth returns the deepest nesting of fields in the operations of a
+// validated document, so fragments do not cycle.
+func queryDepth(doc *ast.Document) int {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[f.Name.Value] = f
+		}
+	}
+
+	var depth func(set *ast.SelectionSet) int
+	depth = func(set *ast.SelectionSet) int {
+		if set == nil {
+			return 0
+		}
+		deepest := 0
+		for _, sel := range set.Selections {
+			var d int
+			switch sel := sel.(type) {
+			case *ast.Field:
+				d = 1 + depth(sel.SelectionSet)
+			case *ast.InlineFragment:
+				d = depth(sel.SelectionSet)
+			case *ast.FragmentSpread:
+				if f, ok := fragments[sel.Name.Value]; ok {
+					d = depth(f.SelectionSet)
+				}
+			}
+			deepest = max(deepest, d)
+		}
+		return deepest
+	}
+
+	deepest := 0
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			deepest = max(deepest, depth(op.SelectionSet))
+		}
+	}
+	return deepest
+}
@@ -0,0 +1,180 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/graph"
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// countingDB counts the queries of a GraphQL request.
+type countingDB struct {
+	graph.DB
+	queries int
+}
+
+func (db *countingDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.queries++
+	return db.DB.Query(ctx, sql, args...)
+}
+
+// execute runs a GraphQL request and decodes its data into out, returning
+// the messages of the errors and the number of queries.
+func execute(t *testing.T, s *graph.Schema, db graph.DB, query string, out any) ([]string, int) {
+	t.Helper()
+	counter := &countingDB{DB: db}
+	result := s.Execute(context.Background(), counter, graph.Request{Query: query})
+	var messages []string
+	for _, e := range result.Errors {
+		messages = append(messages, e.Message)
+	}
+	data, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+	return messages, counter.queries
+}
+
+const ordersQuery = `{
+    orders(first: 10) {
+        edges { node {
+            id status
+            customer { name bonusPoints }
+            assignedMaster { name } manager { name }
+            services { name }
+            spareParts { quantity part { name } }
+            receipt { totalPaid }
+        } }
+    }
+}`
+
+type ordersReply struct {
+	Orders struct {
+		Edges []struct {
+			Node struct {
+				ID       int
+				Customer struct {
+					BonusPoints *float64
+				}
+				Services   []struct{ Name string }
+				SpareParts []struct{ Quantity int }
+				Receipt    *struct{ TotalPaid float64 }
+			}
+		}
+	}
+}
+
+// An order comes with its customer, employees, services, parts and receipt in
+// one request, in as many queries for three orders as for one; a master gets
+// null for the fields of managers instead of an error of the database.
+func TestNestedOrders(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "graph")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	catalog := model.NewAllowedTables()
+	if err := catalog.Initialize(ctx, tx, "public"); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := graph.NewSchema(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := testutil.NewBase(t, tx)
+	serviceID := testutil.NewService(t, tx, 1000)
+	partID := testutil.NewPart(t, tx, 10)
+	var orders []int
+	for i := range 3 {
+		// A master takes one pending order a day.
+		orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day.AddDate(0, 0, i))
+		if _, err := tx.Exec(ctx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, serviceID, orderID); err != nil {
+			t.Fatal(err)
+		}
+		_, err = tx.Exec(ctx, `
+            INSERT INTO spare_part_order (part_id, order_id, quantity, purchase_price)
+            VALUES ($1, $2, 1, 100)
+        `, partID, orderID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		orders = append(orders, orderID)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO receipts (order_id, total_paid) VALUES ($1, 1100)`, orders[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("manager", func(t *testing.T) {
+		testutil.LoginAs(t, tx, b.Manager, "manager")
+		var one, all ordersReply
+		query := fmt.Sprintf(`{ order(id: %d) { id customer { name } services { name } spareParts { part { name } } receipt { totalPaid } } }`, orders[0])
+		problems, single := execute(t, schema, tx, query, &one)
+		if len(problems) > 0 {
+			t.Fatalf("order of the manager: %v", problems)
+		}
+		problems, batched := execute(t, schema, tx, ordersQuery, &all)
+		if len(problems) > 0 {
+			t.Fatalf("orders of the manager: %v", problems)
+		}
+		if len(all.Orders.Edges) != 3 {
+			t.Fatalf("manager sees %d orders, want 3", len(all.Orders.Edges))
+		}
+		// A query per relation, not per order: the page, customers,
+		// employees, services, order parts, receipts and spare parts.
+		if batched > 7 || single > 6 {
+			t.Errorf("%d queries for three orders and %d for one, want at most 7 and 6", batched, single)
+		}
+		for _, e := range all.Orders.Edges {
+			n := e.Node
+			if len(n.Services) != 1 || len(n.SpareParts) != 1 || n.Customer.BonusPoints == nil {
+				t.Errorf("order %d is %+v", n.ID, n)
+			}
+			if (n.Receipt != nil) != (n.ID == orders[0]) {
+				t.Errorf("order %d has receipt %+v", n.ID, n.Receipt)
+			}
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		testutil.LoginAs(t, tx, b.Manager, "manager")
+		var cursor struct {
+			Orders struct {
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+		}
+		execute(t, schema, tx, `{ orders(first: 2) { pageInfo { hasNextPage endCursor } } }`, &cursor)
+		if !cursor.Orders.PageInfo.HasNextPage {
+			t.Fatal("first page of two orders has no next page")
+		}
+		var page ordersReply
+		execute(t, schema, tx, fmt.Sprintf(`{ orders(first: 2, after: %q) { edges { node { id } } } }`, cursor.Orders.PageInfo.EndCursor), &page)
+		if len(page.Orders.Edges) != 1 || page.Orders.Edges[0].Node.ID != orders[0] {
+			t.Errorf("second page is %+v, want order %d", page.Orders.Edges, orders[0])
+		}
+	})
+
+	t.Run("master", func(t *testing.T) {
+		testutil.LoginAs(t, tx, b.Masters[0], "master")
+		var master ordersReply
+		problems, _ := execute(t, schema, tx, ordersQuery, &master)
+		if len(master.Orders.Edges) != 3 || len(problems) == 0 {
+			t.Fatalf("master sees %d orders with errors %v, want 3 with errors", len(master.Orders.Edges), problems)
+		}
+		for _, e := range master.Orders.Edges {
+			if e.Node.Receipt != nil || e.Node.Customer.BonusPoints != nil {
+				t.Errorf("master sees the receipt or bonus points of order %d", e.Node.ID)
+			}
+		}
+	})
+}
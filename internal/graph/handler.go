@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"vehicles-service-stations/internal/api"
+)
+
+const maxBodySize = 1 << 20
+
+// Handler serves the schema over HTTP. Employees send their database login
+// and password with basic auth and the queries run as them.
+type Handler struct {
+	schema *Schema
+	auth   api.Authenticator
+}
+
+func NewHandler(schema *Schema, auth api.Authenticator) *Handler {
+	return &Handler{schema: schema, auth: auth}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				http.Error(w, "invalid variables", http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if req.Query == "" {
+		http.Error(w, "missing query", http.StatusBadRequest)
+		return
+	}
+
+	login, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="stations"`)
+		http.Error(w, "missing credentials", http.StatusUnauthorized)
+		return
+	}
+	db, release, err := h.auth.Authenticate(r.Context(), login, password)
+	if errors.Is(err, api.ErrBadCredentials) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="stations"`)
+		http.Error(w, "invalid login or password", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Login of %q failed: %v", login, err)
+		http.Error(w, "failed to log in", http.StatusServiceUnavailable)
+		return
+	}
+
+	defer release()
+
+	result := h.schema.Execute(r.Context(), db, req)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		log.Printf("Failed to write the result: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package graph
+
+import "sync"
+
+// loader batches the keys asked for while a level of the query is resolved
+// into one fetch: resolvers return thunks, the executor calls them once the
+// level is done and the first call fetches every key asked for so far.
+type loader struct {
+	mu      sync.Mutex
+	fetch   func(keys []int) (map[int][]any, error)
+	pending []int
+	queued  map[int]bool
+	done    map[int][]any
+	errs    map[int]error
+}
+
+func newLoader(fetch func(keys []int) (map[int][]any, error)) *loader {
+	return &loader{
+		fetch:  fetch,
+		queued: make(map[int]bool),
+		done:   make(map[int][]any),
+		errs:   make(map[int]error),
+	}
+}
+
+func (l *loader) load(key int) func() ([]any, error) {
+	l.mu.Lock()
+	if _, ok := l.done[key]; !ok && !l.queued[key] {
+		l.pending = append(l.pending, key)
+		l.queued[key] = true
+	}
+	l.mu.Unlock()
+
+	return func() ([]any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.queued[key] {
+			l.flush()
+		}
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		return l.done[key], nil
+	}
+}
+
+func (l *loader) flush() {
+	keys := l.pending
+	l.pending = nil
+	rows, err := l.fetch(keys)
+	for _, key := range keys {
+		delete(l.queued, key)
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.done[key] = rows[key]
+	}
+}
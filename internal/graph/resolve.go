@@ -0,0 +1,378 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/query"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// request is the state of one query: the connection of the employee, their
+// role and the loaders.
+type request struct {
+	db      DB
+	login   string
+	role    string
+	me      int
+	loaders map[string]*loader
+}
+
+type requestKey struct{}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// allow checks the role of the caller against the roles of a field.
+func allow(ctx context.Context, roles []string) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	r := requestFrom(ctx)
+	if !slices.Contains(roles, r.role) {
+		role := r.role
+		if role == "" {
+			role = "none"
+		}
+		return fmt.Errorf("not allowed for role %s", role)
+	}
+	return nil
+}
+
+func (r *request) loader(name string, fetch func(keys []int) (map[int][]any, error)) *loader {
+	l, ok := r.loaders[name]
+	if !ok {
+		l = newLoader(fetch)
+		r.loaders[name] = l
+	}
+	return l
+}
+
+func (s *Schema) resolveColumn(c column) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		if err := allow(p.Context, c.roles); err != nil {
+			return nil, err
+		}
+		v := reflect.ValueOf(p.Source).Elem().Field(c.index)
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil, nil
+			}
+			v = v.Elem()
+		}
+		return v.Interface(), nil
+	}
+}
+
+func (s *Schema) resolveRelation(e *entity, rel relation) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		if err := allow(p.Context, rel.roles); err != nil {
+			return nil, err
+		}
+		ctx, r := p.Context, requestFrom(p.Context)
+		target := rel.target
+
+		var key int
+		var ok bool
+		var l *loader
+		switch rel.kind {
+		case relRef:
+			key, ok = e.key(p.Source, rel.column)
+			if !ok {
+				return nil, nil
+			}
+			l = r.loader("pk:"+target.table, func(keys []int) (map[int][]any, error) {
+				return s.fetchBy(ctx, r.db, target, target.pk, keys)
+			})
+		case relBack:
+			key, _ = e.key(p.Source, e.pk)
+			l = r.loader("back:"+target.table+"."+rel.column, func(keys []int) (map[int][]any, error) {
+				return s.fetchBy(ctx, r.db, target, rel.column, keys)
+			})
+		case relVia:
+			key, _ = e.key(p.Source, e.pk)
+			l = r.loader("via:"+rel.join+"."+rel.column, func(keys []int) (map[int][]any, error) {
+				return s.fetchVia(ctx, r.db, target, rel.join, rel.column, keys)
+			})
+		}
+
+		thunk := l.load(key)
+		return func() (any, error) {
+			rows, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			if rel.many {
+				if rows == nil {
+					return []any{}, nil
+				}
+				return rows, nil
+			}
+			if len(rows) == 0 {
+				return nil, nil
+			}
+			return rows[0], nil
+		}, nil
+	}
+}
+
+// fetchBy loads the rows of an entity whose column is one of the keys.
+func (s *Schema) fetchBy(ctx context.Context, db model.Querier, e *entity, col string, keys []int) (map[int][]any, error) {
+	b := query.Select(s.catalog, e.table, e.selectColumns()...).
+		Where(query.Filter{Column: e.table + "." + col, Op: query.OpIn, Value: keys})
+	if e.pk != "" {
+		b = b.OrderBy(e.table+"."+e.pk, false)
+	}
+	return s.collect(ctx, db, e, b, func(rows pgx.Rows) (any, int, error) {
+		v, dest := e.scanDest()
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, err
+		}
+		key, _ := e.key(v.Interface(), col)
+		return v.Interface(), key, nil
+	})
+}
+
+// fetchVia loads the rows of an entity joined to the keys by a join table.
+func (s *Schema) fetchVia(ctx context.Context, db model.Querier, e *entity, join, col string, keys []int) (map[int][]any, error) {
+	b := query.Select(s.catalog, e.table, append(e.selectColumns(), join+"."+col)...).
+		Join(query.Join{Type: query.InnerJoin, Table: join}).
+		Where(query.Filter{Column: join + "." + col, Op: query.OpIn, Value: keys})
+	if e.pk != "" {
+		b = b.OrderBy(e.table+"."+e.pk, false)
+	}
+	return s.collect(ctx, db, e, b, func(rows pgx.Rows) (any, int, error) {
+		var key int
+		v, dest := e.scanDest(&key)
+		return v.Interface(), key, rows.Scan(dest...)
+	})
+}
+
+// collect runs a fetch of the loaders, grouping the rows by their key.
+func (s *Schema) collect(ctx context.Context, db model.Querier, e *entity, b *query.SelectBuilder, scan func(rows pgx.Rows) (any, int, error)) (map[int][]any, error) {
+	sql, args, err := b.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", e.table, err)
+	}
+	defer rows.Close()
+
+	byKey := make(map[int][]any)
+	for rows.Next() {
+		v, key, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", e.table, err)
+		}
+		byKey[key] = append(byKey[key], v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", e.table, err)
+	}
+	return byKey, nil
+}
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		"endCursor":   &graphql.Field{Type: graphql.String},
+	},
+})
+
+// connection is a page of a root list, the cursor of a node is its primary
+// key: pages go from the newest rows to the oldest.
+type connection struct {
+	Edges    []edge         `json:"edges"`
+	PageInfo map[string]any `json:"pageInfo"`
+}
+
+type edge struct {
+	Cursor string `json:"cursor"`
+	Node   any    `json:"node"`
+}
+
+func (s *Schema) connectionType(e *entity) *graphql.Object {
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: e.typ.Name() + "Edge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"node":   &graphql.Field{Type: graphql.NewNonNull(e.object)},
+		},
+	})
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: e.typ.Name() + "Connection",
+		Fields: graphql.Fields{
+			"edges":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+}
+
+func encodeCursor(table string, id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(table + ":" + strconv.Itoa(id)))
+}
+
+func decodeCursor(table, cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		if id, ok := strings.CutPrefix(string(raw), table+":"); ok {
+			if n, err := strconv.Atoi(id); err == nil {
+				return n, nil
+			}
+		}
+	}
+	return 0, errors.New("invalid cursor")
+}
+
+// page resolves a root list of an entity with the filters.
+func (s *Schema) page(p graphql.ResolveParams, e *entity, filters ...query.Filter) (any, error) {
+	first, _ := p.Args["first"].(int)
+	if first <= 0 || first > maxPageSize {
+		return nil, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+	}
+	b := query.Select(s.catalog, e.table, e.selectColumns()...).
+		Where(filters...).
+		OrderBy(e.table+"."+e.pk, true).
+		Limit(uint64(first + 1))
+	if after, ok := p.Args["after"].(string); ok {
+		id, err := decodeCursor(e.table, after)
+		if err != nil {
+			return nil, err
+		}
+		b = b.Where(query.Filter{Column: e.table + "." + e.pk, Op: query.OpLt, Value: id})
+	}
+
+	sql, args, err := b.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := requestFrom(p.Context).db.Query(p.Context, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", e.table, err)
+	}
+	nodes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (any, error) {
+		v, dest := e.scanDest()
+		return v.Interface(), row.Scan(dest...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", e.table, err)
+	}
+
+	c := &connection{Edges: []edge{}, PageInfo: map[string]any{"hasNextPage": len(nodes) > first}}
+	for _, n := range nodes[:min(first, len(nodes))] {
+		id, _ := e.key(n, e.pk)
+		c.Edges = append(c.Edges, edge{Cursor: encodeCursor(e.table, id), Node: n})
+	}
+	if len(c.Edges) > 0 {
+		c.PageInfo["endCursor"] = c.Edges[len(c.Edges)-1].Cursor
+	}
+	return c, nil
+}
+
+func (s *Schema) byID(e *entity) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		r := requestFrom(p.Context)
+		thunk := r.loader("pk:"+e.table, func(keys []int) (map[int][]any, error) {
+			return s.fetchBy(p.Context, r.db, e, e.pk, keys)
+		}).load(p.Args["id"].(int))
+		return func() (any, error) {
+			rows, err := thunk()
+			if err != nil || len(rows) == 0 {
+				return nil, err
+			}
+			return rows[0], nil
+		}, nil
+	}
+}
+
+func (s *Schema) queryType() *graphql.Object {
+	orders, customers, employees := s.byTable["orders"], s.byTable["customers"], s.byTable["employees"]
+	pageArgs := func(extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+			"after": &graphql.ArgumentConfig{Type: graphql.String},
+		}
+		for name, arg := range extra {
+			args[name] = arg
+		}
+		return args
+	}
+	idArgs := graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}}
+
+	meType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Me",
+		Fields: graphql.Fields{
+			"login": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return requestFrom(p.Context).login, nil
+			}},
+			"role": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+				if role := requestFrom(p.Context).role; role != "" {
+					return role, nil
+				}
+				return nil, nil
+			}},
+			"employee": &graphql.Field{Type: employees.object, Resolve: func(p graphql.ResolveParams) (any, error) {
+				me := requestFrom(p.Context).me
+				if me == 0 {
+					return nil, nil
+				}
+				return s.byID(employees)(graphql.ResolveParams{Context: p.Context, Args: map[string]any{"id": me}})
+			}},
+		},
+	})
+
+	status, _ := orders.column("status")
+	statusType := s.scalar(orders, status).(graphql.Input)
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"me": &graphql.Field{Type: graphql.NewNonNull(meType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return struct{}{}, nil
+			}},
+			"order":    &graphql.Field{Type: orders.object, Args: idArgs, Resolve: s.byID(orders)},
+			"customer": &graphql.Field{Type: customers.object, Args: idArgs, Resolve: s.byID(customers)},
+			"orders": &graphql.Field{
+				Type: s.connectionType(orders),
+				Args: pageArgs(graphql.FieldConfigArgument{
+					"status":          &graphql.ArgumentConfig{Type: statusType},
+					"serviceCenterId": &graphql.ArgumentConfig{Type: graphql.Int},
+				}),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					var filters []query.Filter
+					if status, ok := p.Args["status"].(string); ok {
+						filters = append(filters, query.Eq("orders.status", status))
+					}
+					if center, ok := p.Args["serviceCenterId"].(int); ok {
+						filters = append(filters, query.Eq("orders.service_center_id", center))
+					}
+					return s.page(p, orders, filters...)
+				},
+			},
+			"customers": &graphql.Field{
+				Type: s.connectionType(customers),
+				Args: pageArgs(nil),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return s.page(p, customers)
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,348 @@
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"vehicles-service-stations/internal/query"
+)
+
+type relationKind int
+
+const (
+	relRef relationKind = iota
+	relBack
+	relVia
+)
+
+type column struct {
+	name  string
+	index int
+	// gql is empty for columns only loaded for relations.
+	gql   string
+	roles []string
+}
+
+type relation struct {
+	kind   relationKind
+	gql    string
+	index  int
+	target *entity
+	many   bool
+	roles  []string
+	// column is the referencing column: of this table for ref, of the
+	// target table for back and of the join table for via.
+	column string
+	join   string
+}
+
+type entity struct {
+	table     string
+	pk        string
+	typ       reflect.Type
+	columns   []column
+	relations []relation
+	object    *graphql.Object
+}
+
+func (e *entity) column(name string) (column, bool) {
+	for _, c := range e.columns {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+// selectColumns returns the columns of the entity qualified by its table.
+func (e *entity) selectColumns() []string {
+	names := make([]string, len(e.columns))
+	for i, c := range e.columns {
+		names[i] = e.table + "." + c.name
+	}
+	return names
+}
+
+// scanDest returns a new value of the entity and the pointers its columns
+// are scanned into, followed by extra.
+func (e *entity) scanDest(extra ...any) (reflect.Value, []any) {
+	v := reflect.New(e.typ)
+	dest := make([]any, 0, len(e.columns)+len(extra))
+	for _, c := range e.columns {
+		dest = append(dest, v.Elem().Field(c.index).Addr().Interface())
+	}
+	return v, append(dest, extra...)
+}
+
+// key returns the int value of a column of a loaded entity, false for NULL.
+func (e *entity) key(v any, name string) (int, bool) {
+	c, _ := e.column(name)
+	f := reflect.ValueOf(v).Elem().Field(c.index)
+	if f.Kind() == reflect.Pointer {
+		if f.IsNil() {
+			return 0, false
+		}
+		f = f.Elem()
+	}
+	return int(f.Int()), true
+}
+
+// Schema is the GraphQL schema built from the domain types.
+type Schema struct {
+	catalog  query.Catalog
+	entities map[reflect.Type]*entity
+	byTable  map[string]*entity
+	enums    map[string]*graphql.Enum
+	schema   graphql.Schema
+}
+
+// NewSchema derives the schema from the domain types, checking every table,
+// column and relation of them against the catalog.
+func NewSchema(catalog query.Catalog) (*Schema, error) {
+	s := &Schema{
+		catalog:  catalog,
+		entities: make(map[reflect.Type]*entity),
+		byTable:  make(map[string]*entity),
+		enums:    make(map[string]*graphql.Enum),
+	}
+	for _, def := range entities {
+		e := &entity{table: def.table, typ: reflect.TypeOf(def.typ)}
+		s.entities[e.typ] = e
+		s.byTable[e.table] = e
+	}
+	for _, def := range entities {
+		if err := s.inspect(s.byTable[def.table]); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range s.byTable {
+		for _, rel := range e.relations {
+			if _, ok := rel.target.column(rel.column); rel.kind == relBack && !ok {
+				return nil, fmt.Errorf("%s.%s: column %s is not a field of %s", e.typ.Name(), rel.gql, rel.column, rel.target.typ.Name())
+			}
+		}
+	}
+	for _, def := range entities {
+		s.buildObject(s.byTable[def.table])
+	}
+
+	var err error
+	s.schema, err = graphql.NewSchema(graphql.SchemaConfig{Query: s.queryType()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build schema: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Schema) inspect(e *entity) error {
+	t, ok := s.catalog.Table(e.table)
+	if !ok || t.IsView() {
+		return fmt.Errorf("%s: no table %s", e.typ.Name(), e.table)
+	}
+	if len(t.PrimaryKey) == 1 {
+		e.pk = t.PrimaryKey[0]
+	}
+
+	for i := 0; i < e.typ.NumField(); i++ {
+		f := e.typ.Field(i)
+		var roles []string
+		if r := f.Tag.Get("roles"); r != "" {
+			roles = strings.Split(r, ",")
+		}
+		if name, ok := f.Tag.Lookup("db"); ok {
+			col, ok := t.Column(name)
+			if !ok {
+				return fmt.Errorf("%s.%s: no column %s.%s", e.typ.Name(), f.Name, e.table, name)
+			}
+			if col.Nullable && f.Type.Kind() != reflect.Pointer {
+				return fmt.Errorf("%s.%s: column %s.%s is nullable", e.typ.Name(), f.Name, e.table, name)
+			}
+			e.columns = append(e.columns, column{name: name, index: i, gql: f.Tag.Get("gql"), roles: roles})
+			continue
+		}
+		if _, ok := f.Tag.Lookup("gql"); ok {
+			rel, err := s.relation(e, f, roles)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", e.typ.Name(), f.Name, err)
+			}
+			rel.index = i
+			e.relations = append(e.relations, rel)
+		}
+	}
+	if e.pk != "" {
+		if _, ok := e.column(e.pk); !ok {
+			return fmt.Errorf("%s: primary key %s is not a field", e.typ.Name(), e.pk)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) relation(e *entity, f reflect.StructField, roles []string) (relation, error) {
+	rel := relation{gql: f.Tag.Get("gql"), roles: roles}
+	typ := f.Type
+	if typ.Kind() == reflect.Slice {
+		rel.many = true
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Pointer || s.entities[typ.Elem()] == nil {
+		return rel, fmt.Errorf("%s is not a domain type", f.Type)
+	}
+	rel.target = s.entities[typ.Elem()]
+	target := rel.target
+
+	switch {
+	case f.Tag.Get("ref") != "":
+		rel.kind, rel.column = relRef, f.Tag.Get("ref")
+		if rel.many {
+			return rel, fmt.Errorf("ref relations are single")
+		}
+		if !s.references(e.table, rel.column, target.table) {
+			return rel, fmt.Errorf("no foreign key %s.%s to %s", e.table, rel.column, target.table)
+		}
+		if _, ok := e.column(rel.column); !ok {
+			return rel, fmt.Errorf("column %s is not a field", rel.column)
+		}
+		if pk, _ := s.primaryKey(target.table); pk == "" {
+			return rel, fmt.Errorf("%s has no single column primary key", target.table)
+		}
+	case f.Tag.Get("back") != "":
+		rel.kind, rel.column = relBack, f.Tag.Get("back")
+		if !s.references(target.table, rel.column, e.table) {
+			return rel, fmt.Errorf("no foreign key %s.%s to %s", target.table, rel.column, e.table)
+		}
+		// The column is checked to be a field of the target once the
+		// target is inspected, see NewSchema.
+	case f.Tag.Get("via") != "":
+		rel.kind, rel.join = relVia, f.Tag.Get("via")
+		if !rel.many {
+			return rel, fmt.Errorf("via relations are lists")
+		}
+		t, ok := s.catalog.Table(rel.join)
+		if !ok {
+			return rel, fmt.Errorf("no table %s", rel.join)
+		}
+		for _, fk := range t.ForeignKeysTo(e.table) {
+			if len(fk.Columns) == 1 {
+				rel.column = fk.Columns[0]
+			}
+		}
+		if rel.column == "" || len(t.ForeignKeysTo(target.table)) != 1 {
+			return rel, fmt.Errorf("%s does not join %s to %s", rel.join, e.table, target.table)
+		}
+	default:
+		return rel, fmt.Errorf("relation needs ref, back or via")
+	}
+	if rel.kind != relRef {
+		if pk, _ := s.primaryKey(e.table); pk == "" {
+			return rel, fmt.Errorf("%s has no single column primary key", e.table)
+		}
+	}
+	return rel, nil
+}
+
+func (s *Schema) primaryKey(table string) (string, bool) {
+	t, ok := s.catalog.Table(table)
+	if !ok || len(t.PrimaryKey) != 1 {
+		return "", false
+	}
+	return t.PrimaryKey[0], true
+}
+
+// references tells whether table.column is a foreign key to ref.
+func (s *Schema) references(table, column, ref string) bool {
+	t, ok := s.catalog.Table(table)
+	if !ok {
+		return false
+	}
+	for _, fk := range t.ForeignKeysTo(ref) {
+		if slices.Equal(fk.Columns, []string{column}) {
+			return true
+		}
+	}
+	return false
+}
+
+// scalar returns the GraphQL type of a column, enums of the schema become
+// GraphQL enums.
+func (s *Schema) scalar(e *entity, c column) graphql.Output {
+	t, _ := s.catalog.Table(e.table)
+	col, _ := t.Column(c.name)
+	if enum, ok := s.catalog.Enum(col.UDTName); ok {
+		return s.enum(enum.Name, enum.Values)
+	}
+
+	typ := e.typ.Field(c.index).Type
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		return graphql.DateTime
+	case typ.Kind() == reflect.Int:
+		return graphql.Int
+	case typ.Kind() == reflect.Float64:
+		return graphql.Float
+	case typ.Kind() == reflect.Bool:
+		return graphql.Boolean
+	default:
+		return graphql.String
+	}
+}
+
+// enum returns the GraphQL enum of a schema enum: order_status becomes
+// OrderStatus with the value 'In Progress' as IN_PROGRESS.
+func (s *Schema) enum(name string, values []string) *graphql.Enum {
+	if e, ok := s.enums[name]; ok {
+		return e
+	}
+	config := graphql.EnumValueConfigMap{}
+	for _, v := range values {
+		config[strings.ToUpper(strings.Join(strings.Fields(v), "_"))] = &graphql.EnumValueConfig{Value: v}
+	}
+	e := graphql.NewEnum(graphql.EnumConfig{Name: camel(name), Values: config})
+	s.enums[name] = e
+	return e
+}
+
+func camel(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+func (s *Schema) buildObject(e *entity) {
+	e.object = graphql.NewObject(graphql.ObjectConfig{
+		Name: e.typ.Name(),
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := graphql.Fields{}
+			for _, c := range e.columns {
+				if c.gql == "" {
+					continue
+				}
+				var typ graphql.Output = s.scalar(e, c)
+				// Fields some roles may not read are null for them.
+				if e.typ.Field(c.index).Type.Kind() != reflect.Pointer && len(c.roles) == 0 {
+					typ = graphql.NewNonNull(typ)
+				}
+				fields[c.gql] = &graphql.Field{Type: typ, Resolve: s.resolveColumn(c)}
+			}
+			for _, rel := range e.relations {
+				var typ graphql.Output = rel.target.object
+				if rel.many {
+					typ = graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(typ)))
+				}
+				fields[rel.gql] = &graphql.Field{Type: typ, Resolve: s.resolveRelation(e, rel)}
+			}
+			return fields
+		}),
+	})
+}
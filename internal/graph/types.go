@@ -0,0 +1,111 @@
+package graph
+
+import "time"
+
+// The domain types are the schema. Fields with a db tag are columns of the
+// table of the type, those with a gql tag as well are fields of the GraphQL
+// type. The other fields declare relations:
+//
+//	ref:"column"   the column of this table references the related type
+//	back:"column"  the column of the related table references this type
+//	via:"table"    a join table references both types
+//
+// roles lists the employee roles allowed to read a field, everybody may
+// when it is empty. Row level security applies on top of it.
+
+type ServiceCenter struct {
+	ID         int    `db:"service_center_id" gql:"id"`
+	Address    string `db:"full_address" gql:"address"`
+	City       string `db:"city" gql:"city"`
+	PostalCode string `db:"postal_code" gql:"postalCode"`
+	Phone      string `db:"phone_number" gql:"phone"`
+}
+
+type Employee struct {
+	ID         int     `db:"employee_id" gql:"id"`
+	Name       string  `db:"full_name" gql:"name"`
+	Experience int     `db:"experience" gql:"experience"`
+	Age        int     `db:"age" gql:"age" roles:"Administrator"`
+	Salary     float64 `db:"salary" gql:"salary" roles:"Administrator"`
+	Username   string  `db:"username" gql:"username" roles:"Administrator"`
+}
+
+type Customer struct {
+	ID            int      `db:"customer_id" gql:"id"`
+	Name          string   `db:"full_name" gql:"name"`
+	Phone         string   `db:"phone_number" gql:"phone" roles:"Administrator,Manager,Master"`
+	LoyaltyStatus string   `db:"loyalty_status" gql:"loyaltyStatus"`
+	SpentMoney    float64  `db:"spent_money" gql:"spentMoney" roles:"Administrator,Analyst,Manager"`
+	BonusPoints   float64  `db:"bonus_points" gql:"bonusPoints" roles:"Administrator,Analyst,Manager"`
+	Orders        []*Order `gql:"orders" back:"customer_id"`
+}
+
+type Service struct {
+	ID          int     `db:"service_id" gql:"id"`
+	Name        string  `db:"full_name" gql:"name"`
+	Description *string `db:"description" gql:"description"`
+	VehicleType string  `db:"vehicle_type" gql:"vehicleType"`
+	Price       float64 `db:"price" gql:"price"`
+}
+
+type SparePart struct {
+	ID          int     `db:"part_id" gql:"id"`
+	Name        string  `db:"name" gql:"name"`
+	Article     int     `db:"article_number" gql:"article"`
+	Description *string `db:"description" gql:"description"`
+	Price       float64 `db:"price" gql:"price"`
+	Stock       int     `db:"stock_quantity" gql:"stock"`
+}
+
+// OrderPart is a spare part bought for an order.
+type OrderPart struct {
+	OrderID       int        `db:"order_id"`
+	PartID        int        `db:"part_id"`
+	Part          *SparePart `gql:"part" ref:"part_id"`
+	Quantity      int        `db:"quantity" gql:"quantity"`
+	PurchasePrice float64    `db:"purchase_price" gql:"purchasePrice"`
+}
+
+type Receipt struct {
+	ID               int       `db:"receipt_id" gql:"id"`
+	OrderID          int       `db:"order_id"`
+	BonusPointsSpent float64   `db:"bonus_points_spent" gql:"bonusPointsSpent"`
+	TotalPaid        float64   `db:"total_paid" gql:"totalPaid"`
+	Date             time.Time `db:"receipt_date" gql:"date"`
+}
+
+type Order struct {
+	ID                 int            `db:"order_id" gql:"id"`
+	CustomerID         int            `db:"customer_id"`
+	CenterID           int            `db:"service_center_id"`
+	ManagerID          int            `db:"manager_id"`
+	AssignedMasterID   int            `db:"assigned_master_id"`
+	ReassignedMasterID *int           `db:"reassigned_master_id"`
+	CreationDate       time.Time      `db:"creation_date" gql:"creationDate"`
+	ScheduledDate      time.Time      `db:"scheduled_date" gql:"scheduledDate"`
+	Status             *string        `db:"status" gql:"status"`
+	TotalCost          *float64       `db:"total_cost" gql:"totalCost"`
+	Customer           *Customer      `gql:"customer" ref:"customer_id"`
+	ServiceCenter      *ServiceCenter `gql:"serviceCenter" ref:"service_center_id"`
+	Manager            *Employee      `gql:"manager" ref:"manager_id"`
+	AssignedMaster     *Employee      `gql:"assignedMaster" ref:"assigned_master_id"`
+	ReassignedMaster   *Employee      `gql:"reassignedMaster" ref:"reassigned_master_id"`
+	Services           []*Service     `gql:"services" via:"service_order"`
+	SpareParts         []*OrderPart   `gql:"spareParts" back:"order_id"`
+	Receipt            *Receipt       `gql:"receipt" back:"order_id" roles:"Administrator,Analyst,Manager"`
+}
+
+// entities are the types of the schema and their tables.
+var entities = []struct {
+	table string
+	typ   any
+}{
+	{"service_centers", ServiceCenter{}},
+	{"employees", Employee{}},
+	{"customers", Customer{}},
+	{"services", Service{}},
+	{"spare_parts", SparePart{}},
+	{"spare_part_order", OrderPart{}},
+	{"receipts", Receipt{}},
+	{"orders", Order{}},
+}
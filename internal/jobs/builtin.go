@@ -0,0 +1,128 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"vehicles-service-stations/internal/check"
+	"vehicles-service-stations/internal/dashboard"
+)
+
+type BuiltinOptions struct {
+	// ReportsDir is where daily_report writes its reports.
+	ReportsDir string
+	// LowStock is the stock quantity parts are reported below.
+	LowStock int
+}
+
+// Builtin returns the jobs of the service stations.
+func Builtin(opts BuiltinOptions) []Job {
+	return []Job{
+		{Name: "bonus_expiry", Schedule: "0 0 * * *", Run: BonusExpiry},
+		{Name: "invariant_checks", Schedule: "30 2 * * *", Timeout: 30 * time.Minute, Run: InvariantChecks},
+		{Name: "daily_report", Schedule: "0 6 * * *", Run: DailyReport(opts.ReportsDir, opts.LowStock)},
+		{Name: "order_reminders", Schedule: "0 9 * * *", Run: OrderReminders},
+	}
+}
+
+// BonusExpiry resets the bonus points of customers without a purchase for
+// a year, the job pg_cron used to run.
+func BonusExpiry(ctx context.Context, db DB) (string, error) {
+	if _, err := db.Exec(ctx, `SELECT reset_inactive_bonus_points()`); err != nil {
+		return "", fmt.Errorf("failed to reset bonus points: %w", err)
+	}
+	return "", nil
+}
+
+// InvariantChecks checks the invariants of internal/check without repairing
+// them, the run fails when some are violated. The output is the report.
+func InvariantChecks(ctx context.Context, db DB) (string, error) {
+	report, err := check.Run(ctx, db, check.Options{SampleLimit: 5})
+	if err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(report)
+	if err != nil {
+		return "", err
+	}
+	var violated []string
+	for _, res := range report.Results {
+		if res.Remaining > 0 {
+			violated = append(violated, fmt.Sprintf("%s (%d)", res.Invariant, res.Remaining))
+		}
+	}
+	if len(violated) > 0 {
+		return string(out), fmt.Errorf("invariants violated: %s", strings.Join(violated, ", "))
+	}
+	return string(out), nil
+}
+
+// OrderReminders enqueues order.reminder_due events for the orders due
+// tomorrow, cmd/notify sends them. An order is reminded of once per date.
+func OrderReminders(ctx context.Context, db DB) (string, error) {
+	tag, err := db.Exec(ctx, `
+        SELECT outbox.enqueue('order', o.order_id::TEXT, 'order.reminder_due', 1, jsonb_build_object(
+            'order_id', o.order_id,
+            'scheduled_date', o.scheduled_date
+        ))
+        FROM orders o
+        WHERE o.scheduled_date = CURRENT_DATE + 1
+          AND o.status IN ('Pending', 'In Progress')
+          AND NOT EXISTS (
+              SELECT 1
+              FROM outbox.events e
+              WHERE e.aggregate_type = 'order'
+                AND e.aggregate_id = o.order_id::TEXT
+                AND e.event_type = 'order.reminder_due'
+                AND e.payload ->> 'scheduled_date' = o.scheduled_date::TEXT
+          )
+    `)
+	if err != nil {
+		return "", fmt.Errorf("failed to enqueue reminders: %w", err)
+	}
+	return fmt.Sprintf("%d reminders enqueued", tag.RowsAffected()), nil
+}
+
+// DailyReport writes the dashboard of yesterday over every center as JSON
+// to dir, the output is the path of the report.
+func DailyReport(dir string, lowStock int) func(ctx context.Context, db DB) (string, error) {
+	return func(ctx context.Context, db DB) (string, error) {
+		now := time.Now()
+		yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+		data, err := dashboard.Load(ctx, db, dashboard.Filter{From: yesterday, To: yesterday, LowStock: max(lowStock, 1)})
+		if err != nil {
+			return "", err
+		}
+		out, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			return "", err
+		}
+
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+		path := filepath.Join(dir, "report-"+yesterday.Format(time.DateOnly)+".json")
+		// Readers never see a partial report.
+		tmp, err := os.CreateTemp(dir, ".report-*")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(tmp.Name())
+		if _, err := tmp.Write(append(out, '\n')); err != nil {
+			tmp.Close()
+			return "", err
+		}
+		if err := tmp.Close(); err != nil {
+			return "", err
+		}
+		if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+			return "", err
+		}
+		return path, os.Rename(tmp.Name(), path)
+	}
+}
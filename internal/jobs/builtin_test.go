@@ -0,0 +1,146 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/dashboard"
+	"vehicles-service-stations/internal/jobs"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// txDB runs jobs inside the transaction of the test, their transactions
+// become savepoints.
+type txDB struct {
+	pgx.Tx
+}
+
+func (db txDB) BeginTx(ctx context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	return db.Begin(ctx)
+}
+
+// The bonus points of a customer without a purchase for a year are reset,
+// those of a recent customer are kept.
+func TestBonusExpiry(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "jobs")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	inactive := testutil.NewCustomer(t, tx, 0, 50)
+	recent := testutil.NewCustomer(t, tx, 0, 50)
+	_, err := tx.Exec(ctx, `
+        UPDATE customers SET last_bonus_charge_date = CURRENT_TIMESTAMP - INTERVAL '2 years'
+        WHERE customer_id = $1
+    `, inactive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := jobs.BonusExpiry(ctx, txDB{tx}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		customer int
+		want     float64
+	}{
+		{"inactive", inactive, 0},
+		{"recent", recent, 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bonus float64
+			if err := tx.QueryRow(ctx, `SELECT bonus_points FROM customers WHERE customer_id = $1`, tt.customer).Scan(&bonus); err != nil {
+				t.Fatal(err)
+			}
+			if bonus != tt.want {
+				t.Errorf("bonus points %v, want %v", bonus, tt.want)
+			}
+		})
+	}
+}
+
+// An order due tomorrow gets one reminder however often the job runs, a
+// completed one gets none.
+func TestOrderRemindersOnce(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "jobs")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	var tomorrow time.Time
+	if err := tx.QueryRow(ctx, `SELECT CURRENT_DATE + 1`).Scan(&tomorrow); err != nil {
+		t.Fatal(err)
+	}
+	due := b.Order(t, tx, b.Customer, b.Masters[0], tomorrow)
+	done := b.Order(t, tx, b.Customer, b.Masters[1], tomorrow)
+	if _, err := tx.Exec(ctx, `UPDATE orders SET status = 'Completed' WHERE order_id = $1`, done); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if _, err := jobs.OrderReminders(ctx, txDB{tx}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name  string
+		order int
+		want  int
+	}{
+		{"due", due, 1},
+		{"completed", done, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := 0
+			for _, m := range testutil.OrderOutbox(t, tx, tt.order) {
+				if m.Type == "order.reminder_due" {
+					n++
+				}
+			}
+			if n != tt.want {
+				t.Errorf("order %d got %d reminders, want %d", tt.order, n, tt.want)
+			}
+		})
+	}
+}
+
+// The report of yesterday counts the completed orders of the center.
+func TestDailyReport(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "jobs")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	now := time.Now()
+	yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, time.UTC)
+	orderID := b.Order(t, tx, b.Customer, b.Masters[0], yesterday)
+	if _, err := tx.Exec(ctx, `UPDATE orders SET status = 'Completed', total_cost = 1000 WHERE order_id = $1`, orderID); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := jobs.DailyReport(t.TempDir(), 1)(ctx, txDB{tx})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data dashboard.Data
+	if err := json.Unmarshal(raw, &data); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	for _, kpi := range data.CenterKPIs {
+		if kpi.ID == b.Center {
+			if kpi.Orders != 1 || kpi.Revenue != 1000 {
+				t.Errorf("center reported with %d orders and revenue %v, want 1 and 1000", kpi.Orders, kpi.Revenue)
+			}
+			return
+		}
+	}
+	t.Errorf("center %d is not in the report", b.Center)
+}
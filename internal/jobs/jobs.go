@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DB is satisfied by pools and connections.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+type Job struct {
+	Name string
+	// Schedule is a cron expression of five fields or a descriptor such
+	// as @daily, optionally prefixed with CRON_TZ=<zone>.
+	Schedule string
+	// Timeout cancels runs taking longer, 10 minutes when 0.
+	Timeout time.Duration
+	// Run does the job and returns a summary of what it did.
+	Run func(ctx context.Context, db DB) (string, error)
+}
+
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Run is a row of jobs.runs. ScheduledFor is nil for manual runs.
+type Run struct {
+	ID           int64         `json:"id"`
+	Job          string        `json:"job"`
+	ScheduledFor *time.Time    `json:"scheduled_for,omitempty"`
+	Instance     string        `json:"instance"`
+	StartedAt    time.Time     `json:"started_at"`
+	Duration     time.Duration `json:"duration"`
+	Status       string        `json:"status"`
+	Output       string        `json:"output,omitempty"`
+	Error        string        `json:"error,omitempty"`
+}
+
+var (
+	// ErrLocked means the job is running elsewhere.
+	ErrLocked = errors.New("job is running on another instance")
+	// ErrDone means the scheduled run was made by another instance.
+	ErrDone = errors.New("job already ran for this schedule")
+)
+
+// History returns the last runs, of one job unless job is empty.
+func History(ctx context.Context, db DB, job string, limit int) ([]Run, error) {
+	rows, err := db.Query(ctx, `
+        SELECT run_id, job_name, scheduled_for, instance, started_at,
+               COALESCE(EXTRACT(EPOCH FROM duration) * 1000, 0)::BIGINT, status,
+               COALESCE(output, ''), COALESCE(error, '')
+        FROM jobs.runs
+        WHERE $1 = '' OR job_name = $1
+        ORDER BY started_at DESC, run_id DESC
+        LIMIT $2
+    `, job, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history: %w", err)
+	}
+	runs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Run, error) {
+		var r Run
+		var ms int64
+		err := row.Scan(&r.ID, &r.Job, &r.ScheduledFor, &r.Instance, &r.StartedAt, &ms, &r.Status, &r.Output, &r.Error)
+		r.Duration = time.Duration(ms) * time.Millisecond
+		return r, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history: %w", err)
+	}
+	return runs, nil
+}
+
+// start records a run, failing with ErrDone when the slot was run already.
+func start(ctx context.Context, db DB, job string, scheduledFor *time.Time, instance string) (int64, error) {
+	var id int64
+	err := db.QueryRow(ctx, `
+        INSERT INTO jobs.runs (job_name, scheduled_for, instance)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (job_name, scheduled_for) WHERE scheduled_for IS NOT NULL DO NOTHING
+        RETURNING run_id
+    `, job, scheduledFor, instance).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrDone
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record the run of %s: %w", job, err)
+	}
+	return id, nil
+}
+
+func finish(ctx context.Context, db DB, id int64, output string, runErr error) error {
+	status, message := StatusSucceeded, ""
+	if runErr != nil {
+		status, message = StatusFailed, runErr.Error()
+	}
+	_, err := db.Exec(ctx, `
+        UPDATE jobs.runs
+        SET finished_at = clock_timestamp(), duration = clock_timestamp() - started_at,
+            status = $2, output = NULLIF($3, ''), error = NULLIF($4, '')
+        WHERE run_id = $1
+    `, id, status, output, message)
+	if err != nil {
+		return fmt.Errorf("failed to record the end of run %d: %w", id, err)
+	}
+	return nil
+}
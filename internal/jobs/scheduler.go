@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/robfig/cron/v3"
+)
+
+type Options struct {
+	// Instance names this scheduler in the history, host:pid when empty.
+	Instance string
+	// OnRun is called after every run, OnSkip when another instance had
+	// the slot or the lock.
+	OnRun  func(Run)
+	OnSkip func(job string, err error)
+	// OnError is called when a run could not be made or recorded.
+	OnError func(job string, err error)
+}
+
+// Scheduler runs the jobs on their schedules. Any number of instances may run
+// against a database: an advisory lock per job keeps a job from running
+// twice at a time and the history from running a slot twice.
+type Scheduler struct {
+	pool      *pgxpool.Pool
+	jobs      []Job
+	schedules map[string]cron.Schedule
+	opts      Options
+}
+
+func NewScheduler(pool *pgxpool.Pool, jobs []Job, opts Options) (*Scheduler, error) {
+	if opts.Instance == "" {
+		host, _ := os.Hostname()
+		opts.Instance = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+	s := &Scheduler{pool: pool, jobs: jobs, schedules: make(map[string]cron.Schedule), opts: opts}
+	for _, job := range jobs {
+		if _, ok := s.schedules[job.Name]; ok {
+			return nil, fmt.Errorf("job %s is defined twice", job.Name)
+		}
+		schedule, err := cron.ParseStandard(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule of %s: %w", job.Name, err)
+		}
+		// Instances would start the delay at different times, so their
+		// slots would never match.
+		if _, ok := schedule.(cron.ConstantDelaySchedule); ok {
+			return nil, fmt.Errorf("invalid schedule of %s: @every is not supported", job.Name)
+		}
+		s.schedules[job.Name] = schedule
+	}
+	return s, nil
+}
+
+func (s *Scheduler) Jobs() []Job {
+	return s.jobs
+}
+
+// Next returns when a job is due next after t.
+func (s *Scheduler) Next(name string, t time.Time) time.Time {
+	if schedule, ok := s.schedules[name]; ok {
+		return schedule.Next(t)
+	}
+	return time.Time{}
+}
+
+// Run runs the jobs on their schedules until ctx is done, then waits for
+// the runs in progress. Slots passed while nothing ran are skipped.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				next := s.schedules[job.Name].Next(time.Now())
+				timer := time.NewTimer(time.Until(next))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+				s.run(ctx, job, &next)
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// RunNow runs a job right away whatever its schedule, unless it is running
+// elsewhere.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*Run, error) {
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return s.run(ctx, job, nil)
+		}
+	}
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		names[i] = job.Name
+	}
+	return nil, fmt.Errorf("unknown job %q, want one of %s", name, strings.Join(names, ", "))
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job, scheduledFor *time.Time) (*Run, error) {
+	run, err := s.lockAndRun(ctx, job, scheduledFor)
+	switch {
+	case errors.Is(err, ErrLocked) || errors.Is(err, ErrDone):
+		if s.opts.OnSkip != nil {
+			s.opts.OnSkip(job.Name, err)
+		}
+	case err != nil:
+		if s.opts.OnError != nil {
+			s.opts.OnError(job.Name, err)
+		}
+	case s.opts.OnRun != nil:
+		s.opts.OnRun(*run)
+	}
+	return run, err
+}
+
+func (s *Scheduler) lockAndRun(ctx context.Context, job Job, scheduledFor *time.Time) (*Run, error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The lock belongs to the session, it goes with the connection if the
+	// scheduler dies.
+	key := "jobs:" + job.Name
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, key).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to lock %s: %w", job.Name, err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, ErrLocked
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, key); err != nil {
+			// A connection holding the lock must not go back to the pool,
+			// so it is taken out of it and closed.
+			conn.Hijack().Close(unlockCtx)
+			return
+		}
+		conn.Release()
+	}()
+
+	id, err := start(ctx, s.pool, job.Name, scheduledFor, s.opts.Instance)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	output, runErr := s.call(ctx, job)
+	run := &Run{
+		ID:           id,
+		Job:          job.Name,
+		ScheduledFor: scheduledFor,
+		Instance:     s.opts.Instance,
+		StartedAt:    started,
+		Duration:     time.Since(started),
+		Status:       StatusSucceeded,
+		Output:       output,
+	}
+	if runErr != nil {
+		run.Status, run.Error = StatusFailed, runErr.Error()
+	}
+
+	// The run is recorded even when ctx was cancelled during it.
+	finishCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return run, finish(finishCtx, s.pool, id, output, runErr)
+}
+
+// call runs a job with its timeout, a panic fails the run.
+func (s *Scheduler) call(ctx context.Context, job Job) (output string, err error) {
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx, s.pool)
+}
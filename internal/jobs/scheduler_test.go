@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewScheduler(t *testing.T) {
+	tests := []struct {
+		name    string
+		jobs    []Job
+		wantErr string
+	}{
+		{"cron", []Job{{Name: "a", Schedule: "0 3 * * *"}, {Name: "b", Schedule: "@hourly"}}, ""},
+		{"time zone", []Job{{Name: "a", Schedule: "CRON_TZ=Europe/Moscow 0 3 * * *"}}, ""},
+		{"every", []Job{{Name: "a", Schedule: "@every 1h"}}, "@every is not supported"},
+		{"duplicate", []Job{{Name: "a", Schedule: "@daily"}, {Name: "a", Schedule: "@hourly"}}, "defined twice"},
+		{"bad cron", []Job{{Name: "a", Schedule: "0 25 * * *"}}, "invalid schedule of a"},
+		{"seconds", []Job{{Name: "a", Schedule: "0 0 3 * * *"}}, "invalid schedule of a"},
+		{"empty", []Job{{Name: "a"}}, "invalid schedule of a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScheduler(nil, tt.jobs, Options{Instance: "test"})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(s.Jobs()) != len(tt.jobs) {
+					t.Errorf("%d jobs, want %d", len(s.Jobs()), len(tt.jobs))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip(err)
+	}
+	s, err := NewScheduler(nil, []Job{
+		{Name: "nightly", Schedule: "30 3 * * *"},
+		{Name: "hourly", Schedule: "@hourly"},
+		{Name: "monday", Schedule: "0 9 * * 1"},
+		{Name: "moscow", Schedule: "CRON_TZ=Europe/Moscow 0 0 * * *"},
+	}, Options{Instance: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wednesday.
+	at := time.Date(2030, 1, 2, 3, 30, 0, 0, time.UTC)
+	tests := []struct {
+		job  string
+		at   time.Time
+		want time.Time
+	}{
+		{"nightly", at.Add(-time.Minute), at},
+		{"nightly", at, at.AddDate(0, 0, 1)},
+		{"hourly", at, time.Date(2030, 1, 2, 4, 0, 0, 0, time.UTC)},
+		{"monday", at, time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)},
+		{"moscow", at, time.Date(2030, 1, 3, 0, 0, 0, 0, moscow)},
+		{"unknown", at, time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := s.Next(tt.job, tt.at); !got.Equal(tt.want) {
+			t.Errorf("%s after %s is due %s, want %s", tt.job, tt.at, got, tt.want)
+		}
+	}
+
+	// The same slot is computed whenever an instance asks, so instances
+	// agree on the slot they run.
+	if a, b := s.Next("nightly", at.Add(-time.Hour)), s.Next("nightly", at.Add(-time.Second)); !a.Equal(b) {
+		t.Errorf("slots %s and %s differ", a, b)
+	}
+}
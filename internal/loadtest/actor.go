@@ -0,0 +1,166 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/creds"
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/utils"
+)
+
+// Actor is a generated employee logged in with their own database role, so
+// every statement it runs goes through the RLS policies of that role.
+type Actor struct {
+	User       *model.User
+	EmployeeID int
+	Pool       *pgxpool.Pool
+
+	// masters of the actor's service center, used by managers to assign
+	// orders.
+	masters []int
+	// foreignOrders are orders the actor must not be able to change, used by
+	// the RLS probes.
+	foreignOrders []int
+	// foreignEmployee is an employee of another service center.
+	foreignEmployee int
+}
+
+// Connect logs in up to perRole users of every role and loads what their
+// workloads need. admin is a pool that bypasses RLS and is only used to find
+// rows the actors must not see.
+func Connect(ctx context.Context, cfg *db.Config, admin *pgxpool.Pool, users []*model.User, perRole int) ([]*Actor, error) {
+	var actors []*Actor
+	for _, u := range creds.Sample(users, perRole) {
+		if u.Role == "Administrator" {
+			continue
+		}
+
+		poolCfg, err := cfg.WithCredentials(u.Login, u.Password).PoolConfig()
+		if err != nil {
+			closeActors(actors)
+			return nil, err
+		}
+		poolCfg.MaxConns = 2
+
+		pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+		if err != nil {
+			closeActors(actors)
+			return nil, fmt.Errorf("failed to connect as %s: %w", u.Login, err)
+		}
+		a := &Actor{User: u, Pool: pool}
+		actors = append(actors, a)
+
+		if err := a.prepare(ctx, admin); err != nil {
+			closeActors(actors)
+			return nil, fmt.Errorf("failed to prepare %s: %w", u.Login, err)
+		}
+	}
+
+	if len(actors) == 0 {
+		return nil, fmt.Errorf("no credentials of masters, managers or analysts")
+	}
+	return actors, nil
+}
+
+func (a *Actor) prepare(ctx context.Context, admin *pgxpool.Pool) error {
+	err := admin.QueryRow(ctx, `SELECT employee_id FROM employees WHERE username = $1`, a.User.Login).Scan(&a.EmployeeID)
+	if err != nil {
+		return fmt.Errorf("failed to find employee: %w", err)
+	}
+
+	rows, err := admin.Query(ctx, `
+        SELECT employee_id
+        FROM employee_service_center
+        WHERE service_center_id = $1
+          AND employee_role = 'Master'
+    `, a.User.ServiceCenterID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		a.masters = append(a.masters, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = admin.Query(ctx, `
+        SELECT order_id
+        FROM orders
+        WHERE `+foreignOrderPredicate(a.User.Role)+`
+        ORDER BY md5(order_id::text || $2)
+        LIMIT 50
+    `, a.EmployeeID, strconv.FormatUint(utils.Rand.Uint64(), 16))
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		a.foreignOrders = append(a.foreignOrders, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	err = admin.QueryRow(ctx, `
+        SELECT COALESCE(MIN(employee_id), 0)
+        FROM employee_service_center
+        WHERE service_center_id <> $1
+    `, a.User.ServiceCenterID).Scan(&a.foreignEmployee)
+	return err
+}
+
+// foreignOrderPredicate negates the USING clause of the orders update policy
+// of a role, $1 being the employee. Roles without one may update no order.
+func foreignOrderPredicate(role string) string {
+	switch role {
+	case "Master":
+		return `assigned_master_id <> $1 AND reassigned_master_id IS DISTINCT FROM $1`
+	case "Manager":
+		return `manager_id IS DISTINCT FROM $1`
+	default:
+		return `$1::int IS NOT NULL`
+	}
+}
+
+func (a *Actor) foreignOrder(r *rand.Rand) (int, bool) {
+	if len(a.foreignOrders) == 0 {
+		return 0, false
+	}
+	return a.foreignOrders[r.IntN(len(a.foreignOrders))], true
+}
+
+func (a *Actor) master(r *rand.Rand) (int, bool) {
+	if len(a.masters) == 0 {
+		return 0, false
+	}
+	return a.masters[r.IntN(len(a.masters))], true
+}
+
+func closeActors(actors []*Actor) {
+	for _, a := range actors {
+		a.Pool.Close()
+	}
+}
+
+// Close closes the connections of all actors.
+func Close(actors []*Actor) {
+	closeActors(actors)
+}
@@ -0,0 +1,303 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vehicles-service-stations/internal/audit"
+	"vehicles-service-stations/internal/utils"
+)
+
+type Options struct {
+	// Rate is the target number of operations started per second.
+	Rate     float64
+	Duration time.Duration
+	// Concurrency caps operations in flight; ticks arriving while the cap is
+	// reached are dropped and reported, the schedule does not slow down.
+	Concurrency int
+	// Mix weights roles when picking the next actor, e.g. Master: 5.
+	Mix map[string]float64
+}
+
+type WorkloadStats struct {
+	Role     string         `json:"role"`
+	Workload string         `json:"workload"`
+	Probe    bool           `json:"probe"`
+	Calls    int            `json:"calls"`
+	OK       int            `json:"ok"`
+	Skipped  int            `json:"skipped"`
+	Denied   int            `json:"rls_denied"`
+	Leaked   int            `json:"leaked,omitempty"`
+	Errors   map[string]int `json:"errors,omitempty"`
+	P50      time.Duration  `json:"p50"`
+	P95      time.Duration  `json:"p95"`
+	P99      time.Duration  `json:"p99"`
+	Max      time.Duration  `json:"max"`
+
+	latencies []time.Duration
+}
+
+type Report struct {
+	Started    time.Time        `json:"started"`
+	Elapsed    time.Duration    `json:"elapsed"`
+	Actors     map[string]int   `json:"actors"`
+	Operations int              `json:"operations"`
+	Dropped    int              `json:"dropped"`
+	Stats      []*WorkloadStats `json:"workloads"`
+}
+
+type pick struct {
+	actor    *Actor
+	workload *Workload
+	rand     *rand.Rand
+}
+
+func Run(ctx context.Context, actors []*Actor, opts Options) (*Report, error) {
+	interval := time.Duration(float64(time.Second) / opts.Rate)
+	if opts.Rate <= 0 || interval <= 0 {
+		return nil, fmt.Errorf("rate must be positive and below one operation per nanosecond")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 32
+	}
+
+	picker, err := newPicker(actors, opts.Mix)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Started: time.Now(), Actors: make(map[string]int)}
+	for _, a := range actors {
+		report.Actors[a.User.Role]++
+	}
+
+	var mu sync.Mutex
+	stats := make(map[string]*WorkloadStats)
+	record := func(w *Workload, latency time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		s, ok := stats[w.Name]
+		if !ok {
+			s = &WorkloadStats{Role: w.Role, Workload: w.Name, Probe: w.Probe, Errors: make(map[string]int)}
+			stats[w.Name] = s
+		}
+		s.Calls++
+		switch outcome, code := classify(w, err); outcome {
+		case outcomeOK:
+			s.OK++
+			s.latencies = append(s.latencies, latency)
+		case outcomeSkipped:
+			s.Skipped++
+		case outcomeDenied:
+			s.Denied++
+			s.latencies = append(s.latencies, latency)
+		case outcomeLeaked:
+			s.Leaked++
+			s.latencies = append(s.latencies, latency)
+		default:
+			s.Errors[code]++
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			report.Dropped++
+			continue
+		}
+
+		p := picker()
+		report.Operations++
+		requestID := fmt.Sprintf("loadtest-%d-%d", report.Started.Unix(), report.Operations)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// Operations already started get their own deadline so that the
+			// end of the run does not show up as a burst of cancellations.
+			opCtx, opCancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer opCancel()
+
+			start := time.Now()
+			err := execute(opCtx, p, requestID)
+			record(p.workload, time.Since(start), err)
+		}()
+	}
+	wg.Wait()
+
+	report.Elapsed = time.Since(report.Started)
+	for _, s := range stats {
+		s.summarize()
+		report.Stats = append(report.Stats, s)
+	}
+	sort.Slice(report.Stats, func(i, j int) bool {
+		if report.Stats[i].Role != report.Stats[j].Role {
+			return report.Stats[i].Role < report.Stats[j].Role
+		}
+		return report.Stats[i].Workload < report.Stats[j].Workload
+	})
+
+	return report, nil
+}
+
+func execute(ctx context.Context, p pick, requestID string) error {
+	tx, err := p.actor.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := audit.SetRequestID(ctx, tx, requestID); err != nil {
+		return err
+	}
+
+	if err := p.workload.Run(ctx, tx, p.actor, p.rand); err != nil {
+		return err
+	}
+	if p.workload.Probe {
+		return nil
+	}
+	return tx.Commit(ctx)
+}
+
+// newPicker returns a function choosing a role by the mix, then an actor of
+// that role, then one of the role's workloads by weight. Every choice comes
+// from utils.Rand, and so does the source the operation draws from.
+func newPicker(actors []*Actor, mix map[string]float64) (func() pick, error) {
+	byRole := make(map[string][]*Actor)
+	for _, a := range actors {
+		byRole[a.User.Role] = append(byRole[a.User.Role], a)
+	}
+	// Roles are taken in order, map order would change the picks of a seed.
+	names := make([]string, 0, len(byRole))
+	for role := range byRole {
+		names = append(names, role)
+	}
+	sort.Strings(names)
+
+	var roles []string
+	var roleWeights []float64
+	workloads := make(map[string]*utils.Weighted[*Workload])
+	for _, role := range names {
+		group := byRole[role]
+		weight := 1.0
+		if mix != nil {
+			weight = mix[role]
+		}
+		if weight <= 0 || len(group) == 0 {
+			continue
+		}
+
+		var items []*Workload
+		var weights []float64
+		for i := range Workloads {
+			if Workloads[i].Role == role {
+				items = append(items, &Workloads[i])
+				weights = append(weights, Workloads[i].Weight)
+			}
+		}
+		w, err := utils.NewWeighted(items, weights)
+		if err != nil {
+			continue
+		}
+		workloads[role] = w
+		roles = append(roles, role)
+		roleWeights = append(roleWeights, weight)
+	}
+
+	rolePicker, err := utils.NewWeighted(roles, roleWeights)
+	if err != nil {
+		return nil, fmt.Errorf("no actors match the role mix: %w", err)
+	}
+
+	return func() pick {
+		role := rolePicker.Pick()
+		group := byRole[role]
+		return pick{
+			actor:    group[utils.Rand.IntN(len(group))],
+			workload: workloads[role].Pick(),
+			rand:     rand.New(rand.NewPCG(utils.Rand.Uint64(), utils.Rand.Uint64())),
+		}
+	}, nil
+}
+
+type outcome int
+
+const (
+	outcomeOK outcome = iota
+	outcomeSkipped
+	outcomeDenied
+	outcomeLeaked
+	outcomeError
+)
+
+// classify maps the result of a workload to an outcome. Errors are keyed by
+// SQLSTATE, or by "client" when the server did not answer with one.
+func classify(w *Workload, err error) (outcome, string) {
+	if errors.Is(err, ErrSkipped) {
+		return outcomeSkipped, ""
+	}
+
+	denied := errors.Is(err, ErrFiltered)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.InsufficientPrivilege {
+		denied = true
+	}
+
+	switch {
+	case denied:
+		return outcomeDenied, ""
+	case err == nil && w.Probe:
+		return outcomeLeaked, ""
+	case err == nil:
+		return outcomeOK, ""
+	case pgErr != nil:
+		return outcomeError, pgErr.Code
+	default:
+		return outcomeError, "client"
+	}
+}
+
+func (s *WorkloadStats) summarize() {
+	if len(s.Errors) == 0 {
+		s.Errors = nil
+	}
+	if len(s.latencies) == 0 {
+		return
+	}
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	s.P50 = percentile(s.latencies, 0.50)
+	s.P95 = percentile(s.latencies, 0.95)
+	s.P99 = percentile(s.latencies, 0.99)
+	s.Max = s.latencies[len(s.latencies)-1]
+}
+
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	return sorted[int(q*float64(len(sorted)-1))]
+}
@@ -0,0 +1,162 @@
+package loadtest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/utils"
+)
+
+func TestClassify(t *testing.T) {
+	write := &Workload{Name: "orders.create"}
+	probe := &Workload{Name: "customers.insert", Probe: true}
+	denied := &pgconn.PgError{Code: pgerrcode.InsufficientPrivilege}
+	deadlock := &pgconn.PgError{Code: pgerrcode.DeadlockDetected}
+	tests := []struct {
+		name     string
+		w        *Workload
+		err      error
+		want     outcome
+		wantCode string
+	}{
+		{"ok", write, nil, outcomeOK, ""},
+		{"skipped", write, ErrSkipped, outcomeSkipped, ""},
+		{"skipped probe", probe, fmt.Errorf("no target: %w", ErrSkipped), outcomeSkipped, ""},
+		{"filtered", write, ErrFiltered, outcomeDenied, ""},
+		{"insufficient privilege", probe, fmt.Errorf("insert: %w", denied), outcomeDenied, ""},
+		{"probe leaked", probe, nil, outcomeLeaked, ""},
+		{"server error", write, deadlock, outcomeError, pgerrcode.DeadlockDetected},
+		{"client error", write, errors.New("connection reset"), outcomeError, "client"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, code := classify(tt.w, tt.err)
+			if got != tt.want || code != tt.wantCode {
+				t.Errorf("classify = %d, %q; want %d, %q", got, code, tt.want, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+	tests := []struct {
+		latencies []time.Duration
+		q         float64
+		want      time.Duration
+	}{
+		{sorted, 0.50, 50 * time.Millisecond},
+		{sorted, 0.95, 95 * time.Millisecond},
+		{sorted, 0.99, 99 * time.Millisecond},
+		{sorted, 1, 100 * time.Millisecond},
+		{sorted, 0, time.Millisecond},
+		{sorted[:1], 0.99, time.Millisecond},
+		{sorted[:2], 0.50, time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.latencies, tt.q); got != tt.want {
+			t.Errorf("p%v of %d latencies is %s, want %s", tt.q*100, len(tt.latencies), got, tt.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := &WorkloadStats{Errors: map[string]int{}, latencies: []time.Duration{3, 1, 2}}
+	s.summarize()
+	if s.Errors != nil || s.P50 != 2 || s.Max != 3 {
+		t.Errorf("summarized as %+v", s)
+	}
+}
+
+func testActors() []*Actor {
+	var actors []*Actor
+	for _, role := range []string{"Master", "Manager", "Analyst", "Administrator"} {
+		for i := range 3 {
+			actors = append(actors, &Actor{User: &model.User{Login: fmt.Sprintf("%s_%d", role, i), Role: role}})
+		}
+	}
+	return actors
+}
+
+func TestPicker(t *testing.T) {
+	mix := map[string]float64{"Master": 5, "Manager": 3, "Analyst": 2}
+	picker, err := newPicker(testActors(), mix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 100000
+	roles := make(map[string]int)
+	workloads := make(map[string]int)
+	for range n {
+		p := picker()
+		if p.actor.User.Role != p.workload.Role {
+			t.Fatalf("%s picked for a %s", p.workload.Name, p.actor.User.Role)
+		}
+		roles[p.actor.User.Role]++
+		workloads[p.workload.Name]++
+	}
+	for role, weight := range mix {
+		if got, want := float64(roles[role])/n, weight/10; math.Abs(got-want) > 0.01 {
+			t.Errorf("%s picked %.3f of the time, want %.3f", role, got, want)
+		}
+	}
+	if roles["Administrator"] != 0 {
+		t.Errorf("Administrator is not in the mix and picked %d times", roles["Administrator"])
+	}
+
+	// Within a role, workloads follow their weights.
+	var masterWeight float64
+	for _, w := range Workloads {
+		if w.Role == "Master" {
+			masterWeight += w.Weight
+		}
+	}
+	for _, w := range Workloads {
+		if w.Role != "Master" {
+			continue
+		}
+		got := float64(workloads[w.Name]) / float64(roles["Master"])
+		if want := w.Weight / masterWeight; math.Abs(got-want) > 0.02 {
+			t.Errorf("%s picked %.3f of master operations, want %.3f", w.Name, got, want)
+		}
+	}
+}
+
+func TestPickerSeeded(t *testing.T) {
+	run := func() []string {
+		utils.Seed(7)
+		picker, err := newPicker(testActors(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var picks []string
+		for range 200 {
+			p := picker()
+			picks = append(picks, fmt.Sprintf("%s %s %d", p.actor.User.Login, p.workload.Name, p.rand.Uint64()))
+		}
+		return picks
+	}
+	first, second := run(), run()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("pick %d is %s, then %s with the same seed", i, first[i], second[i])
+		}
+	}
+}
+
+func TestPickerNoActors(t *testing.T) {
+	if _, err := newPicker(testActors(), map[string]float64{"Director": 1}); err == nil {
+		t.Error("picker built for a mix without actors")
+	}
+}
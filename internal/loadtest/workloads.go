@@ -0,0 +1,196 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	// ErrSkipped means the actor has nothing to run the workload against,
+	// e.g. a manager of a service center without masters.
+	ErrSkipped = errors.New("workload skipped")
+	// ErrFiltered means a targeted write matched no rows because RLS hid them.
+	ErrFiltered = errors.New("rows filtered by row level security")
+)
+
+// Workload is an operation typical for a role. Probes are operations the role
+// must not be allowed to do: they run in a transaction that is always rolled
+// back, and succeeding counts as a leak.
+type Workload struct {
+	Name   string
+	Role   string
+	Weight float64
+	Probe  bool
+	// Run draws its random choices from r, so that a seeded run repeats
+	// whatever order the operations finish in.
+	Run func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error
+}
+
+var Workloads = []Workload{
+	{
+		Name:   "orders.list_own",
+		Role:   "Master",
+		Weight: 5,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			return drain(tx.Query(ctx, `
+                SELECT order_id, status, scheduled_date
+                FROM orders
+                ORDER BY scheduled_date DESC
+                LIMIT 20
+            `))
+		},
+	},
+	{
+		Name:   "orders.advance_own",
+		Role:   "Master",
+		Weight: 3,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			_, err := tx.Exec(ctx, `
+                UPDATE orders
+                SET status = CASE status WHEN 'Pending' THEN 'In Progress'::order_status ELSE 'Completed'::order_status END
+                WHERE order_id = (
+                    SELECT order_id
+                    FROM orders
+                    WHERE status IN ('Pending', 'In Progress')
+                    ORDER BY scheduled_date
+                    LIMIT 1
+                )
+            `)
+			return err
+		},
+	},
+	{
+		Name:   "orders.update_foreign",
+		Role:   "Master",
+		Weight: 1,
+		Probe:  true,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			orderID, ok := a.foreignOrder(r)
+			if !ok {
+				return ErrSkipped
+			}
+			return expectRows(tx.Exec(ctx, `UPDATE orders SET status = 'Cancelled' WHERE order_id = $1`, orderID))
+		},
+	},
+	{
+		Name:   "orders.list_center",
+		Role:   "Manager",
+		Weight: 3,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			return drain(tx.Query(ctx, `
+                SELECT order_id, customer_id, assigned_master_id, status, total_cost
+                FROM orders
+                ORDER BY creation_date DESC
+                LIMIT 50
+            `))
+		},
+	},
+	{
+		Name:   "orders.create",
+		Role:   "Manager",
+		Weight: 3,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			masterID, ok := a.master(r)
+			if !ok {
+				return ErrSkipped
+			}
+			_, err := tx.Exec(ctx, `
+                INSERT INTO orders (customer_id, service_center_id, manager_id, assigned_master_id, scheduled_date)
+                SELECT customer_id, $1, $2, $3, CURRENT_DATE + $4::int
+                FROM customers
+                ORDER BY customer_id
+                OFFSET $5 % GREATEST((SELECT count(*) FROM customers), 1)
+                LIMIT 1
+            `, a.User.ServiceCenterID, a.EmployeeID, masterID, 1+r.IntN(60), r.Int64())
+			return err
+		},
+	},
+	{
+		Name:   "receipts.create",
+		Role:   "Manager",
+		Weight: 2,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			_, err := tx.Exec(ctx, `
+                INSERT INTO receipts (order_id, total_paid)
+                SELECT o.order_id, o.total_cost
+                FROM orders o
+                WHERE o.status = 'Completed'
+                  AND o.manager_id = $1
+                  AND NOT EXISTS (SELECT 1 FROM receipts r WHERE r.order_id = o.order_id)
+                LIMIT 1
+                ON CONFLICT (order_id) DO NOTHING
+            `, a.EmployeeID)
+			return err
+		},
+	},
+	{
+		Name:   "orders.create_for_other_manager",
+		Role:   "Manager",
+		Weight: 1,
+		Probe:  true,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			masterID, ok := a.master(r)
+			if !ok || a.foreignEmployee == 0 {
+				return ErrSkipped
+			}
+			// Scheduled far ahead so the double booking trigger does not fire
+			// before the policy is checked.
+			_, err := tx.Exec(ctx, `
+                INSERT INTO orders (customer_id, service_center_id, manager_id, assigned_master_id, scheduled_date)
+                SELECT customer_id, $1, $2, $3, CURRENT_DATE + 3650 + $4::int
+                FROM customers
+                LIMIT 1
+            `, a.User.ServiceCenterID, a.foreignEmployee, masterID, r.IntN(3650))
+			return err
+		},
+	},
+	analystView("bookings_by_date"),
+	analystView("most_demanded_services"),
+	analystView("revenue_by_date"),
+	analystView("employee_performance"),
+	analystView("service_center_performance"),
+	{
+		Name:   "customers.insert",
+		Role:   "Analyst",
+		Weight: 1,
+		Probe:  true,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			_, err := tx.Exec(ctx, `INSERT INTO customers (full_name, phone_number) VALUES ('loadtest', '+70000000000')`)
+			return err
+		},
+	},
+}
+
+func analystView(view string) Workload {
+	return Workload{
+		Name:   "views." + view,
+		Role:   "Analyst",
+		Weight: 1,
+		Run: func(ctx context.Context, tx pgx.Tx, a *Actor, r *rand.Rand) error {
+			return drain(tx.Query(ctx, "SELECT * FROM "+pgx.Identifier{view}.Sanitize()+" LIMIT 100"))
+		},
+	}
+}
+
+func drain(rows pgx.Rows, err error) error {
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	return rows.Err()
+}
+
+func expectRows(tag interface{ RowsAffected() int64 }, err error) error {
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrFiltered
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package masking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/query"
+	"vehicles-service-stations/internal/snapshot"
+)
+
+type Options struct {
+	Schema string
+	Rules  *Config
+	Secret []byte
+	// DisableTriggers writes with session_replication_role = replica so that
+	// triggers do not recompute totals and bonuses already present in the
+	// source. It needs a superuser connection to the target.
+	DisableTriggers bool
+	// Truncate empties the target tables first instead of refusing to write
+	// into a non-empty database.
+	Truncate bool
+	// Force skips the comparison of source and target schemas.
+	Force     bool
+	BatchSize int
+}
+
+type TableReport struct {
+	Name   string   `json:"name"`
+	Rows   int64    `json:"rows"`
+	Masked []string `json:"masked,omitempty"`
+	// Detached columns reference tables of other schemas, which are not
+	// copied, and are written as NULL.
+	Detached []string `json:"detached,omitempty"`
+}
+
+// Copy reads every base table of the source in foreign key order, masks it
+// according to the rules and writes it to the target in a single transaction.
+func Copy(ctx context.Context, source, target *pgxpool.Pool, opts Options) ([]TableReport, error) {
+	if opts.Schema == "" {
+		opts.Schema = "public"
+	}
+	if opts.Rules == nil {
+		opts.Rules = DefaultConfig()
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	masker, err := NewMasker(opts.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := source.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin source transaction: %w", err)
+	}
+	defer src.Rollback(ctx)
+
+	dst, err := target.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin target transaction: %w", err)
+	}
+	defer dst.Rollback(ctx)
+
+	catalog := model.NewAllowedTables()
+	if err := catalog.Initialize(ctx, src, opts.Schema); err != nil {
+		return nil, err
+	}
+	if err := opts.Rules.Validate(catalog); err != nil {
+		return nil, err
+	}
+
+	targetCatalog := model.NewAllowedTables()
+	if err := targetCatalog.Initialize(ctx, dst, opts.Schema); err != nil {
+		return nil, err
+	}
+	if !opts.Force && snapshot.SchemaFingerprint(catalog) != snapshot.SchemaFingerprint(targetCatalog) {
+		return nil, fmt.Errorf("source and target schemas differ")
+	}
+
+	order, err := catalog.LoadOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.DisableTriggers {
+		if _, err := dst.Exec(ctx, `SET LOCAL session_replication_role = replica`); err != nil {
+			return nil, fmt.Errorf("failed to disable triggers: %w", err)
+		}
+	}
+	if err := snapshot.PrepareTargets(ctx, dst, opts.Schema, order, opts.Truncate); err != nil {
+		return nil, err
+	}
+
+	reports := make([]TableReport, 0, len(order))
+	for _, name := range order {
+		t, _ := catalog.Table(name)
+		report, err := copyTable(ctx, src, dst, masker, opts, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy %s: %w", name, err)
+		}
+		if err := copySequence(ctx, src, dst, opts.Schema, t); err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+
+	if err := dst.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit masked data: %w", err)
+	}
+
+	return reports, nil
+}
+
+func copyTable(ctx context.Context, src, dst pgx.Tx, masker *Masker, opts Options, t *model.Table) (*TableReport, error) {
+	report := &TableReport{Name: t.Name}
+	detached, err := snapshot.DetachedColumns(t)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dropped columns with a default are left out of the insert so that the
+	// target fills them in.
+	var columns []model.Column
+	for _, col := range t.Columns {
+		if detached[col.Name] {
+			report.Detached = append(report.Detached, col.Name)
+		}
+		rule := opts.Rules.Rule(t.Name, col.Name)
+		if rule.Kind != KindKeep && rule.Kind != "" {
+			report.Masked = append(report.Masked, col.Name)
+		}
+		if rule.Kind == KindDrop && col.Default != nil {
+			continue
+		}
+		columns = append(columns, col)
+	}
+
+	quoted := make([]string, 0, len(columns))
+	for _, col := range columns {
+		quoted = append(quoted, query.Quote(col.Name))
+	}
+	columnList := strings.Join(quoted, ", ")
+	table := query.Quote(opts.Schema, t.Name)
+
+	sql := fmt.Sprintf("SELECT to_jsonb(s)::text FROM (SELECT %s FROM %s", columnList, table)
+	if len(t.PrimaryKey) > 0 {
+		keys := make([]string, 0, len(t.PrimaryKey))
+		for _, col := range t.PrimaryKey {
+			keys = append(keys, query.Quote(col))
+		}
+		sql += " ORDER BY " + strings.Join(keys, ", ")
+	}
+	sql += ") s"
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM jsonb_populate_recordset(NULL::%s, $1::jsonb)",
+		table, columnList, columnList, table)
+
+	// The source cursor stays open while batches are written, which is fine
+	// because source and target use different connections.
+	rows, err := src.Query(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batch := make([]json.RawMessage, 0, opts.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		payload, err := json.Marshal(batch)
+		if err != nil {
+			return err
+		}
+		tag, err := dst.Exec(ctx, insert, string(payload))
+		if err != nil {
+			return err
+		}
+		report.Rows += tag.RowsAffected()
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+
+		row := make(map[string]any, len(columns))
+		dec := json.NewDecoder(strings.NewReader(line))
+		dec.UseNumber()
+		if err := dec.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode row: %w", err)
+		}
+
+		key := rowKey(t, row)
+		for _, col := range columns {
+			if detached[col.Name] {
+				row[col.Name] = nil
+				continue
+			}
+			rule := opts.Rules.Rule(t.Name, col.Name)
+			masked, err := masker.Apply(rule, col, row[col.Name], key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", col.Name, err)
+			}
+			row[col.Name] = masked
+		}
+
+		encoded, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, encoded)
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// rowKey joins the primary key values of the row, or the whole row for tables
+// without a primary key.
+func rowKey(t *model.Table, row map[string]any) string {
+	var buf bytes.Buffer
+	if len(t.PrimaryKey) == 0 {
+		encoded, _ := json.Marshal(row)
+		return string(encoded)
+	}
+	for _, col := range t.PrimaryKey {
+		fmt.Fprintf(&buf, "%v|", row[col])
+	}
+	return buf.String()
+}
+
+func copySequence(ctx context.Context, src, dst pgx.Tx, schema string, t *model.Table) error {
+	seq, err := snapshot.ReadSequence(ctx, src, schema, t)
+	if err != nil {
+		return err
+	}
+	return snapshot.RestoreSequence(ctx, dst, seq)
+}
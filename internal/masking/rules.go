@@ -0,0 +1,251 @@
+package masking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/spf13/viper"
+
+	"vehicles-service-stations/internal/model"
+)
+
+type RuleKind string
+
+const (
+	// KindKeep copies the value unchanged, the default for unlisted columns.
+	KindKeep RuleKind = "keep"
+	// KindFake replaces the value with a gofakeit value seeded by the original,
+	// so equal originals map to equal fakes across tables and runs.
+	KindFake RuleKind = "fake"
+	// KindPhone replaces digits while keeping the leading '+', the length and
+	// the first KeepPrefix digits.
+	KindPhone RuleKind = "phone"
+	// KindNoise multiplies a number by a factor within ±Percent.
+	KindNoise RuleKind = "noise"
+	// KindConstant writes Value into every row.
+	KindConstant RuleKind = "constant"
+	// KindDrop removes the value: the column default is used if there is one,
+	// NULL if the column is nullable, and droppedPlaceholder otherwise.
+	KindDrop RuleKind = "drop"
+)
+
+// droppedPlaceholder is not a valid bcrypt hash, so dropped password hashes
+// cannot be used to log in.
+const droppedPlaceholder = "!dropped"
+
+type Rule struct {
+	Kind RuleKind `mapstructure:"kind"`
+	// Generator names the gofakeit value used by KindFake.
+	Generator string `mapstructure:"generator"`
+	// Unique appends a short hash of the original to fakes for columns with a
+	// unique constraint.
+	Unique     bool    `mapstructure:"unique"`
+	KeepPrefix int     `mapstructure:"keep_prefix"`
+	Percent    float64 `mapstructure:"percent"`
+	Decimals   int     `mapstructure:"decimals"`
+	Value      string  `mapstructure:"value"`
+}
+
+var generators = map[string]func(f *gofakeit.Faker) string{
+	"name":       func(f *gofakeit.Faker) string { return f.Name() },
+	"first_name": func(f *gofakeit.Faker) string { return f.FirstName() },
+	"last_name":  func(f *gofakeit.Faker) string { return f.LastName() },
+	"username":   func(f *gofakeit.Faker) string { return f.Username() },
+	"email":      func(f *gofakeit.Faker) string { return f.Email() },
+	"company":    func(f *gofakeit.Faker) string { return f.Company() },
+	"city":       func(f *gofakeit.Faker) string { return f.City() },
+	"street":     func(f *gofakeit.Faker) string { return f.Street() },
+	"word":       func(f *gofakeit.Faker) string { return f.Word() },
+	"sentence":   func(f *gofakeit.Faker) string { return f.Sentence(6) },
+}
+
+func (r Rule) Validate() error {
+	switch r.Kind {
+	case KindKeep, KindDrop, KindConstant:
+	case KindFake:
+		if _, ok := generators[r.Generator]; !ok {
+			return fmt.Errorf("unknown fake generator %q", r.Generator)
+		}
+	case KindPhone:
+		if r.KeepPrefix < 0 {
+			return fmt.Errorf("keep_prefix must not be negative")
+		}
+	case KindNoise:
+		if r.Percent <= 0 || r.Percent >= 100 {
+			return fmt.Errorf("noise percent must be within (0, 100)")
+		}
+		if r.Decimals < 0 {
+			return fmt.Errorf("decimals must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown rule kind %q", r.Kind)
+	}
+	return nil
+}
+
+// Config maps table and column names to masking rules.
+type Config struct {
+	Tables map[string]map[string]Rule `mapstructure:"tables"`
+}
+
+// DefaultConfig masks the personal data of the service stations schema.
+func DefaultConfig() *Config {
+	return &Config{
+		Tables: map[string]map[string]Rule{
+			"customers": {
+				"full_name":    {Kind: KindFake, Generator: "name"},
+				"phone_number": {Kind: KindPhone, KeepPrefix: 1},
+			},
+			"employees": {
+				"full_name":     {Kind: KindFake, Generator: "name"},
+				"username":      {Kind: KindFake, Generator: "username", Unique: true},
+				"salary":        {Kind: KindNoise, Percent: 10, Decimals: 2},
+				"password_hash": {Kind: KindDrop},
+			},
+		},
+	}
+}
+
+func LoadConfig(path string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("unable to read masking rules %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("unable to decode masking rules, %v", err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks the rules and that every table and column they name exists
+// in the catalogue.
+func (c *Config) Validate(catalog *model.AllowedTables) error {
+	for table, columns := range c.Tables {
+		t, ok := catalog.Table(table)
+		if !ok || t.IsView() {
+			return fmt.Errorf("masking rules reference unknown table %s", table)
+		}
+		for column, rule := range columns {
+			if !catalog.IsValid(table, column) {
+				return fmt.Errorf("masking rules reference unknown column %s.%s", table, column)
+			}
+			if err := rule.Validate(); err != nil {
+				return fmt.Errorf("%s.%s: %w", table, column, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) Rule(table, column string) Rule {
+	if rule, ok := c.Tables[table][column]; ok {
+		return rule
+	}
+	return Rule{Kind: KindKeep}
+}
+
+// Masker applies rules deterministically: every replacement is derived from
+// an HMAC of the original value keyed by the secret, so the mapping is stable
+// between runs but cannot be reversed without the secret.
+type Masker struct {
+	secret []byte
+}
+
+func NewMasker(secret []byte) (*Masker, error) {
+	if len(secret) < 16 {
+		return nil, fmt.Errorf("masking secret must be at least 16 bytes")
+	}
+	return &Masker{secret: secret}, nil
+}
+
+func (m *Masker) digest(scope, value string) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// Apply masks a single JSON value of the column. rowKey identifies the row
+// and seeds rules that should not depend on the value itself.
+func (m *Masker) Apply(rule Rule, col model.Column, value any, rowKey string) (any, error) {
+	switch rule.Kind {
+	case KindKeep, "":
+		return value, nil
+	case KindConstant:
+		return rule.Value, nil
+	case KindDrop:
+		if col.Nullable {
+			return nil, nil
+		}
+		return droppedPlaceholder, nil
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	switch rule.Kind {
+	case KindFake:
+		original := fmt.Sprint(value)
+		sum := m.digest(rule.Generator, original)
+		seed := binary.BigEndian.Uint64(sum)
+		if seed == 0 {
+			// gofakeit treats a zero seed as a request for a random one.
+			seed = 1
+		}
+		fake := generators[rule.Generator](gofakeit.New(seed))
+		if rule.Unique {
+			fake += "_" + hex.EncodeToString(sum[8:11])
+		}
+		return fake, nil
+	case KindPhone:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("phone rule needs a text column, got %T", value)
+		}
+		return m.phone(s, rule.KeepPrefix), nil
+	case KindNoise:
+		n, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("noise rule needs a numeric column, got %T", value)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return nil, err
+		}
+		u := float64(binary.BigEndian.Uint64(m.digest("noise:"+col.Name, rowKey))) / math.MaxUint64
+		f *= 1 + (u*2-1)*rule.Percent/100
+		return json.Number(strconv.FormatFloat(math.Max(f, 0), 'f', rule.Decimals, 64)), nil
+	}
+
+	return nil, fmt.Errorf("unknown rule kind %q", rule.Kind)
+}
+
+// phone replaces every digit after the first keepPrefix ones. Non-digit
+// characters, and so the number's format, are left in place.
+func (m *Masker) phone(s string, keepPrefix int) string {
+	sum := m.digest("phone", s)
+	out := []byte(s)
+	seen := 0
+	for i, c := range out {
+		if c < '0' || c > '9' {
+			continue
+		}
+		if seen >= keepPrefix {
+			out[i] = '0' + sum[seen%len(sum)]%10
+		}
+		seen++
+	}
+	return string(out)
+}
@@ -0,0 +1,156 @@
+package masking
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"vehicles-service-stations/internal/model"
+)
+
+func newMasker(t *testing.T, secret string) *Masker {
+	t.Helper()
+	m, err := NewMasker([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestPhone(t *testing.T) {
+	m := newMasker(t, "0123456789abcdef")
+	rule := DefaultConfig().Rule("customers", "phone_number")
+	format := regexp.MustCompile(`^\+?\d{10,15}$`)
+	for i := range 500 {
+		phone := fmt.Sprintf("+7%010d", i*7919)
+		if i%2 == 1 {
+			phone = fmt.Sprintf("8%014d", i*104729)
+		}
+		got, err := m.Apply(rule, model.Column{Name: "phone_number"}, phone, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		masked := got.(string)
+		if !format.MatchString(masked) || len(masked) != len(phone) {
+			t.Fatalf("%s masked as %s, which does not keep the format", phone, masked)
+		}
+		keep := rule.KeepPrefix
+		if phone[0] == '+' {
+			keep++
+		}
+		if masked[:keep] != phone[:keep] {
+			t.Errorf("%s masked as %s, which does not keep the prefix", phone, masked)
+		}
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	m := newMasker(t, "0123456789abcdef")
+	other := newMasker(t, "fedcba9876543210")
+	cfg := DefaultConfig()
+	tests := []struct {
+		table, column string
+		value         any
+	}{
+		{"customers", "full_name", "Ivan Petrov"},
+		{"customers", "phone_number", "+79161234567"},
+		{"employees", "username", "ipetrov"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.table+"."+tt.column, func(t *testing.T) {
+			rule := cfg.Rule(tt.table, tt.column)
+			col := model.Column{Name: tt.column}
+			first, err := m.Apply(rule, col, tt.value, "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, _ := m.Apply(rule, col, tt.value, "2")
+			if first != second {
+				t.Errorf("%v masked as %v and %v", tt.value, first, second)
+			}
+			if first == tt.value {
+				t.Errorf("%v is not masked", tt.value)
+			}
+			if again, _ := newMasker(t, "0123456789abcdef").Apply(rule, col, tt.value, "1"); again != first {
+				t.Errorf("the same salt masked %v as %v and %v", tt.value, first, again)
+			}
+			if salted, _ := other.Apply(rule, col, tt.value, "1"); salted == first {
+				t.Errorf("another salt masked %v as %v too", tt.value, first)
+			}
+		})
+	}
+}
+
+func TestUniqueUsernames(t *testing.T) {
+	m := newMasker(t, "0123456789abcdef")
+	rule := DefaultConfig().Rule("employees", "username")
+	seen := make(map[any]string)
+	for i := range 5000 {
+		username := fmt.Sprintf("user_%d", i)
+		got, err := m.Apply(rule, model.Column{Name: "username"}, username, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev, ok := seen[got]; ok {
+			t.Fatalf("%s and %s are both masked as %v", prev, username, got)
+		}
+		seen[got] = username
+	}
+}
+
+func TestSalaryNoise(t *testing.T) {
+	m := newMasker(t, "0123456789abcdef")
+	rule := DefaultConfig().Rule("employees", "salary")
+	changed := 0
+	for i := range 1000 {
+		got, err := m.Apply(rule, model.Column{Name: "salary"}, json.Number("100000.00"), fmt.Sprint(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := got.(json.Number).Float64()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f < 90000 || f > 110000 {
+			t.Fatalf("salary of row %d is %v, outside of ±%v%%", i, got, rule.Percent)
+		}
+		if f != 100000 {
+			changed++
+		}
+	}
+	if changed < 990 {
+		t.Errorf("only %d of 1000 salaries changed", changed)
+	}
+}
+
+func TestDrop(t *testing.T) {
+	m := newMasker(t, "0123456789abcdef")
+	rule := DefaultConfig().Rule("employees", "password_hash")
+	hash := "$2a$10$abcdefghijklmnopqrstuv"
+	if got, err := m.Apply(rule, model.Column{Name: "password_hash"}, hash, ""); err != nil || got != droppedPlaceholder {
+		t.Errorf("password hash masked as %v, %v; want %s", got, err, droppedPlaceholder)
+	}
+	if got, err := m.Apply(rule, model.Column{Name: "password_hash", Nullable: true}, hash, ""); err != nil || got != nil {
+		t.Errorf("nullable password hash masked as %v, %v; want NULL", got, err)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		ok   bool
+	}{
+		{Rule{Kind: KindFake, Generator: "name"}, true},
+		{Rule{Kind: KindFake, Generator: "ssn"}, false},
+		{Rule{Kind: KindPhone, KeepPrefix: -1}, false},
+		{Rule{Kind: KindNoise, Percent: 10}, true},
+		{Rule{Kind: KindNoise, Percent: 100}, false},
+		{Rule{Kind: "shuffle"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.Validate(); (err == nil) != tt.ok {
+			t.Errorf("%+v: error %v", tt.rule, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package model
+
+type RelationKind string
+
+const (
+	RelationTable            RelationKind = "table"
+	RelationPartitionedTable RelationKind = "partitioned table"
+	RelationView             RelationKind = "view"
+	RelationMaterializedView RelationKind = "materialized view"
+)
+
+func relationKindFromRelkind(relkind string) RelationKind {
+	switch relkind {
+	case "p":
+		return RelationPartitionedTable
+	case "v":
+		return RelationView
+	case "m":
+		return RelationMaterializedView
+	default:
+		return RelationTable
+	}
+}
+
+type Column struct {
+	Name     string
+	Position int
+	// Type is the SQL type as printed by format_type, e.g. "numeric(12,2)".
+	Type string
+	// UDTName is the underlying type name, e.g. "numeric" or "order_status".
+	UDTName  string
+	Nullable bool
+	Default  *string
+	Identity bool
+}
+
+type ForeignKey struct {
+	Name    string
+	Columns []string
+	// RefSchema is the schema of RefTable, which may differ from the schema
+	// of the referencing table.
+	RefSchema  string
+	RefTable   string
+	RefColumns []string
+	OnDelete   string
+	OnUpdate   string
+}
+
+type UniqueConstraint struct {
+	Name    string
+	Columns []string
+}
+
+type CheckConstraint struct {
+	Name       string
+	Columns    []string
+	Definition string
+}
+
+type Trigger struct {
+	Name       string
+	Table      string
+	Function   string
+	Timing     string
+	Events     []string
+	ForEachRow bool
+	Definition string
+}
+
+type Policy struct {
+	Name       string
+	Table      string
+	Command    string
+	Roles      []string
+	Permissive bool
+	Using      *string
+	WithCheck  *string
+}
+
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+type Table struct {
+	Schema           string
+	Name             string
+	Kind             RelationKind
+	Columns          []Column
+	PrimaryKey       []string
+	ForeignKeys      []ForeignKey
+	Uniques          []UniqueConstraint
+	Checks           []CheckConstraint
+	Triggers         []Trigger
+	Policies         []Policy
+	RowLevelSecurity bool
+	ForceRLS         bool
+	// ViewDefinition is only set for views and materialized views.
+	ViewDefinition string
+}
+
+func (t *Table) IsView() bool {
+	return t.Kind == RelationView || t.Kind == RelationMaterializedView
+}
+
+func (t *Table) Column(name string) (*Column, bool) {
+	for i := range t.Columns {
+		if t.Columns[i].Name == name {
+			return &t.Columns[i], true
+		}
+	}
+	return nil, false
+}
+
+func (t *Table) ColumnNames() []string {
+	names := make([]string, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// ForeignKeysTo returns the foreign keys of t that reference the given table
+// of its schema.
+func (t *Table) ForeignKeysTo(table string) []ForeignKey {
+	var fks []ForeignKey
+	for _, fk := range t.ForeignKeys {
+		if fk.RefTable == table && fk.RefSchema == t.Schema {
+			fks = append(fks, fk)
+		}
+	}
+	return fks
+}
+
+// ExternalForeignKeys returns the foreign keys of t that reference a table of
+// another schema.
+func (t *Table) ExternalForeignKeys() []ForeignKey {
+	var fks []ForeignKey
+	for _, fk := range t.ForeignKeys {
+		if fk.RefSchema != t.Schema {
+			fks = append(fks, fk)
+		}
+	}
+	return fks
+}
+
+func (e *Enum) Has(value string) bool {
+	for _, v := range e.Values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestForeignKeysAcrossSchemas(t *testing.T) {
+	orders := &Table{
+		Schema: "public",
+		Name:   "orders",
+		Kind:   RelationTable,
+		Columns: []Column{
+			{Name: "order_id"},
+			{Name: "customer_id"},
+			{Name: "quote_id", Nullable: true},
+		},
+		ForeignKeys: []ForeignKey{
+			{Name: "orders_customer_id_fkey", Columns: []string{"customer_id"}, RefSchema: "public", RefTable: "customers"},
+			{Name: "orders_quote_id_fkey", Columns: []string{"quote_id"}, RefSchema: "pricing", RefTable: "quotes"},
+		},
+	}
+	// A table of the same name in the schema is not what the key references.
+	quotes := &Table{Schema: "public", Name: "quotes", Kind: RelationTable,
+		ForeignKeys: []ForeignKey{{Name: "quotes_order_id_fkey", Columns: []string{"order_id"}, RefSchema: "public", RefTable: "orders"}}}
+	customers := &Table{Schema: "public", Name: "customers", Kind: RelationTable}
+
+	if fks := orders.ForeignKeysTo("quotes"); len(fks) != 0 {
+		t.Errorf("foreign keys to public.quotes %v, want none", fks)
+	}
+	if fks := orders.ExternalForeignKeys(); len(fks) != 1 || fks[0].Name != "orders_quote_id_fkey" {
+		t.Errorf("external foreign keys %v, want orders_quote_id_fkey", fks)
+	}
+
+	at := NewAllowedTables()
+	at.schema = "public"
+	for _, table := range []*Table{orders, quotes, customers} {
+		at.tables[table.Name] = table
+	}
+	order, err := at.LoadOrder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i, j := slices.Index(order, "customers"), slices.Index(order, "orders"); i > j {
+		t.Errorf("load order %v, want customers before orders", order)
+	}
+}
@@ -3,91 +3,518 @@ package model
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
+	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5"
 )
 
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
 type AllowedTables struct {
-	tables map[string][]string
+	mu       sync.RWMutex
+	schema   string
+	tables   map[string]*Table
+	enums    map[string]*Enum
+	loadedAt time.Time
 }
 
 func NewAllowedTables() *AllowedTables {
 	return &AllowedTables{
-		tables: make(map[string][]string),
+		tables: make(map[string]*Table),
+		enums:  make(map[string]*Enum),
+	}
+}
+
+func (at *AllowedTables) Initialize(ctx context.Context, db Querier, schema string) error {
+	tables, err := loadRelations(ctx, db, schema)
+	if err != nil {
+		return err
+	}
+	if err := loadColumns(ctx, db, schema, tables); err != nil {
+		return err
+	}
+	if err := loadConstraints(ctx, db, schema, tables); err != nil {
+		return err
+	}
+	if err := loadTriggers(ctx, db, schema, tables); err != nil {
+		return err
+	}
+	if err := loadPolicies(ctx, db, schema, tables); err != nil {
+		return err
+	}
+	enums, err := loadEnums(ctx, db, schema)
+	if err != nil {
+		return err
+	}
+
+	at.mu.Lock()
+	defer at.mu.Unlock()
+
+	at.schema = schema
+	at.tables = tables
+	at.enums = enums
+	at.loadedAt = time.Now()
+
+	return nil
+}
+
+func (at *AllowedTables) Refresh(ctx context.Context, db Querier) error {
+	at.mu.RLock()
+	schema := at.schema
+	at.mu.RUnlock()
+
+	if schema == "" {
+		return fmt.Errorf("allowed tables are not initialized")
+	}
+
+	return at.Initialize(ctx, db, schema)
+}
+
+func (at *AllowedTables) Schema() string {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+	return at.schema
+}
+
+func (at *AllowedTables) LoadedAt() time.Time {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+	return at.loadedAt
+}
+
+func (at *AllowedTables) IsValid(table, column string) bool {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+
+	t, exists := at.tables[table]
+	if !exists {
+		return false
+	}
+	if column == "" {
+		return true
+	}
+	_, ok := t.Column(column)
+	return ok
+}
+
+func (at *AllowedTables) Table(name string) (*Table, bool) {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+
+	t, ok := at.tables[name]
+	return t, ok
+}
+
+// Tables returns the names of base tables sorted alphabetically.
+func (at *AllowedTables) Tables() []string {
+	return at.relationNames(func(t *Table) bool { return !t.IsView() })
+}
+
+// Views returns the names of views and materialized views sorted alphabetically.
+func (at *AllowedTables) Views() []string {
+	return at.relationNames(func(t *Table) bool { return t.IsView() })
+}
+
+func (at *AllowedTables) relationNames(filter func(*Table) bool) []string {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+
+	var names []string
+	for name, t := range at.tables {
+		if filter(t) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (at *AllowedTables) Enum(name string) (*Enum, bool) {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+
+	e, ok := at.enums[name]
+	return e, ok
+}
+
+func (at *AllowedTables) Enums() []*Enum {
+	at.mu.RLock()
+	defer at.mu.RUnlock()
+
+	enums := make([]*Enum, 0, len(at.enums))
+	for _, e := range at.enums {
+		enums = append(enums, e)
+	}
+	sort.Slice(enums, func(i, j int) bool { return enums[i].Name < enums[j].Name })
+	return enums
+}
+
+// ReferencingTables returns the tables holding a foreign key to the given table.
+func (at *AllowedTables) ReferencingTables(table string) []string {
+	return at.relationNames(func(t *Table) bool { return len(t.ForeignKeysTo(table)) > 0 })
+}
+
+// LoadOrder sorts base tables so that referenced tables come first. Foreign
+// keys to other schemas are left out.
+func (at *AllowedTables) LoadOrder() ([]string, error) {
+	tables := at.Tables()
+	deps := make(map[string]map[string]struct{}, len(tables))
+	for _, name := range tables {
+		t, _ := at.Table(name)
+		deps[name] = make(map[string]struct{})
+		for _, fk := range t.ForeignKeys {
+			if fk.RefTable != name && fk.RefSchema == t.Schema {
+				deps[name][fk.RefTable] = struct{}{}
+			}
+		}
 	}
+
+	var order []string
+	done := make(map[string]bool, len(tables))
+	for len(order) < len(tables) {
+		progressed := false
+		for _, name := range tables {
+			if done[name] {
+				continue
+			}
+			ready := true
+			for dep := range deps[name] {
+				if _, known := deps[dep]; known && !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				done[name] = true
+				order = append(order, name)
+				progressed = true
+			}
+		}
+		if !progressed {
+			return nil, fmt.Errorf("foreign keys between tables form a cycle")
+		}
+	}
+
+	return order, nil
 }
 
-func (at *AllowedTables) Initialize(ctx context.Context, db *pgxpool.Pool, schema string) error {
-	tablesQuery := `
-        SELECT table_name
-        FROM information_schema.tables
-        WHERE table_schema = $1
-          AND table_type = 'BASE TABLE';
-    `
-	rows, err := db.Query(ctx, tablesQuery, schema)
+func loadRelations(ctx context.Context, db Querier, schema string) (map[string]*Table, error) {
+	rows, err := db.Query(ctx, `
+        SELECT c.relname,
+               c.relkind::text,
+               c.relrowsecurity,
+               c.relforcerowsecurity,
+               CASE WHEN c.relkind IN ('v', 'm') THEN pg_get_viewdef(c.oid) ELSE '' END
+        FROM pg_catalog.pg_class c
+        JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = $1
+          AND c.relkind IN ('r', 'p', 'v', 'm');
+    `, schema)
 	if err != nil {
-		return fmt.Errorf("failed to retrieve tables: %w", err)
+		return nil, fmt.Errorf("failed to retrieve tables: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make(map[string]*Table)
+	for rows.Next() {
+		var t Table
+		var relkind string
+		if err := rows.Scan(&t.Name, &relkind, &t.RowLevelSecurity, &t.ForceRLS, &t.ViewDefinition); err != nil {
+			return nil, fmt.Errorf("failed to scan table: %w", err)
+		}
+		t.Schema = schema
+		t.Kind = relationKindFromRelkind(relkind)
+		tables[t.Name] = &t
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+func loadColumns(ctx context.Context, db Querier, schema string, tables map[string]*Table) error {
+	rows, err := db.Query(ctx, `
+        SELECT c.relname,
+               a.attname,
+               a.attnum,
+               format_type(a.atttypid, a.atttypmod),
+               t.typname,
+               NOT a.attnotnull,
+               pg_get_expr(d.adbin, d.adrelid),
+               a.attidentity <> ''
+        FROM pg_catalog.pg_attribute a
+        JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+        JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+        JOIN pg_catalog.pg_type t ON t.oid = a.atttypid
+        LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+        WHERE n.nspname = $1
+          AND c.relkind IN ('r', 'p', 'v', 'm')
+          AND a.attnum > 0
+          AND NOT a.attisdropped
+        ORDER BY c.relname, a.attnum;
+    `, schema)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve columns: %w", err)
 	}
 	defer rows.Close()
 
-	var tables []string
 	for rows.Next() {
 		var table string
-		if err := rows.Scan(&table); err != nil {
-			return fmt.Errorf("failed to scan table name: %w", err)
+		var col Column
+		if err := rows.Scan(&table, &col.Name, &col.Position, &col.Type, &col.UDTName, &col.Nullable, &col.Default, &col.Identity); err != nil {
+			return fmt.Errorf("failed to scan column: %w", err)
+		}
+		if t, ok := tables[table]; ok {
+			t.Columns = append(t.Columns, col)
 		}
-		tables = append(tables, table)
 	}
 
 	if err := rows.Err(); err != nil {
-		return fmt.Errorf("error iterating tables: %w", err)
-	}
-
-	for _, table := range tables {
-		columnsQuery := `
-            SELECT column_name
-            FROM information_schema.columns
-            WHERE table_schema = $1
-              AND table_name = $2;
-        `
-		columnsRows, err := db.Query(ctx, columnsQuery, schema, table)
-		if err != nil {
-			return fmt.Errorf("failed to retrieve columns for table %s: %w", table, err)
+		return fmt.Errorf("error iterating columns: %w", err)
+	}
+
+	return nil
+}
+
+func loadConstraints(ctx context.Context, db Querier, schema string, tables map[string]*Table) error {
+	rows, err := db.Query(ctx, `
+        SELECT c.relname,
+               con.conname,
+               con.contype::text,
+               ARRAY(
+                   SELECT a.attname::text
+                   FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+                   JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+                   ORDER BY k.ord
+               ),
+               COALESCE(fn.nspname::text, ''),
+               COALESCE(fc.relname::text, ''),
+               ARRAY(
+                   SELECT a.attname::text
+                   FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+                   JOIN pg_catalog.pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+                   ORDER BY k.ord
+               ),
+               con.confdeltype::text,
+               con.confupdtype::text,
+               pg_get_constraintdef(con.oid)
+        FROM pg_catalog.pg_constraint con
+        JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+        JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+        LEFT JOIN pg_catalog.pg_class fc ON fc.oid = con.confrelid
+        LEFT JOIN pg_catalog.pg_namespace fn ON fn.oid = fc.relnamespace
+        WHERE n.nspname = $1
+          AND con.contype IN ('p', 'f', 'u', 'c')
+        ORDER BY c.relname, con.conname;
+    `, schema)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve constraints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			table, name, contype           string
+			refSchema, refTable            string
+			columns, refColumns            []string
+			onDelete, onUpdate, definition string
+		)
+		if err := rows.Scan(&table, &name, &contype, &columns, &refSchema, &refTable, &refColumns, &onDelete, &onUpdate, &definition); err != nil {
+			return fmt.Errorf("failed to scan constraint: %w", err)
 		}
 
-		var columns []string
-		for columnsRows.Next() {
-			var column string
-			if err := columnsRows.Scan(&column); err != nil {
-				columnsRows.Close()
-				return fmt.Errorf("failed to scan column for table %s: %w", table, err)
-			}
-			columns = append(columns, column)
+		t, ok := tables[table]
+		if !ok {
+			continue
 		}
-		columnsRows.Close()
 
-		if err := columnsRows.Err(); err != nil {
-			return fmt.Errorf("error iterating columns for table %s: %w", table, err)
+		switch contype {
+		case "p":
+			t.PrimaryKey = columns
+		case "f":
+			t.ForeignKeys = append(t.ForeignKeys, ForeignKey{
+				Name:       name,
+				Columns:    columns,
+				RefSchema:  refSchema,
+				RefTable:   refTable,
+				RefColumns: refColumns,
+				OnDelete:   foreignKeyAction(onDelete),
+				OnUpdate:   foreignKeyAction(onUpdate),
+			})
+		case "u":
+			t.Uniques = append(t.Uniques, UniqueConstraint{Name: name, Columns: columns})
+		case "c":
+			t.Checks = append(t.Checks, CheckConstraint{Name: name, Columns: columns, Definition: definition})
 		}
+	}
 
-		at.tables[table] = columns
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating constraints: %w", err)
 	}
 
 	return nil
 }
 
-func (at *AllowedTables) IsValid(table, column string) bool {
-	columns, exists := at.tables[table]
-	if !exists {
-		return false
+func foreignKeyAction(code string) string {
+	switch code {
+	case "r":
+		return "RESTRICT"
+	case "c":
+		return "CASCADE"
+	case "n":
+		return "SET NULL"
+	case "d":
+		return "SET DEFAULT"
+	default:
+		return "NO ACTION"
 	}
-	if column == "" {
-		return true
+}
+
+func loadTriggers(ctx context.Context, db Querier, schema string, tables map[string]*Table) error {
+	rows, err := db.Query(ctx, `
+        SELECT c.relname,
+               t.tgname,
+               p.proname,
+               t.tgtype::int,
+               pg_get_triggerdef(t.oid)
+        FROM pg_catalog.pg_trigger t
+        JOIN pg_catalog.pg_class c ON c.oid = t.tgrelid
+        JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+        JOIN pg_catalog.pg_proc p ON p.oid = t.tgfoid
+        WHERE n.nspname = $1
+          AND NOT t.tgisinternal
+        ORDER BY c.relname, t.tgname;
+    `, schema)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve triggers: %w", err)
 	}
-	for _, col := range columns {
-		if col == column {
-			return true
+	defer rows.Close()
+
+	for rows.Next() {
+		var tr Trigger
+		var tgtype int
+		if err := rows.Scan(&tr.Table, &tr.Name, &tr.Function, &tgtype, &tr.Definition); err != nil {
+			return fmt.Errorf("failed to scan trigger: %w", err)
+		}
+		tr.Timing, tr.Events, tr.ForEachRow = decodeTriggerType(tgtype)
+		if t, ok := tables[tr.Table]; ok {
+			t.Triggers = append(t.Triggers, tr)
 		}
 	}
-	return false
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating triggers: %w", err)
+	}
+
+	return nil
+}
+
+// decodeTriggerType unpacks pg_trigger.tgtype, see TRIGGER_TYPE_* in
+// src/include/catalog/pg_trigger.h.
+func decodeTriggerType(tgtype int) (timing string, events []string, forEachRow bool) {
+	const (
+		typeRow      = 1 << 0
+		typeBefore   = 1 << 1
+		typeInsert   = 1 << 2
+		typeDelete   = 1 << 3
+		typeUpdate   = 1 << 4
+		typeTruncate = 1 << 5
+		typeInstead  = 1 << 6
+	)
+
+	switch {
+	case tgtype&typeInstead != 0:
+		timing = "INSTEAD OF"
+	case tgtype&typeBefore != 0:
+		timing = "BEFORE"
+	default:
+		timing = "AFTER"
+	}
+
+	if tgtype&typeInsert != 0 {
+		events = append(events, "INSERT")
+	}
+	if tgtype&typeUpdate != 0 {
+		events = append(events, "UPDATE")
+	}
+	if tgtype&typeDelete != 0 {
+		events = append(events, "DELETE")
+	}
+	if tgtype&typeTruncate != 0 {
+		events = append(events, "TRUNCATE")
+	}
+
+	return timing, events, tgtype&typeRow != 0
+}
+
+func loadPolicies(ctx context.Context, db Querier, schema string, tables map[string]*Table) error {
+	rows, err := db.Query(ctx, `
+        SELECT tablename::text,
+               policyname::text,
+               cmd,
+               roles::text[],
+               permissive = 'PERMISSIVE',
+               qual,
+               with_check
+        FROM pg_catalog.pg_policies
+        WHERE schemaname = $1
+        ORDER BY tablename, policyname;
+    `, schema)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve policies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Policy
+		if err := rows.Scan(&p.Table, &p.Name, &p.Command, &p.Roles, &p.Permissive, &p.Using, &p.WithCheck); err != nil {
+			return fmt.Errorf("failed to scan policy: %w", err)
+		}
+		if t, ok := tables[p.Table]; ok {
+			t.Policies = append(t.Policies, p)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating policies: %w", err)
+	}
+
+	return nil
+}
+
+func loadEnums(ctx context.Context, db Querier, schema string) (map[string]*Enum, error) {
+	rows, err := db.Query(ctx, `
+        SELECT t.typname::text,
+               array_agg(e.enumlabel::text ORDER BY e.enumsortorder)
+        FROM pg_catalog.pg_type t
+        JOIN pg_catalog.pg_enum e ON e.enumtypid = t.oid
+        JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+        WHERE n.nspname = $1
+        GROUP BY t.typname;
+    `, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve enums: %w", err)
+	}
+	defer rows.Close()
+
+	enums := make(map[string]*Enum)
+	for rows.Next() {
+		var e Enum
+		if err := rows.Scan(&e.Name, &e.Values); err != nil {
+			return nil, fmt.Errorf("failed to scan enum: %w", err)
+		}
+		enums[e.Name] = &e
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating enums: %w", err)
+	}
+
+	return enums, nil
 }
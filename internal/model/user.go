@@ -1,6 +1,8 @@
 package model
 
 type User struct {
-	Login    string `json:"login"`
-	Password string `json:"pass"`
+	Login           string `json:"login"`
+	Password        string `json:"pass"`
+	Role            string `json:"role"`
+	ServiceCenterID int    `json:"service_center_id"`
 }
@@ -0,0 +1,225 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"vehicles-service-stations/internal/telegram"
+)
+
+// postJSON sends body and decodes the response into out. Client errors other
+// than rate limiting are permanent.
+func postJSON(ctx context.Context, client *http.Client, url string, header http.Header, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		err := fmt.Errorf("responded %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusRequestTimeout {
+			return fmt.Errorf("%w: %v", ErrPermanent, err)
+		}
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+	}
+	return nil
+}
+
+// SMSChannel sends text messages through the HTTP API of an SMS gateway:
+//
+//	POST <url> {"to": "+79990000000", "text": "..."} -> {"id": "..."}
+//
+// authorized with a bearer token.
+type SMSChannel struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func NewSMSChannel(url, token string) (*SMSChannel, error) {
+	if url == "" {
+		return nil, fmt.Errorf("sms gateway url is empty")
+	}
+	return &SMSChannel{url: url, token: token, client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (c *SMSChannel) Name() string { return ChannelSMS }
+
+func (c *SMSChannel) Send(ctx context.Context, d *Delivery) (string, error) {
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	}
+	var resp struct {
+		ID string `json:"id"`
+	}
+	err := postJSON(ctx, c.client, c.url, header, map[string]string{"to": d.Recipient, "text": d.Body}, &resp)
+	return resp.ID, err
+}
+
+// TelegramChannel sends messages through the Telegram Bot API.
+type TelegramChannel struct {
+	client *telegram.Client
+}
+
+func NewTelegramChannel(apiURL, token string) (*TelegramChannel, error) {
+	client, err := telegram.NewClient(apiURL, token)
+	if err != nil {
+		return nil, err
+	}
+	return &TelegramChannel{client: client}, nil
+}
+
+func (c *TelegramChannel) Name() string { return ChannelTelegram }
+
+func (c *TelegramChannel) Send(ctx context.Context, d *Delivery) (string, error) {
+	chatID, err := strconv.ParseInt(d.Recipient, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid chat id %q", ErrPermanent, d.Recipient)
+	}
+	msg, err := c.client.SendMessage(ctx, telegram.SendMessage{ChatID: chatID, Text: d.Body})
+	var apiErr *telegram.Error
+	if errors.As(err, &apiErr) && !apiErr.Temporary() {
+		return "", fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(msg.MessageID, 10), nil
+}
+
+// EmailChannel sends plain text mails through an SMTP server, upgrading the
+// connection with STARTTLS when the server offers it.
+type EmailChannel struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+func NewEmailChannel(addr, from, username, password string) (*EmailChannel, error) {
+	if addr == "" || from == "" {
+		return nil, fmt.Errorf("smtp address and sender are required")
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return nil, fmt.Errorf("invalid smtp address: %w", err)
+	}
+	return &EmailChannel{addr: addr, from: from, username: username, password: password}, nil
+}
+
+func (c *EmailChannel) Name() string { return ChannelEmail }
+
+func (c *EmailChannel) Send(ctx context.Context, d *Delivery) (string, error) {
+	host, _, _ := net.SplitHostPort(c.addr)
+	messageID := fmt.Sprintf("<delivery-%d-%d@%s>", d.EventID, d.ID, host)
+
+	err := c.send(ctx, host, d.Recipient, c.message(d, messageID))
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return "", fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	if err != nil {
+		return "", err
+	}
+	return messageID, nil
+}
+
+func (c *EmailChannel) message(d *Delivery, messageID string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", c.from)
+	fmt.Fprintf(&b, "To: %s\r\n", d.Recipient)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "Message-ID: %s\r\n", messageID)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(d.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+func (c *EmailChannel) send(ctx context.Context, host, to string, msg []byte) error {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if c.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.username, c.password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/time/rate"
+)
+
+// Route is a channel with its rate limit in messages per second, unlimited
+// when Rate is zero.
+type Route struct {
+	Channel Channel
+	Rate    float64
+	Burst   int
+}
+
+type DispatcherOptions struct {
+	// BatchSize is the number of deliveries claimed per transaction. The
+	// transaction lasts while the batch waits for the rate limits.
+	BatchSize int
+	// MaxAttempts is the number of failed sends after which a delivery fails.
+	MaxAttempts int
+	// Backoff delays the next attempt, doubling from MinBackoff up to
+	// MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// PollInterval is the pause of Run once nothing is pending.
+	PollInterval time.Duration
+	// OnBatch and OnError report the progress of Run.
+	OnBatch func(DispatchResult)
+	OnError func(error)
+}
+
+func (o *DispatcherOptions) defaults() {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 20
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 5
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = 5 * time.Second
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = max(time.Hour, o.MinBackoff)
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+}
+
+func (o *DispatcherOptions) backoff(attempts int) time.Duration {
+	d := o.MinBackoff
+	for i := 1; i < attempts && d < o.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, o.MaxBackoff)
+}
+
+type DispatchResult struct {
+	Claimed int
+	Sent    int
+	Retried int
+	Failed  int
+}
+
+// Dispatcher sends the pending deliveries of its channels. Deliveries of
+// other channels are left to other dispatchers; several dispatchers may serve
+// the same channel.
+type Dispatcher struct {
+	db       DB
+	channels map[string]Channel
+	limiters map[string]*rate.Limiter
+	names    []string
+	opts     DispatcherOptions
+}
+
+func NewDispatcher(db DB, routes []Route, opts DispatcherOptions) *Dispatcher {
+	opts.defaults()
+	d := &Dispatcher{
+		db:       db,
+		channels: make(map[string]Channel),
+		limiters: make(map[string]*rate.Limiter),
+		opts:     opts,
+	}
+	for _, r := range routes {
+		name := r.Channel.Name()
+		d.channels[name] = r.Channel
+		d.names = append(d.names, name)
+		limit := rate.Inf
+		if r.Rate > 0 {
+			limit = rate.Limit(r.Rate)
+		}
+		d.limiters[name] = rate.NewLimiter(limit, max(r.Burst, 1))
+	}
+	return d
+}
+
+// Run dispatches until ctx is done. Errors are reported to OnError and retried
+// after PollInterval.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		res, err := d.RunOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && d.opts.OnError != nil {
+			d.opts.OnError(err)
+		}
+		if err == nil && res.Claimed > 0 && d.opts.OnBatch != nil {
+			d.opts.OnBatch(res)
+		}
+		if err == nil && res.Claimed == d.opts.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(d.opts.PollInterval):
+		}
+	}
+}
+
+// RunOnce claims a batch of due deliveries, sends them and records the
+// outcome. When ctx is cancelled midway the deliveries sent so far are still
+// recorded, the others stay pending.
+func (d *Dispatcher) RunOnce(ctx context.Context) (DispatchResult, error) {
+	var res DispatchResult
+
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	rows, err := tx.Query(ctx, `
+        SELECT delivery_id, event_id, kind, customer_id, order_id, channel, recipient, language, subject, body, attempts
+        FROM notifications.deliveries
+        WHERE status = 'pending' AND available_at <= now() AND channel = ANY($2)
+        ORDER BY delivery_id
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    `, d.opts.BatchSize, d.names)
+	if err != nil {
+		return res, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Delivery, error) {
+		var dl Delivery
+		var kind, lang string
+		err := row.Scan(&dl.ID, &dl.EventID, &kind, &dl.CustomerID, &dl.OrderID, &dl.Channel, &dl.Recipient,
+			&lang, &dl.Subject, &dl.Body, &dl.Attempts)
+		dl.Kind, dl.Language = Kind(kind), Language(lang)
+		return &dl, err
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to claim deliveries: %w", err)
+	}
+	res.Claimed = len(deliveries)
+
+	batch := &pgx.Batch{}
+	for _, dl := range deliveries {
+		if err := d.limiters[dl.Channel].Wait(ctx); err != nil {
+			break
+		}
+
+		providerID, err := d.channels[dl.Channel].Send(ctx, dl)
+		switch {
+		case err == nil:
+			res.Sent++
+			batch.Queue(`
+                UPDATE notifications.deliveries
+                SET status = 'sent', sent_at = now(), attempts = attempts + 1, last_error = NULL, provider_id = NULLIF($2, '')
+                WHERE delivery_id = $1
+            `, dl.ID, providerID)
+		case errors.Is(err, ErrPermanent) || dl.Attempts+1 >= d.opts.MaxAttempts:
+			res.Failed++
+			batch.Queue(`
+                UPDATE notifications.deliveries
+                SET status = 'failed', attempts = attempts + 1, last_error = $2
+                WHERE delivery_id = $1
+            `, dl.ID, err.Error())
+		default:
+			res.Retried++
+			batch.Queue(`
+                UPDATE notifications.deliveries
+                SET available_at = now() + $3::interval, attempts = attempts + 1, last_error = $2
+                WHERE delivery_id = $1
+            `, dl.ID, err.Error(), d.opts.backoff(dl.Attempts+1))
+		}
+	}
+
+	recordCtx := context.WithoutCancel(ctx)
+	if batch.Len() > 0 {
+		if err := tx.SendBatch(recordCtx, batch).Close(); err != nil {
+			return res, fmt.Errorf("failed to record deliveries: %w", err)
+		}
+	}
+	if err := tx.Commit(recordCtx); err != nil {
+		return res, fmt.Errorf("failed to record deliveries: %w", err)
+	}
+	return res, nil
+}
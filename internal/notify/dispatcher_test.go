@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     DispatcherOptions
+		attempts int
+		want     time.Duration
+	}{
+		{"defaults first", DispatcherOptions{}, 1, 5 * time.Second},
+		{"defaults doubled", DispatcherOptions{}, 3, 20 * time.Second},
+		{"defaults capped", DispatcherOptions{}, 20, time.Hour},
+		{"first", DispatcherOptions{MinBackoff: time.Second, MaxBackoff: time.Minute}, 1, time.Second},
+		{"no attempts yet", DispatcherOptions{MinBackoff: time.Second, MaxBackoff: time.Minute}, 0, time.Second},
+		{"doubled", DispatcherOptions{MinBackoff: time.Second, MaxBackoff: time.Minute}, 4, 8 * time.Second},
+		{"capped", DispatcherOptions{MinBackoff: time.Second, MaxBackoff: 10 * time.Second}, 5, 10 * time.Second},
+		{"max below min", DispatcherOptions{MinBackoff: 2 * time.Hour, MaxBackoff: time.Minute}, 3, 2 * time.Hour},
+		{"many attempts", DispatcherOptions{MinBackoff: time.Second, MaxBackoff: time.Minute}, 1000, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.defaults()
+			if got := tt.opts.backoff(tt.attempts); got != tt.want {
+				t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+type namedChannel string
+
+func (c namedChannel) Name() string { return string(c) }
+
+func (c namedChannel) Send(ctx context.Context, d *Delivery) (string, error) { return "", nil }
+
+// A route with a rate lets its burst through and spaces the rest, a route
+// without one is not limited.
+func TestRouteLimits(t *testing.T) {
+	d := NewDispatcher(nil, []Route{
+		{Channel: namedChannel(ChannelSMS), Rate: 2, Burst: 3},
+		{Channel: namedChannel(ChannelEmail)},
+	}, DispatcherOptions{})
+
+	now := time.Now()
+	sms := d.limiters[ChannelSMS]
+	for i := range 3 {
+		if delay := sms.ReserveN(now, 1).DelayFrom(now); delay != 0 {
+			t.Fatalf("sms message %d of the burst delayed by %s", i+1, delay)
+		}
+	}
+	if delay := sms.ReserveN(now, 1).DelayFrom(now); delay != 500*time.Millisecond {
+		t.Errorf("sms message past the burst delayed by %s, want 500ms", delay)
+	}
+
+	email := d.limiters[ChannelEmail]
+	if email.Limit() != rate.Inf {
+		t.Errorf("email limited to %v messages per second", email.Limit())
+	}
+	for i := range 100 {
+		if delay := email.ReserveN(now, 1).DelayFrom(now); delay != 0 {
+			t.Fatalf("email message %d delayed by %s", i+1, delay)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeMessage is a message received by one of the fake providers.
+type FakeMessage struct {
+	Channel string
+	ID      string
+	To      string
+	Subject string
+	Text    string
+}
+
+// fakeInbox records messages and can be told to fail the next requests.
+type fakeInbox struct {
+	mu        sync.Mutex
+	messages  []FakeMessage
+	failNext  int
+	OnMessage func(FakeMessage)
+}
+
+// FailNext makes the next n sends fail with a temporary error.
+func (f *fakeInbox) FailNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = n
+}
+
+func (f *fakeInbox) Messages() []FakeMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeMessage(nil), f.messages...)
+}
+
+func (f *fakeInbox) shouldFail() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failNext > 0 {
+		f.failNext--
+		return true
+	}
+	return false
+}
+
+func (f *fakeInbox) add(m FakeMessage) FakeMessage {
+	f.mu.Lock()
+	m.ID = strconv.Itoa(len(f.messages) + 1)
+	f.messages = append(f.messages, m)
+	onMessage := f.OnMessage
+	f.mu.Unlock()
+	if onMessage != nil {
+		onMessage(m)
+	}
+	return m
+}
+
+// FakeSMSGateway serves the API expected by SMSChannel on any path.
+type FakeSMSGateway struct {
+	fakeInbox
+	// Token is the expected bearer token, any token is accepted when empty.
+	Token string
+}
+
+func (f *FakeSMSGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+		return
+	}
+	if f.Token != "" && r.Header.Get("Authorization") != "Bearer "+f.Token {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	if f.shouldFail() {
+		http.Error(w, "gateway unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	var req struct {
+		To   string `json:"to"`
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil || req.To == "" || req.Text == "" {
+		http.Error(w, "to and text are required", http.StatusBadRequest)
+		return
+	}
+	m := f.add(FakeMessage{Channel: ChannelSMS, To: req.To, Text: req.Text})
+	json.NewEncoder(w).Encode(map[string]string{"id": "sms-" + m.ID})
+}
+
+// FakeSMTP is a minimal SMTP server without TLS or authentication, enough for
+// EmailChannel.
+type FakeSMTP struct {
+	fakeInbox
+}
+
+func (f *FakeSMTP) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *FakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Minute))
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...any) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	reply("220 fake-smtp ready")
+	var to []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250 fake-smtp")
+		case "MAIL":
+			to = nil
+			reply("250 OK")
+		case "RCPT":
+			addr := arg
+			if len(addr) >= 3 && strings.EqualFold(addr[:3], "TO:") {
+				addr = addr[3:]
+			}
+			to = append(to, strings.Trim(addr, "<> "))
+			reply("250 OK")
+		case "DATA":
+			if f.shouldFail() {
+				reply("451 try again later")
+				continue
+			}
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" || l == ".\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			for _, addr := range to {
+				f.add(parseFakeMail(addr, data.String()))
+			}
+			reply("250 OK")
+		case "RSET":
+			to = nil
+			reply("250 OK")
+		case "NOOP":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+func parseFakeMail(to, data string) FakeMessage {
+	m := FakeMessage{Channel: ChannelEmail, To: to, Text: data}
+	msg, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		return m
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); err == nil {
+		m.Subject = subject
+	}
+	if body, err := io.ReadAll(msg.Body); err == nil {
+		m.Text = strings.TrimRight(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	}
+	return m
+}
@@ -0,0 +1,105 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+)
+
+// LogEntry is a delivery with its outcome.
+type LogEntry struct {
+	Delivery
+	Status     string
+	LastError  string
+	ProviderID string
+	CreatedAt  time.Time
+	SentAt     *time.Time
+}
+
+type LogFilter struct {
+	CustomerID int
+	OrderID    int
+	Status     string
+	Channel    string
+	Limit      uint64
+}
+
+// Log lists deliveries, newest first.
+func Log(ctx context.Context, db DB, f LogFilter) ([]LogEntry, error) {
+	q := sq.Select(
+		"delivery_id", "event_id", "kind", "customer_id", "order_id", "channel", "recipient", "language",
+		"subject", "body", "attempts", "status", "COALESCE(last_error, '')", "COALESCE(provider_id, '')",
+		"created_at", "sent_at",
+	).From("notifications.deliveries").OrderBy("delivery_id DESC").PlaceholderFormat(sq.Dollar)
+	if f.CustomerID != 0 {
+		q = q.Where(sq.Eq{"customer_id": f.CustomerID})
+	}
+	if f.OrderID != 0 {
+		q = q.Where(sq.Eq{"order_id": f.OrderID})
+	}
+	if f.Status != "" {
+		q = q.Where(sq.Eq{"status": f.Status})
+	}
+	if f.Channel != "" {
+		q = q.Where(sq.Eq{"channel": f.Channel})
+	}
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+
+	sql, args, err := q.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delivery log: %w", err)
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (LogEntry, error) {
+		var e LogEntry
+		var kind, lang string
+		err := row.Scan(&e.ID, &e.EventID, &kind, &e.CustomerID, &e.OrderID, &e.Channel, &e.Recipient, &lang,
+			&e.Subject, &e.Body, &e.Attempts, &e.Status, &e.LastError, &e.ProviderID, &e.CreatedAt, &e.SentAt)
+		e.Kind, e.Language = Kind(kind), Language(lang)
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delivery log: %w", err)
+	}
+	return entries, nil
+}
+
+// Contact is how a customer is reached besides their phone number.
+type Contact struct {
+	CustomerID     int
+	Email          *string
+	TelegramChatID *int64
+	Language       Language
+	SMSEnabled     bool
+}
+
+// SetContact creates or replaces the contact of a customer.
+func SetContact(ctx context.Context, db DB, c Contact) error {
+	if c.Language == "" {
+		c.Language = LangRU
+	}
+	if _, ok := messages[c.Language]; !ok {
+		return fmt.Errorf("unsupported language %q", c.Language)
+	}
+	_, err := db.Exec(ctx, `
+        INSERT INTO notifications.contacts (customer_id, email, telegram_chat_id, language, sms_enabled)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (customer_id) DO UPDATE
+        SET email = EXCLUDED.email,
+            telegram_chat_id = EXCLUDED.telegram_chat_id,
+            language = EXCLUDED.language,
+            sms_enabled = EXCLUDED.sms_enabled
+    `, c.CustomerID, c.Email, c.TelegramChatID, string(c.Language), c.SMSEnabled)
+	if err != nil {
+		return fmt.Errorf("failed to save contact of customer %d: %w", c.CustomerID, err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/events"
+	"vehicles-service-stations/internal/outbox"
+)
+
+// Notifier turns outbox events into deliveries. It is an outbox.Broker, so an
+// outbox relay feeds it; deliveries are sent later by a Dispatcher.
+type Notifier struct {
+	db       DB
+	channels []string
+}
+
+// NewNotifier renders deliveries for the given channels only.
+func NewNotifier(db DB, channels []string) *Notifier {
+	return &Notifier{db: db, channels: channels}
+}
+
+func (n *Notifier) Name() string { return "notifications" }
+
+func (n *Notifier) Publish(ctx context.Context, msg *outbox.Message) error {
+	event, err := msg.Decode(events.Default)
+	if err != nil {
+		return fmt.Errorf("%w: %v", outbox.ErrPermanent, err)
+	}
+
+	var kind Kind
+	var orderID int
+	data := &Data{}
+	switch e := event.(type) {
+	case events.OrderCreated:
+		kind, orderID = KindBooked, e.OrderID
+		if data.ScheduledDate, err = time.Parse(time.DateOnly, e.ScheduledDate); err != nil {
+			return fmt.Errorf("%w: %v", outbox.ErrPermanent, err)
+		}
+	case events.OrderRescheduled:
+		kind, orderID = KindRescheduled, e.OrderID
+		if data.PreviousDate, err = time.Parse(time.DateOnly, e.From); err != nil {
+			return fmt.Errorf("%w: %v", outbox.ErrPermanent, err)
+		}
+		if data.ScheduledDate, err = time.Parse(time.DateOnly, e.To); err != nil {
+			return fmt.Errorf("%w: %v", outbox.ErrPermanent, err)
+		}
+	case events.OrderMasterChanged:
+		kind, orderID = KindMasterChanged, e.OrderID
+	case events.OrderStatusChanged:
+		if e.To != "Completed" {
+			return nil
+		}
+		kind, orderID = KindCompleted, e.OrderID
+	case events.ReceiptIssued:
+		kind, orderID = KindReceipt, e.OrderID
+		data.TotalPaid, data.BonusPointsSpent = e.TotalPaid, e.BonusPointsSpent
+	case events.OrderReminderDue:
+		kind, orderID = KindReminder, e.OrderID
+		if data.ScheduledDate, err = time.Parse(time.DateOnly, e.ScheduledDate); err != nil {
+			return fmt.Errorf("%w: %v", outbox.ErrPermanent, err)
+		}
+	default:
+		return nil
+	}
+
+	deliveries, err := n.render(ctx, msg.ID, kind, orderID, data)
+	if err != nil {
+		return err
+	}
+	return n.save(ctx, deliveries)
+}
+
+func (n *Notifier) enabled(channel string) bool {
+	return slices.Contains(n.channels, channel)
+}
+
+// render fills data with the current state of the order, values carried by
+// the event take precedence. Deleted orders are not notified about.
+func (n *Notifier) render(ctx context.Context, eventID int64, kind Kind, orderID int, data *Data) ([]*Delivery, error) {
+	var (
+		customerID  int
+		phone       string
+		email       *string
+		chatID      *int64
+		language    string
+		smsEnabled  bool
+		scheduledAt time.Time
+	)
+	err := n.db.QueryRow(ctx, `
+        SELECT c.customer_id, c.full_name, c.phone_number, ct.email, ct.telegram_chat_id,
+               COALESCE(ct.language, 'ru'), COALESCE(ct.sms_enabled, TRUE),
+               sc.full_address, sc.city, sc.phone_number, o.scheduled_date, COALESCE(m.full_name, '')
+        FROM orders o
+        JOIN customers c ON c.customer_id = o.customer_id
+        JOIN service_centers sc ON sc.service_center_id = o.service_center_id
+        LEFT JOIN employees m ON m.employee_id = COALESCE(o.reassigned_master_id, o.assigned_master_id)
+        LEFT JOIN notifications.contacts ct ON ct.customer_id = c.customer_id
+        WHERE o.order_id = $1
+    `, orderID).Scan(&customerID, &data.CustomerName, &phone, &email, &chatID, &language, &smsEnabled,
+		&data.CenterAddress, &data.CenterCity, &data.CenterPhone, &scheduledAt, &data.MasterName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order %d: %w", orderID, err)
+	}
+	data.OrderID = orderID
+	if data.ScheduledDate.IsZero() {
+		data.ScheduledDate = scheduledAt
+	}
+	lang := Language(language)
+	if _, ok := messages[lang]; !ok {
+		lang = LangRU
+	}
+
+	subject, body, err := Render(lang, kind, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", outbox.ErrPermanent, err)
+	}
+
+	recipients := make(map[string]string)
+	if smsEnabled {
+		recipients[ChannelSMS] = phone
+	}
+	if email != nil {
+		recipients[ChannelEmail] = *email
+	}
+	if chatID != nil {
+		recipients[ChannelTelegram] = strconv.FormatInt(*chatID, 10)
+	}
+
+	var out []*Delivery
+	for _, channel := range []string{ChannelSMS, ChannelEmail, ChannelTelegram} {
+		recipient, ok := recipients[channel]
+		if !ok || !n.enabled(channel) {
+			continue
+		}
+		out = append(out, &Delivery{
+			EventID:    eventID,
+			Kind:       kind,
+			CustomerID: customerID,
+			OrderID:    orderID,
+			Channel:    channel,
+			Recipient:  recipient,
+			Language:   lang,
+			Subject:    subject,
+			Body:       body,
+		})
+	}
+	return out, nil
+}
+
+// save logs the deliveries as pending. An event is rendered once per channel,
+// a redelivered event changes nothing.
+func (n *Notifier) save(ctx context.Context, deliveries []*Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(`
+            INSERT INTO notifications.deliveries
+                (event_id, kind, customer_id, order_id, channel, recipient, language, subject, body)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+            ON CONFLICT (event_id, channel) DO NOTHING
+        `, d.EventID, string(d.Kind), d.CustomerID, d.OrderID, d.Channel, d.Recipient, string(d.Language), d.Subject, d.Body)
+	}
+
+	tx, err := n.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to log deliveries: %w", err)
+	}
+	return tx.Commit(ctx)
+}
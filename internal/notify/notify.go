@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Kind is what a notification tells the customer about.
+type Kind string
+
+const (
+	KindBooked        Kind = "booking_confirmed"
+	KindRescheduled   Kind = "rescheduled"
+	KindMasterChanged Kind = "master_changed"
+	KindCompleted     Kind = "completed"
+	KindReceipt       Kind = "receipt_issued"
+	KindReminder      Kind = "reminder"
+)
+
+type Language string
+
+const (
+	LangRU Language = "ru"
+	LangEN Language = "en"
+)
+
+const (
+	ChannelSMS      = "sms"
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+)
+
+// Delivery is a notification rendered for one channel, a row of
+// notifications.deliveries.
+type Delivery struct {
+	ID         int64
+	EventID    int64
+	Kind       Kind
+	CustomerID int
+	OrderID    int
+	Channel    string
+	// Recipient is a phone number, an email address or a Telegram chat id
+	// depending on the channel.
+	Recipient string
+	Language  Language
+	Subject   string
+	Body      string
+	Attempts  int
+}
+
+// Channel delivers notifications. Send returns the id the provider assigned
+// to the message, if any.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, d *Delivery) (providerID string, err error)
+}
+
+// ErrPermanent marks failures retrying cannot fix, such as a rejected
+// recipient. The delivery fails right away.
+var ErrPermanent = errors.New("permanent failure")
+
+// DB is satisfied by pools and transactions.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
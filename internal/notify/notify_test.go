@@ -0,0 +1,162 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"vehicles-service-stations/internal/notify"
+	"vehicles-service-stations/internal/telegram"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// Booking and rescheduling of an order reach the customer by SMS and email
+// in their language, through the fake providers.
+func TestBookingAndReschedule(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "notify")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+
+	email := "customer@test.local"
+	err := notify.SetContact(ctx, tx, notify.Contact{CustomerID: b.Customer, Email: &email, Language: notify.LangEN, SMSEnabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+	if _, err := tx.Exec(ctx, `UPDATE orders SET scheduled_date = $1 WHERE order_id = $2`, testutil.Day.AddDate(0, 0, 1), orderID); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := testutil.OrderOutbox(t, tx, orderID)
+	notifier := notify.NewNotifier(tx, []string{notify.ChannelSMS, notify.ChannelEmail})
+	// Every event is published twice, as after a relay crash.
+	for range 2 {
+		for _, m := range messages {
+			if err := notifier.Publish(ctx, m); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	sms := &notify.FakeSMSGateway{Token: "test"}
+	smsServer := httptest.NewServer(sms)
+	defer smsServer.Close()
+	smtp := &notify.FakeSMTP{}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go smtp.Serve(l)
+
+	smsChannel, err := notify.NewSMSChannel(smsServer.URL, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	emailChannel, err := notify.NewEmailChannel(l.Addr().String(), "noreply@test.local", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dispatcher := notify.NewDispatcher(tx, []notify.Route{
+		{Channel: smsChannel},
+		{Channel: emailChannel},
+	}, notify.DispatcherOptions{})
+
+	sms.FailNext(1)
+	res, err := dispatcher.RunOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Claimed != 4 || res.Sent != 3 || res.Retried != 1 {
+		t.Fatalf("first dispatch %+v, want 4 claimed, 3 sent and 1 retried", res)
+	}
+	// now() is fixed in the transaction, so the retry is due only once moved
+	// back.
+	if _, err := tx.Exec(ctx, `UPDATE notifications.deliveries SET available_at = now() WHERE status = 'pending'`); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = dispatcher.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if res.Sent != 1 {
+		t.Fatalf("second dispatch %+v, want the retry sent", res)
+	}
+
+	received := append(sms.Messages(), smtp.Messages()...)
+	if len(received) != 4 {
+		t.Fatalf("providers received %d messages, want 2 SMS and 2 emails", len(received))
+	}
+	// The booking and the rescheduling both mention the booked date.
+	for _, m := range received {
+		if !strings.Contains(m.Text, "Fixture street 1") || !strings.Contains(m.Text, "Jan 10, 2030") {
+			t.Errorf("%s message lacks the address or the date: %q", m.Channel, m.Text)
+		}
+	}
+
+	entries, err := notify.Log(ctx, tx, notify.LogFilter{OrderID: orderID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Status != "sent" {
+			t.Errorf("delivery %d of %s is %s", e.ID, e.Kind, e.Status)
+		}
+	}
+}
+
+// A Telegram delivery goes to the chat of its recipient, rate limiting is
+// retried and a rejected request is not.
+func TestTelegramChannel(t *testing.T) {
+	fake := telegram.NewFakeServer("test")
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	ctx := context.Background()
+
+	channel, err := notify.NewTelegramChannel(server.URL, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := channel.Send(ctx, &notify.Delivery{Recipient: "42", Body: "Your car is ready"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := fake.Sent(42)
+	if len(sent) != 1 || sent[0].Text != "Your car is ready" || id != strconv.FormatInt(sent[0].MessageID, 10) {
+		t.Fatalf("sent %+v with id %s, want the message with its id", sent, id)
+	}
+
+	fake.FailNext(1)
+	if _, err := channel.Send(ctx, &notify.Delivery{Recipient: "42", Body: "again"}); err == nil || errors.Is(err, notify.ErrPermanent) {
+		t.Errorf("rate limited send returned %v, want a temporary error", err)
+	}
+
+	wrongToken, err := notify.NewTelegramChannel(server.URL, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		channel   *notify.TelegramChannel
+		recipient string
+		body      string
+	}{
+		{"invalid chat id", channel, "@customer", "text"},
+		{"empty text", channel, "42", ""},
+		{"unknown bot", wrongToken, "42", "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.channel.Send(ctx, &notify.Delivery{Recipient: tt.recipient, Body: tt.body}); !errors.Is(err, notify.ErrPermanent) {
+				t.Errorf("send returned %v, want a permanent error", err)
+			}
+		})
+	}
+	if n := len(fake.Sent(42)); n != 1 {
+		t.Errorf("chat has %d messages, want only the first one", n)
+	}
+}
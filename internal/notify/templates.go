@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Data is what the templates may refer to.
+type Data struct {
+	CustomerName  string
+	OrderID       int
+	CenterAddress string
+	CenterCity    string
+	CenterPhone   string
+	ScheduledDate time.Time
+	// PreviousDate is set for rescheduled orders.
+	PreviousDate time.Time
+	MasterName   string
+	// TotalPaid and BonusPointsSpent are set for receipts.
+	TotalPaid        float64
+	BonusPointsSpent float64
+}
+
+type message struct {
+	subject string
+	body    string
+}
+
+// SMS and Telegram only carry the body, so it must stand on its own.
+var messages = map[Language]map[Kind]message{
+	LangRU: {
+		KindBooked: {
+			subject: "Запись №{{.OrderID}} подтверждена",
+			body: "{{.CustomerName}}, вы записаны на {{date .ScheduledDate}} в сервисный центр по адресу " +
+				"{{.CenterCity}}, {{.CenterAddress}}. Заказ №{{.OrderID}}, мастер {{.MasterName}}. Телефон центра {{.CenterPhone}}.",
+		},
+		KindRescheduled: {
+			subject: "Запись №{{.OrderID}} перенесена",
+			body: "{{.CustomerName}}, ваша запись №{{.OrderID}} перенесена с {{date .PreviousDate}} на {{date .ScheduledDate}}. " +
+				"Адрес: {{.CenterCity}}, {{.CenterAddress}}. Телефон центра {{.CenterPhone}}.",
+		},
+		KindMasterChanged: {
+			subject: "Новый мастер по заказу №{{.OrderID}}",
+			body: "{{.CustomerName}}, вашим заказом №{{.OrderID}} на {{date .ScheduledDate}} теперь занимается мастер {{.MasterName}}. " +
+				"Адрес: {{.CenterCity}}, {{.CenterAddress}}.",
+		},
+		KindCompleted: {
+			subject: "Заказ №{{.OrderID}} выполнен",
+			body: "{{.CustomerName}}, работы по заказу №{{.OrderID}} завершены. Автомобиль можно забрать по адресу " +
+				"{{.CenterCity}}, {{.CenterAddress}}. Телефон центра {{.CenterPhone}}.",
+		},
+		KindReceipt: {
+			subject: "Чек по заказу №{{.OrderID}}",
+			body: "{{.CustomerName}}, по заказу №{{.OrderID}} оплачено {{money .TotalPaid}} руб." +
+				"{{if .BonusPointsSpent}} Списано бонусов: {{money .BonusPointsSpent}}.{{end}} Спасибо, что выбрали нас!",
+		},
+		KindReminder: {
+			subject: "Напоминание о записи №{{.OrderID}}",
+			body: "{{.CustomerName}}, напоминаем: вы записаны на {{date .ScheduledDate}} в сервисный центр по адресу " +
+				"{{.CenterCity}}, {{.CenterAddress}}, мастер {{.MasterName}}. Телефон центра {{.CenterPhone}}.",
+		},
+	},
+	LangEN: {
+		KindBooked: {
+			subject: "Booking #{{.OrderID}} confirmed",
+			body: "{{.CustomerName}}, you are booked for {{date .ScheduledDate}} at the service center at " +
+				"{{.CenterAddress}}, {{.CenterCity}}. Order #{{.OrderID}}, master {{.MasterName}}. Center phone {{.CenterPhone}}.",
+		},
+		KindRescheduled: {
+			subject: "Booking #{{.OrderID}} rescheduled",
+			body: "{{.CustomerName}}, your booking #{{.OrderID}} moved from {{date .PreviousDate}} to {{date .ScheduledDate}}. " +
+				"Address: {{.CenterAddress}}, {{.CenterCity}}. Center phone {{.CenterPhone}}.",
+		},
+		KindMasterChanged: {
+			subject: "New master for order #{{.OrderID}}",
+			body: "{{.CustomerName}}, your order #{{.OrderID}} on {{date .ScheduledDate}} is now handled by {{.MasterName}}. " +
+				"Address: {{.CenterAddress}}, {{.CenterCity}}.",
+		},
+		KindCompleted: {
+			subject: "Order #{{.OrderID}} completed",
+			body: "{{.CustomerName}}, the work on order #{{.OrderID}} is done. Your vehicle is ready for pickup at " +
+				"{{.CenterAddress}}, {{.CenterCity}}. Center phone {{.CenterPhone}}.",
+		},
+		KindReceipt: {
+			subject: "Receipt for order #{{.OrderID}}",
+			body: "{{.CustomerName}}, you paid {{money .TotalPaid}} RUB for order #{{.OrderID}}." +
+				"{{if .BonusPointsSpent}} Bonus points spent: {{money .BonusPointsSpent}}.{{end}} Thank you for choosing us!",
+		},
+		KindReminder: {
+			subject: "Reminder of booking #{{.OrderID}}",
+			body: "{{.CustomerName}}, a reminder that you are booked for {{date .ScheduledDate}} at the service center at " +
+				"{{.CenterAddress}}, {{.CenterCity}}, master {{.MasterName}}. Center phone {{.CenterPhone}}.",
+		},
+	},
+}
+
+var dateLayouts = map[Language]string{
+	LangRU: "02.01.2006",
+	LangEN: "Jan 2, 2006",
+}
+
+var templates = parseTemplates()
+
+func parseTemplates() map[Language]*template.Template {
+	out := make(map[Language]*template.Template, len(messages))
+	for lang, kinds := range messages {
+		layout := dateLayouts[lang]
+		t := template.New(string(lang)).Option("missingkey=error").Funcs(template.FuncMap{
+			"date":  func(t time.Time) string { return t.Format(layout) },
+			"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+		})
+		for kind, m := range kinds {
+			template.Must(t.New(string(kind) + ".subject").Parse(m.subject))
+			template.Must(t.New(string(kind) + ".body").Parse(m.body))
+		}
+		out[lang] = t
+	}
+	return out
+}
+
+// Render returns the subject and the body of a notification. Unknown
+// languages fall back to Russian.
+func Render(lang Language, kind Kind, data *Data) (subject, body string, err error) {
+	t, ok := templates[lang]
+	if !ok {
+		t = templates[LangRU]
+	}
+	if t.Lookup(string(kind)+".body") == nil {
+		return "", "", fmt.Errorf("no template for %s", kind)
+	}
+
+	var sb strings.Builder
+	if err := t.ExecuteTemplate(&sb, string(kind)+".subject", data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", kind, err)
+	}
+	subject = sb.String()
+	sb.Reset()
+	if err := t.ExecuteTemplate(&sb, string(kind)+".body", data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s: %w", kind, err)
+	}
+	return subject, sb.String(), nil
+}
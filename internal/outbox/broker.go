@@ -0,0 +1,143 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type BrokerKind string
+
+const (
+	BrokerLog  BrokerKind = "log"
+	BrokerHTTP BrokerKind = "http"
+)
+
+type BrokerConfig struct {
+	Kind BrokerKind
+	// Addr is the address of the HTTP broker, e.g. cdc.BrokerServer.
+	Addr string
+}
+
+func NewBroker(cfg BrokerConfig, out io.Writer) (Broker, error) {
+	switch cfg.Kind {
+	case BrokerLog:
+		return NewLogBroker(out), nil
+	case BrokerHTTP:
+		return NewHTTPBroker(cfg.Addr)
+	default:
+		return nil, fmt.Errorf("unknown broker %q", cfg.Kind)
+	}
+}
+
+// wireMessage is the published form of a message. The id is prefixed so
+// outbox messages never collide with the change stream ids in a shared topic.
+type wireMessage struct {
+	ID string `json:"id"`
+	*Message
+}
+
+func encode(msg *Message) ([]byte, error) {
+	return json.Marshal(wireMessage{ID: "outbox-" + strconv.FormatInt(msg.ID, 10), Message: msg})
+}
+
+// LogBroker writes messages as JSON lines, for development.
+type LogBroker struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func NewLogBroker(out io.Writer) *LogBroker {
+	return &LogBroker{out: out}
+}
+
+func (b *LogBroker) Name() string { return "log" }
+
+func (b *LogBroker) Publish(_ context.Context, msg *Message) error {
+	line, err := encode(msg)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, err = b.out.Write(append(line, '\n'))
+	return err
+}
+
+// HTTPBroker publishes every message to the topic named by its type through
+// the HTTP API of cdc.BrokerServer.
+type HTTPBroker struct {
+	addr   string
+	client *http.Client
+}
+
+func NewHTTPBroker(addr string) (*HTTPBroker, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("broker address is empty")
+	}
+	return &HTTPBroker{addr: strings.TrimRight(addr, "/"), client: &http.Client{Timeout: 10 * time.Second}}, nil
+}
+
+func (b *HTTPBroker) Name() string { return "broker " + b.addr }
+
+func (b *HTTPBroker) Publish(ctx context.Context, msg *Message) error {
+	line, err := encode(msg)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	endpoint := fmt.Sprintf("%s/topics/%s", b.addr, url.PathEscape(msg.Type))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(append(line, '\n')))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusBadRequest:
+		// The broker rejects the message itself, sending it again won't help.
+		return fmt.Errorf("%w: broker responded %s", ErrPermanent, resp.Status)
+	default:
+		return fmt.Errorf("broker responded %s", resp.Status)
+	}
+}
+
+type fanOut []Broker
+
+// FanOut publishes every message to all brokers in order. A failure of any
+// of them fails the message, so the others see it again on the next attempt.
+func FanOut(brokers ...Broker) Broker {
+	return fanOut(brokers)
+}
+
+func (f fanOut) Name() string {
+	names := make([]string, len(f))
+	for i, b := range f {
+		names[i] = b.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+func (f fanOut) Publish(ctx context.Context, msg *Message) error {
+	for _, b := range f {
+		if err := b.Publish(ctx, msg); err != nil {
+			return fmt.Errorf("%s: %w", b.Name(), err)
+		}
+	}
+	return nil
+}
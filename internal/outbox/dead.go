@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeadLetter is a message the relay gave up on.
+type DeadLetter struct {
+	Message
+	LastError string
+	DeadAt    time.Time
+}
+
+// DeadLetters lists the dead-lettered messages, oldest first.
+func DeadLetters(ctx context.Context, pool *pgxpool.Pool, limit int) ([]DeadLetter, error) {
+	rows, err := pool.Query(ctx, `
+        SELECT event_id, aggregate_type, aggregate_id, event_type, version, payload, created_at, attempts,
+               COALESCE(last_error, ''), dead_at
+        FROM outbox.events
+        WHERE dead_at IS NOT NULL
+        ORDER BY event_id
+        LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	letters, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (DeadLetter, error) {
+		var d DeadLetter
+		err := row.Scan(&d.ID, &d.AggregateType, &d.AggregateID, &d.Type, &d.Version, &d.Payload, &d.CreatedAt, &d.Attempts,
+			&d.LastError, &d.DeadAt)
+		return d, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+// Requeue makes dead letters available to the relay again with a fresh
+// attempt budget. All dead letters are requeued when ids is empty. It returns
+// the number of requeued messages.
+func Requeue(ctx context.Context, pool *pgxpool.Pool, ids []int64) (int64, error) {
+	if ids == nil {
+		// A nil slice is sent as NULL, not as an empty array.
+		ids = []int64{}
+	}
+	tag, err := pool.Exec(ctx, `
+        UPDATE outbox.events
+        SET dead_at = NULL, attempts = 0, available_at = now()
+        WHERE dead_at IS NOT NULL AND (cardinality($1::BIGINT[]) = 0 OR event_id = ANY($1))
+    `, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead letters: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
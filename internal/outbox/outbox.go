@@ -0,0 +1,50 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/events"
+)
+
+// Message is an event of the outbox as handed to a broker.
+type Message struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	// Attempts counts the earlier failed publications.
+	Attempts int `json:"attempts"`
+}
+
+// Decode returns the payload as the latest version of its type known to the
+// registry.
+func (m *Message) Decode(r *events.Registry) (events.Event, error) {
+	return r.Decode(m.Type, m.Version, m.Payload)
+}
+
+// Enqueue writes an event to the outbox in the caller's transaction, it is
+// published only if the transaction commits. The event must be registered in
+// the default registry.
+func Enqueue(ctx context.Context, tx pgx.Tx, aggregateType, aggregateID string, event events.Event) error {
+	if err := events.Default.Check(event); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", event.EventType(), err)
+	}
+	_, err = tx.Exec(ctx, `SELECT outbox.enqueue($1, $2, $3, $4, $5)`,
+		aggregateType, aggregateID, event.EventType(), event.EventVersion(), payload)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s: %w", event.EventType(), err)
+	}
+	return nil
+}
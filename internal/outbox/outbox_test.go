@@ -0,0 +1,40 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+
+	"vehicles-service-stations/internal/events"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// Payloads written by the triggers of the schema must decode with the
+// registered schemas.
+func TestOrderEventsOfTheSchema(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "outbox")
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+	if _, err := tx.Exec(context.Background(), `UPDATE orders SET status = 'In Progress' WHERE order_id = $1`, orderID); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := testutil.OrderOutbox(t, tx, orderID)
+	if len(messages) != 2 {
+		t.Fatalf("got %d outbox events, want order.created and order.status_changed", len(messages))
+	}
+	created, err := messages[0].Decode(events.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := created.(events.OrderCreated); !ok || e.OrderID != orderID || e.MasterID != b.Masters[0] {
+		t.Errorf("first event is %#v, want order.created of order %d", created, orderID)
+	}
+	changed, err := messages[1].Decode(events.Default)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, ok := changed.(events.OrderStatusChanged); !ok || e.From != "Pending" || e.To != "In Progress" {
+		t.Errorf("second event is %#v, want order.status_changed from Pending to In Progress", changed)
+	}
+}
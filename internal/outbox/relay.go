@@ -0,0 +1,203 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/events"
+)
+
+// Broker publishes messages. Publish must be idempotent by message ID on the
+// consumer side: a message is published again when the relay fails to record
+// its publication.
+type Broker interface {
+	Name() string
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// ErrPermanent marks failures retrying cannot fix, the message is
+// dead-lettered right away.
+var ErrPermanent = errors.New("permanent failure")
+
+type Options struct {
+	// BatchSize is the number of messages claimed per transaction.
+	BatchSize int
+	// MaxAttempts is the number of failed publications after which a message
+	// is dead-lettered.
+	MaxAttempts int
+	// Backoff delays the next attempt, doubling from MinBackoff up to
+	// MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// PollInterval is the pause of Run once the outbox is drained.
+	PollInterval time.Duration
+	// Registry validates payloads before publication, events.Default when
+	// nil. Payloads it cannot decode are dead-lettered.
+	Registry *events.Registry
+	// OnBatch and OnError report the progress of Run.
+	OnBatch func(BatchResult)
+	OnError func(error)
+}
+
+func (o *Options) defaults() {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = 10
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = time.Second
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = max(10*time.Minute, o.MinBackoff)
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = time.Second
+	}
+	if o.Registry == nil {
+		o.Registry = events.Default
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (o *Options) backoff(attempts int) time.Duration {
+	d := o.MinBackoff
+	for i := 1; i < attempts && d < o.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, o.MaxBackoff)
+}
+
+type BatchResult struct {
+	Claimed   int
+	Published int
+	Retried   int
+	Dead      int
+}
+
+type Relay struct {
+	pool   *pgxpool.Pool
+	broker Broker
+	opts   Options
+}
+
+func NewRelay(pool *pgxpool.Pool, broker Broker, opts Options) *Relay {
+	opts.defaults()
+	return &Relay{pool: pool, broker: broker, opts: opts}
+}
+
+// Run relays until ctx is done. Errors are reported to OnError and retried
+// after PollInterval. Several relays may run against the same outbox.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		res, err := r.RunOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && r.opts.OnError != nil {
+			r.opts.OnError(err)
+		}
+		if err == nil && res.Claimed > 0 && r.opts.OnBatch != nil {
+			r.opts.OnBatch(res)
+		}
+		if err == nil && res.Claimed == r.opts.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(r.opts.PollInterval):
+		}
+	}
+}
+
+// Messages of an aggregate are published in order: a message is only claimed
+// when no earlier message of its aggregate is pending, so a batch holds one
+// message of an aggregate at most. Rows locked by other relays are skipped.
+const claimQuery = `
+    SELECT e.event_id, e.aggregate_type, e.aggregate_id, e.event_type, e.version, e.payload, e.created_at, e.attempts
+    FROM outbox.events e
+    WHERE e.published_at IS NULL AND e.dead_at IS NULL AND e.available_at <= now()
+      AND NOT EXISTS (
+          SELECT 1
+          FROM outbox.events p
+          WHERE p.aggregate_type = e.aggregate_type
+            AND p.aggregate_id = e.aggregate_id
+            AND p.event_id < e.event_id
+            AND p.published_at IS NULL
+            AND p.dead_at IS NULL
+      )
+    ORDER BY e.event_id
+    LIMIT $1
+    FOR UPDATE OF e SKIP LOCKED
+`
+
+// RunOnce claims one batch, publishes it and records the outcome in the same
+// transaction. A message is published again if that transaction fails.
+func (r *Relay) RunOnce(ctx context.Context) (BatchResult, error) {
+	var res BatchResult
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, claimQuery, r.opts.BatchSize)
+	if err != nil {
+		return res, fmt.Errorf("failed to claim messages: %w", err)
+	}
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*Message, error) {
+		m := &Message{}
+		err := row.Scan(&m.ID, &m.AggregateType, &m.AggregateID, &m.Type, &m.Version, &m.Payload, &m.CreatedAt, &m.Attempts)
+		return m, err
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to claim messages: %w", err)
+	}
+	res.Claimed = len(messages)
+
+	batch := &pgx.Batch{}
+	for _, m := range messages {
+		err := r.publish(ctx, m)
+		switch {
+		case err == nil:
+			res.Published++
+			batch.Queue(`UPDATE outbox.events SET published_at = now(), attempts = attempts + 1, last_error = NULL WHERE event_id = $1`, m.ID)
+		case errors.Is(err, ErrPermanent) || m.Attempts+1 >= r.opts.MaxAttempts:
+			res.Dead++
+			batch.Queue(`UPDATE outbox.events SET dead_at = now(), attempts = attempts + 1, last_error = $2 WHERE event_id = $1`, m.ID, err.Error())
+		default:
+			res.Retried++
+			batch.Queue(`UPDATE outbox.events SET available_at = now() + $3::interval, attempts = attempts + 1, last_error = $2 WHERE event_id = $1`,
+				m.ID, err.Error(), r.opts.backoff(m.Attempts+1))
+		}
+	}
+
+	if batch.Len() > 0 {
+		if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+			return res, fmt.Errorf("failed to record publications: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return res, fmt.Errorf("failed to record publications: %w", err)
+	}
+	return res, nil
+}
+
+func (r *Relay) publish(ctx context.Context, m *Message) error {
+	if _, err := m.Decode(r.opts.Registry); err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	if err := r.broker.Publish(ctx, m); err != nil {
+		return fmt.Errorf("%s: %w", r.broker.Name(), err)
+	}
+	return nil
+}
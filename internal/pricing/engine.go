@@ -0,0 +1,227 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ItemKind string
+
+const (
+	ItemService ItemKind = "service"
+	ItemPart    ItemKind = "part"
+)
+
+const (
+	RoundHalfUp = "half_up"
+	RoundDown   = "down"
+)
+
+var (
+	ErrUnknownCode = errors.New("unknown promo code")
+	ErrCodeExpired = errors.New("promo code is not valid now")
+	ErrCodeUsedUp  = errors.New("promo code is used up")
+)
+
+type Settings struct {
+	VATRate          Decimal
+	PricesIncludeVAT bool
+	RoundingStep     Money
+	RoundingMode     string
+}
+
+// Item is a service or spare parts of an order. Services cost their price
+// plus their labour hours at the hourly rate of the center.
+type Item struct {
+	Kind        ItemKind
+	ID          int
+	Name        string
+	Quantity    int
+	UnitPrice   Money
+	LabourHours Decimal
+	LabourRate  Money
+}
+
+type Promotion struct {
+	ID      int
+	Name    string
+	Percent Decimal
+	// ServiceID and CenterID are 0 for promotions of every service or
+	// center.
+	ServiceID int
+	CenterID  int
+	StartsAt  time.Time
+	EndsAt    time.Time
+}
+
+func (p Promotion) applies(item Item, centerID int, at time.Time) bool {
+	return item.Kind == ItemService &&
+		(p.ServiceID == 0 || p.ServiceID == item.ID) &&
+		(p.CenterID == 0 || p.CenterID == centerID) &&
+		!at.Before(p.StartsAt) && at.Before(p.EndsAt)
+}
+
+type PromoCode struct {
+	Code string
+	// Either Percent or Amount is set.
+	Percent    Decimal
+	Amount     Money
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+	MaxUses    int
+	Uses       int
+}
+
+// Input is everything an order is priced from.
+type Input struct {
+	OrderID     int
+	CenterID    int
+	At          time.Time
+	Tier        string
+	TierPercent Decimal
+	Settings    Settings
+	Items       []Item
+	Promotions  []Promotion
+	Code        *PromoCode
+}
+
+type Adjustment struct {
+	Reason      string  `json:"reason"`
+	PromotionID int     `json:"promotion_id,omitempty"`
+	Percent     Decimal `json:"percent,omitempty"`
+	Amount      Money   `json:"amount"`
+}
+
+type Line struct {
+	Kind        ItemKind `json:"kind"`
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Quantity    int      `json:"quantity"`
+	UnitPrice   Money    `json:"unit_price"`
+	LabourHours Decimal  `json:"labour_hours,omitempty"`
+	LabourRate  Money    `json:"labour_rate,omitempty"`
+	// Amount is the price of the quantity with labour, Total is what is
+	// left of it after the discount.
+	Amount   Money       `json:"amount"`
+	Discount *Adjustment `json:"discount,omitempty"`
+	Total    Money       `json:"total"`
+}
+
+// Quote is the itemised price of an order. It is stored as it is, receipts
+// are reproduced from it.
+type Quote struct {
+	ID       int64     `json:"-"`
+	OrderID  int       `json:"order_id"`
+	QuotedAt time.Time `json:"quoted_at"`
+	Tier     string    `json:"tier"`
+	Lines    []Line    `json:"lines"`
+	// Subtotal is the sum of the amounts of the lines, Discount of the line
+	// discounts and of the promo code.
+	Subtotal         Money       `json:"subtotal"`
+	Code             string      `json:"code,omitempty"`
+	PromoCode        *Adjustment `json:"promo_code,omitempty"`
+	Discount         Money       `json:"discount"`
+	VATRate          Decimal     `json:"vat_rate"`
+	PricesIncludeVAT bool        `json:"prices_include_vat"`
+	VAT              Money       `json:"vat"`
+	Rounding         Money       `json:"rounding"`
+	Total            Money       `json:"total"`
+}
+
+// Compute prices the items of an order. A line gets the larger of the tier
+// discount and the promotions of it, the promo code applies to what is left
+// of the order. VAT is included in the total or added to it, then the total
+// is rounded.
+func Compute(in Input) (*Quote, error) {
+	q := &Quote{
+		OrderID:          in.OrderID,
+		QuotedAt:         in.At,
+		Tier:             in.Tier,
+		Lines:            make([]Line, 0, len(in.Items)),
+		VATRate:          in.Settings.VATRate,
+		PricesIncludeVAT: in.Settings.PricesIncludeVAT,
+	}
+
+	var net Money
+	for _, item := range in.Items {
+		line := Line{
+			Kind:        item.Kind,
+			ID:          item.ID,
+			Name:        item.Name,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.UnitPrice,
+			LabourHours: item.LabourHours,
+		}
+		unit := item.UnitPrice
+		if item.LabourHours > 0 {
+			line.LabourRate = item.LabourRate
+			unit += Money(divRound(int64(item.LabourHours)*int64(item.LabourRate), 100))
+		}
+		line.Amount = unit * Money(item.Quantity)
+
+		best := Adjustment{Reason: "tier " + in.Tier, Percent: in.TierPercent}
+		for _, p := range in.Promotions {
+			if p.applies(item, in.CenterID, in.At) && p.Percent > best.Percent {
+				best = Adjustment{Reason: p.Name, PromotionID: p.ID, Percent: p.Percent}
+			}
+		}
+		line.Total = line.Amount
+		if best.Percent > 0 {
+			best.Amount = percentOf(line.Amount, best.Percent)
+			line.Discount = &best
+			line.Total -= best.Amount
+		}
+
+		q.Subtotal += line.Amount
+		net += line.Total
+		q.Lines = append(q.Lines, line)
+	}
+
+	if in.Code != nil {
+		off, err := codeDiscount(in.Code, net, in.At)
+		if err != nil {
+			return nil, err
+		}
+		q.Code, q.PromoCode = in.Code.Code, off
+		net -= off.Amount
+	}
+	q.Discount = q.Subtotal - net
+
+	s := in.Settings
+	if s.PricesIncludeVAT {
+		q.Total = round(net, s)
+		q.VAT = Money(divRound(int64(q.Total)*int64(s.VATRate), 100*100+int64(s.VATRate)))
+		q.Rounding = q.Total - net
+	} else {
+		q.VAT = percentOf(net, s.VATRate)
+		q.Total = round(net+q.VAT, s)
+		q.Rounding = q.Total - net - q.VAT
+	}
+	return q, nil
+}
+
+func codeDiscount(c *PromoCode, net Money, at time.Time) (*Adjustment, error) {
+	if c.ValidFrom != nil && at.Before(*c.ValidFrom) || c.ValidUntil != nil && !at.Before(*c.ValidUntil) {
+		return nil, fmt.Errorf("%s: %w", c.Code, ErrCodeExpired)
+	}
+	if c.MaxUses > 0 && c.Uses >= c.MaxUses {
+		return nil, fmt.Errorf("%s: %w", c.Code, ErrCodeUsedUp)
+	}
+	off := &Adjustment{Reason: "promo code " + c.Code, Percent: c.Percent}
+	if c.Percent > 0 {
+		off.Amount = percentOf(net, c.Percent)
+	} else {
+		off.Amount = min(c.Amount, net)
+	}
+	return off, nil
+}
+
+// round rounds a total to the rounding step of the settings.
+func round(m Money, s Settings) Money {
+	step := int64(max(s.RoundingStep, 1))
+	if s.RoundingMode == RoundDown {
+		return Money(int64(m) / step * step)
+	}
+	return Money(divRound(int64(m), step) * step)
+}
@@ -0,0 +1,260 @@
+package pricing
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompute(t *testing.T) {
+	at := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	included := Settings{VATRate: 2000, PricesIncludeVAT: true, RoundingStep: 1, RoundingMode: RoundHalfUp}
+	service := Item{Kind: ItemService, ID: 1, Name: "Oil change", Quantity: 1, UnitPrice: 100000}
+	parts := Item{Kind: ItemPart, ID: 2, Name: "Filter", Quantity: 2, UnitPrice: 10000}
+	promotion := func(percent Decimal) Promotion {
+		return Promotion{ID: 7, Name: "Spring", Percent: percent, StartsAt: at.Add(-day), EndsAt: at.Add(day)}
+	}
+	past, future := at.Add(-day), at.Add(day)
+
+	tests := []struct {
+		name       string
+		in         Input
+		wantErr    error
+		wantTotal  Money
+		wantVAT    Money
+		wantOff    Money
+		wantRound  Money
+		wantReason string
+	}{
+		{
+			name:      "tier",
+			in:        Input{Tier: "Gold", TierPercent: 500, Settings: included, Items: []Item{service}},
+			wantTotal: 95000, wantVAT: 15833, wantOff: 5000, wantReason: "tier Gold",
+		},
+		{
+			name: "promotion_bigger_than_tier",
+			in: Input{Tier: "Gold", TierPercent: 500, Settings: included, Items: []Item{service},
+				Promotions: []Promotion{promotion(1000)}},
+			wantTotal: 90000, wantVAT: 15000, wantOff: 10000, wantReason: "Spring",
+		},
+		{
+			name: "promotion_smaller_than_tier",
+			in: Input{Tier: "Gold", TierPercent: 500, Settings: included, Items: []Item{service},
+				Promotions: []Promotion{promotion(300)}},
+			wantTotal: 95000, wantVAT: 15833, wantOff: 5000, wantReason: "tier Gold",
+		},
+		{
+			name: "promotion_over",
+			in: Input{Tier: "Gold", TierPercent: 500, Settings: included, Items: []Item{service},
+				Promotions: []Promotion{{ID: 7, Name: "Winter", Percent: 1000, StartsAt: at.Add(-2 * day), EndsAt: at.Add(-day)}}},
+			wantTotal: 95000, wantVAT: 15833, wantOff: 5000, wantReason: "tier Gold",
+		},
+		{
+			name: "promotion_of_another_center",
+			in: Input{CenterID: 1, Tier: "Gold", TierPercent: 500, Settings: included, Items: []Item{service},
+				Promotions: []Promotion{{ID: 7, Name: "Spring", Percent: 1000, CenterID: 2, StartsAt: at.Add(-day), EndsAt: at.Add(day)}}},
+			wantTotal: 95000, wantVAT: 15833, wantOff: 5000, wantReason: "tier Gold",
+		},
+		{
+			name:      "code_percent",
+			in:        Input{Settings: included, Items: []Item{service}, Code: &PromoCode{Code: "SPRING5", Percent: 500}},
+			wantTotal: 95000, wantVAT: 15833, wantOff: 5000,
+		},
+		{
+			name:      "code_amount",
+			in:        Input{Settings: included, Items: []Item{service}, Code: &PromoCode{Code: "MINUS200", Amount: 20000}},
+			wantTotal: 80000, wantVAT: 13333, wantOff: 20000,
+		},
+		{
+			name:      "code_amount_capped_at_net",
+			in:        Input{Settings: included, Items: []Item{service}, Code: &PromoCode{Code: "MINUS1500", Amount: 150000}},
+			wantTotal: 0, wantVAT: 0, wantOff: 100000,
+		},
+		{
+			name:    "code_expired",
+			in:      Input{Settings: included, Items: []Item{service}, Code: &PromoCode{Code: "OLD", Percent: 500, ValidUntil: &past}},
+			wantErr: ErrCodeExpired,
+		},
+		{
+			name:    "code_not_valid_yet",
+			in:      Input{Settings: included, Items: []Item{service}, Code: &PromoCode{Code: "NEW", Percent: 500, ValidFrom: &future}},
+			wantErr: ErrCodeExpired,
+		},
+		{
+			name:    "code_used_up",
+			in:      Input{Settings: included, Items: []Item{service}, Code: &PromoCode{Code: "ONCE", Percent: 500, MaxUses: 1, Uses: 1}},
+			wantErr: ErrCodeUsedUp,
+		},
+		{
+			name:      "vat_added",
+			in:        Input{Settings: Settings{VATRate: 2000, RoundingStep: 1, RoundingMode: RoundHalfUp}, Items: []Item{service}},
+			wantTotal: 120000, wantVAT: 20000,
+		},
+		{
+			name: "vat_added_then_rounded",
+			in: Input{Settings: Settings{VATRate: 2000, RoundingStep: 100, RoundingMode: RoundHalfUp},
+				Items: []Item{{Kind: ItemPart, Quantity: 1, UnitPrice: 100050}}},
+			wantTotal: 120100, wantVAT: 20010, wantRound: 40,
+		},
+		{
+			name: "round_half_up",
+			in: Input{Settings: Settings{VATRate: 2000, PricesIncludeVAT: true, RoundingStep: 100, RoundingMode: RoundHalfUp},
+				Items: []Item{{Kind: ItemPart, Quantity: 1, UnitPrice: 100050}}},
+			wantTotal: 100100, wantVAT: 16683, wantRound: 50,
+		},
+		{
+			name: "round_down",
+			in: Input{Settings: Settings{VATRate: 2000, PricesIncludeVAT: true, RoundingStep: 100, RoundingMode: RoundDown},
+				Items: []Item{{Kind: ItemPart, Quantity: 1, UnitPrice: 100050}}},
+			wantTotal: 100000, wantVAT: 16667, wantRound: -50,
+		},
+		{
+			name: "labour_hours",
+			in: Input{Settings: included,
+				Items: []Item{{Kind: ItemService, Quantity: 2, UnitPrice: 100000, LabourHours: 150, LabourRate: 200000}}},
+			wantTotal: 800000, wantVAT: 133333,
+		},
+		{
+			// The order of the console test of the store: a service with
+			// labour under a promotion, parts with the tier discount and a
+			// promo code on the rest.
+			name: "console_order",
+			in: Input{Tier: "Gold", TierPercent: 500,
+				Settings:   Settings{VATRate: 2000, PricesIncludeVAT: true, RoundingStep: 100, RoundingMode: RoundHalfUp},
+				Items:      []Item{{Kind: ItemService, ID: 1, Quantity: 1, UnitPrice: 100000, LabourHours: 150, LabourRate: 200000}, parts},
+				Promotions: []Promotion{promotion(1000)},
+				Code:       &PromoCode{Code: "FIXTURE5", Percent: 500}},
+			wantTotal: 360100, wantVAT: 60017, wantOff: 59950, wantRound: 50, wantReason: "Spring",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.At = at
+			q, err := Compute(tt.in)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.Total != tt.wantTotal || q.VAT != tt.wantVAT || q.Discount != tt.wantOff || q.Rounding != tt.wantRound {
+				t.Errorf("total %s, VAT %s, discount %s, rounding %s; want %s, %s, %s, %s",
+					q.Total, q.VAT, q.Discount, q.Rounding, tt.wantTotal, tt.wantVAT, tt.wantOff, tt.wantRound)
+			}
+			var reason string
+			if d := q.Lines[0].Discount; d != nil {
+				reason = d.Reason
+			}
+			if reason != tt.wantReason {
+				t.Errorf("discount of the first line is %q, want %q", reason, tt.wantReason)
+			}
+			var lines Money
+			for _, l := range q.Lines {
+				lines += l.Total
+			}
+			off := Money(0)
+			if q.PromoCode != nil {
+				off = q.PromoCode.Amount
+			}
+			if q.Subtotal-q.Discount != lines-off {
+				t.Errorf("subtotal %s less discount %s is not the lines %s less the code %s", q.Subtotal, q.Discount, lines, off)
+			}
+		})
+	}
+}
+
+func TestLabourLine(t *testing.T) {
+	q, err := Compute(Input{Items: []Item{{Kind: ItemService, Quantity: 1, UnitPrice: 100000, LabourHours: 150, LabourRate: 200000}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := q.Lines[0]; l.Amount != 400000 || l.LabourRate != 200000 || l.LabourHours != 150 {
+		t.Errorf("line %+v, want 1000 + 1.5 h × 2000 = 4000", l)
+	}
+}
+
+func TestParseHundredths(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"12", 1200, false},
+		{"12.5", 1250, false},
+		{"12.05", 1205, false},
+		{"-3.10", -310, false},
+		{"1.", 100, false},
+		{"1.234", 0, true},
+		{"1.-5", 0, true},
+		{"1.+5", 0, true},
+		{"+1", 0, true},
+		{"--1", 0, true},
+		{"1.5e", 0, true},
+		{"", 0, true},
+		{".5", 0, true},
+		{"1,5", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseHundredths(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseHundredths(%q) = %d, %v; want %d, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	for _, m := range []Money{0, 5, 1250, -310, 360100} {
+		data, err := m.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Money
+		if err := back.UnmarshalJSON(data); err != nil || back != m {
+			t.Errorf("%d encoded as %s decodes to %d, %v", m, data, back, err)
+		}
+	}
+}
+
+func TestDivRound(t *testing.T) {
+	tests := []struct {
+		a, b, want int64
+	}{
+		{0, 3, 0},
+		{4, 3, 1},
+		{5, 3, 2},
+		{3, 2, 2},
+		{1, 2, 1},
+		{1, 3, 0},
+		{10, 5, 2},
+	}
+	for _, tt := range tests {
+		if got := divRound(tt.a, tt.b); got != tt.want {
+			t.Errorf("divRound(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestPercentOf(t *testing.T) {
+	tests := []struct {
+		m       Money
+		percent Decimal
+		want    Money
+	}{
+		{100000, 500, 5000},
+		{10001, 500, 500},
+		{10010, 500, 501},
+		{999, 3333, 333},
+		{12345, 0, 0},
+		{12345, 10000, 12345},
+	}
+	for _, tt := range tests {
+		if got := percentOf(tt.m, tt.percent); got != tt.want {
+			t.Errorf("percentOf(%s, %s) = %s, want %s", tt.m, tt.percent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package pricing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in kopecks, so sums and rounding are exact. It is
+// written to JSON as a decimal number of rubles.
+type Money int64
+
+// Decimal is a number with two decimal places in hundredths, for percents,
+// rates and hours.
+type Decimal int64
+
+func (m Money) String() string {
+	return formatHundredths(int64(m))
+}
+
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	v, err := parseHundredths(string(data))
+	*m = Money(v)
+	return err
+}
+
+func (d Decimal) String() string {
+	return formatHundredths(int64(d))
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	v, err := parseHundredths(string(data))
+	*d = Decimal(v)
+	return err
+}
+
+func formatHundredths(v int64) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+func parseHundredths(s string) (int64, error) {
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	whole, frac, _ := strings.Cut(s, ".")
+	if strings.Trim(whole+frac, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid amount %s", s)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("%s has more than two decimal places", s)
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %s: %w", s, err)
+	}
+	var f int64
+	if frac != "" {
+		if f, err = strconv.ParseInt(frac+strings.Repeat("0", 2-len(frac)), 10, 64); err != nil {
+			return 0, fmt.Errorf("invalid amount %s: %w", s, err)
+		}
+	}
+	v := w*100 + f
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
+
+// divRound divides rounding half up, for a >= 0 and b > 0.
+func divRound(a, b int64) int64 {
+	return (2*a + b) / (2 * b)
+}
+
+// percentOf returns percent of m rounded half up to a kopeck.
+func percentOf(m Money, percent Decimal) Money {
+	return Money(divRound(int64(m)*int64(percent), 100*100))
+}
@@ -0,0 +1,207 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DB is satisfied by pools and transactions. Queries run as the caller,
+// who sees the orders their role lets them see.
+type DB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var ErrNotFound = errors.New("not found")
+
+// Load reads what an order is priced from at the time of the database, with
+// the promo code unless it is empty.
+func Load(ctx context.Context, db DB, orderID int, code string) (*Input, error) {
+	in := &Input{OrderID: orderID}
+	var defaultRate Money
+	err := db.QueryRow(ctx, `
+        SELECT (vat_rate * 100)::BIGINT, prices_include_vat, (rounding_step * 100)::BIGINT, rounding_mode,
+               (default_labour_rate * 100)::BIGINT, now()
+        FROM pricing.settings
+    `).Scan(&in.Settings.VATRate, &in.Settings.PricesIncludeVAT, &in.Settings.RoundingStep, &in.Settings.RoundingMode,
+		&defaultRate, &in.At)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pricing settings: %w", err)
+	}
+
+	err = db.QueryRow(ctx, `
+        SELECT o.service_center_id, c.loyalty_status::TEXT, COALESCE((td.percent * 100)::BIGINT, 0)
+        FROM orders o
+        JOIN customers c ON c.customer_id = o.customer_id
+        LEFT JOIN pricing.tier_discounts td ON td.loyalty_status = c.loyalty_status
+        WHERE o.order_id = $1
+    `, orderID).Scan(&in.CenterID, &in.Tier, &in.TierPercent)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("order %d: %w", orderID, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order: %w", err)
+	}
+
+	rows, err := db.Query(ctx, `
+        SELECT 'service', s.service_id, s.full_name, 1, (s.price * 100)::BIGINT,
+               COALESCE((s.labour_hours * 100)::BIGINT, 0), COALESCE((lr.hourly_rate * 100)::BIGINT, $3)
+        FROM service_order so
+        JOIN services s ON s.service_id = so.service_id
+        LEFT JOIN pricing.labour_rates lr ON lr.service_center_id = $2 AND lr.vehicle_type = s.vehicle_type
+        WHERE so.order_id = $1
+        UNION ALL
+        SELECT 'part', p.part_id, p.name, spo.quantity, (spo.purchase_price * 100)::BIGINT, 0, 0
+        FROM spare_part_order spo
+        JOIN spare_parts p ON p.part_id = spo.part_id
+        WHERE spo.order_id = $1
+        ORDER BY 1 DESC, 2
+    `, orderID, in.CenterID, defaultRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order lines: %w", err)
+	}
+	if in.Items, err = pgx.CollectRows(rows, pgx.RowToStructByPos[Item]); err != nil {
+		return nil, fmt.Errorf("failed to load order lines: %w", err)
+	}
+
+	rows, err = db.Query(ctx, `
+        SELECT promotion_id, name, (percent * 100)::BIGINT, COALESCE(service_id, 0), COALESCE(service_center_id, 0),
+               starts_at, ends_at
+        FROM pricing.promotions
+        WHERE (service_center_id IS NULL OR service_center_id = $1) AND starts_at <= $2 AND ends_at > $2
+        ORDER BY promotion_id
+    `, in.CenterID, in.At)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load promotions: %w", err)
+	}
+	if in.Promotions, err = pgx.CollectRows(rows, pgx.RowToStructByPos[Promotion]); err != nil {
+		return nil, fmt.Errorf("failed to load promotions: %w", err)
+	}
+
+	if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+		c := &PromoCode{Code: code}
+		var percent, amount, maxUses *int64
+		err := db.QueryRow(ctx, `
+            SELECT (percent * 100)::BIGINT, (amount * 100)::BIGINT, valid_from, valid_until, max_uses, uses
+            FROM pricing.promo_codes
+            WHERE code = $1
+        `, code).Scan(&percent, &amount, &c.ValidFrom, &c.ValidUntil, &maxUses, &c.Uses)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", code, ErrUnknownCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load promo code: %w", err)
+		}
+		if percent != nil {
+			c.Percent = Decimal(*percent)
+		}
+		if amount != nil {
+			c.Amount = Money(*amount)
+		}
+		if maxUses != nil {
+			c.MaxUses = int(*maxUses)
+		}
+		in.Code = c
+	}
+	return in, nil
+}
+
+// Save stores a quote as the current one of its order, whose total cost
+// becomes the total of the quote.
+func Save(ctx context.Context, db DB, q *Quote) error {
+	breakdown, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	var code *string
+	if q.Code != "" {
+		code = &q.Code
+	}
+	err = db.QueryRow(ctx, `
+        WITH quote AS (
+            INSERT INTO pricing.quotes (order_id, promo_code, subtotal, discount, vat, rounding, total, breakdown)
+            VALUES ($1, $2, $3::NUMERIC / 100, $4::NUMERIC / 100, $5::NUMERIC / 100, $6::NUMERIC / 100, $7::NUMERIC / 100, $8)
+            RETURNING quote_id, total
+        )
+        UPDATE orders o
+        SET quote_id = quote.quote_id, total_cost = quote.total
+        FROM quote
+        WHERE o.order_id = $1
+        RETURNING quote.quote_id
+    `, q.OrderID, code, q.Subtotal, q.Discount, q.VAT, q.Rounding, q.Total, breakdown).Scan(&q.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("order %d: %w", q.OrderID, ErrNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save quote: %w", err)
+	}
+	return nil
+}
+
+// QuoteOrder prices an order as it is now and saves the quote.
+func QuoteOrder(ctx context.Context, db DB, orderID int, code string) (*Quote, error) {
+	in, err := Load(ctx, db, orderID, code)
+	if err != nil {
+		return nil, err
+	}
+	q, err := Compute(*in)
+	if err != nil {
+		return nil, err
+	}
+	if err := Save(ctx, db, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// OrderQuote returns the current quote of an order, nil when its services or
+// spare parts changed since it was quoted.
+func OrderQuote(ctx context.Context, db DB, orderID int) (*Quote, error) {
+	q, err := loadQuote(ctx, db, `
+        SELECT q.quote_id, q.breakdown
+        FROM orders o
+        JOIN pricing.quotes q ON q.quote_id = o.quote_id
+        WHERE o.order_id = $1
+    `, orderID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return q, err
+}
+
+// ReceiptQuote returns the quote a receipt charged.
+func ReceiptQuote(ctx context.Context, db DB, receiptID int) (*Quote, error) {
+	q, err := loadQuote(ctx, db, `
+        SELECT q.quote_id, q.breakdown
+        FROM receipts r
+        JOIN pricing.quotes q ON q.quote_id = r.quote_id
+        WHERE r.receipt_id = $1
+    `, receiptID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("quote of receipt %d: %w", receiptID, ErrNotFound)
+	}
+	return q, err
+}
+
+func loadQuote(ctx context.Context, db DB, sql string, id int) (*Quote, error) {
+	q := &Quote{}
+	var breakdown []byte
+	err := db.QueryRow(ctx, sql, id).Scan(&q.ID, &breakdown)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quote: %w", err)
+	}
+	if err := json.Unmarshal(breakdown, q); err != nil {
+		return nil, fmt.Errorf("invalid quote %d: %w", q.ID, err)
+	}
+	return q, nil
+}
@@ -0,0 +1,175 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/console"
+	"vehicles-service-stations/internal/pricing"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// A Gold customer books a service with labour hours under a promotion and
+// spare parts with a promo code from the console. The receipt charges the
+// quote and is reproduced from it after the VAT rate changed; the code has
+// one use, so another order quoted with it cannot be charged.
+func TestQuoteChargedByReceipt(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "pricing")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	for _, sql := range []string{
+		`UPDATE pricing.settings
+         SET vat_rate = 20, prices_include_vat = TRUE, rounding_step = 1, rounding_mode = 'half_up',
+             default_labour_rate = 0`,
+		`UPDATE pricing.tier_discounts SET percent = 5 WHERE loyalty_status = 'Gold'`,
+		`INSERT INTO pricing.promo_codes (code, percent, max_uses) VALUES ('FIXTURE5', 5, 1)`,
+	} {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := tx.Exec(ctx, `UPDATE customers SET spent_money = 60000 WHERE customer_id = $1`, b.Customer); err != nil {
+		t.Fatal(err)
+	}
+	_, err := tx.Exec(ctx, `
+        INSERT INTO pricing.labour_rates (service_center_id, vehicle_type, hourly_rate)
+        VALUES ($1, 'Car', 2000)
+    `, b.Center)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var serviceID int
+	err = tx.QueryRow(ctx, `
+        INSERT INTO services (full_name, vehicle_type, price, labour_hours)
+        VALUES ('Fixture oil change', 'Car', 1000, 1.5)
+        RETURNING service_id
+    `).Scan(&serviceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO pricing.promotions (name, percent, service_id, service_center_id, starts_at, ends_at)
+        VALUES ('Fixture promotion', 10, $1, $2, now() - INTERVAL '1 day', now() + INTERVAL '1 day')
+    `, serviceID, b.Center)
+	if err != nil {
+		t.Fatal(err)
+	}
+	partID := testutil.NewPart(t, tx, 5)
+
+	// Quoted with the code before the first order uses it up.
+	other := b.Order(t, tx, b.Customer, b.Masters[1], testutil.Day)
+	if _, err := pricing.QuoteOrder(ctx, tx, other, "FIXTURE5"); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.LoginAs(t, tx, b.Manager, "manager")
+	manager, err := console.NewStore(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderID, err := manager.CreateOrder(ctx, console.OrderDraft{
+		CustomerID: b.Customer,
+		MasterID:   b.Masters[0],
+		Date:       testutil.Day,
+		Services:   []int{serviceID},
+		Parts:      []console.PartQuantity{{PartID: partID, Quantity: 2}},
+		PromoCode:  " fixture5",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := manager.Order(ctx, orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1000 + 1.5 h × 2000 less 10% is 3600, the parts less 5% for Gold 190,
+	// less 5% of the code 3600.50, rounded 3601 with 600.17 of VAT in it.
+	q := order.Quote
+	if q == nil || order.TotalCost != 3601 {
+		t.Fatalf("order costs %.2f with quote %+v, want 3601", order.TotalCost, q)
+	}
+	if len(q.Lines) != 2 || q.Lines[0].Discount == nil || q.Lines[0].Discount.Reason != "Fixture promotion" ||
+		q.Lines[1].Discount == nil || q.Lines[1].Discount.Reason != "tier Gold" {
+		t.Errorf("quote lines %+v, want the promotion on the service and the tier on the parts", q.Lines)
+	}
+	if q.Subtotal != 420000 || q.Discount != 59950 || q.VAT != 60017 || q.Rounding != 50 || q.Code != "FIXTURE5" {
+		t.Errorf("quote %+v, want 4200 less 599.50 with 600.17 of VAT", q)
+	}
+	if err := manager.Complete(ctx, orderID); err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := manager.IssueReceipt(ctx, orderID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.TotalPaid != 3601 {
+		t.Errorf("receipt paid %.2f, want 3601", receipt.TotalPaid)
+	}
+	if _, err := tx.Exec(ctx, `RESET SESSION AUTHORIZATION`); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("receipt_reproduced", func(t *testing.T) {
+		if _, err := tx.Exec(ctx, `UPDATE pricing.settings SET vat_rate = 10`); err != nil {
+			t.Fatal(err)
+		}
+		charged, err := pricing.ReceiptQuote(ctx, tx, receipt.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if charged.ID != q.ID || charged.Total != q.Total || charged.VAT != q.VAT || len(charged.Lines) != 2 {
+			t.Errorf("receipt reproduced as %+v, want %+v", charged, q)
+		}
+	})
+
+	t.Run("code_used_up", func(t *testing.T) {
+		if _, err := tx.Exec(ctx, `UPDATE orders SET status = 'Completed' WHERE order_id = $1`, other); err != nil {
+			t.Fatal(err)
+		}
+		err := testutil.Savepoint(ctx, tx, func(tx pgx.Tx) error {
+			_, err := tx.Exec(ctx, `INSERT INTO receipts (order_id, total_paid) VALUES ($1, 0)`, other)
+			return err
+		})
+		testutil.ExpectCode(t, err, pgerrcode.RaiseException)
+		if _, err := pricing.QuoteOrder(ctx, tx, other, "FIXTURE5"); !errors.Is(err, pricing.ErrCodeUsedUp) {
+			t.Errorf("quote with a used up code: %v, want %v", err, pricing.ErrCodeUsedUp)
+		}
+	})
+}
+
+// Adding a service to a quoted order drops the quote, the order costs the sum
+// of its prices until quoted again.
+func TestChangedOrderLosesQuote(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "pricing")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	b := testutil.NewBase(t, tx)
+	orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+	if _, err := pricing.QuoteOrder(ctx, tx, orderID, ""); err != nil {
+		t.Fatal(err)
+	}
+	if q, err := pricing.OrderQuote(ctx, tx, orderID); err != nil || q == nil {
+		t.Fatalf("quote of a quoted order: %v, %v", q, err)
+	}
+
+	serviceID := testutil.NewService(t, tx, 333.33)
+	if _, err := tx.Exec(ctx, `INSERT INTO service_order (service_id, order_id) VALUES ($1, $2)`, serviceID, orderID); err != nil {
+		t.Fatal(err)
+	}
+	q, err := pricing.OrderQuote(ctx, tx, orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	if err := tx.QueryRow(ctx, `SELECT total_cost::float8 FROM orders WHERE order_id = $1`, orderID).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	if q != nil || total != 333.33 {
+		t.Errorf("changed order costs %.2f with quote %+v, want 333.33 without", total, q)
+	}
+}
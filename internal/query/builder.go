@@ -0,0 +1,519 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/model"
+)
+
+// Catalog is the part of model.AllowedTables the builder validates against.
+type Catalog interface {
+	Table(name string) (*model.Table, bool)
+	Enum(name string) (*model.Enum, bool)
+}
+
+var (
+	identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+	andRe        = regexp.MustCompile(`(?i)\s+AND\s+`)
+)
+
+func Quote(parts ...string) string {
+	return pgx.Identifier(parts).Sanitize()
+}
+
+type ColumnRef struct {
+	Table  string
+	Column string
+}
+
+func ParseColumnRef(ref string) (ColumnRef, error) {
+	parts := strings.Split(strings.TrimSpace(ref), ".")
+	for _, part := range parts {
+		if !identifierRe.MatchString(part) {
+			return ColumnRef{}, &UnknownColumnError{Column: ref}
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return ColumnRef{Column: parts[0]}, nil
+	case 2:
+		return ColumnRef{Table: parts[0], Column: parts[1]}, nil
+	default:
+		return ColumnRef{}, &UnknownColumnError{Column: ref}
+	}
+}
+
+func (r ColumnRef) String() string {
+	if r.Table == "" {
+		return r.Column
+	}
+	return r.Table + "." + r.Column
+}
+
+type JoinType string
+
+const (
+	InnerJoin JoinType = "INNER"
+	LeftJoin  JoinType = "LEFT"
+	RightJoin JoinType = "RIGHT"
+)
+
+func ParseJoinType(s string) (JoinType, error) {
+	switch t := JoinType(strings.ToUpper(strings.TrimSpace(s))); t {
+	case InnerJoin, LeftJoin, RightJoin:
+		return t, nil
+	case "":
+		return InnerJoin, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported join type %q", ErrInvalidJoin, s)
+	}
+}
+
+// On is one equality of a join condition, both sides are column references.
+type On struct {
+	Left  string
+	Right string
+}
+
+type Join struct {
+	Type  JoinType
+	Table string
+	// On may be left empty, the condition is then derived from the single
+	// foreign key relating Table to a table already in the query.
+	On []On
+}
+
+// ParseCondition accepts conditions of the form "a.x = b.y [AND c.z = d.w ...]",
+// every column qualified with its table.
+func ParseCondition(condition string) ([]On, error) {
+	if strings.TrimSpace(condition) == "" {
+		return nil, nil
+	}
+
+	var ons []On
+	for _, part := range andRe.Split(strings.TrimSpace(condition), -1) {
+		sides := strings.Split(part, "=")
+		if len(sides) != 2 {
+			return nil, fmt.Errorf("%w: only column equalities are allowed, got %q", ErrInvalidJoin, part)
+		}
+		left, right := strings.TrimSpace(sides[0]), strings.TrimSpace(sides[1])
+		for _, side := range []string{left, right} {
+			ref, err := ParseColumnRef(side)
+			if err != nil {
+				return nil, err
+			}
+			if ref.Table == "" {
+				return nil, fmt.Errorf("%w: column %q of a condition must be qualified with its table", ErrInvalidJoin, side)
+			}
+		}
+		ons = append(ons, On{Left: left, Right: right})
+	}
+
+	return ons, nil
+}
+
+type Op string
+
+const (
+	OpEq        Op = "="
+	OpNotEq     Op = "<>"
+	OpLt        Op = "<"
+	OpLte       Op = "<="
+	OpGt        Op = ">"
+	OpGte       Op = ">="
+	OpIn        Op = "IN"
+	OpNotIn     Op = "NOT IN"
+	OpLike      Op = "LIKE"
+	OpILike     Op = "ILIKE"
+	OpIsNull    Op = "IS NULL"
+	OpIsNotNull Op = "IS NOT NULL"
+)
+
+func ParseOp(s string) (Op, error) {
+	op := Op(strings.Join(strings.Fields(strings.ToUpper(s)), " "))
+	switch op {
+	case "!=":
+		return OpNotEq, nil
+	case "==":
+		return OpEq, nil
+	case OpEq, OpNotEq, OpLt, OpLte, OpGt, OpGte, OpIn, OpNotIn, OpLike, OpILike, OpIsNull, OpIsNotNull:
+		return op, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported operator %q", ErrInvalidFilter, s)
+	}
+}
+
+type Filter struct {
+	Column string
+	Op     Op
+	Value  any
+}
+
+func Eq(column string, value any) Filter {
+	return Filter{Column: column, Op: OpEq, Value: value}
+}
+
+type orderTerm struct {
+	column string
+	desc   bool
+	random bool
+}
+
+type SelectBuilder struct {
+	catalog Catalog
+	from    string
+	columns []string
+	joins   []Join
+	filters []Filter
+	orderBy []orderTerm
+	limit   *uint64
+	offset  *uint64
+}
+
+// Select starts a query over from. With a nil catalog identifiers are only
+// checked syntactically and quoted, and joins must carry explicit conditions.
+func Select(catalog Catalog, from string, columns ...string) *SelectBuilder {
+	return &SelectBuilder{
+		catalog: catalog,
+		from:    from,
+		columns: columns,
+	}
+}
+
+func (b *SelectBuilder) Join(joins ...Join) *SelectBuilder {
+	b.joins = append(b.joins, joins...)
+	return b
+}
+
+func (b *SelectBuilder) Where(filters ...Filter) *SelectBuilder {
+	b.filters = append(b.filters, filters...)
+	return b
+}
+
+func (b *SelectBuilder) OrderBy(column string, desc bool) *SelectBuilder {
+	b.orderBy = append(b.orderBy, orderTerm{column: column, desc: desc})
+	return b
+}
+
+func (b *SelectBuilder) OrderByRandom() *SelectBuilder {
+	b.orderBy = append(b.orderBy, orderTerm{random: true})
+	return b
+}
+
+func (b *SelectBuilder) Limit(n uint64) *SelectBuilder {
+	b.limit = &n
+	return b
+}
+
+func (b *SelectBuilder) Offset(n uint64) *SelectBuilder {
+	b.offset = &n
+	return b
+}
+
+func (b *SelectBuilder) ToSql() (string, []any, error) {
+	builder, err := b.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	return builder.ToSql()
+}
+
+// Build validates the query and returns the underlying squirrel builder so
+// callers can add trusted clauses of their own.
+func (b *SelectBuilder) Build() (sq.SelectBuilder, error) {
+	r := &resolver{catalog: b.catalog}
+
+	if err := r.addTable(b.from); err != nil {
+		return sq.SelectBuilder{}, err
+	}
+
+	builder := sq.Select().From(Quote(b.from)).PlaceholderFormat(sq.Dollar)
+
+	for _, join := range b.joins {
+		clause, err := r.join(join)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		builder = builder.JoinClause(clause)
+	}
+
+	// Columns are resolved once every joined table is in scope.
+	var columns []string
+	for _, column := range b.columns {
+		quoted, _, err := r.column(column)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		columns = append(columns, quoted)
+	}
+	if len(columns) == 0 {
+		columns = append(columns, Quote(b.from)+".*")
+	}
+	builder = builder.Columns(columns...)
+
+	for _, filter := range b.filters {
+		pred, err := r.filter(filter)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		builder = builder.Where(pred)
+	}
+
+	for _, term := range b.orderBy {
+		if term.random {
+			builder = builder.OrderBy("RANDOM()")
+			continue
+		}
+		quoted, _, err := r.column(term.column)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		if term.desc {
+			quoted += " DESC"
+		}
+		builder = builder.OrderBy(quoted)
+	}
+
+	if b.limit != nil {
+		builder = builder.Limit(*b.limit)
+	}
+	if b.offset != nil {
+		builder = builder.Offset(*b.offset)
+	}
+
+	return builder, nil
+}
+
+type resolver struct {
+	catalog Catalog
+	scope   []string
+}
+
+func (r *resolver) inScope(table string) bool {
+	for _, t := range r.scope {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *resolver) addTable(table string) error {
+	if !identifierRe.MatchString(table) {
+		return &UnknownTableError{Table: table}
+	}
+	if r.catalog != nil {
+		if _, ok := r.catalog.Table(table); !ok {
+			return &UnknownTableError{Table: table}
+		}
+	}
+	if r.inScope(table) {
+		return &InvalidJoinError{Table: table, Reason: "table is already part of the query"}
+	}
+	r.scope = append(r.scope, table)
+	return nil
+}
+
+// column resolves a possibly unqualified column reference against the tables
+// in scope and returns it quoted and qualified.
+func (r *resolver) column(ref string) (string, *model.Column, error) {
+	cr, err := ParseColumnRef(ref)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if cr.Table != "" {
+		if !r.inScope(cr.Table) {
+			return "", nil, &UnknownTableError{Table: cr.Table}
+		}
+		if r.catalog == nil {
+			return Quote(cr.Table, cr.Column), nil, nil
+		}
+		t, _ := r.catalog.Table(cr.Table)
+		col, ok := t.Column(cr.Column)
+		if !ok {
+			return "", nil, &UnknownColumnError{Table: cr.Table, Column: cr.Column}
+		}
+		return Quote(cr.Table, cr.Column), col, nil
+	}
+
+	if r.catalog == nil {
+		if len(r.scope) == 1 {
+			return Quote(r.scope[0], cr.Column), nil, nil
+		}
+		return Quote(cr.Column), nil, nil
+	}
+
+	var owners []string
+	var found *model.Column
+	for _, table := range r.scope {
+		t, _ := r.catalog.Table(table)
+		if col, ok := t.Column(cr.Column); ok {
+			owners = append(owners, table)
+			found = col
+		}
+	}
+
+	switch len(owners) {
+	case 0:
+		return "", nil, &UnknownColumnError{Column: cr.Column}
+	case 1:
+		return Quote(owners[0], cr.Column), found, nil
+	default:
+		return "", nil, &AmbiguousColumnError{Column: cr.Column, Tables: owners}
+	}
+}
+
+func (r *resolver) join(join Join) (string, error) {
+	joinType, err := ParseJoinType(string(join.Type))
+	if err != nil {
+		return "", err
+	}
+
+	ons := join.On
+	if len(ons) == 0 {
+		ons, err = r.deriveOn(join.Table)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := r.addTable(join.Table); err != nil {
+		return "", err
+	}
+
+	conditions := make([]string, 0, len(ons))
+	for _, on := range ons {
+		left, _, err := r.column(on.Left)
+		if err != nil {
+			return "", err
+		}
+		right, _, err := r.column(on.Right)
+		if err != nil {
+			return "", err
+		}
+		if !strings.HasPrefix(left, Quote(join.Table)+".") && !strings.HasPrefix(right, Quote(join.Table)+".") {
+			return "", &InvalidJoinError{Table: join.Table, Reason: fmt.Sprintf("condition %s = %s does not reference the joined table", on.Left, on.Right)}
+		}
+		conditions = append(conditions, left+" = "+right)
+	}
+
+	return fmt.Sprintf("%s JOIN %s ON %s", joinType, Quote(join.Table), strings.Join(conditions, " AND ")), nil
+}
+
+func (r *resolver) deriveOn(table string) ([]On, error) {
+	if r.catalog == nil {
+		return nil, &InvalidJoinError{Table: table, Reason: "no condition given and no catalog to derive it from"}
+	}
+	joined, ok := r.catalog.Table(table)
+	if !ok {
+		return nil, &UnknownTableError{Table: table}
+	}
+
+	type candidate struct {
+		name string
+		ons  []On
+	}
+	var candidates []candidate
+
+	for _, scoped := range r.scope {
+		t, _ := r.catalog.Table(scoped)
+		for _, fk := range joined.ForeignKeysTo(scoped) {
+			candidates = append(candidates, candidate{fk.Name, foreignKeyOn(table, fk)})
+		}
+		for _, fk := range t.ForeignKeysTo(table) {
+			candidates = append(candidates, candidate{fk.Name, foreignKeyOn(scoped, fk)})
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, &InvalidJoinError{Table: table, Reason: "no foreign key relates it to the query"}
+	case 1:
+		return candidates[0].ons, nil
+	default:
+		names := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			names = append(names, c.name)
+		}
+		return nil, &InvalidJoinError{Table: table, Reason: "several foreign keys apply (" + strings.Join(names, ", ") + "), give the condition explicitly"}
+	}
+}
+
+func foreignKeyOn(table string, fk model.ForeignKey) []On {
+	ons := make([]On, 0, len(fk.Columns))
+	for i := range fk.Columns {
+		ons = append(ons, On{
+			Left:  table + "." + fk.Columns[i],
+			Right: fk.RefTable + "." + fk.RefColumns[i],
+		})
+	}
+	return ons
+}
+
+func (r *resolver) filter(f Filter) (sq.Sqlizer, error) {
+	quoted, col, err := r.column(f.Column)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := ParseOp(string(f.Op))
+	if err != nil {
+		return nil, &InvalidFilterError{Column: f.Column, Reason: err.Error()}
+	}
+
+	switch op {
+	case OpIsNull, OpIsNotNull:
+		if f.Value != nil {
+			return nil, &InvalidFilterError{Column: f.Column, Reason: string(op) + " takes no value"}
+		}
+		return sq.Expr(quoted + " " + string(op)), nil
+	case OpIn, OpNotIn:
+		v := reflect.ValueOf(f.Value)
+		if f.Value == nil || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() == 0 {
+			return nil, &InvalidFilterError{Column: f.Column, Reason: string(op) + " needs a non-empty list"}
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := r.checkEnum(f.Column, col, v.Index(i).Interface()); err != nil {
+				return nil, err
+			}
+		}
+		if op == OpIn {
+			return sq.Eq{quoted: f.Value}, nil
+		}
+		return sq.NotEq{quoted: f.Value}, nil
+	default:
+		if f.Value == nil {
+			return nil, &InvalidFilterError{Column: f.Column, Reason: "comparison with NULL, use IS NULL"}
+		}
+		if err := r.checkEnum(f.Column, col, f.Value); err != nil {
+			return nil, err
+		}
+		return sq.Expr(quoted+" "+string(op)+" ?", f.Value), nil
+	}
+}
+
+func (r *resolver) checkEnum(ref string, col *model.Column, value any) error {
+	if r.catalog == nil || col == nil {
+		return nil
+	}
+	enum, ok := r.catalog.Enum(col.UDTName)
+	if !ok {
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return &InvalidFilterError{Column: ref, Reason: fmt.Sprintf("%s expects a string", enum.Name)}
+	}
+	if !enum.Has(s) {
+		return &InvalidFilterError{Column: ref, Reason: fmt.Sprintf("%q is not a valid %s", s, enum.Name)}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"vehicles-service-stations/internal/model"
+)
+
+type catalog struct {
+	tables map[string]*model.Table
+	enums  map[string]*model.Enum
+}
+
+func (c catalog) Table(name string) (*model.Table, bool) {
+	t, ok := c.tables[name]
+	return t, ok
+}
+
+func (c catalog) Enum(name string) (*model.Enum, bool) {
+	e, ok := c.enums[name]
+	return e, ok
+}
+
+func testCatalog() catalog {
+	fk := func(column, table string) model.ForeignKey {
+		return model.ForeignKey{
+			Name:       "orders_" + column + "_fkey",
+			Columns:    []string{column},
+			RefSchema:  "public",
+			RefTable:   table,
+			RefColumns: []string{table[:len(table)-1] + "_id"},
+		}
+	}
+	return catalog{
+		tables: map[string]*model.Table{
+			"orders": {
+				Schema: "public",
+				Name:   "orders",
+				Columns: []model.Column{
+					{Name: "order_id", UDTName: "int4"},
+					{Name: "customer_id", UDTName: "int4"},
+					{Name: "manager_id", UDTName: "int4"},
+					{Name: "assigned_master_id", UDTName: "int4"},
+					{Name: "status", UDTName: "order_status"},
+				},
+				ForeignKeys: []model.ForeignKey{
+					fk("customer_id", "customers"),
+					fk("manager_id", "employees"),
+					fk("assigned_master_id", "employees"),
+				},
+			},
+			"customers": {
+				Schema:  "public",
+				Name:    "customers",
+				Columns: []model.Column{{Name: "customer_id"}, {Name: "full_name"}},
+			},
+			"employees": {
+				Schema:  "public",
+				Name:    "employees",
+				Columns: []model.Column{{Name: "employee_id"}, {Name: "full_name"}},
+			},
+		},
+		enums: map[string]*model.Enum{
+			"order_status": {Name: "order_status", Values: []string{"Pending", "In Progress", "Completed"}},
+		},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	cat := testCatalog()
+	tests := []struct {
+		name    string
+		builder *SelectBuilder
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "quoted",
+			builder: Select(cat, "orders", "order_id", "orders.status").OrderBy("order_id", true),
+			want:    `SELECT "orders"."order_id", "orders"."status" FROM "orders" ORDER BY "orders"."order_id" DESC`,
+		},
+		{
+			name:    "quoted_without_catalog",
+			builder: Select(nil, "orders", "status"),
+			want:    `SELECT "orders"."status" FROM "orders"`,
+		},
+		{
+			name:    "unknown_table",
+			builder: Select(cat, "payments"),
+			wantErr: ErrUnknownTable,
+		},
+		{
+			name:    "table_injection",
+			builder: Select(nil, `orders; DROP TABLE orders`),
+			wantErr: ErrUnknownTable,
+		},
+		{
+			name:    "unknown_column",
+			builder: Select(cat, "orders", "orders.total"),
+			wantErr: ErrUnknownColumn,
+		},
+		{
+			name:    "column_injection",
+			builder: Select(cat, "orders", "order_id FROM customers --"),
+			wantErr: ErrUnknownColumn,
+		},
+		{
+			name:    "column_of_a_table_not_joined",
+			builder: Select(cat, "orders", "customers.full_name"),
+			wantErr: ErrUnknownTable,
+		},
+		{
+			name:    "ambiguous_column",
+			builder: Select(cat, "orders", "full_name").Join(Join{Table: "customers"}, Join{Table: "employees", On: []On{{"employees.employee_id", "orders.manager_id"}}}),
+			wantErr: ErrAmbiguousColumn,
+		},
+		{
+			name:    "join_on_the_foreign_key",
+			builder: Select(cat, "orders", "customers.full_name").Join(Join{Table: "customers"}),
+			want:    `SELECT "customers"."full_name" FROM "orders" INNER JOIN "customers" ON "orders"."customer_id" = "customers"."customer_id"`,
+		},
+		{
+			name:    "join_on_one_of_several_foreign_keys",
+			builder: Select(cat, "orders").Join(Join{Table: "employees"}),
+			wantErr: ErrInvalidJoin,
+		},
+		{
+			name:    "join_condition_not_on_the_joined_table",
+			builder: Select(cat, "orders").Join(Join{Table: "customers", On: []On{{"orders.customer_id", "orders.order_id"}}}),
+			wantErr: ErrInvalidJoin,
+		},
+		{
+			name:    "join_type",
+			builder: Select(cat, "orders").Join(Join{Type: "CROSS", Table: "customers"}),
+			wantErr: ErrInvalidJoin,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, _, err := tt.builder.ToSql()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want {
+				t.Errorf("got  %s\nwant %s", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	_, _, err := Select(testCatalog(), "orders", "orders.total").ToSql()
+	var columnErr *UnknownColumnError
+	if !errors.As(err, &columnErr) || columnErr.Table != "orders" || columnErr.Column != "total" {
+		t.Errorf("error %#v, want an UnknownColumnError of orders.total", err)
+	}
+	_, _, err = Select(testCatalog(), "payments").ToSql()
+	var tableErr *UnknownTableError
+	if !errors.As(err, &tableErr) || tableErr.Table != "payments" {
+		t.Errorf("error %#v, want an UnknownTableError of payments", err)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		parts []string
+		want  string
+	}{
+		{[]string{"orders"}, `"orders"`},
+		{[]string{"orders", "status"}, `"orders"."status"`},
+		{[]string{`we"ird`}, `"we""ird"`},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.parts...); got != tt.want {
+			t.Errorf("Quote(%q) = %s, want %s", tt.parts, got, tt.want)
+		}
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      []On
+		wantErr   error
+	}{
+		{"", nil, nil},
+		{"a.x = b.y", []On{{"a.x", "b.y"}}, nil},
+		{"a.x = b.y and c.z = d.w", []On{{"a.x", "b.y"}, {"c.z", "d.w"}}, nil},
+		{"a.x >= b.y", nil, ErrUnknownColumn},
+		{"a.x = b.y OR 1 = 1", nil, ErrInvalidJoin},
+		{"1=1; DROP TABLE orders", nil, ErrUnknownColumn},
+		{"a.x = b.y; DROP TABLE orders", nil, ErrUnknownColumn},
+		{"x = b.y", nil, ErrInvalidJoin},
+		{"a.x = y", nil, ErrInvalidJoin},
+		{"a.x = b.y = c.z", nil, ErrInvalidJoin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			ons, err := ParseCondition(tt.condition)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ons) != len(tt.want) {
+				t.Fatalf("got %v, want %v", ons, tt.want)
+			}
+			for i := range ons {
+				if ons[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", ons, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestFilters(t *testing.T) {
+	cat := testCatalog()
+	tests := []struct {
+		name     string
+		filter   Filter
+		want     string
+		wantArgs int
+		wantErr  error
+	}{
+		{"enum", Eq("status", "Pending"), `SELECT "orders".* FROM "orders" WHERE "orders"."status" = $1`, 1, nil},
+		{"enum_unknown_value", Eq("status", "Lost"), "", 0, ErrInvalidFilter},
+		{"enum_not_a_string", Eq("status", 3), "", 0, ErrInvalidFilter},
+		{"in", Filter{"status", OpIn, []string{"Pending", "Completed"}}, `SELECT "orders".* FROM "orders" WHERE "orders"."status" IN ($1,$2)`, 2, nil},
+		{"in_unknown_value", Filter{"status", OpIn, []string{"Pending", "Lost"}}, "", 0, ErrInvalidFilter},
+		{"in_empty", Filter{"order_id", OpIn, []int{}}, "", 0, ErrInvalidFilter},
+		{"in_nil", Filter{"order_id", OpNotIn, nil}, "", 0, ErrInvalidFilter},
+		{"in_not_a_list", Filter{"order_id", OpIn, 1}, "", 0, ErrInvalidFilter},
+		{"is_null", Filter{"manager_id", OpIsNull, nil}, `SELECT "orders".* FROM "orders" WHERE "orders"."manager_id" IS NULL`, 0, nil},
+		{"is_null_with_value", Filter{"manager_id", OpIsNull, 1}, "", 0, ErrInvalidFilter},
+		{"compare_with_null", Eq("manager_id", nil), "", 0, ErrInvalidFilter},
+		{"operator", Filter{"order_id", "; DROP", 1}, "", 0, ErrInvalidFilter},
+		{"not_equal", Filter{"order_id", "!=", 1}, `SELECT "orders".* FROM "orders" WHERE "orders"."order_id" <> $1`, 1, nil},
+		{"unknown_column", Eq("total", 1), "", 0, ErrUnknownColumn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := Select(cat, "orders").Where(tt.filter).ToSql()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sql != tt.want || len(args) != tt.wantArgs {
+				t.Errorf("got  %s %v\nwant %s with %d arguments", sql, args, tt.want, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownTable    = errors.New("unknown table")
+	ErrUnknownColumn   = errors.New("unknown column")
+	ErrAmbiguousColumn = errors.New("ambiguous column")
+	ErrInvalidJoin     = errors.New("invalid join")
+	ErrInvalidFilter   = errors.New("invalid filter")
+)
+
+type UnknownTableError struct {
+	Table string
+}
+
+func (e *UnknownTableError) Error() string {
+	return fmt.Sprintf("unknown table %q", e.Table)
+}
+
+func (e *UnknownTableError) Is(target error) bool {
+	return target == ErrUnknownTable
+}
+
+type UnknownColumnError struct {
+	Table  string
+	Column string
+}
+
+func (e *UnknownColumnError) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("unknown column %q", e.Column)
+	}
+	return fmt.Sprintf("unknown column %q in table %q", e.Column, e.Table)
+}
+
+func (e *UnknownColumnError) Is(target error) bool {
+	return target == ErrUnknownColumn
+}
+
+type AmbiguousColumnError struct {
+	Column string
+	Tables []string
+}
+
+func (e *AmbiguousColumnError) Error() string {
+	return fmt.Sprintf("column %q is ambiguous between %s", e.Column, strings.Join(e.Tables, ", "))
+}
+
+func (e *AmbiguousColumnError) Is(target error) bool {
+	return target == ErrAmbiguousColumn
+}
+
+type InvalidJoinError struct {
+	Table  string
+	Reason string
+}
+
+func (e *InvalidJoinError) Error() string {
+	return fmt.Sprintf("invalid join of %q: %s", e.Table, e.Reason)
+}
+
+func (e *InvalidJoinError) Is(target error) bool {
+	return target == ErrInvalidJoin
+}
+
+type InvalidFilterError struct {
+	Column string
+	Reason string
+}
+
+func (e *InvalidFilterError) Error() string {
+	return fmt.Sprintf("invalid filter on %q: %s", e.Column, e.Reason)
+}
+
+func (e *InvalidFilterError) Is(target error) bool {
+	return target == ErrInvalidFilter
+}
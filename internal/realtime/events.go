@@ -0,0 +1,128 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Channel is notified by realtime.notify() with the events of the audit log.
+const Channel = "realtime"
+
+type Kind string
+
+const (
+	OrderCreated  Kind = "order.created"
+	OrderUpdated  Kind = "order.updated"
+	ReceiptIssued Kind = "receipt.issued"
+	StockLow      Kind = "stock.low"
+)
+
+// Event is the payload of a Channel notification. ID is the audit_id of the
+// change, the fields of other kinds are zero.
+type Event struct {
+	ID               int64     `json:"id"`
+	Kind             Kind      `json:"kind"`
+	At               time.Time `json:"at"`
+	OrderID          int       `json:"order_id,omitempty"`
+	CenterID         int       `json:"service_center_id,omitempty"`
+	CustomerID       int       `json:"customer_id,omitempty"`
+	MasterID         int       `json:"master_id,omitempty"`
+	PreviousMasterID int       `json:"previous_master_id,omitempty"`
+	Status           string    `json:"status,omitempty"`
+	ReceiptID        int       `json:"receipt_id,omitempty"`
+	PartID           int       `json:"part_id,omitempty"`
+	Stock            int       `json:"stock,omitempty"`
+}
+
+// Filter picks the events of a subscriber, its zero fields match
+// everything. Parts belong to no center, master or customer: their events
+// only match filters without them.
+type Filter struct {
+	Kinds      []Kind
+	CenterID   int
+	MasterID   int
+	CustomerID int
+}
+
+func (f Filter) Match(e Event) bool {
+	if len(f.Kinds) > 0 && !slices.Contains(f.Kinds, e.Kind) {
+		return false
+	}
+	if f.CenterID != 0 && e.CenterID != f.CenterID {
+		return false
+	}
+	// A master is told about the orders taken from them as well.
+	if f.MasterID != 0 && e.MasterID != f.MasterID && e.PreviousMasterID != f.MasterID {
+		return false
+	}
+	return f.CustomerID == 0 || e.CustomerID == f.CustomerID
+}
+
+// Querier is the connection the events are read with. It needs to read the
+// audit log.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Since returns at most limit events of the audit log after the one with the
+// ID after, in order.
+func Since(ctx context.Context, db Querier, after int64, limit int) ([]Event, error) {
+	rows, err := db.Query(ctx, `
+        SELECT e.payload
+        FROM audit.log l, realtime.event(l) AS e(payload)
+        WHERE l.audit_id > $1 AND e.payload IS NOT NULL
+        ORDER BY l.audit_id
+        LIMIT $2
+    `, after, limit)
+	return collectEvents(rows, err)
+}
+
+// Tail returns the last limit events of the audit log after the one with the
+// ID after, in order.
+func Tail(ctx context.Context, db Querier, after int64, limit int) ([]Event, error) {
+	rows, err := db.Query(ctx, `
+        SELECT payload
+        FROM (
+            SELECT l.audit_id, e.payload
+            FROM audit.log l, realtime.event(l) AS e(payload)
+            WHERE l.audit_id > $1 AND e.payload IS NOT NULL
+            ORDER BY l.audit_id DESC
+            LIMIT $2
+        ) t
+        ORDER BY audit_id
+    `, after, limit)
+	return collectEvents(rows, err)
+}
+
+func collectEvents(rows pgx.Rows, err error) ([]Event, error) {
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Event, error) {
+		var payload []byte
+		var e Event
+		if err := row.Scan(&payload); err != nil {
+			return e, err
+		}
+		return e, json.Unmarshal(payload, &e)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read events: %w", err)
+	}
+	return events, nil
+}
+
+// Latest returns the ID of the last change of the audit log.
+func Latest(ctx context.Context, db Querier) (int64, error) {
+	var id int64
+	if err := db.QueryRow(ctx, `SELECT COALESCE(max(audit_id), 0) FROM audit.log`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to read the audit log: %w", err)
+	}
+	return id, nil
+}
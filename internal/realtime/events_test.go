@@ -0,0 +1,89 @@
+package realtime_test
+
+import (
+	"context"
+	"testing"
+
+	"vehicles-service-stations/internal/realtime"
+	"vehicles-service-stations/internal/testutil"
+)
+
+// drain returns the events a subscription received so far.
+func drain(sub *realtime.Subscription) []realtime.Event {
+	var events []realtime.Event
+	for {
+		select {
+		case e := <-sub.C:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+// A job assigned and then taken over by another master, its receipt and a
+// part running low come out of the audit log as events, and every subscriber
+// gets only those of its center, master or kind.
+func TestEventsFromAuditLog(t *testing.T) {
+	d := testutil.NewTestDatabase(t, "realtime")
+	ctx := context.Background()
+	tx := testutil.Tx(t, d.Pool)
+	before, err := realtime.Latest(ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := testutil.NewBase(t, tx)
+	orderID := b.Order(t, tx, b.Customer, b.Masters[0], testutil.Day)
+	if _, err := tx.Exec(ctx, `UPDATE orders SET reassigned_master_id = $1 WHERE order_id = $2`, b.Masters[1], orderID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO receipts (order_id, total_paid) VALUES ($1, 500)`, orderID); err != nil {
+		t.Fatal(err)
+	}
+	partID := testutil.NewPart(t, tx, 6)
+	if _, err := tx.Exec(ctx, `UPDATE spare_parts SET stock_quantity = 3 WHERE part_id = $1`, partID); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := realtime.Since(ctx, tx, before, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := realtime.NewHub(nil, "")
+	first := hub.Subscribe(realtime.Filter{MasterID: b.Masters[0]})
+	receipts := hub.Subscribe(realtime.Filter{Kinds: []realtime.Kind{realtime.ReceiptIssued}, CenterID: b.Center})
+	stock := hub.Subscribe(realtime.Filter{Kinds: []realtime.Kind{realtime.StockLow}})
+	stranger := hub.Subscribe(realtime.Filter{CustomerID: b.Customer + 1000000})
+	for _, e := range events {
+		hub.Publish(e)
+	}
+
+	t.Run("master", func(t *testing.T) {
+		var created, takenOver bool
+		for _, e := range drain(first) {
+			if e.OrderID != orderID || e.CenterID != b.Center || e.CustomerID != b.Customer {
+				t.Errorf("first master got %+v", e)
+			}
+			created = created || e.Kind == realtime.OrderCreated && e.MasterID == b.Masters[0]
+			takenOver = takenOver || e.Kind == realtime.OrderUpdated && e.MasterID == b.Masters[1] && e.PreviousMasterID == b.Masters[0]
+		}
+		if !created || !takenOver {
+			t.Errorf("first master told about creation %t and take over %t, want both", created, takenOver)
+		}
+	})
+	t.Run("receipts", func(t *testing.T) {
+		if got := drain(receipts); len(got) != 1 || got[0].OrderID != orderID || got[0].MasterID != b.Masters[1] {
+			t.Errorf("receipts %+v, want the one of order %d by %d", got, orderID, b.Masters[1])
+		}
+	})
+	t.Run("stock", func(t *testing.T) {
+		if got := drain(stock); len(got) != 1 || got[0].PartID != partID || got[0].Stock != 3 {
+			t.Errorf("low stock %+v, want part %d at 3", got, partID)
+		}
+	})
+	t.Run("another_customer", func(t *testing.T) {
+		if got := drain(stranger); len(got) != 0 {
+			t.Errorf("another customer got %+v", got)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"vehicles-service-stations/internal/db"
+)
+
+const (
+	// subscriberBuffer is how many events a subscriber may lag behind
+	// before it is dropped.
+	subscriberBuffer = 64
+	// maxReplay bounds the events replayed after a reconnect, a listener
+	// gone for longer starts from the latest change.
+	maxReplay = 10000
+)
+
+// Hub fans the events out to the subscribers of this process. It listens
+// with a connection of the pool of role in the connection manager, taken out
+// of the pool for as long as it listens.
+type Hub struct {
+	pools *db.ConnectionManager
+	role  string
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	// last is the ID of the last event seen, the replay after a reconnect
+	// starts from it. started tells a hub that saw nothing yet from one that
+	// started on an empty log.
+	last    int64
+	started bool
+}
+
+func NewHub(pools *db.ConnectionManager, role string) *Hub {
+	return &Hub{pools: pools, role: role, subscribers: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events matching its filter published after it
+// was made. C is closed when the subscriber fell behind.
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	filter Filter
+	hub    *Hub
+}
+
+func (h *Hub) Subscribe(f Filter) *Subscription {
+	c := make(chan Event, subscriberBuffer)
+	s := &Subscription{C: c, c: c, filter: f, hub: h}
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subscribers[s]; ok {
+		delete(s.hub.subscribers, s)
+		close(s.c)
+	}
+}
+
+// Subscribers returns how many subscribers there are.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
+
+func (h *Hub) Publish(e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.last = max(h.last, e.ID)
+	for s := range h.subscribers {
+		if !s.filter.Match(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			delete(h.subscribers, s)
+			close(s.c)
+		}
+	}
+}
+
+// Run publishes the events until ctx is done, reconnecting when the
+// connection is lost and replaying the events missed meanwhile. Changes
+// committed while disconnected behind one seen before, by their audit ID,
+// are not replayed.
+func (h *Hub) Run(ctx context.Context) error {
+	backoff := time.Second
+	for {
+		err := h.listen(ctx, func() { backoff = time.Second })
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("Listening to %s failed, retrying in %s: %v", Channel, backoff, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+func (h *Hub) listen(ctx context.Context, connected func()) error {
+	pooled, err := h.pools.GetPool(h.role).Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection is listening, it must not go back to the pool.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	// Listening comes first so that nothing falls between the replay and
+	// the notifications, those replayed already are skipped.
+	replayed, err := h.replay(ctx, conn)
+	if err != nil {
+		return err
+	}
+	connected()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(n.Payload), &e); err != nil {
+			log.Printf("Malformed %s payload %q: %v", Channel, n.Payload, err)
+			continue
+		}
+		if replayed[e.ID] {
+			continue
+		}
+		h.Publish(e)
+	}
+}
+
+// replay publishes the events after the last one seen, or only remembers
+// the latest change on the first connect.
+func (h *Hub) replay(ctx context.Context, conn Querier) (map[int64]bool, error) {
+	h.mu.Lock()
+	last, started := h.last, h.started
+	h.mu.Unlock()
+
+	if !started {
+		latest, err := Latest(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		h.mu.Lock()
+		h.last = max(h.last, latest)
+		h.started = true
+		h.mu.Unlock()
+		return nil, nil
+	}
+
+	events, err := Since(ctx, conn, last, maxReplay)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == maxReplay {
+		// Too many were missed, only the latest ones are replayed.
+		if events, err = Tail(ctx, conn, last, maxReplay); err != nil {
+			return nil, err
+		}
+		log.Printf("More than %d events missed, those from audit ID %d to %d are lost", maxReplay, last+1, events[0].ID-1)
+	}
+	replayed := make(map[int64]bool, len(events))
+	for _, e := range events {
+		replayed[e.ID] = true
+		h.Publish(e)
+	}
+	if len(events) > 0 {
+		log.Printf("Replayed %d events after %d", len(events), last)
+	}
+	return replayed, nil
+}
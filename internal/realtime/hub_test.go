@@ -0,0 +1,161 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeLog serves Latest, Since and Tail from events in memory.
+type fakeLog struct {
+	events []Event
+}
+
+func (l *fakeLog) add(ids ...int64) {
+	for _, id := range ids {
+		l.events = append(l.events, Event{ID: id, Kind: OrderUpdated})
+	}
+}
+
+func (l *fakeLog) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	after, limit := args[0].(int64), args[1].(int)
+	var events []Event
+	for _, e := range l.events {
+		if e.ID > after {
+			events = append(events, e)
+		}
+	}
+	if len(events) > limit {
+		if strings.Contains(sql, "DESC") {
+			events = events[len(events)-limit:]
+		} else {
+			events = events[:limit]
+		}
+	}
+	return &fakeRows{events: events, i: -1}, nil
+}
+
+func (l *fakeLog) QueryRow(context.Context, string, ...any) pgx.Row {
+	var latest int64
+	if len(l.events) > 0 {
+		latest = l.events[len(l.events)-1].ID
+	}
+	return fakeRow(latest)
+}
+
+type fakeRow int64
+
+func (r fakeRow) Scan(dest ...any) error {
+	*dest[0].(*int64) = int64(r)
+	return nil
+}
+
+type fakeRows struct {
+	events []Event
+	i      int
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return nil }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) Values() ([]any, error)                       { return nil, nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i < len(r.events)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	payload, err := json.Marshal(r.events[r.i])
+	*dest[0].(*[]byte) = payload
+	return err
+}
+
+func ids(replayed map[int64]bool) (int, int64, int64) {
+	lo, hi := int64(-1), int64(-1)
+	for id := range replayed {
+		if lo < 0 || id < lo {
+			lo = id
+		}
+		hi = max(hi, id)
+	}
+	return len(replayed), lo, hi
+}
+
+func TestReplay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("empty log", func(t *testing.T) {
+		log := &fakeLog{}
+		h := NewHub(nil, "")
+		if replayed, err := h.replay(ctx, log); err != nil || len(replayed) != 0 {
+			t.Fatalf("first connect replayed %v, %v", replayed, err)
+		}
+		// Everything logged after the first connect is replayed, although
+		// the log was empty then.
+		log.add(1, 2, 4)
+		sub := h.Subscribe(Filter{})
+		replayed, err := h.replay(ctx, log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, lo, hi := ids(replayed); n != 3 || lo != 1 || hi != 4 {
+			t.Errorf("replayed %v, want 1, 2 and 4", replayed)
+		}
+		if len(sub.C) != 3 {
+			t.Errorf("subscriber got %d events, want 3", len(sub.C))
+		}
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		log := &fakeLog{}
+		log.add(1, 2, 3)
+		h := NewHub(nil, "")
+		if replayed, err := h.replay(ctx, log); err != nil || len(replayed) != 0 {
+			t.Fatalf("first connect replayed %v, %v", replayed, err)
+		}
+		log.add(5, 6)
+		replayed, err := h.replay(ctx, log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, lo, hi := ids(replayed); n != 2 || lo != 5 || hi != 6 {
+			t.Errorf("replayed %v, want 5 and 6", replayed)
+		}
+		if replayed, _ := h.replay(ctx, log); len(replayed) != 0 {
+			t.Errorf("replayed %v again", replayed)
+		}
+	})
+
+	// Audit IDs have gaps, the replay takes the latest events rather than
+	// the latest IDs.
+	t.Run("too many missed", func(t *testing.T) {
+		log := &fakeLog{}
+		log.add(1)
+		h := NewHub(nil, "")
+		if _, err := h.replay(ctx, log); err != nil {
+			t.Fatal(err)
+		}
+		for i := range maxReplay + 5 {
+			log.add(int64(10 + 3*i))
+		}
+		replayed, err := h.replay(ctx, log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, lo, hi := ids(replayed)
+		if want := int64(10 + 3*(maxReplay+4)); n != maxReplay || lo != int64(10+3*5) || hi != want {
+			t.Errorf("replayed %d events from %d to %d, want %d from %d to %d", n, lo, hi, maxReplay, 10+3*5, want)
+		}
+		if h.last != hi {
+			t.Errorf("last is %d, want %d", h.last, hi)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package rls
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/testutil"
+)
+
+// Actor is a fixture employee logged in with their own role.
+type Actor struct {
+	Key        string
+	Role       string
+	Center     string
+	Login      string
+	EmployeeID int
+	Pool       *pgxpool.Pool
+}
+
+// Row identifies a single fixture row by a condition on its table.
+type Row struct {
+	Table string
+	Where string
+	Args  []any
+}
+
+// Insert is a row a case tries to insert.
+type Insert struct {
+	Table  string
+	Values map[string]any
+}
+
+// Fixture is a small, fully known data set: two service centers with staff of
+// every role, customers with and without orders and orders covering direct
+// assignment, reassignment and a NULL reassigned master.
+type Fixture struct {
+	DB      *testutil.Database
+	Actors  map[string]*Actor
+	Rows    map[string]Row
+	Inserts map[string]Insert
+
+	centers   map[string]int
+	customers map[string]int
+}
+
+type fixtureEmployee struct {
+	key    string
+	role   string
+	center string
+}
+
+var fixtureEmployees = []fixtureEmployee{
+	{"admin_a", "Administrator", "A"},
+	{"manager_a", "Manager", "A"},
+	{"master_a1", "Master", "A"},
+	{"master_a2", "Master", "A"},
+	{"analyst_a", "Analyst", "A"},
+	{"manager_b", "Manager", "B"},
+	{"master_b", "Master", "B"},
+}
+
+const fixturePassword = "Fixture-Password-1"
+
+// NewFixture seeds d and logs in every fixture employee.
+func NewFixture(ctx context.Context, d *testutil.Database) (*Fixture, error) {
+	f := &Fixture{
+		DB:        d,
+		Actors:    make(map[string]*Actor),
+		Rows:      make(map[string]Row),
+		Inserts:   make(map[string]Insert),
+		centers:   make(map[string]int),
+		customers: make(map[string]int),
+	}
+
+	tx, err := d.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	for i, center := range []string{"A", "B"} {
+		var id int
+		err := tx.QueryRow(ctx, `
+            INSERT INTO service_centers (full_address, city, postal_code, phone_number)
+            VALUES ($1, 'Fixture City', '000000', $2)
+            RETURNING service_center_id
+        `, "Center "+center, fmt.Sprintf("+7000000000%d", i)).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create center %s: %w", center, err)
+		}
+		f.centers[center] = id
+		f.Rows["center:"+center] = Row{Table: "service_centers", Where: "service_center_id = $1", Args: []any{id}}
+	}
+
+	// Logins are cluster wide, the database suffix keeps parallel fixtures
+	// apart.
+	suffix := d.Name[strings.LastIndex(d.Name, "_")+1:]
+	for _, e := range fixtureEmployees {
+		login := "rls_" + suffix + "_" + e.key
+		_, err := tx.Exec(ctx, `SELECT create_user($1, 5, 30, 50000, $2, $3, $4, $5)`,
+			"Fixture "+e.key, login, fixturePassword, e.role, f.centers[e.center])
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", e.key, err)
+		}
+		d.TrackRole(login)
+
+		a := &Actor{Key: e.key, Role: e.role, Center: e.center, Login: login}
+		if err := tx.QueryRow(ctx, `SELECT employee_id FROM employees WHERE username = $1`, login).Scan(&a.EmployeeID); err != nil {
+			return nil, err
+		}
+		f.Actors[e.key] = a
+		f.Rows["employee:"+e.key] = Row{Table: "employees", Where: "employee_id = $1", Args: []any{a.EmployeeID}}
+	}
+
+	for i, key := range []string{"A", "B", "none"} {
+		var id int
+		err := tx.QueryRow(ctx, `
+            INSERT INTO customers (full_name, phone_number)
+            VALUES ($1, $2)
+            RETURNING customer_id
+        `, "Customer "+key, fmt.Sprintf("+7111111111%d", i)).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create customer %s: %w", key, err)
+		}
+		f.customers[key] = id
+		f.Rows["customer:"+key] = Row{Table: "customers", Where: "customer_id = $1", Args: []any{id}}
+	}
+
+	orders := []struct {
+		key, center, customer, manager, master, reassigned string
+		days                                               int
+	}{
+		{"A1", "A", "A", "manager_a", "master_a1", "", 1},
+		{"A2", "A", "A", "manager_a", "master_a2", "master_a1", 2},
+		{"A3", "A", "A", "manager_a", "master_a2", "", 3},
+		{"B1", "B", "B", "manager_b", "master_b", "", 1},
+	}
+	for _, o := range orders {
+		var reassigned *int
+		if o.reassigned != "" {
+			reassigned = &f.Actors[o.reassigned].EmployeeID
+		}
+		var id int
+		err := tx.QueryRow(ctx, `
+            INSERT INTO orders (customer_id, service_center_id, manager_id, assigned_master_id, reassigned_master_id, scheduled_date)
+            VALUES ($1, $2, $3, $4, $5, CURRENT_DATE + $6::int)
+            RETURNING order_id
+        `, f.customers[o.customer], f.centers[o.center], f.Actors[o.manager].EmployeeID,
+			f.Actors[o.master].EmployeeID, reassigned, o.days).Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create order %s: %w", o.key, err)
+		}
+		f.Rows["order:"+o.key] = Row{Table: "orders", Where: "order_id = $1", Args: []any{id}}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, a := range f.Actors {
+		pool, err := d.ConnectAs(ctx, a.Login, fixturePassword)
+		if err != nil {
+			return nil, err
+		}
+		a.Pool = pool
+	}
+
+	f.Inserts["order:A_by_manager_a"] = Insert{Table: "orders", Values: map[string]any{
+		"customer_id":        f.customers["none"],
+		"service_center_id":  f.centers["A"],
+		"manager_id":         f.Actors["manager_a"].EmployeeID,
+		"assigned_master_id": f.Actors["master_a1"].EmployeeID,
+		"scheduled_date":     time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+	}}
+	f.Inserts["order:A_for_manager_b"] = Insert{Table: "orders", Values: map[string]any{
+		"customer_id":        f.customers["none"],
+		"service_center_id":  f.centers["A"],
+		"manager_id":         f.Actors["manager_b"].EmployeeID,
+		"assigned_master_id": f.Actors["master_a1"].EmployeeID,
+		"scheduled_date":     time.Date(2100, 1, 2, 0, 0, 0, 0, time.UTC),
+	}}
+	f.Inserts["customer"] = Insert{Table: "customers", Values: map[string]any{
+		"full_name":    "Inserted Customer",
+		"phone_number": "+79999999999",
+	}}
+	f.Inserts["service_center"] = Insert{Table: "service_centers", Values: map[string]any{
+		"full_address": "Inserted Center",
+		"city":         "Fixture City",
+		"postal_code":  "000001",
+		"phone_number": "+79999999998",
+	}}
+
+	return f, nil
+}
+
+// exec runs fn as the actor in a transaction that is always rolled back, so
+// cases never see each other's writes.
+func (f *Fixture) exec(ctx context.Context, actor string, fn func(tx pgx.Tx) error) error {
+	a, ok := f.Actors[actor]
+	if !ok {
+		return fmt.Errorf("unknown fixture actor %q", actor)
+	}
+	tx, err := a.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	return fn(tx)
+}
@@ -0,0 +1,167 @@
+package rls
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/query"
+)
+
+// Cell is the effective permission of a role on a table for one operation.
+// For SELECT, UPDATE and DELETE it counts the fixture rows the role reaches,
+// INSERT only shows whether the privilege is granted since whether a row
+// passes WITH CHECK depends on the row.
+type Cell struct {
+	Granted bool
+	Rows    int64
+	Total   int64
+	Error   string
+}
+
+func (c Cell) String() string {
+	switch {
+	case c.Error != "":
+		return "error " + c.Error
+	case !c.Granted:
+		return "—"
+	case c.Total < 0:
+		return "granted"
+	case c.Total == 0:
+		return "granted, no rows"
+	case c.Rows == c.Total:
+		return "all"
+	case c.Rows == 0:
+		return "none"
+	default:
+		return fmt.Sprintf("%d/%d", c.Rows, c.Total)
+	}
+}
+
+type TableRow struct {
+	Table string
+	RLS   bool
+	Cells map[Op]Cell
+}
+
+type RoleMatrix struct {
+	Role  string
+	Actor string
+	Rows  []TableRow
+}
+
+// MatrixActors are the fixture employees whose permissions stand for their
+// role.
+var MatrixActors = []string{"admin_a", "manager_a", "master_a1", "analyst_a"}
+
+// Matrix computes the effective permissions of every role on every table of
+// the fixture database.
+func Matrix(ctx context.Context, f *Fixture) ([]RoleMatrix, error) {
+	catalog := model.NewAllowedTables()
+	if err := catalog.Initialize(ctx, f.DB.Pool, "public"); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]int64)
+	for _, table := range catalog.Tables() {
+		var n int64
+		if err := f.DB.Pool.QueryRow(ctx, "SELECT count(*) FROM "+query.Quote(table)).Scan(&n); err != nil {
+			return nil, err
+		}
+		totals[table] = n
+	}
+
+	var matrices []RoleMatrix
+	for _, key := range MatrixActors {
+		a, ok := f.Actors[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown fixture actor %q", key)
+		}
+		m := RoleMatrix{Role: a.Role, Actor: key}
+		for _, table := range catalog.Tables() {
+			t, _ := catalog.Table(table)
+			row := TableRow{Table: table, RLS: t.RowLevelSecurity, Cells: make(map[Op]Cell)}
+			for _, op := range []Op{OpSelect, OpInsert, OpUpdate, OpDelete} {
+				cell, err := f.cell(ctx, key, t, op, totals[table])
+				if err != nil {
+					return nil, fmt.Errorf("%s %s %s: %w", key, op, table, err)
+				}
+				row.Cells[op] = cell
+			}
+			m.Rows = append(m.Rows, row)
+		}
+		matrices = append(matrices, m)
+	}
+	return matrices, nil
+}
+
+func (f *Fixture) cell(ctx context.Context, actor string, t *model.Table, op Op, total int64) (Cell, error) {
+	cell := Cell{Total: total}
+	err := f.exec(ctx, actor, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `SELECT has_table_privilege(current_user, $1, $2)`, query.Quote(t.Name), string(op)).Scan(&cell.Granted); err != nil {
+			return err
+		}
+		if !cell.Granted {
+			return nil
+		}
+
+		table := query.Quote(t.Name)
+		var stmt string
+		switch op {
+		case OpSelect:
+			stmt = "SELECT 1 FROM " + table
+		case OpInsert:
+			cell.Total = -1
+			return nil
+		case OpUpdate:
+			col := query.Quote(t.Columns[0].Name)
+			stmt = fmt.Sprintf("UPDATE %s SET %s = %s", table, col, col)
+		case OpDelete:
+			stmt = "DELETE FROM " + table
+		}
+
+		tag, err := tx.Exec(ctx, stmt)
+		outcome, detail, oerr := outcomeOf(tag.RowsAffected(), err)
+		if oerr != nil {
+			return oerr
+		}
+		switch {
+		case outcome == Failed:
+			cell.Error = strings.SplitN(detail, ":", 2)[0]
+		case err != nil:
+			// Denied by privileges on a column or a referenced table.
+			cell.Granted = false
+		}
+		cell.Rows = tag.RowsAffected()
+		return nil
+	})
+	return cell, err
+}
+
+// WriteMarkdown renders the matrices as Markdown.
+func WriteMarkdown(w io.Writer, matrices []RoleMatrix) error {
+	var b strings.Builder
+	b.WriteString("# Effective permissions\n\n")
+	b.WriteString("Rows reached out of the fixture rows of each table; \"—\" means the privilege is not granted.\n")
+
+	for _, m := range matrices {
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n", m.Role, m.Actor)
+		b.WriteString("| table | RLS | SELECT | INSERT | UPDATE | DELETE |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, row := range m.Rows {
+			rls := ""
+			if row.RLS {
+				rls = "yes"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", row.Table, rls,
+				row.Cells[OpSelect], row.Cells[OpInsert], row.Cells[OpUpdate], row.Cells[OpDelete])
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,164 @@
+package rls
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/testutil"
+)
+
+func newFixture(t *testing.T) *Fixture {
+	t.Helper()
+	f, err := NewFixture(context.Background(), testutil.NewTestDatabase(t, "rls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestPolicies(t *testing.T) {
+	f := newFixture(t)
+	tests := []struct {
+		actor  string
+		op     Op
+		target string
+		want   Outcome
+		// skip explains why the policies do not give want yet.
+		skip string
+	}{
+		// Masters see and change only orders assigned or reassigned to
+		// them, and a NULL reassigned_master_id must not match anybody.
+		{"master_a1", OpSelect, "order:A1", Allowed, ""},
+		{"master_a1", OpSelect, "order:A2", Allowed, ""},
+		{"master_a1", OpSelect, "order:A3", Denied, ""},
+		{"master_a1", OpSelect, "order:B1", Denied, ""},
+		{"master_a2", OpSelect, "order:A2", Allowed, ""},
+		{"master_a2", OpSelect, "order:A3", Allowed, ""},
+		{"master_a1", OpUpdate, "order:A1", Allowed, ""},
+		{"master_a1", OpUpdate, "order:A2", Allowed, ""},
+		{"master_a1", OpUpdate, "order:A3", Denied, ""},
+		{"master_a1", OpUpdate, "order:B1", Denied, ""},
+		{"master_a1", OpDelete, "order:A1", Denied, ""},
+		{"master_a1", OpInsert, "order:A_by_manager_a", Denied, ""},
+		{"master_a1", OpSelect, "customer:A", Allowed, ""},
+		{"master_a1", OpSelect, "customer:B", Denied, ""},
+		{"master_a1", OpSelect, "customer:none", Denied, ""},
+		{"master_a1", OpUpdate, "customer:A", Denied, ""},
+		{"master_a1", OpSelect, "employee:master_a1", Allowed, ""},
+		{"master_a1", OpSelect, "employee:manager_a", Denied, ""},
+		{"master_a1", OpSelect, "center:A", Allowed, ""},
+		{"master_a1", OpSelect, "center:B", Denied, ""},
+
+		// Managers see their center, create orders in their own name and
+		// change only orders they manage.
+		{"manager_a", OpSelect, "order:A1", Allowed, ""},
+		{"manager_a", OpSelect, "order:A3", Allowed, ""},
+		{"manager_a", OpSelect, "order:B1", Denied, ""},
+		{"manager_a", OpUpdate, "order:A1", Allowed, ""},
+		{"manager_a", OpUpdate, "order:B1", Denied, ""},
+		{"manager_a", OpDelete, "order:A1", Denied, ""},
+		{"manager_a", OpInsert, "order:A_by_manager_a", Allowed, ""},
+		{"manager_a", OpInsert, "order:A_for_manager_b", Denied, ""},
+		{"manager_a", OpSelect, "customer:B", Allowed, ""},
+		{"manager_a", OpUpdate, "customer:B", Allowed, ""},
+		{"manager_a", OpInsert, "customer", Allowed, ""},
+		{"manager_a", OpDelete, "customer:none", Denied, ""},
+		{"manager_a", OpSelect, "employee:master_a1", Allowed, ""},
+		{"manager_a", OpSelect, "employee:master_b", Denied, ""},
+		{"manager_a", OpSelect, "center:A", Allowed, ""},
+		{"manager_a", OpSelect, "center:B", Denied, ""},
+		{"manager_b", OpUpdate, "order:A1", Denied, ""},
+
+		// Analysts read everything and write nothing.
+		{"analyst_a", OpSelect, "order:B1", Allowed, ""},
+		{"analyst_a", OpSelect, "customer:none", Allowed, ""},
+		{"analyst_a", OpSelect, "employee:manager_b", Allowed, ""},
+		{"analyst_a", OpSelect, "center:B", Allowed, ""},
+		{"analyst_a", OpUpdate, "customer:A", Denied, ""},
+		{"analyst_a", OpInsert, "customer", Denied, ""},
+		{"analyst_a", OpDelete, "order:A1", Denied, ""},
+
+		// Administrators manage staff everywhere and customers and the
+		// center of their own service center.
+		{"admin_a", OpSelect, "employee:master_b", Allowed, ""},
+		{"admin_a", OpUpdate, "employee:master_b", Allowed, ""},
+		{"admin_a", OpSelect, "center:B", Allowed, ""},
+		{"admin_a", OpUpdate, "center:A", Allowed, ""},
+		{"admin_a", OpUpdate, "center:B", Denied, ""},
+		{"admin_a", OpSelect, "customer:B", Allowed, ""},
+		{"admin_a", OpInsert, "customer", Allowed, ""},
+		{"admin_a", OpUpdate, "customer:B", Denied, ""},
+		{"admin_a", OpSelect, "order:A1", Allowed, "orders has no policy for administrator, so RLS hides every order"},
+		{"admin_a", OpUpdate, "customer:A", Allowed, "admin_customers_update_policy looks up orders, which administrators cannot see"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%s/%s", tt.actor, tt.op, tt.target), func(t *testing.T) {
+			if tt.skip != "" {
+				t.Skip(tt.skip)
+			}
+			got, detail, err := f.Run(context.Background(), tt.actor, tt.op, tt.target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("%s, want %s %s", got, tt.want, detail)
+			}
+		})
+	}
+}
+
+// grants are the table privileges of every role on the tables of the
+// public schema, as S, I, U and D for SELECT, INSERT, UPDATE and DELETE.
+// Column privileges, such as UPDATE of the stock of spare parts for
+// managers, are not table privileges.
+var grants = map[string]map[string]string{
+	"services":                {"Administrator": "SIUD", "Analyst": "S", "Manager": "S", "Master": "S"},
+	"customers":               {"Administrator": "SIUD", "Analyst": "S", "Manager": "SIU", "Master": "S"},
+	"service_centers":         {"Administrator": "SIUD", "Analyst": "S", "Manager": "S", "Master": "S"},
+	"employees":               {"Administrator": "SIUD", "Analyst": "S", "Manager": "S", "Master": "S"},
+	"employee_service_center": {"Administrator": "SIUD", "Analyst": "S", "Manager": "S", "Master": "S"},
+	"orders":                  {"Administrator": "SIUD", "Analyst": "S", "Manager": "SIU", "Master": "SU"},
+	"service_order":           {"Administrator": "SIUD", "Analyst": "S", "Manager": "SIU", "Master": "S"},
+	"stockpile":               {"Administrator": "SIUD", "Analyst": "S", "Manager": "S", "Master": "S"},
+	"spare_parts":             {"Administrator": "SIUD", "Analyst": "S", "Manager": "S", "Master": "S"},
+	"spare_part_order":        {"Administrator": "SIUD", "Analyst": "S", "Manager": "SIU", "Master": "S"},
+	"receipts":                {"Administrator": "SIUD", "Analyst": "S", "Manager": "SIU", "Master": ""},
+}
+
+// TestMatrix checks the effective permissions of every role on every table
+// against grants. A role granted a privilege it cannot use, because of a
+// column or a referenced table, fails as well.
+func TestMatrix(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	matrices, err := Matrix(ctx, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog := model.NewAllowedTables()
+	if err := catalog.Initialize(ctx, f.DB.Pool, "public"); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range matrices {
+		for _, row := range m.Rows {
+			if table, _ := catalog.Table(row.Table); table.IsView() {
+				continue
+			}
+			for _, op := range []Op{OpSelect, OpInsert, OpUpdate, OpDelete} {
+				t.Run(fmt.Sprintf("%s/%s/%s", m.Role, row.Table, op), func(t *testing.T) {
+					roles, ok := grants[row.Table]
+					if !ok {
+						t.Fatal("the table is missing from grants")
+					}
+					want := strings.Contains(roles[m.Role], string(op[0]))
+					if cell := row.Cells[op]; cell.Granted != want {
+						t.Errorf("granted %v (%s), want %v", cell.Granted, cell, want)
+					}
+				})
+			}
+		}
+	}
+}
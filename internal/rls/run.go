@@ -0,0 +1,103 @@
+package rls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vehicles-service-stations/internal/query"
+)
+
+type Op string
+
+const (
+	OpSelect Op = "SELECT"
+	OpInsert Op = "INSERT"
+	OpUpdate Op = "UPDATE"
+	OpDelete Op = "DELETE"
+)
+
+type Outcome string
+
+const (
+	Allowed Outcome = "allowed"
+	// Denied covers both missing privileges and rows hidden by a policy.
+	Denied Outcome = "denied"
+	Failed Outcome = "failed"
+)
+
+// Run performs op on the target as the actor and rolls it back. Target is
+// a key of Fixture.Rows, or of Fixture.Inserts for OpInsert.
+func (f *Fixture) Run(ctx context.Context, actor string, op Op, target string) (Outcome, string, error) {
+	var stmt string
+	var args []any
+
+	if op == OpInsert {
+		ins, ok := f.Inserts[target]
+		if !ok {
+			return "", "", fmt.Errorf("unknown insert %q", target)
+		}
+		columns := make([]string, 0, len(ins.Values))
+		for col := range ins.Values {
+			columns = append(columns, col)
+		}
+		sort.Strings(columns)
+		placeholders := make([]string, len(columns))
+		for i, col := range columns {
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+			args = append(args, ins.Values[col])
+			columns[i] = query.Quote(col)
+		}
+		stmt = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", query.Quote(ins.Table), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	} else {
+		row, ok := f.Rows[target]
+		if !ok {
+			return "", "", fmt.Errorf("unknown row %q", target)
+		}
+		args = row.Args
+		table := query.Quote(row.Table)
+		switch op {
+		case OpSelect:
+			stmt = fmt.Sprintf("SELECT 1 FROM %s WHERE %s", table, row.Where)
+		case OpUpdate:
+			// A no-op assignment still needs the row to pass USING and
+			// WITH CHECK.
+			col := strings.Fields(row.Where)[0]
+			stmt = fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s", table, col, col, row.Where)
+		case OpDelete:
+			stmt = fmt.Sprintf("DELETE FROM %s WHERE %s", table, row.Where)
+		default:
+			return "", "", fmt.Errorf("unknown operation %q", op)
+		}
+	}
+
+	var affected int64
+	err := f.exec(ctx, actor, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, stmt, args...)
+		affected = tag.RowsAffected()
+		return err
+	})
+	return outcomeOf(affected, err)
+}
+
+func outcomeOf(affected int64, err error) (Outcome, string, error) {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil && affected > 0:
+		return Allowed, "", nil
+	case err == nil:
+		return Denied, "", nil
+	case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.InsufficientPrivilege:
+		return Denied, "", nil
+	case errors.As(err, &pgErr):
+		return Failed, pgErr.Code + ": " + pgErr.Message, nil
+	default:
+		return "", "", err
+	}
+}
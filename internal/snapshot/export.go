@@ -0,0 +1,192 @@
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/query"
+)
+
+type ExportOptions struct {
+	Dir    string
+	Schema string
+	Format Format
+	// IncludeSecrets keeps employees' password hashes in the snapshot.
+	IncludeSecrets bool
+}
+
+func Export(ctx context.Context, db *pgxpool.Pool, opts ExportOptions) (*Manifest, error) {
+	if opts.Schema == "" {
+		opts.Schema = "public"
+	}
+	if opts.Format == "" {
+		opts.Format = FormatJSONL
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot dir: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin export transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	catalog := model.NewAllowedTables()
+	if err := catalog.Initialize(ctx, tx, opts.Schema); err != nil {
+		return nil, err
+	}
+	order, err := catalog.LoadOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		FormatVersion:     FormatVersion,
+		SchemaFingerprint: SchemaFingerprint(catalog),
+		CreatedAt:         time.Now().UTC(),
+		Format:            opts.Format,
+		IncludesSecrets:   opts.IncludeSecrets,
+		Note:              "database roles of employees are not part of the snapshot",
+	}
+
+	for _, name := range order {
+		t, _ := catalog.Table(name)
+		entry, err := exportTable(ctx, tx, opts, t)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %w", name, err)
+		}
+		manifest.Tables = append(manifest.Tables, *entry)
+	}
+
+	if err := writeManifest(opts.Dir, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func exportTable(ctx context.Context, tx pgx.Tx, opts ExportOptions, t *model.Table) (*TableEntry, error) {
+	entry := &TableEntry{
+		Name:    t.Name,
+		File:    t.Name + "." + string(opts.Format),
+		Columns: t.ColumnNames(),
+	}
+
+	detached, err := DetachedColumns(t)
+	if err != nil {
+		return nil, err
+	}
+
+	selectList := make([]string, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		if detached[col.Name] {
+			selectList = append(selectList, fmt.Sprintf("NULL::%s AS %s", col.Type, query.Quote(col.Name)))
+			entry.Detached = append(entry.Detached, col.Name)
+			continue
+		}
+		placeholder, sensitive := sensitiveColumns[t.Name][col.Name]
+		if sensitive && !opts.IncludeSecrets {
+			selectList = append(selectList, fmt.Sprintf("%s::%s AS %s", quoteLiteral(placeholder), col.Type, query.Quote(col.Name)))
+			entry.Redacted = append(entry.Redacted, col.Name)
+			continue
+		}
+		selectList = append(selectList, query.Quote(col.Name))
+	}
+
+	sql := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selectList, ", "), query.Quote(opts.Schema, t.Name))
+	if len(t.PrimaryKey) > 0 {
+		keys := make([]string, 0, len(t.PrimaryKey))
+		for _, col := range t.PrimaryKey {
+			keys = append(keys, query.Quote(col))
+		}
+		sql += " ORDER BY " + strings.Join(keys, ", ")
+	}
+
+	perm := os.FileMode(0o644)
+	if opts.IncludeSecrets && len(sensitiveColumns[t.Name]) > 0 {
+		perm = 0o600
+	}
+	err = replaceFile(filepath.Join(opts.Dir, entry.File), perm, func(w io.Writer) error {
+		switch opts.Format {
+		case FormatCSV:
+			tag, err := tx.Conn().PgConn().CopyTo(ctx, w, "COPY ("+sql+") TO STDOUT WITH (FORMAT csv, HEADER true)")
+			if err != nil {
+				return err
+			}
+			entry.Rows = tag.RowsAffected()
+		default:
+			rows, err := tx.Query(ctx, "SELECT to_jsonb(s)::text FROM ("+sql+") s")
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var line string
+				if err := rows.Scan(&line); err != nil {
+					return err
+				}
+				if _, err := io.WriteString(w, line+"\n"); err != nil {
+					return err
+				}
+				entry.Rows++
+			}
+			return rows.Err()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Sequence, err = ReadSequence(ctx, tx, opts.Schema, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// replaceFile writes a file under a temporary name and renames it into place.
+// The permissions of os.OpenFile only apply to new files, so a snapshot
+// written over an older one would keep its mode otherwise.
+func replaceFile(path string, perm os.FileMode, write func(io.Writer) error) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return err
+	}
+	w := bufio.NewWriter(file)
+	if err := write(w); err != nil {
+		file.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,168 @@
+package snapshot
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/query"
+)
+
+type ImportOptions struct {
+	Dir    string
+	Schema string
+	// DisableTriggers loads with session_replication_role = replica so that
+	// triggers do not recount stock, totals or bonuses already present in the
+	// snapshot. It needs a superuser connection.
+	DisableTriggers bool
+	// Truncate empties the target tables first instead of refusing to load
+	// into a non-empty database.
+	Truncate bool
+	// Force skips the schema fingerprint comparison.
+	Force     bool
+	BatchSize int
+}
+
+func Import(ctx context.Context, db *pgxpool.Pool, opts ImportOptions) (*Manifest, error) {
+	if opts.Schema == "" {
+		opts.Schema = "public"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	manifest, err := readManifest(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	catalog := model.NewAllowedTables()
+	if err := catalog.Initialize(ctx, tx, opts.Schema); err != nil {
+		return nil, err
+	}
+	if fp := SchemaFingerprint(catalog); fp != manifest.SchemaFingerprint && !opts.Force {
+		return nil, fmt.Errorf("snapshot schema %s does not match database schema %s", manifest.SchemaFingerprint, fp)
+	}
+	for _, entry := range manifest.Tables {
+		if !catalog.IsValid(entry.Name, "") {
+			return nil, fmt.Errorf("table %s of the snapshot does not exist", entry.Name)
+		}
+		for _, col := range entry.Columns {
+			if !catalog.IsValid(entry.Name, col) {
+				return nil, fmt.Errorf("column %s.%s of the snapshot does not exist", entry.Name, col)
+			}
+		}
+	}
+
+	if opts.DisableTriggers {
+		if _, err := tx.Exec(ctx, `SET LOCAL session_replication_role = replica`); err != nil {
+			return nil, fmt.Errorf("failed to disable triggers: %w", err)
+		}
+	}
+
+	tables := make([]string, 0, len(manifest.Tables))
+	for _, entry := range manifest.Tables {
+		tables = append(tables, entry.Name)
+	}
+	if err := PrepareTargets(ctx, tx, opts.Schema, tables, opts.Truncate); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range manifest.Tables {
+		loaded, err := importTable(ctx, tx, opts, manifest.Format, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import %s: %w", entry.Name, err)
+		}
+		if loaded != entry.Rows {
+			return nil, fmt.Errorf("imported %d rows into %s, manifest says %d", loaded, entry.Name, entry.Rows)
+		}
+
+		if err := RestoreSequence(ctx, tx, entry.Sequence); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	return manifest, nil
+}
+
+func importTable(ctx context.Context, tx pgx.Tx, opts ImportOptions, format Format, entry TableEntry) (int64, error) {
+	file, err := os.Open(filepath.Join(opts.Dir, entry.File))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	table := query.Quote(opts.Schema, entry.Name)
+	columns := make([]string, 0, len(entry.Columns))
+	for _, col := range entry.Columns {
+		columns = append(columns, query.Quote(col))
+	}
+	columnList := strings.Join(columns, ", ")
+
+	if format == FormatCSV {
+		tag, err := tx.Conn().PgConn().CopyFrom(ctx, file,
+			fmt.Sprintf("COPY %s (%s) FROM STDIN WITH (FORMAT csv, HEADER true)", table, columnList))
+		if err != nil {
+			return 0, err
+		}
+		return tag.RowsAffected(), nil
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM jsonb_populate_recordset(NULL::%s, $1::jsonb)",
+		table, columnList, columnList, table)
+
+	var loaded int64
+	batch := make([]string, 0, opts.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		tag, err := tx.Exec(ctx, insert, "["+strings.Join(batch, ",")+"]")
+		if err != nil {
+			return err
+		}
+		loaded += tag.RowsAffected()
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		batch = append(batch, line)
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return loaded, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return loaded, err
+	}
+	if err := flush(); err != nil {
+		return loaded, err
+	}
+
+	return loaded, nil
+}
@@ -0,0 +1,127 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"vehicles-service-stations/internal/model"
+)
+
+const (
+	FormatVersion = 1
+	manifestFile  = "manifest.json"
+)
+
+type Format string
+
+const (
+	FormatJSONL Format = "jsonl"
+	FormatCSV   Format = "csv"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatJSONL, FormatCSV:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown snapshot format %q", s)
+	}
+}
+
+// sensitiveColumns are replaced by a placeholder on export unless secrets are
+// requested. The placeholder is not a valid bcrypt hash, so restored employees
+// cannot log in through the employees table.
+var sensitiveColumns = map[string]map[string]string{
+	"employees": {"password_hash": "!redacted"},
+}
+
+type Sequence struct {
+	Name      string `json:"name"`
+	Column    string `json:"column"`
+	LastValue *int64 `json:"last_value"`
+}
+
+type TableEntry struct {
+	Name     string   `json:"name"`
+	File     string   `json:"file"`
+	Columns  []string `json:"columns"`
+	Rows     int64    `json:"rows"`
+	Redacted []string `json:"redacted,omitempty"`
+	// Detached columns reference tables of other schemas and are exported
+	// as NULL.
+	Detached []string  `json:"detached,omitempty"`
+	Sequence *Sequence `json:"sequence,omitempty"`
+}
+
+type Manifest struct {
+	FormatVersion     int          `json:"format_version"`
+	SchemaFingerprint string       `json:"schema_fingerprint"`
+	CreatedAt         time.Time    `json:"created_at"`
+	Format            Format       `json:"format"`
+	IncludesSecrets   bool         `json:"includes_secrets"`
+	Note              string       `json:"note,omitempty"`
+	Tables            []TableEntry `json:"tables"`
+}
+
+func readManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if m.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported snapshot format version %d", m.FormatVersion)
+	}
+	return &m, nil
+}
+
+func writeManifest(dir string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Catalog is the part of model.AllowedTables a fingerprint is computed from.
+type Catalog interface {
+	Tables() []string
+	Table(name string) (*model.Table, bool)
+	Enums() []*model.Enum
+}
+
+// SchemaFingerprint hashes the tables, columns, types and foreign keys of the
+// catalogue, snapshots only load into databases with the same fingerprint.
+func SchemaFingerprint(catalog Catalog) string {
+	h := sha256.New()
+	for _, name := range catalog.Tables() {
+		t, _ := catalog.Table(name)
+		fmt.Fprintf(h, "table %s\n", name)
+		for _, col := range t.Columns {
+			fmt.Fprintf(h, "  column %s %s %t\n", col.Name, col.Type, col.Nullable)
+		}
+		fks := append([]model.ForeignKey(nil), t.ForeignKeys...)
+		sort.Slice(fks, func(i, j int) bool { return fks[i].Name < fks[j].Name })
+		for _, fk := range fks {
+			fmt.Fprintf(h, "  fk %v -> %s.%s%v\n", fk.Columns, fk.RefSchema, fk.RefTable, fk.RefColumns)
+		}
+	}
+	for _, e := range catalog.Enums() {
+		fmt.Fprintf(h, "enum %s %v\n", e.Name, e.Values)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
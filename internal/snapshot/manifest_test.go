@@ -0,0 +1,207 @@
+package snapshot
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"vehicles-service-stations/internal/model"
+)
+
+type catalog struct {
+	tables map[string]*model.Table
+	enums  []*model.Enum
+}
+
+func (c catalog) Tables() []string {
+	var names []string
+	for name := range c.tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c catalog) Table(name string) (*model.Table, bool) {
+	t, ok := c.tables[name]
+	return t, ok
+}
+
+func (c catalog) Enums() []*model.Enum { return c.enums }
+
+func testCatalog() catalog {
+	return catalog{
+		tables: map[string]*model.Table{
+			"customers": {
+				Schema:  "public",
+				Name:    "customers",
+				Columns: []model.Column{{Name: "customer_id", Type: "integer"}, {Name: "full_name", Type: "text"}},
+			},
+			"orders": {
+				Schema: "public",
+				Name:   "orders",
+				Columns: []model.Column{
+					{Name: "order_id", Type: "integer"},
+					{Name: "customer_id", Type: "integer"},
+					{Name: "status", Type: "order_status"},
+					{Name: "created_by", Type: "integer", Nullable: true},
+				},
+				ForeignKeys: []model.ForeignKey{
+					{Name: "orders_customer_id_fkey", Columns: []string{"customer_id"}, RefSchema: "public", RefTable: "customers", RefColumns: []string{"customer_id"}},
+					{Name: "orders_created_by_fkey", Columns: []string{"created_by"}, RefSchema: "auth", RefTable: "users", RefColumns: []string{"user_id"}},
+				},
+			},
+		},
+		enums: []*model.Enum{{Name: "order_status", Values: []string{"Pending", "Completed"}}},
+	}
+}
+
+func TestSchemaFingerprint(t *testing.T) {
+	base := SchemaFingerprint(testCatalog())
+	if base != SchemaFingerprint(testCatalog()) {
+		t.Fatal("the fingerprint of the same catalogue differs")
+	}
+
+	reordered := testCatalog()
+	fks := reordered.tables["orders"].ForeignKeys
+	fks[0], fks[1] = fks[1], fks[0]
+	if SchemaFingerprint(reordered) != base {
+		t.Error("the order of foreign keys changes the fingerprint")
+	}
+
+	tests := []struct {
+		name   string
+		change func(c catalog)
+	}{
+		{"column type", func(c catalog) { c.tables["customers"].Columns[1].Type = "varchar(100)" }},
+		{"nullability", func(c catalog) { c.tables["customers"].Columns[1].Nullable = true }},
+		{"new column", func(c catalog) {
+			c.tables["customers"].Columns = append(c.tables["customers"].Columns, model.Column{Name: "phone", Type: "text"})
+		}},
+		{"new table", func(c catalog) { c.tables["services"] = &model.Table{Schema: "public", Name: "services"} }},
+		{"foreign key target", func(c catalog) { c.tables["orders"].ForeignKeys[1].RefSchema = "public" }},
+		{"enum value", func(c catalog) { c.enums[0].Values = append(c.enums[0].Values, "Cancelled") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testCatalog()
+			tt.change(c)
+			if SchemaFingerprint(c) == base {
+				t.Error("the fingerprint did not change")
+			}
+		})
+	}
+}
+
+func TestManifest(t *testing.T) {
+	dir := t.TempDir()
+	last := int64(42)
+	m := &Manifest{
+		FormatVersion:     FormatVersion,
+		SchemaFingerprint: SchemaFingerprint(testCatalog()),
+		CreatedAt:         time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Format:            FormatCSV,
+		Tables: []TableEntry{{
+			Name:     "orders",
+			File:     "orders.csv",
+			Columns:  []string{"order_id", "customer_id", "status", "created_by"},
+			Rows:     3,
+			Detached: []string{"created_by"},
+			Sequence: &Sequence{Name: "public.orders_order_id_seq", Column: "order_id", LastValue: &last},
+		}},
+	}
+	if err := writeManifest(dir, m); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("read %+v, want %+v", got, m)
+	}
+
+	m.FormatVersion = FormatVersion + 1
+	if err := writeManifest(dir, m); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readManifest(dir); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("read a manifest of another version, error %v", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"jsonl": FormatJSONL, "CSV": FormatCSV} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("parsed an unknown format")
+	}
+}
+
+func TestDetachedColumns(t *testing.T) {
+	orders, _ := testCatalog().Table("orders")
+	detached, err := DetachedColumns(orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"created_by": true}; !reflect.DeepEqual(detached, want) {
+		t.Errorf("detached %v, want %v", detached, want)
+	}
+
+	customers, _ := testCatalog().Table("customers")
+	if detached, err := DetachedColumns(customers); err != nil || len(detached) != 0 {
+		t.Errorf("detached %v, %v of a table without external keys", detached, err)
+	}
+
+	orders.Columns[3].Nullable = false
+	if _, err := DetachedColumns(orders); err == nil {
+		t.Error("detached a column that cannot be NULL")
+	}
+}
+
+func TestReplaceFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "employees.jsonl")
+	if err := os.WriteFile(path, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := replaceFile(path, 0o600, func(w io.Writer) error {
+		_, err := io.WriteString(w, "new\n")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("file mode %o after replacing a 644 file, want 600", mode)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Errorf("file holds %q", data)
+	}
+
+	err = replaceFile(path, 0o600, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errors.New("export failed")
+	})
+	if err == nil {
+		t.Fatal("a failed write succeeded")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new\n" {
+		t.Errorf("a failed write left %q", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("a failed write left %d files", len(entries))
+	}
+}
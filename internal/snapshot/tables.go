@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/query"
+)
+
+// PrepareTargets truncates the tables of the schema about to be loaded, or
+// checks that they are empty. It is shared with the masking copy.
+func PrepareTargets(ctx context.Context, tx pgx.Tx, schema string, tables []string, truncate bool) error {
+	if truncate {
+		quoted := make([]string, 0, len(tables))
+		for _, name := range tables {
+			quoted = append(quoted, query.Quote(schema, name))
+		}
+		if _, err := tx.Exec(ctx, "TRUNCATE "+strings.Join(quoted, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+			return fmt.Errorf("failed to truncate target tables: %w", err)
+		}
+		return nil
+	}
+
+	for _, name := range tables {
+		var exists bool
+		err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM "+query.Quote(schema, name)+")").Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", name, err)
+		}
+		if exists {
+			return fmt.Errorf("target table %s is not empty, use truncate to replace its rows", name)
+		}
+	}
+	return nil
+}
+
+// ReadSequence returns the sequence behind the single column primary key of
+// a table, nil when there is none.
+func ReadSequence(ctx context.Context, tx pgx.Tx, schema string, t *model.Table) (*Sequence, error) {
+	if len(t.PrimaryKey) != 1 {
+		return nil, nil
+	}
+
+	var name *string
+	err := tx.QueryRow(ctx, `SELECT pg_get_serial_sequence($1, $2)`, query.Quote(schema, t.Name), t.PrimaryKey[0]).Scan(&name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sequence: %w", err)
+	}
+	if name == nil {
+		return nil, nil
+	}
+
+	seq := &Sequence{Name: *name, Column: t.PrimaryKey[0]}
+	if err := tx.QueryRow(ctx, `SELECT pg_sequence_last_value($1::regclass)`, *name).Scan(&seq.LastValue); err != nil {
+		return nil, fmt.Errorf("failed to read sequence %s: %w", *name, err)
+	}
+	return seq, nil
+}
+
+// RestoreSequence sets a sequence to the value read by ReadSequence.
+func RestoreSequence(ctx context.Context, tx pgx.Tx, seq *Sequence) error {
+	if seq == nil {
+		return nil
+	}
+
+	var err error
+	if seq.LastValue == nil {
+		_, err = tx.Exec(ctx, `SELECT setval($1::regclass, 1, false)`, seq.Name)
+	} else {
+		_, err = tx.Exec(ctx, `SELECT setval($1::regclass, $2, true)`, seq.Name, *seq.LastValue)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore sequence %s: %w", seq.Name, err)
+	}
+	return nil
+}
+
+// DetachedColumns returns the columns of foreign keys to tables of other
+// schemas. Those tables are not copied along, so the columns are written as
+// NULL; a column that cannot be NULL is an error.
+func DetachedColumns(t *model.Table) (map[string]bool, error) {
+	detached := make(map[string]bool)
+	for _, fk := range t.ExternalForeignKeys() {
+		for _, name := range fk.Columns {
+			col, ok := t.Column(name)
+			if !ok {
+				continue
+			}
+			if !col.Nullable {
+				return nil, fmt.Errorf("column %s.%s references %s.%s outside the copied schema and cannot be NULL",
+					t.Name, name, fk.RefSchema, fk.RefTable)
+			}
+			detached[name] = true
+		}
+	}
+	return detached, nil
+}
@@ -0,0 +1,134 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultAPI = "https://api.telegram.org"
+
+// Error is a failure reported by the Bot API.
+type Error struct {
+	Code        int
+	Description string
+	// RetryAfter is set when the bot is rate limited.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.Code, e.Description)
+}
+
+// Temporary tells rate limiting and server failures from rejected requests.
+func (e *Error) Temporary() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= 500
+}
+
+// Client calls the Bot API, or anything serving it such as FakeServer.
+type Client struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+func NewClient(apiURL, token string) (*Client, error) {
+	if token == "" {
+		return nil, fmt.Errorf("telegram bot token is empty")
+	}
+	if apiURL == "" {
+		apiURL = DefaultAPI
+	}
+	// Long polling requests last up to their timeout, the client timeout
+	// comes from the request context instead.
+	return &Client{apiURL: strings.TrimRight(apiURL, "/"), token: token, client: &http.Client{}}, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/%s", c.apiURL, c.token, method), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		// The token is part of the url, keep it out of the logs.
+		return fmt.Errorf("telegram %s: %s", method, strings.ReplaceAll(err.Error(), c.token, "<token>"))
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter int `json:"retry_after"`
+		} `json:"parameters"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&reply); err != nil {
+		return &Error{Code: resp.StatusCode, Description: "invalid response: " + err.Error()}
+	}
+	if !reply.OK {
+		code := reply.ErrorCode
+		if code == 0 {
+			code = resp.StatusCode
+		}
+		return &Error{Code: code, Description: reply.Description, RetryAfter: time.Duration(reply.Parameters.RetryAfter) * time.Second}
+	}
+	if result != nil {
+		return json.Unmarshal(reply.Result, result)
+	}
+	return nil
+}
+
+// GetUpdates long polls for updates after offset, waiting up to timeout.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+10*time.Second)
+	defer cancel()
+	var updates []Update
+	err := c.call(ctx, "getUpdates", map[string]any{
+		"offset":          offset,
+		"timeout":         int(timeout.Seconds()),
+		"allowed_updates": []string{"message", "callback_query"},
+	}, &updates)
+	return updates, err
+}
+
+func (c *Client) SendMessage(ctx context.Context, msg SendMessage) (*Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	var sent Message
+	if err := c.call(ctx, "sendMessage", msg, &sent); err != nil {
+		return nil, err
+	}
+	return &sent, nil
+}
+
+// AnswerCallbackQuery stops the progress indicator of a pressed button.
+func (c *Client) AnswerCallbackQuery(ctx context.Context, id, text string) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	return c.call(ctx, "answerCallbackQuery", map[string]any{"callback_query_id": id, "text": text}, nil)
+}
+
+// EditMessageText replaces the text and the inline keyboard of a message.
+func (c *Client) EditMessageText(ctx context.Context, chatID, messageID int64, text string, markup *InlineKeyboardMarkup) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	params := map[string]any{"chat_id": chatID, "message_id": messageID, "text": text}
+	if markup != nil {
+		params["reply_markup"] = markup
+	}
+	return c.call(ctx, "editMessageText", params, nil)
+}
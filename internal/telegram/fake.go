@@ -0,0 +1,275 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeServer serves getUpdates, sendMessage, editMessageText and
+// answerCallbackQuery of the Bot API for one token and plays the users: their
+// messages are queued as updates and the messages of the bot are recorded per
+// chat. Chats are private, the chat id is the id of the user.
+type FakeServer struct {
+	Token string
+	// OnSend is called for every message the bot sends.
+	OnSend func(Message)
+	// OnEdit is called for every message the bot edits.
+	OnEdit func(Message)
+
+	mu          sync.Mutex
+	updates     []Update
+	nextUpdate  int64
+	nextMessage int64
+	sent        map[int64][]Message
+	failNext    int
+	// changed is closed and replaced whenever updates are queued or messages
+	// sent, to wake up waiting requests.
+	changed chan struct{}
+}
+
+func NewFakeServer(token string) *FakeServer {
+	return &FakeServer{
+		Token:      token,
+		nextUpdate: 1,
+		sent:       make(map[int64][]Message),
+		changed:    make(chan struct{}),
+	}
+}
+
+var fakeBot = User{ID: 1, IsBot: true, FirstName: "Fake bot"}
+
+// FailNext makes the next n sendMessage calls fail with 429.
+func (f *FakeServer) FailNext(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failNext = n
+}
+
+func (f *FakeServer) notifyLocked() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *FakeServer) queue(u Update) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u.UpdateID = f.nextUpdate
+	f.nextUpdate++
+	f.updates = append(f.updates, u)
+	f.notifyLocked()
+}
+
+func (f *FakeServer) newMessageLocked(from User, text string) *Message {
+	f.nextMessage++
+	return &Message{
+		MessageID: f.nextMessage,
+		From:      &from,
+		Chat:      Chat{ID: from.ID, Type: "private"},
+		Date:      time.Now().Unix(),
+		Text:      text,
+	}
+}
+
+// SendText sends a text message from the user to the bot.
+func (f *FakeServer) SendText(from User, text string) {
+	f.mu.Lock()
+	msg := f.newMessageLocked(from, text)
+	f.mu.Unlock()
+	f.queue(Update{Message: msg})
+}
+
+// SendContact shares a phone number as a contact, the own number of the user
+// unless ownerID says otherwise.
+func (f *FakeServer) SendContact(from User, phone string, ownerID int64) {
+	f.mu.Lock()
+	msg := f.newMessageLocked(from, "")
+	f.mu.Unlock()
+	msg.Contact = &Contact{PhoneNumber: phone, FirstName: from.FirstName, UserID: ownerID}
+	f.queue(Update{Message: msg})
+}
+
+// Press presses the inline button with the given callback data under a
+// message of the bot.
+func (f *FakeServer) Press(from User, msg Message, data string) {
+	f.mu.Lock()
+	id := strconv.FormatInt(f.nextUpdate, 10)
+	f.mu.Unlock()
+	f.queue(Update{CallbackQuery: &CallbackQuery{ID: id, From: from, Message: &msg, Data: data}})
+}
+
+// Sent returns the messages the bot sent to a chat.
+func (f *FakeServer) Sent(chatID int64) []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Message(nil), f.sent[chatID]...)
+}
+
+// WaitSent waits until the bot has sent at least n messages to a chat.
+func (f *FakeServer) WaitSent(ctx context.Context, chatID int64, n int) ([]Message, error) {
+	for {
+		f.mu.Lock()
+		sent, changed := f.sent[chatID], f.changed
+		f.mu.Unlock()
+		if len(sent) >= n {
+			return append([]Message(nil), sent...), nil
+		}
+		select {
+		case <-ctx.Done():
+			return append([]Message(nil), sent...), ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+func (f *FakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+f.Token+"/")
+	if !ok || r.Method != http.MethodPost {
+		fakeReply(w, http.StatusNotFound, nil, "Not Found")
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		fakeReply(w, http.StatusBadRequest, nil, "Bad Request: "+err.Error())
+		return
+	}
+
+	switch method {
+	case "getUpdates":
+		f.getUpdates(w, r, body)
+	case "sendMessage":
+		f.sendMessage(w, body)
+	case "editMessageText":
+		f.editMessageText(w, body)
+	case "answerCallbackQuery":
+		fakeReply(w, http.StatusOK, true, "")
+	default:
+		fakeReply(w, http.StatusNotFound, nil, "Not Found: method not found")
+	}
+}
+
+func (f *FakeServer) getUpdates(w http.ResponseWriter, r *http.Request, body []byte) {
+	var params struct {
+		Offset  int64 `json:"offset"`
+		Timeout int   `json:"timeout"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &params); err != nil {
+			fakeReply(w, http.StatusBadRequest, nil, "Bad Request: "+err.Error())
+			return
+		}
+	}
+
+	deadline := time.After(time.Duration(params.Timeout) * time.Second)
+	for {
+		f.mu.Lock()
+		// Requesting an offset confirms the updates before it.
+		for len(f.updates) > 0 && f.updates[0].UpdateID < params.Offset {
+			f.updates = f.updates[1:]
+		}
+		updates, changed := append([]Update{}, f.updates...), f.changed
+		f.mu.Unlock()
+
+		if len(updates) > 0 {
+			fakeReply(w, http.StatusOK, updates, "")
+			return
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			fakeReply(w, http.StatusOK, updates, "")
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (f *FakeServer) sendMessage(w http.ResponseWriter, body []byte) {
+	var params struct {
+		ChatID      int64           `json:"chat_id"`
+		Text        string          `json:"text"`
+		ReplyMarkup json.RawMessage `json:"reply_markup"`
+	}
+	if err := json.Unmarshal(body, &params); err != nil || params.ChatID == 0 || params.Text == "" {
+		fakeReply(w, http.StatusBadRequest, nil, "Bad Request: chat_id and text are required")
+		return
+	}
+	var markup InlineKeyboardMarkup
+	if len(params.ReplyMarkup) > 0 {
+		json.Unmarshal(params.ReplyMarkup, &markup)
+	}
+
+	f.mu.Lock()
+	if f.failNext > 0 {
+		f.failNext--
+		f.mu.Unlock()
+		fakeReply(w, http.StatusTooManyRequests, nil, "Too Many Requests: retry after 1")
+		return
+	}
+	msg := f.newMessageLocked(fakeBot, params.Text)
+	msg.Chat = Chat{ID: params.ChatID, Type: "private"}
+	if len(markup.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = &markup
+	}
+	f.sent[params.ChatID] = append(f.sent[params.ChatID], *msg)
+	f.notifyLocked()
+	onSend := f.OnSend
+	f.mu.Unlock()
+
+	if onSend != nil {
+		onSend(*msg)
+	}
+	fakeReply(w, http.StatusOK, msg, "")
+}
+
+func (f *FakeServer) editMessageText(w http.ResponseWriter, body []byte) {
+	var params struct {
+		ChatID      int64                 `json:"chat_id"`
+		MessageID   int64                 `json:"message_id"`
+		Text        string                `json:"text"`
+		ReplyMarkup *InlineKeyboardMarkup `json:"reply_markup"`
+	}
+	if err := json.Unmarshal(body, &params); err != nil || params.Text == "" {
+		fakeReply(w, http.StatusBadRequest, nil, "Bad Request: message_id and text are required")
+		return
+	}
+
+	f.mu.Lock()
+	for i, m := range f.sent[params.ChatID] {
+		if m.MessageID == params.MessageID {
+			m.Text, m.ReplyMarkup = params.Text, params.ReplyMarkup
+			f.sent[params.ChatID][i] = m
+			f.notifyLocked()
+			onEdit := f.OnEdit
+			f.mu.Unlock()
+
+			if onEdit != nil {
+				onEdit(m)
+			}
+			fakeReply(w, http.StatusOK, true, "")
+			return
+		}
+	}
+	f.mu.Unlock()
+	fakeReply(w, http.StatusBadRequest, nil, "Bad Request: message to edit not found")
+}
+
+func fakeReply(w http.ResponseWriter, status int, result any, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if status != http.StatusOK {
+		reply := map[string]any{"ok": false, "error_code": status, "description": description}
+		if status == http.StatusTooManyRequests {
+			reply["parameters"] = map[string]any{"retry_after": 1}
+		}
+		json.NewEncoder(w).Encode(reply)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+}
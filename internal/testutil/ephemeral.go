@@ -0,0 +1,170 @@
+package testutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/db"
+)
+
+// DefaultAssetsDir holds the migrations applied to every ephemeral database.
+const DefaultAssetsDir = "deployments/assets"
+
+// Server creates throwaway databases on a running Postgres. It connects as a
+// superuser to the database of the configuration, which is never modified.
+type Server struct {
+	cfg   *db.Config
+	admin *pgxpool.Pool
+}
+
+func NewServer(ctx context.Context, cfg *db.Config) (*Server, error) {
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		return nil, err
+	}
+	admin, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to maintenance database: %w", err)
+	}
+	return &Server{cfg: cfg, admin: admin}, nil
+}
+
+func (s *Server) Close() {
+	s.admin.Close()
+}
+
+// Database is a uniquely named database with the schema applied. Login roles
+// are cluster wide, so roles created for it must be registered with
+// TrackRole to be dropped together with the database.
+type Database struct {
+	Name   string
+	server *Server
+	// Pool connects as the superuser of the server configuration.
+	Pool *pgxpool.Pool
+
+	mu    sync.Mutex
+	pools []*pgxpool.Pool
+	roles []string
+}
+
+// CreateDatabase creates a database named <prefix>_<random suffix> and
+// applies every .sql file of assetsDir in name order.
+func (s *Server) CreateDatabase(ctx context.Context, prefix, assetsDir string) (*Database, error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	name := strings.ToLower(prefix) + "_" + hex.EncodeToString(suffix)
+
+	if _, err := s.admin.Exec(ctx, "CREATE DATABASE "+pgx.Identifier{name}.Sanitize()); err != nil {
+		return nil, fmt.Errorf("failed to create database %s: %w", name, err)
+	}
+
+	d := &Database{Name: name, server: s}
+	pool, err := d.connect(ctx, s.cfg)
+	if err != nil {
+		d.Drop(context.WithoutCancel(ctx))
+		return nil, err
+	}
+	d.Pool = pool
+
+	if err := ApplySchema(ctx, pool, assetsDir); err != nil {
+		d.Drop(context.WithoutCancel(ctx))
+		return nil, err
+	}
+	return d, nil
+}
+
+// ConnectAs opens a pool to the database logged in as the given user.
+func (d *Database) ConnectAs(ctx context.Context, user, password string) (*pgxpool.Pool, error) {
+	return d.connect(ctx, d.server.cfg.WithCredentials(user, password))
+}
+
+func (d *Database) connect(ctx context.Context, cfg *db.Config) (*pgxpool.Pool, error) {
+	poolCfg, err := cfg.PoolConfig()
+	if err != nil {
+		return nil, err
+	}
+	poolCfg.ConnConfig.Database = d.Name
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", d.Name, err)
+	}
+
+	d.mu.Lock()
+	d.pools = append(d.pools, pool)
+	d.mu.Unlock()
+	return pool, nil
+}
+
+// TrackRole registers a login role to drop on cleanup.
+func (d *Database) TrackRole(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.roles = append(d.roles, name)
+}
+
+// Drop closes every pool opened through the database, drops it and the
+// tracked roles.
+func (d *Database) Drop(ctx context.Context) error {
+	d.mu.Lock()
+	pools, roles := d.pools, d.roles
+	d.pools, d.roles = nil, nil
+	d.mu.Unlock()
+
+	for _, pool := range pools {
+		pool.Close()
+	}
+
+	var errs []error
+	if _, err := d.server.admin.Exec(ctx, "DROP DATABASE IF EXISTS "+pgx.Identifier{d.Name}.Sanitize()+" WITH (FORCE)"); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drop database %s: %w", d.Name, err))
+	}
+	for _, role := range roles {
+		if _, err := d.server.admin.Exec(ctx, "DROP ROLE IF EXISTS "+pgx.Identifier{role}.Sanitize()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drop role %s: %w", role, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pg_cron can only be installed into the database named by cron.database_name,
+// so its extension and jobs are left out of ephemeral databases.
+var cronStatements = regexp.MustCompile(`(?is)CREATE EXTENSION IF NOT EXISTS pg_cron;|SELECT cron\.schedule\((?:[^;']|'[^']*')*\);`)
+
+// ApplySchema runs the .sql files of dir in name order, without pg_cron.
+func ApplySchema(ctx context.Context, pool *pgxpool.Pool, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no migrations found in %s", dir)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		sql := cronStatements.ReplaceAllString(string(content), "")
+		if _, err := pool.Exec(ctx, sql); err != nil {
+			return fmt.Errorf("failed to apply %s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"vehicles-service-stations/internal/outbox"
+)
+
+// Day is the date fixture orders are booked on, far from the seeded data.
+var Day = time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC)
+
+// Base is the smallest data set orders can be created against: a center
+// with a manager, two masters and a customer. Employees are inserted
+// directly, without login roles.
+type Base struct {
+	Center   int
+	Manager  int
+	Masters  [2]int
+	Customer int
+}
+
+// NewBase inserts a Base in the transaction of the test.
+func NewBase(tb testing.TB, tx pgx.Tx) *Base {
+	tb.Helper()
+	ctx := context.Background()
+	b := &Base{}
+	err := tx.QueryRow(ctx, `
+        INSERT INTO service_centers (full_address, city, postal_code, phone_number)
+        VALUES ('Fixture street 1', 'Fixture City', '000000', '+70000000000')
+        RETURNING service_center_id
+    `).Scan(&b.Center)
+	if err != nil {
+		tb.Fatalf("failed to create center: %v", err)
+	}
+
+	employees := []struct {
+		username string
+		role     string
+		id       *int
+	}{
+		{"fixture_manager", "Manager", &b.Manager},
+		{"fixture_master_1", "Master", &b.Masters[0]},
+		{"fixture_master_2", "Master", &b.Masters[1]},
+	}
+	for _, e := range employees {
+		err := tx.QueryRow(ctx, `
+            INSERT INTO employees (full_name, experience, age, salary, username, password_hash)
+            VALUES ($1, 5, 30, 50000, $1, '!')
+            RETURNING employee_id
+        `, e.username).Scan(e.id)
+		if err != nil {
+			tb.Fatalf("failed to create %s: %v", e.username, err)
+		}
+		_, err = tx.Exec(ctx, `
+            INSERT INTO employee_service_center (employee_id, service_center_id, employee_role)
+            VALUES ($1, $2, $3)
+        `, *e.id, b.Center, e.role)
+		if err != nil {
+			tb.Fatalf("failed to assign %s: %v", e.username, err)
+		}
+	}
+
+	b.Customer = NewCustomer(tb, tx, 0, 0)
+	return b
+}
+
+func NewCustomer(tb testing.TB, tx pgx.Tx, spent, bonus float64) int {
+	tb.Helper()
+	var id int
+	err := tx.QueryRow(context.Background(), `
+        INSERT INTO customers (full_name, phone_number, spent_money, bonus_points)
+        VALUES ('Fixture Customer', '+71111111111', $1, $2)
+        RETURNING customer_id
+    `, spent, bonus).Scan(&id)
+	if err != nil {
+		tb.Fatalf("failed to create customer: %v", err)
+	}
+	return id
+}
+
+// InsertOrder books an order of the center, for the tests expecting the
+// triggers to refuse it.
+func (b *Base) InsertOrder(ctx context.Context, tx pgx.Tx, customer, master int, scheduled time.Time) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, `
+        INSERT INTO orders (customer_id, service_center_id, manager_id, assigned_master_id, creation_date, scheduled_date)
+        VALUES ($1, $2, $3, $4, $5, $5)
+        RETURNING order_id
+    `, customer, b.Center, b.Manager, master, scheduled).Scan(&id)
+	return id, err
+}
+
+func (b *Base) Order(tb testing.TB, tx pgx.Tx, customer, master int, scheduled time.Time) int {
+	tb.Helper()
+	id, err := b.InsertOrder(context.Background(), tx, customer, master, scheduled)
+	if err != nil {
+		tb.Fatalf("failed to create order: %v", err)
+	}
+	return id
+}
+
+func NewService(tb testing.TB, tx pgx.Tx, price float64) int {
+	tb.Helper()
+	var id int
+	err := tx.QueryRow(context.Background(), `
+        INSERT INTO services (full_name, vehicle_type, price)
+        VALUES ('Fixture service', 'Car', $1)
+        RETURNING service_id
+    `, price).Scan(&id)
+	if err != nil {
+		tb.Fatalf("failed to create service: %v", err)
+	}
+	return id
+}
+
+func NewPart(tb testing.TB, tx pgx.Tx, stock int) int {
+	tb.Helper()
+	var id int
+	err := tx.QueryRow(context.Background(), `
+        INSERT INTO spare_parts (name, article_number, price, stock_quantity)
+        VALUES ('Fixture part', 1, 100, $1)
+        RETURNING part_id
+    `, stock).Scan(&id)
+	if err != nil {
+		tb.Fatalf("failed to create spare part: %v", err)
+	}
+	return id
+}
+
+// LoginAs makes an employee a database role and switches the transaction to
+// it, session_user included: policies use both. The roles are named after
+// the transaction, roles are shared by the databases of a server; logging in
+// again as the same employee reuses the role.
+func LoginAs(tb testing.TB, tx pgx.Tx, employeeID int, role string) {
+	tb.Helper()
+	ctx := context.Background()
+	if _, err := tx.Exec(ctx, `RESET SESSION AUTHORIZATION`); err != nil {
+		tb.Fatal(err)
+	}
+	var name string
+	err := tx.QueryRow(ctx, `
+        UPDATE employees SET username = 'fixture_' || txid_current() || '_' || employee_id
+        WHERE employee_id = $1
+        RETURNING username
+    `, employeeID).Scan(&name)
+	if err != nil {
+		tb.Fatalf("failed to rename employee %d: %v", employeeID, err)
+	}
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)`, name).Scan(&exists); err != nil {
+		tb.Fatal(err)
+	}
+	ident := pgx.Identifier{name}.Sanitize()
+	statements := []string{
+		`GRANT ` + pgx.Identifier{role}.Sanitize() + ` TO ` + ident,
+		`SET LOCAL SESSION AUTHORIZATION ` + ident,
+	}
+	if !exists {
+		statements = append([]string{`CREATE ROLE ` + ident}, statements...)
+	}
+	for _, sql := range statements {
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			tb.Fatalf("failed to log in as %s: %v", name, err)
+		}
+	}
+}
+
+// ExpectCode checks that err is a server error with the given SQLSTATE.
+func ExpectCode(tb testing.TB, err error, code string) {
+	tb.Helper()
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		tb.Errorf("expected error %s, statement succeeded", code)
+	case !errors.As(err, &pgErr):
+		tb.Errorf("expected error %s, got %v", code, err)
+	case pgErr.Code != code:
+		tb.Errorf("expected error %s, got %s: %s", code, pgErr.Code, pgErr.Message)
+	}
+}
+
+// Savepoint runs fn in a savepoint so an expected error does not abort the
+// transaction of the test.
+func Savepoint(ctx context.Context, tx pgx.Tx, fn func(tx pgx.Tx) error) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer sp.Rollback(ctx)
+	if err := fn(sp); err != nil {
+		return err
+	}
+	return sp.Commit(ctx)
+}
+
+// OrderOutbox returns the outbox events of an order in publication order.
+func OrderOutbox(tb testing.TB, tx pgx.Tx, orderID int) []*outbox.Message {
+	tb.Helper()
+	rows, err := tx.Query(context.Background(), `
+        SELECT event_id, aggregate_type, aggregate_id, event_type, version, payload, created_at, attempts
+        FROM outbox.events
+        WHERE aggregate_type = 'order' AND aggregate_id = $1::text
+        ORDER BY event_id
+    `, orderID)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	messages, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*outbox.Message, error) {
+		m := &outbox.Message{}
+		err := row.Scan(&m.ID, &m.AggregateType, &m.AggregateID, &m.Type, &m.Version, &m.Payload, &m.CreatedAt, &m.Attempts)
+		return m, err
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return messages
+}
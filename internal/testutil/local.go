@@ -0,0 +1,136 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"vehicles-service-stations/internal/db"
+)
+
+// LocalPostgres is a private Postgres cluster started from the binaries of an
+// installation, for machines without a running server. It lives in a
+// temporary directory, trusts local connections and is removed on Stop.
+type LocalPostgres struct {
+	dir  string
+	cmd  *exec.Cmd
+	done chan error
+	Port int
+}
+
+// StartLocal runs initdb and postgres from binDir. pgcrypto must be available
+// in that installation for the schema to apply.
+func StartLocal(ctx context.Context, binDir string) (*LocalPostgres, error) {
+	dir, err := os.MkdirTemp("", "vss-postgres-")
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	initdb := exec.CommandContext(ctx, filepath.Join(binDir, "initdb"), "-D", filepath.Join(dir, "data"), "-U", "postgres", "-A", "trust", "--no-sync")
+	initdb.Stdout, initdb.Stderr = &out, &out
+	if err := initdb.Run(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb failed: %w: %s", err, out.String())
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	lp := &LocalPostgres{dir: dir, Port: port, done: make(chan error, 1)}
+	lp.cmd = exec.Command(filepath.Join(binDir, "postgres"),
+		"-D", filepath.Join(dir, "data"),
+		"-p", fmt.Sprint(port),
+		"-k", dir,
+		"-c", "listen_addresses=127.0.0.1",
+		"-c", "fsync=off",
+		"-c", "synchronous_commit=off",
+		"-c", "full_page_writes=off",
+	)
+	logPath := filepath.Join(dir, "postgres.log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	defer logFile.Close()
+	lp.cmd.Stdout, lp.cmd.Stderr = logFile, logFile
+	if err := lp.cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start postgres: %w", err)
+	}
+	go func() { lp.done <- lp.cmd.Wait() }()
+
+	if err := lp.waitReady(ctx); err != nil {
+		log, _ := os.ReadFile(logPath)
+		lp.Stop()
+		return nil, fmt.Errorf("%w: %s", err, log)
+	}
+	return lp, nil
+}
+
+// Config returns a superuser configuration of the cluster.
+func (lp *LocalPostgres) Config() *db.Config {
+	return &db.Config{
+		Addr:    "127.0.0.1",
+		Port:    lp.Port,
+		User:    "postgres",
+		DBName:  "postgres",
+		SSLMode: "disable",
+	}
+}
+
+func (lp *LocalPostgres) waitReady(ctx context.Context) error {
+	connStr := lp.Config().ConnectionString()
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-lp.done:
+			return fmt.Errorf("postgres exited: %v", err)
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		conn, err := pgx.Connect(ctx, connStr)
+		if err == nil {
+			conn.Close(ctx)
+			return nil
+		}
+	}
+	return fmt.Errorf("postgres did not accept connections in time")
+}
+
+// Stop shuts the cluster down and removes its files.
+func (lp *LocalPostgres) Stop() error {
+	if lp.cmd.Process != nil {
+		// SIGINT is the fast shutdown of postgres.
+		lp.cmd.Process.Signal(os.Interrupt)
+		select {
+		case <-lp.done:
+		case <-time.After(10 * time.Second):
+			lp.cmd.Process.Kill()
+			<-lp.done
+		}
+	}
+	return os.RemoveAll(lp.dir)
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,82 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+
+	"vehicles-service-stations/internal/gomock"
+	"vehicles-service-stations/internal/model"
+	"vehicles-service-stations/internal/utils"
+)
+
+type SeedOptions struct {
+	// Seed fixes the random source of the generators, 0 picks a random one.
+	Seed           uint64
+	Cities         int
+	ServiceCenters int
+	Customers      int
+	Employees      int
+	Services       int
+	SpareParts     int
+	OrderTries     int
+}
+
+func DefaultSeedOptions() SeedOptions {
+	return SeedOptions{
+		Seed:           42,
+		Cities:         3,
+		ServiceCenters: 5,
+		Customers:      30,
+		Employees:      25,
+		Services:       10,
+		SpareParts:     10,
+		OrderTries:     40,
+	}
+}
+
+// Seed fills the database through the gomock generators, one after another so
+// that a fixed seed reproduces the data. The generated employees are returned
+// and their login roles tracked for cleanup.
+func (d *Database) Seed(ctx context.Context, opts SeedOptions) ([]*model.User, error) {
+	utils.Seed(opts.Seed)
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"service centers", func() error {
+			return gomock.CreateServiceCenters(ctx, d.Pool, opts.Cities, opts.ServiceCenters)
+		}},
+		{"stockpile", func() error { return gomock.CreateStockpile(ctx, d.Pool) }},
+		{"customers", func() error { return gomock.CreateCustomers(ctx, d.Pool, opts.Customers, 150000.0) }},
+		{"services", func() error {
+			return gomock.CreateServices(ctx, d.Pool, opts.Services, &gomock.Pair[float64]{First: 2000.0, Second: 50000.0})
+		}},
+		{"spare parts", func() error {
+			return gomock.CreateSpareParts(ctx, d.Pool, opts.SpareParts, &gomock.Pair[float64]{First: 500.0, Second: 50000.0}, &gomock.Pair[int]{First: 50, Second: 100})
+		}},
+	}
+	for _, step := range steps {
+		if err := step.run(); err != nil {
+			return nil, fmt.Errorf("failed to seed %s: %w", step.name, err)
+		}
+	}
+
+	var users []*model.User
+	err := gomock.CreateEmployees(ctx, d.Pool, opts.Employees, &users, nil, gomock.WithUsernamePrefix(d.Name+"_"))
+	for _, u := range users {
+		d.TrackRole(u.Login)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed employees: %w", err)
+	}
+
+	if err := gomock.CreateOrders(ctx, d.Pool, opts.OrderTries, &gomock.Pair[float64]{First: 500.0, Second: 50000.0}, 2, 2, nil); err != nil {
+		return nil, fmt.Errorf("failed to seed orders: %w", err)
+	}
+	if err := gomock.CreateReceipts(ctx, d.Pool); err != nil {
+		return nil, fmt.Errorf("failed to seed receipts: %w", err)
+	}
+
+	return users, nil
+}
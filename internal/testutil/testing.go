@@ -0,0 +1,113 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/db"
+	"vehicles-service-stations/internal/model"
+)
+
+// DSNEnv names the variable with the superuser connection string of the
+// Postgres server tests create their databases on. Without it, tests start a
+// private cluster from the binaries in PostgresBinEnv, and are skipped when
+// neither is set.
+const (
+	DSNEnv         = "TEST_DATABASE_DSN"
+	PostgresBinEnv = "TEST_POSTGRES_BIN"
+)
+
+// NewTestDatabase creates a throwaway database with the schema applied and
+// drops it when the test ends.
+func NewTestDatabase(tb testing.TB, prefix string) *Database {
+	tb.Helper()
+	cfg := serverConfig(tb)
+	assets, err := AssetsDir()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	ctx := context.Background()
+	server, err := NewServer(ctx, cfg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(server.Close)
+
+	d, err := server.CreateDatabase(ctx, prefix, assets)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		if err := d.Drop(context.Background()); err != nil {
+			tb.Error(err)
+		}
+	})
+	return d
+}
+
+func serverConfig(tb testing.TB) *db.Config {
+	tb.Helper()
+	if dsn := os.Getenv(DSNEnv); dsn != "" {
+		return &db.Config{DSN: dsn}
+	}
+	binDir := os.Getenv(PostgresBinEnv)
+	if binDir == "" {
+		tb.Skipf("neither %s nor %s is set", DSNEnv, PostgresBinEnv)
+	}
+	local, err := StartLocal(context.Background(), binDir)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { local.Stop() })
+	return local.Config()
+}
+
+// NewSeededDatabase is NewTestDatabase filled by Seed with the default
+// options, it returns the generated employees.
+func NewSeededDatabase(tb testing.TB, prefix string) (*Database, []*model.User) {
+	tb.Helper()
+	d := NewTestDatabase(tb, prefix)
+	users, err := d.Seed(context.Background(), DefaultSeedOptions())
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return d, users
+}
+
+// Tx begins a transaction that is rolled back when the test ends.
+func Tx(tb testing.TB, pool *pgxpool.Pool) pgx.Tx {
+	tb.Helper()
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { tx.Rollback(ctx) })
+	return tx
+}
+
+// AssetsDir finds DefaultAssetsDir from the module root above the working
+// directory, which is the package directory under go test.
+func AssetsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return filepath.Join(dir, DefaultAssetsDir), nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("go.mod not found above the working directory")
+		}
+		dir = parent
+	}
+}
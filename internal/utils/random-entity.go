@@ -3,24 +3,29 @@ package utils
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	sq "github.com/Masterminds/squirrel"
+
+	"vehicles-service-stations/internal/query"
 )
 
 type Join struct {
-	Type      string
-	Table     string
+	Type  string
+	Table string
+	// Condition is optional when a catalog is set, see query.Join.
 	Condition string
 }
 
 type Option func(*randomIDOptions) error
 
 type randomIDOptions struct {
+	catalog     query.Catalog
 	joins       []Join
+	filters     []query.Filter
 	whereClause sq.Sqlizer
+	strategy    Strategy
 }
 
 func WithJoins(joins []Join) Option {
@@ -30,6 +35,8 @@ func WithJoins(joins []Join) Option {
 	}
 }
 
+// WithWhereClause adds a trusted predicate that is not validated, use
+// WithFilters for anything that comes from user input.
 func WithWhereClause(where sq.Sqlizer) Option {
 	return func(opts *randomIDOptions) error {
 		opts.whereClause = where
@@ -37,51 +44,57 @@ func WithWhereClause(where sq.Sqlizer) Option {
 	}
 }
 
-func RandomIDWithBuilder(ctx context.Context, db *pgxpool.Pool, table, column string, opts ...Option) (int, error) {
-
-	options := &randomIDOptions{
-		joins:       nil,
-		whereClause: nil,
+func WithFilters(filters ...query.Filter) Option {
+	return func(opts *randomIDOptions) error {
+		opts.filters = append(opts.filters, filters...)
+		return nil
 	}
+}
 
-	for _, opt := range opts {
-		if err := opt(options); err != nil {
-			return 0, fmt.Errorf("option build error: %w", err)
-		}
-	}
-	qualifiedColumn := fmt.Sprintf("%s.%s", table, column)
-	queryBuilder := sq.Select(qualifiedColumn).From(table).PlaceholderFormat(sq.Dollar)
-
-	for _, join := range options.joins {
-		joinType := strings.ToUpper(join.Type)
-		switch joinType {
-		case "INNER":
-			queryBuilder = queryBuilder.InnerJoin(fmt.Sprintf("%s ON %s", join.Table, join.Condition))
-		case "LEFT":
-			queryBuilder = queryBuilder.LeftJoin(fmt.Sprintf("%s ON %s", join.Table, join.Condition))
-		case "RIGHT":
-			queryBuilder = queryBuilder.RightJoin(fmt.Sprintf("%s ON %s", join.Table, join.Condition))
-		default:
-			return 0, fmt.Errorf("неподдерживаемый тип JOIN: %s", join.Type)
+// WithCatalog validates tables, columns, joins and filters against the
+// introspected schema, usually a *model.AllowedTables.
+func WithCatalog(catalog query.Catalog) Option {
+	return func(opts *randomIDOptions) error {
+		if catalog == nil {
+			return fmt.Errorf("catalog is nil")
 		}
+		opts.catalog = catalog
+		return nil
 	}
+}
 
-	if options.whereClause != nil {
-		queryBuilder = queryBuilder.Where(options.whereClause)
+func RandomIDWithBuilder(ctx context.Context, db *pgxpool.Pool, table, column string, opts ...Option) (int, error) {
+	ids, err := RandomIDs(ctx, db, table, column, 1, opts...)
+	if err != nil {
+		return 0, err
 	}
 
-	queryBuilder = queryBuilder.OrderBy("RANDOM()").Limit(1)
+	return ids[0], nil
+}
 
-	query, args, err := queryBuilder.ToSql()
-	if err != nil {
-		return 0, fmt.Errorf("query build error: %w", err)
+func (o *randomIDOptions) selectBuilder(table, column string) (sq.SelectBuilder, error) {
+	builder := query.Select(o.catalog, table, table+"."+column)
+
+	for _, join := range o.joins {
+		joinType, err := query.ParseJoinType(join.Type)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		on, err := query.ParseCondition(join.Condition)
+		if err != nil {
+			return sq.SelectBuilder{}, err
+		}
+		builder = builder.Join(query.Join{Type: joinType, Table: join.Table, On: on})
 	}
 
-	var id int
-	err = db.QueryRow(ctx, query, args...).Scan(&id)
+	queryBuilder, err := builder.Where(o.filters...).Build()
 	if err != nil {
-		return 0, fmt.Errorf("cant find random %s: %v", column, err)
+		return sq.SelectBuilder{}, err
+	}
+
+	if o.whereClause != nil {
+		queryBuilder = queryBuilder.Where(o.whereClause)
 	}
 
-	return id, nil
+	return queryBuilder, nil
 }
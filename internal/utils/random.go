@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"math/rand/v2"
+	"sync"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+var source = &lockedSource{src: rand.NewPCG(rand.Uint64(), rand.Uint64())}
+
+// Rand is the random source of the mock generators and samplers, safe for
+// concurrent use.
+var Rand = rand.New(source)
+
+// Seed makes Rand and gofakeit's global faker reproducible. Generated data
+// only repeats when generators run sequentially; rows picked with
+// ORDER BY random() on the database side are not covered.
+func Seed(seed uint64) {
+	source.mu.Lock()
+	source.src = rand.NewPCG(seed, seed)
+	source.mu.Unlock()
+
+	if seed == 0 {
+		// gofakeit treats zero as a request for a random seed.
+		seed = 1
+	}
+	gofakeit.GlobalFaker = gofakeit.New(seed)
+}
@@ -0,0 +1,430 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"vehicles-service-stations/internal/query"
+)
+
+// SampleSource is the validated query a strategy samples ids from.
+type SampleSource struct {
+	Table  string
+	Column string
+
+	builder sq.SelectBuilder
+	key     string
+}
+
+func newSampleSource(table, column string, builder sq.SelectBuilder) (*SampleSource, error) {
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return &SampleSource{
+		Table:   table,
+		Column:  column,
+		builder: builder,
+		key:     fmt.Sprintf("%s %v", sql, args),
+	}, nil
+}
+
+// Select returns the base query selecting the id column with all joins and
+// filters applied.
+func (s *SampleSource) Select() sq.SelectBuilder {
+	return s.builder
+}
+
+func (s *SampleSource) QualifiedColumn() string {
+	return query.Quote(s.Table, s.Column)
+}
+
+// Key identifies the source for caching, sources with equal keys select the
+// same set of rows.
+func (s *SampleSource) Key() string {
+	return s.key
+}
+
+type Strategy interface {
+	Name() string
+	// Sample returns up to k distinct ids, fewer only when the source has
+	// fewer rows.
+	Sample(ctx context.Context, db *pgxpool.Pool, src *SampleSource, k int) ([]int, error)
+}
+
+func WithStrategy(strategy Strategy) Option {
+	return func(opts *randomIDOptions) error {
+		if strategy == nil {
+			return fmt.Errorf("strategy is nil")
+		}
+		opts.strategy = strategy
+		return nil
+	}
+}
+
+func RandomIDs(ctx context.Context, db *pgxpool.Pool, table, column string, k int, opts ...Option) ([]int, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("sample size must be positive, got %d", k)
+	}
+
+	options := &randomIDOptions{}
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, fmt.Errorf("option build error: %w", err)
+		}
+	}
+
+	builder, err := options.selectBuilder(table, column)
+	if err != nil {
+		return nil, fmt.Errorf("query build error: %w", err)
+	}
+
+	src, err := newSampleSource(table, column, builder)
+	if err != nil {
+		return nil, fmt.Errorf("query build error: %w", err)
+	}
+
+	strategy := options.strategy
+	if strategy == nil {
+		strategy = RandomOrder{}
+	}
+
+	ids, err := strategy.Sample(ctx, db, src, k)
+	if err != nil {
+		return nil, fmt.Errorf("%s sampling of %s failed: %w", strategy.Name(), column, err)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("cant find random %s: no rows", column)
+	}
+
+	return ids, nil
+}
+
+// RandomOrder sorts the whole result by RANDOM(), exact but a full scan.
+type RandomOrder struct{}
+
+func (RandomOrder) Name() string { return "random-order" }
+
+func (RandomOrder) Sample(ctx context.Context, db *pgxpool.Pool, src *SampleSource, k int) ([]int, error) {
+	return queryIDs(ctx, db, src.Select().OrderBy("RANDOM()").Limit(uint64(k)))
+}
+
+type TableSampleMethod string
+
+const (
+	TableSampleSystem    TableSampleMethod = "SYSTEM"
+	TableSampleBernoulli TableSampleMethod = "BERNOULLI"
+)
+
+// TableSample reads only a fraction of the table's pages (SYSTEM) or rows
+// (BERNOULLI). With Percent left at zero the fraction is estimated from
+// pg_class.reltuples so that roughly MinRows rows are read. When the sample
+// turns out too small, e.g. because filters discard most of it, it falls back
+// to RandomOrder.
+type TableSample struct {
+	Method  TableSampleMethod
+	Percent float64
+	MinRows int
+}
+
+func NewTableSample(method TableSampleMethod, percent float64) *TableSample {
+	return &TableSample{Method: method, Percent: percent, MinRows: 200}
+}
+
+func (t *TableSample) Name() string { return "tablesample-" + string(t.Method) }
+
+func (t *TableSample) Sample(ctx context.Context, db *pgxpool.Pool, src *SampleSource, k int) ([]int, error) {
+	percent := t.Percent
+	if percent <= 0 {
+		var err error
+		percent, err = t.estimatePercent(ctx, db, src.Table, k)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if percent >= 100 {
+		return RandomOrder{}.Sample(ctx, db, src, k)
+	}
+
+	from := fmt.Sprintf("%s TABLESAMPLE %s (%s)", query.Quote(src.Table), t.Method, strconv.FormatFloat(percent, 'f', -1, 64))
+	ids, err := queryIDs(ctx, db, src.Select().From(from).OrderBy("RANDOM()").Limit(uint64(k)))
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) < k {
+		return RandomOrder{}.Sample(ctx, db, src, k)
+	}
+
+	return ids, nil
+}
+
+func (t *TableSample) estimatePercent(ctx context.Context, db *pgxpool.Pool, table string, k int) (float64, error) {
+	var reltuples float64
+	err := db.QueryRow(ctx, `SELECT reltuples FROM pg_class WHERE oid = to_regclass($1)`, query.Quote(table)).Scan(&reltuples)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate size of %s: %w", table, err)
+	}
+	if reltuples <= 0 {
+		return 100, nil
+	}
+
+	target := float64(max(k*20, t.MinRows))
+	return min(100, target/reltuples*100), nil
+}
+
+// RandomOffset counts the rows once per TTL and fetches rows at random
+// offsets, all offsets are sent in one batch.
+type RandomOffset struct {
+	counts *ttlCache[int]
+}
+
+func NewRandomOffset(ttl time.Duration) *RandomOffset {
+	return &RandomOffset{counts: newTTLCache[int](ttl)}
+}
+
+func (r *RandomOffset) Name() string { return "random-offset" }
+
+func (r *RandomOffset) Invalidate() { r.counts.clear() }
+
+func (r *RandomOffset) Sample(ctx context.Context, db *pgxpool.Pool, src *SampleSource, k int) ([]int, error) {
+	count, err := r.counts.get(src.Key(), func() (int, error) {
+		countSQL, args, err := sq.Select("COUNT(*)").FromSelect(src.Select(), "src").PlaceholderFormat(sq.Dollar).ToSql()
+		if err != nil {
+			return 0, err
+		}
+		var count int
+		err = db.QueryRow(ctx, countSQL, args...).Scan(&count)
+		return count, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	if k >= count {
+		return RandomOrder{}.Sample(ctx, db, src, k)
+	}
+
+	var probes []sq.SelectBuilder
+	for _, offset := range distinctInts(count, k) {
+		probes = append(probes, src.Select().OrderBy(src.QualifiedColumn()).Offset(uint64(offset)).Limit(1))
+	}
+
+	return batchIDs(ctx, db, probes)
+}
+
+// IDRange probes random values between MIN and MAX of the id column and takes
+// the next existing id. It is cheap on indexed SERIAL keys but favours ids
+// following gaps, so it suits dense keys only.
+type IDRange struct {
+	bounds *ttlCache[[2]int]
+	Rounds int
+}
+
+func NewIDRange(ttl time.Duration) *IDRange {
+	return &IDRange{bounds: newTTLCache[[2]int](ttl), Rounds: 3}
+}
+
+func (r *IDRange) Name() string { return "id-range" }
+
+func (r *IDRange) Invalidate() { r.bounds.clear() }
+
+func (r *IDRange) Sample(ctx context.Context, db *pgxpool.Pool, src *SampleSource, k int) ([]int, error) {
+	bounds, err := r.bounds.get(src.Key(), func() ([2]int, error) {
+		column := src.QualifiedColumn()
+		boundsSQL, args, err := src.Select().
+			RemoveColumns().Columns("COALESCE(MIN("+column+"), 0)", "COALESCE(MAX("+column+"), -1)").ToSql()
+		if err != nil {
+			return [2]int{}, err
+		}
+		var b [2]int
+		err = db.QueryRow(ctx, boundsSQL, args...).Scan(&b[0], &b[1])
+		return b, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read id bounds: %w", err)
+	}
+	if bounds[1] < bounds[0] {
+		return nil, nil
+	}
+
+	seen := make(map[int]struct{}, k)
+	ids := make([]int, 0, k)
+	for round := 0; round < r.Rounds && len(ids) < k; round++ {
+		var probes []sq.SelectBuilder
+		for i := len(ids); i < k; i++ {
+			start := bounds[0] + Rand.IntN(bounds[1]-bounds[0]+1)
+			probes = append(probes, src.Select().
+				Where(sq.GtOrEq{src.QualifiedColumn(): start}).
+				OrderBy(src.QualifiedColumn()).Limit(1))
+		}
+
+		found, err := batchIDs(ctx, db, probes)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range found {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	if len(ids) < k {
+		return RandomOrder{}.Sample(ctx, db, src, k)
+	}
+
+	return ids, nil
+}
+
+// IDCache loads every id of the source once per TTL and samples client side.
+// Suitable for the small reference tables the generators draw from.
+type IDCache struct {
+	ids *ttlCache[[]int]
+}
+
+func NewIDCache(ttl time.Duration) *IDCache {
+	return &IDCache{ids: newTTLCache[[]int](ttl)}
+}
+
+func (c *IDCache) Name() string { return "id-cache" }
+
+func (c *IDCache) Invalidate() { c.ids.clear() }
+
+func (c *IDCache) Sample(ctx context.Context, db *pgxpool.Pool, src *SampleSource, k int) ([]int, error) {
+	ids, err := c.ids.get(src.Key(), func() ([]int, error) {
+		return queryIDs(ctx, db, src.Select())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to preload ids: %w", err)
+	}
+
+	picked := make([]int, 0, min(k, len(ids)))
+	for _, idx := range distinctInts(len(ids), k) {
+		picked = append(picked, ids[idx])
+	}
+	return picked, nil
+}
+
+// distinctInts returns min(k, n) distinct random ints from [0, n).
+func distinctInts(n, k int) []int {
+	if k >= n {
+		return Rand.Perm(n)
+	}
+
+	picked := make(map[int]struct{}, k)
+	result := make([]int, 0, k)
+	for len(result) < k {
+		v := Rand.IntN(n)
+		if _, ok := picked[v]; ok {
+			continue
+		}
+		picked[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
+
+func queryIDs(ctx context.Context, db *pgxpool.Pool, builder sq.SelectBuilder) ([]int, error) {
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("query build error: %w", err)
+	}
+
+	rows, err := db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// batchIDs runs single-row probes in one round-trip and returns the distinct
+// ids found, probes that hit no row are skipped.
+func batchIDs(ctx context.Context, db *pgxpool.Pool, probes []sq.SelectBuilder) ([]int, error) {
+	batch := &pgx.Batch{}
+	for _, probe := range probes {
+		sql, args, err := probe.ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("query build error: %w", err)
+		}
+		batch.Queue(sql, args...)
+	}
+
+	results := db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	seen := make(map[int]struct{}, len(probes))
+	ids := make([]int, 0, len(probes))
+	for range probes {
+		var id int
+		err := results.QueryRow().Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+type ttlEntry[T any] struct {
+	value    T
+	loadedAt time.Time
+}
+
+type ttlCache[T any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]ttlEntry[T]
+}
+
+func newTTLCache[T any](ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{ttl: ttl, entries: make(map[string]ttlEntry[T])}
+}
+
+// get returns the cached value for key, loading it when absent or older than
+// the TTL. A zero TTL caches forever. The value is loaded without holding the
+// lock, so concurrent callers may load it more than once.
+func (c *ttlCache[T]) get(key string, load func() (T, error)) (T, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && (c.ttl == 0 || time.Since(entry.loadedAt) < c.ttl) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = ttlEntry[T]{value: value, loadedAt: time.Now()}
+	return value, nil
+}
+
+func (c *ttlCache[T]) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]ttlEntry[T])
+}
@@ -0,0 +1,36 @@
+package utils_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"vehicles-service-stations/internal/testutil"
+	"vehicles-service-stations/internal/utils"
+)
+
+func BenchmarkRandomIDs(b *testing.B) {
+	d, _ := testutil.NewSeededDatabase(b, "sampling")
+	ctx := context.Background()
+
+	strategies := []utils.Strategy{
+		utils.RandomOrder{},
+		utils.NewTableSample(utils.TableSampleSystem, 0),
+		utils.NewTableSample(utils.TableSampleBernoulli, 0),
+		utils.NewRandomOffset(time.Minute),
+		utils.NewIDRange(time.Minute),
+		utils.NewIDCache(time.Minute),
+	}
+	for _, strategy := range strategies {
+		for _, k := range []int{1, 10} {
+			b.Run(fmt.Sprintf("%s/k=%d", strategy.Name(), k), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, err := utils.RandomIDs(ctx, d.Pool, "customers", "customer_id", k, utils.WithStrategy(strategy)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
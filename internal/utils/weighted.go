@@ -0,0 +1,293 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"gopkg.in/yaml.v3"
+)
+
+type Weighted[T any] struct {
+	items      []T
+	weights    []float64
+	cumulative []float64
+}
+
+func NewWeighted[T any](items []T, weights []float64) (*Weighted[T], error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no items to pick from")
+	}
+	if len(items) != len(weights) {
+		return nil, fmt.Errorf("got %d weights for %d items", len(weights), len(items))
+	}
+
+	cumulative := make([]float64, len(weights))
+	var total float64
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("invalid weight %v for item %d", w, i)
+		}
+		total += w
+		cumulative[i] = total
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("all weights are zero")
+	}
+
+	return &Weighted[T]{items: items, weights: weights, cumulative: cumulative}, nil
+}
+
+func (w *Weighted[T]) Len() int {
+	return len(w.items)
+}
+
+func (w *Weighted[T]) Pick() T {
+	r := Rand.Float64() * w.cumulative[len(w.cumulative)-1]
+	idx := sort.Search(len(w.cumulative), func(i int) bool { return w.cumulative[i] > r })
+	return w.items[min(idx, len(w.items)-1)]
+}
+
+// PickN returns min(k, Len()) distinct items drawn without replacement
+// (Efraimidis-Spirakis), items with zero weight are never returned.
+func (w *Weighted[T]) PickN(k int) []T {
+	type keyed struct {
+		key float64
+		idx int
+	}
+	keys := make([]keyed, 0, len(w.items))
+	for i, weight := range w.weights {
+		if weight == 0 {
+			continue
+		}
+		keys = append(keys, keyed{key: math.Pow(Rand.Float64(), 1/weight), idx: i})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].key > keys[j].key })
+
+	picked := make([]T, 0, min(k, len(keys)))
+	for _, k := range keys[:min(k, len(keys))] {
+		picked = append(picked, w.items[k.idx])
+	}
+	return picked
+}
+
+const (
+	DistributionUniform = "uniform"
+	DistributionZipf    = "zipf"
+	DistributionPareto  = "pareto"
+	DistributionWeights = "weights"
+)
+
+type DistributionSpec struct {
+	Kind string `yaml:"kind"`
+	// S is the Zipf exponent, weight of rank r is 1/r^S.
+	S float64 `yaml:"s"`
+	// Alpha is the Pareto shape, 1.16 gives the 80/20 rule.
+	Alpha float64 `yaml:"alpha"`
+	// Weights are explicit weights by position, missing ones default to 1.
+	Weights []float64 `yaml:"weights"`
+}
+
+func (d DistributionSpec) Validate() error {
+	switch d.Kind {
+	case "", DistributionUniform, DistributionZipf, DistributionPareto, DistributionWeights:
+	default:
+		return fmt.Errorf("unknown distribution %q", d.Kind)
+	}
+	if d.S < 0 || d.Alpha < 0 {
+		return fmt.Errorf("distribution parameters must not be negative")
+	}
+	return nil
+}
+
+// Generate returns n weights following the distribution. Zipf weights are
+// ordered by rank, callers shuffle items when the ranking should be random.
+func (d DistributionSpec) Generate(n int) []float64 {
+	weights := make([]float64, n)
+	for i := range weights {
+		switch d.Kind {
+		case DistributionZipf:
+			s := d.S
+			if s == 0 {
+				s = 1.1
+			}
+			weights[i] = 1 / math.Pow(float64(i+1), s)
+		case DistributionPareto:
+			alpha := d.Alpha
+			if alpha == 0 {
+				alpha = 1.16
+			}
+			weights[i] = math.Pow(1-Rand.Float64(), -1/alpha)
+		case DistributionWeights:
+			weights[i] = 1
+			if i < len(d.Weights) {
+				weights[i] = d.Weights[i]
+			}
+		default:
+			weights[i] = 1
+		}
+	}
+	return weights
+}
+
+type Seasonality struct {
+	// Monthly holds 12 weights, January first.
+	Monthly []float64 `yaml:"monthly"`
+	// Weekday holds 7 weights, Sunday first as in time.Weekday.
+	Weekday []float64 `yaml:"weekday"`
+}
+
+func (s Seasonality) Validate() error {
+	if len(s.Monthly) != 0 && len(s.Monthly) != 12 {
+		return fmt.Errorf("monthly seasonality needs 12 weights, got %d", len(s.Monthly))
+	}
+	if len(s.Weekday) != 0 && len(s.Weekday) != 7 {
+		return fmt.Errorf("weekday seasonality needs 7 weights, got %d", len(s.Weekday))
+	}
+	return nil
+}
+
+func (s Seasonality) Weight(t time.Time) float64 {
+	w := 1.0
+	if len(s.Monthly) == 12 {
+		w *= s.Monthly[t.Month()-1]
+	}
+	if len(s.Weekday) == 7 {
+		w *= s.Weekday[t.Weekday()]
+	}
+	return w
+}
+
+// NewDateSampler picks days in [from, to] weighted by seasonality.
+func NewDateSampler(from, to time.Time, s Seasonality) (*Weighted[time.Time], error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	var days []time.Time
+	var weights []float64
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+		weights = append(weights, s.Weight(d))
+	}
+	return NewWeighted(days, weights)
+}
+
+type DistributionConfig struct {
+	// Customers skews who places orders, e.g. zipf for repeat customers.
+	Customers DistributionSpec `yaml:"customers"`
+	// ServiceCenters skews the size of centers, both staff and orders are
+	// spread over them by CenterWeights.
+	ServiceCenters DistributionSpec `yaml:"service_centers"`
+	// Services maps service names to popularity, unlisted names weigh 1.
+	Services    map[string]float64 `yaml:"services"`
+	Seasonality Seasonality        `yaml:"seasonality"`
+
+	mu      sync.Mutex
+	centers map[int]float64
+}
+
+func DefaultDistributionConfig() *DistributionConfig {
+	return &DistributionConfig{
+		Customers:      DistributionSpec{Kind: DistributionUniform},
+		ServiceCenters: DistributionSpec{Kind: DistributionUniform},
+		Services:       map[string]float64{},
+	}
+}
+
+// LoadDistributionConfig reads a YAML file. It is decoded without viper,
+// which lowercases map keys and so would lose the service names.
+func LoadDistributionConfig(path string) (*DistributionConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read distributions %s: %w", path, err)
+	}
+	defer f.Close()
+
+	cfg := DefaultDistributionConfig()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to decode distributions, %v", err)
+	}
+
+	for name, spec := range map[string]DistributionSpec{"customers": cfg.Customers, "service_centers": cfg.ServiceCenters} {
+		if err := spec.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := cfg.Seasonality.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// CenterWeights returns the weights of service centers following the
+// ServiceCenters distribution over a random ranking. A center keeps the
+// weight it got first, so orders go where the staff was placed.
+func (c *DistributionConfig) CenterWeights(ids []int) []float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.centers == nil {
+		c.centers = make(map[int]float64)
+	}
+
+	var unseen []int
+	for _, id := range ids {
+		if _, ok := c.centers[id]; !ok {
+			unseen = append(unseen, id)
+		}
+	}
+	Rand.Shuffle(len(unseen), func(i, j int) { unseen[i], unseen[j] = unseen[j], unseen[i] })
+	for i, weight := range c.ServiceCenters.Generate(len(unseen)) {
+		c.centers[unseen[i]] = weight
+	}
+
+	weights := make([]float64, len(ids))
+	for i, id := range ids {
+		weights[i] = c.centers[id]
+	}
+	return weights
+}
+
+// WeightedIDs preloads the ids of a source and hands out weights following
+// the distribution over a random but, per TTL, stable ranking of them.
+type WeightedIDs struct {
+	dist    DistributionSpec
+	pickers *ttlCache[*Weighted[int]]
+}
+
+func NewWeightedIDs(dist DistributionSpec, ttl time.Duration) *WeightedIDs {
+	return &WeightedIDs{dist: dist, pickers: newTTLCache[*Weighted[int]](ttl)}
+}
+
+func (w *WeightedIDs) Name() string { return "weighted-" + w.dist.Kind }
+
+func (w *WeightedIDs) Invalidate() { w.pickers.clear() }
+
+func (w *WeightedIDs) Sample(ctx context.Context, db *pgxpool.Pool, src *SampleSource, k int) ([]int, error) {
+	picker, err := w.pickers.get(src.Key(), func() (*Weighted[int], error) {
+		ids, err := queryIDs(ctx, db, src.Select())
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("no rows")
+		}
+		Rand.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+		return NewWeighted(ids, w.dist.Generate(len(ids)))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to preload ids: %w", err)
+	}
+
+	if k == 1 {
+		return []int{picker.Pick()}, nil
+	}
+	return picker.PickN(k), nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadDistributionConfigKeepsServiceNames(t *testing.T) {
+	cfg, err := LoadDistributionConfig(filepath.Join("..", "..", "config", "distributions.example.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		weight float64
+	}{
+		{"Замена масла", 8},
+		{"Шиномонтаж", 6},
+		{"Ремонт двигателя", 0.5},
+	}
+	for _, tt := range tests {
+		if got, ok := cfg.Services[tt.name]; !ok || got != tt.weight {
+			t.Errorf("weight of %q is %v (set %v), want %v", tt.name, got, ok, tt.weight)
+		}
+	}
+	if cfg.Customers.Kind != DistributionZipf || cfg.Customers.S != 1.2 || cfg.ServiceCenters.Kind != DistributionPareto {
+		t.Errorf("distributions %+v and %+v, want zipf 1.2 and pareto", cfg.Customers, cfg.ServiceCenters)
+	}
+	if len(cfg.Seasonality.Monthly) != 12 || len(cfg.Seasonality.Weekday) != 7 {
+		t.Errorf("seasonality %+v, want 12 monthly and 7 weekday weights", cfg.Seasonality)
+	}
+}
+
+func TestLoadDistributionConfigRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"unknown field", "customer:\n  kind: zipf\n"},
+		{"unknown kind", "customers:\n  kind: normal\n"},
+		{"short seasonality", "seasonality:\n  weekday: [1, 2]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "distributions.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadDistributionConfig(path); err == nil {
+				t.Error("loaded, want an error")
+			}
+		})
+	}
+}
+
+func TestCenterWeightsAreStable(t *testing.T) {
+	cfg := DefaultDistributionConfig()
+	cfg.ServiceCenters = DistributionSpec{Kind: DistributionWeights, Weights: []float64{7, 3}}
+
+	first := cfg.CenterWeights([]int{10, 20})
+	sorted := slices.Clone(first)
+	slices.Sort(sorted)
+	if !slices.Equal(sorted, []float64{3, 7}) {
+		t.Fatalf("weights %v, want the configured 3 and 7", first)
+	}
+	again := cfg.CenterWeights([]int{20, 10})
+	if again[0] != first[1] || again[1] != first[0] {
+		t.Errorf("weights %v after %v, want each center to keep its weight", again, first)
+	}
+}